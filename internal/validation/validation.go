@@ -0,0 +1,231 @@
+// Package validation implements the offline format, length and checksum checks
+// used by the Validate methods on request parameters.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a single invalid request parameter. Field is the dotted
+// JSON path of the parameter, for example `creditor.address.unstructured.line1`.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Error is returned by Validate methods and collects every invalid parameter
+// found in a request.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 1 {
+		return "invalid parameter " + e.Fields[0].Error()
+	}
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("%d invalid parameters: %s", len(e.Fields), strings.Join(msgs, "; "))
+}
+
+// Field returns the error recorded for the given path, if any.
+func (e *Error) Field(path string) (FieldError, bool) {
+	for _, f := range e.Fields {
+		if f.Field == path {
+			return f, true
+		}
+	}
+	return FieldError{}, false
+}
+
+// Collector accumulates field errors while walking a request.
+type Collector struct {
+	fields []FieldError
+}
+
+// Addf records an error for the given path.
+func (c *Collector) Addf(path string, format string, args ...any) {
+	c.fields = append(c.fields, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check records err against the given path if it is non-nil.
+func (c *Collector) Check(path string, err error) {
+	if err != nil {
+		c.fields = append(c.fields, FieldError{Field: path, Message: err.Error()})
+	}
+}
+
+// Err returns an [*Error] if any errors were recorded, and nil otherwise.
+func (c *Collector) Err() error {
+	if len(c.fields) == 0 {
+		return nil
+	}
+	return &Error{Fields: c.fields}
+}
+
+// Join builds a dotted path from a prefix and a child name.
+func Join(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// Index builds the path of an array element.
+func Index(prefix string, i int) string {
+	return fmt.Sprintf("%s[%d]", prefix, i)
+}
+
+// Length checks that s is between min and max characters long, inclusive.
+func Length(s string, min int, max int) error {
+	n := len([]rune(s))
+	if n < min {
+		if min == 1 {
+			return errors.New("must not be blank")
+		}
+		return fmt.Errorf("must be at least %d characters", min)
+	}
+	if max > 0 && n > max {
+		return fmt.Errorf("must be at most %d characters, got %d", max, n)
+	}
+	return nil
+}
+
+// PrintableASCII checks that s only contains printable ASCII characters, which is
+// all that the ACH and Fedwire networks will carry.
+func PrintableASCII(s string) error {
+	for i, r := range s {
+		if r < 0x20 || r > 0x7e {
+			return fmt.Errorf("contains unsupported character %q at position %d; only printable ASCII is allowed", r, i)
+		}
+	}
+	return nil
+}
+
+// RoutingNumber checks that s is a nine digit American Bankers' Association
+// routing transit number with a valid check digit.
+func RoutingNumber(s string) error {
+	if len(s) != 9 {
+		return fmt.Errorf("must be 9 digits, got %d characters", len(s))
+	}
+	weights := [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return errors.New("must only contain digits")
+		}
+		sum += int(c-'0') * weights[i]
+	}
+	if sum%10 != 0 {
+		return errors.New("has an invalid check digit")
+	}
+	return nil
+}
+
+// AccountNumber checks that s is a domestic bank account number of at most max
+// characters. ACH entries carry at most 17 characters and Fedwire messages at
+// most 34.
+func AccountNumber(s string, max int) error {
+	if err := Length(s, 1, max); err != nil {
+		return err
+	}
+	for _, r := range s {
+		if !isDigit(r) && !isUpper(r) && !(r >= 'a' && r <= 'z') && r != '-' {
+			return fmt.Errorf("contains unsupported character %q; only letters, digits and hyphens are allowed", r)
+		}
+	}
+	return nil
+}
+
+// BIC checks that s is an 8 or 11 character ISO 9362 bank identification code.
+func BIC(s string) error {
+	if len(s) != 8 && len(s) != 11 {
+		return fmt.Errorf("must be 8 or 11 characters, got %d", len(s))
+	}
+	for i, r := range s {
+		switch {
+		case i < 4 && !isUpper(r):
+			return errors.New("must start with a four letter institution code")
+		case i >= 4 && i < 6 && !isUpper(r):
+			return errors.New("must contain a two letter country code in positions 5 and 6")
+		case i >= 6 && !isUpper(r) && !isDigit(r):
+			return errors.New("must only contain upper-case letters and digits")
+		}
+	}
+	return nil
+}
+
+// Country checks that s looks like an ISO 3166-1 alpha-2 country code.
+func Country(s string) error {
+	if len(s) != 2 || !isUpper(rune(s[0])) || !isUpper(rune(s[1])) {
+		return errors.New("must be a two letter upper-case ISO 3166-1 country code")
+	}
+	return nil
+}
+
+// LooksLikeIBAN reports whether s is shaped like an International Bank Account
+// Number, that is two letters followed by two check digits.
+func LooksLikeIBAN(s string) bool {
+	s = compactIBAN(s)
+	return len(s) >= 4 && isUpper(rune(s[0])) && isUpper(rune(s[1])) && isDigit(rune(s[2])) && isDigit(rune(s[3]))
+}
+
+// IBAN checks the country-specific length and the ISO 13616 mod-97 checksum of
+// an International Bank Account Number. Spaces are ignored.
+func IBAN(s string) error {
+	s = compactIBAN(s)
+	if !LooksLikeIBAN(s) {
+		return errors.New("must start with a two letter country code and two check digits")
+	}
+	if want, ok := ibanLengths[s[:2]]; ok && len(s) != want {
+		return fmt.Errorf("must be %d characters for country %s, got %d", want, s[:2], len(s))
+	}
+	if len(s) > 34 {
+		return fmt.Errorf("must be at most 34 characters, got %d", len(s))
+	}
+	rem := 0
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case isDigit(r):
+			rem = (rem*10 + int(r-'0')) % 97
+		case isUpper(r):
+			rem = (rem*100 + int(r-'A'+10)) % 97
+		default:
+			return fmt.Errorf("contains unsupported character %q", r)
+		}
+	}
+	if rem != 1 {
+		return errors.New("has an invalid checksum")
+	}
+	return nil
+}
+
+func compactIBAN(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+func isUpper(r rune) bool { return r >= 'A' && r <= 'Z' }
+
+// ibanLengths is the registered IBAN length for each participating country.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24,
+	"ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24, "SC": 31,
+	"SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24,
+	"TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
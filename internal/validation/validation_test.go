@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoutingNumber(t *testing.T) {
+	cases := map[string]bool{
+		"101050001": true,
+		"021000021": true,
+		"101050002": false,
+		"10105000":  false,
+		"10105000a": false,
+	}
+	for input, valid := range cases {
+		if err := RoutingNumber(input); (err == nil) != valid {
+			t.Errorf("RoutingNumber(%q) = %v, expected valid=%v", input, err, valid)
+		}
+	}
+}
+
+func TestBIC(t *testing.T) {
+	cases := map[string]bool{
+		"DEUTDEFF":    true,
+		"DEUTDEFF500": true,
+		"deutdeff":    false,
+		"DEUTDEF":     false,
+		"DEU1DEFF":    false,
+		"DEUTD1FF":    false,
+	}
+	for input, valid := range cases {
+		if err := BIC(input); (err == nil) != valid {
+			t.Errorf("BIC(%q) = %v, expected valid=%v", input, err, valid)
+		}
+	}
+}
+
+func TestIBAN(t *testing.T) {
+	cases := map[string]bool{
+		"GB82WEST12345698765432":      true,
+		"GB82 WEST 1234 5698 7654 32": true,
+		"DE89370400440532013000":      true,
+		"GB82WEST12345698765431":      false,
+		"GB82WEST1234569876543":       false,
+		"1234":                        false,
+	}
+	for input, valid := range cases {
+		if err := IBAN(input); (err == nil) != valid {
+			t.Errorf("IBAN(%q) = %v, expected valid=%v", input, err, valid)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := &Collector{}
+	if c.Err() != nil {
+		t.Fatalf("expected no error from an empty collector")
+	}
+	c.Addf(Join("creditor", "name"), "is required")
+	c.Check(Join(Index("entries", 1), "payment_related_information"), Length("", 1, 80))
+	c.Check("amount", nil)
+
+	var verr *Error
+	if !errors.As(c.Err(), &verr) {
+		t.Fatalf("expected *Error, got %T", c.Err())
+	}
+	if len(verr.Fields) != 2 {
+		t.Fatalf("expected 2 field errors, got %d", len(verr.Fields))
+	}
+	if _, ok := verr.Field("entries[1].payment_related_information"); !ok {
+		t.Errorf("expected an error for entries[1].payment_related_information, got %s", verr)
+	}
+}
@@ -0,0 +1,326 @@
+// Package activity describes Transactions for customer-facing activity feeds.
+//
+// A Transaction's source is one of dozens of categories, each with its own
+// fields. [Describe] reduces any of them to a [TransactionSummary]: a
+// description, the counterparty, the rail, whether money came in or went out,
+// and the object the Transaction belongs to.
+//
+// Descriptions are built from a [Message], a key and named arguments, so they
+// can be translated. [English] holds the default wording; pass your own
+// templates to [Templates], or any [Localizer], to [New].
+//
+//	d := activity.New(activity.Templates(map[string]string{
+//		"card_settlement": "Achat chez {counterparty}",
+//	}))
+//	summary := d.Describe(tx)
+package activity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+)
+
+// Direction is whether a Transaction added money to the Account or took it
+// out.
+type Direction string
+
+const (
+	DirectionCredit Direction = "credit"
+	DirectionDebit  Direction = "debit"
+)
+
+// Rail is the network a Transaction moved over.
+type Rail string
+
+const (
+	RailACH              Rail = "ach"
+	RailWire             Rail = "wire"
+	RailRealTimePayments Rail = "real_time_payments"
+	RailFednow           Rail = "fednow"
+	RailCheck            Rail = "check"
+	RailCard             Rail = "card"
+	RailCardPush         Rail = "card_push"
+	RailAccountTransfer  Rail = "account_transfer"
+	RailSwift            Rail = "swift"
+	RailBlockchain       Rail = "blockchain"
+	// Interest, fees, revenue and adjustments made by Increase.
+	RailInternal Rail = "internal"
+)
+
+// TransactionSummary is a uniform description of a Transaction.
+type TransactionSummary struct {
+	TransactionID string
+	Category      increase.TransactionSourceCategory
+	// The localized description of the Transaction.
+	Description string
+	// The other party, such as a merchant, an originating company or another
+	// Account's ID, or "" when the source does not name one.
+	Counterparty string
+	// Empty for categories without a rail.
+	Rail      Rail
+	Direction Direction
+	// In the minor unit of the currency, negative for debits.
+	Amount   int64
+	Currency string
+	// The ID of the transfer, card payment, check deposit or other object the
+	// Transaction belongs to, or "".
+	RelatedObjectID string
+	// The message Description was localized from.
+	Message Message
+}
+
+// Message is a description before localization. Args always include
+// "counterparty" when the summary has one.
+type Message struct {
+	// Such as "card_settlement" or "inbound_ach_transfer.debit". Keys ending
+	// in ".unnamed" are used when the counterparty is unknown.
+	Key  string
+	Args map[string]string
+}
+
+// Localizer turns a message into a description.
+type Localizer func(m Message) string
+
+// Describer describes Transactions.
+type Describer struct {
+	// Defaults to the English templates.
+	Localize Localizer
+}
+
+// New returns a describer that localizes descriptions with localize, which may
+// be nil for English.
+func New(localize Localizer) *Describer {
+	if localize == nil {
+		localize = Templates(nil)
+	}
+	return &Describer{Localize: localize}
+}
+
+var english = New(nil)
+
+// Describe summarizes a Transaction in English.
+func Describe(tx increase.Transaction) TransactionSummary {
+	return english.Describe(tx)
+}
+
+// Describe summarizes a Transaction.
+func (d *Describer) Describe(tx increase.Transaction) TransactionSummary {
+	s := TransactionSummary{
+		TransactionID: tx.ID,
+		Category:      tx.Source.Category,
+		Direction:     DirectionCredit,
+		Amount:        tx.Amount,
+		Currency:      string(tx.Currency),
+	}
+	if tx.Amount < 0 {
+		s.Direction = DirectionDebit
+	}
+	m := source(tx, &s)
+	if m.Args == nil {
+		m.Args = map[string]string{}
+	}
+	if s.Counterparty != "" {
+		m.Args["counterparty"] = s.Counterparty
+	}
+	s.Message = m
+	localize := d.Localize
+	if localize == nil {
+		localize = Templates(nil)
+	}
+	s.Description = localize(m)
+	if s.Description == "" {
+		s.Description = tx.Description
+	}
+	return s
+}
+
+// source fills the rail, counterparty and related object of a summary and
+// returns its message.
+func source(tx increase.Transaction, s *TransactionSummary) Message {
+	src := tx.Source
+	debit := s.Direction == DirectionDebit
+	switch src.Category {
+	case increase.TransactionSourceCategoryAccountTransferIntention:
+		t := src.AccountTransferIntention
+		s.Rail, s.RelatedObjectID = RailAccountTransfer, t.TransferID
+		if debit {
+			s.Counterparty = t.DestinationAccountID
+			return named("account_transfer_intention.debit", s.Counterparty, "memo", t.Description)
+		}
+		s.Counterparty = t.SourceAccountID
+		return named("account_transfer_intention.credit", s.Counterparty, "memo", t.Description)
+	case increase.TransactionSourceCategoryACHTransferIntention:
+		t := src.ACHTransferIntention
+		s.Rail, s.RelatedObjectID = RailACH, t.TransferID
+		return message("ach_transfer_intention", "last4", last4(t.AccountNumber), "descriptor", t.StatementDescriptor)
+	case increase.TransactionSourceCategoryACHTransferRejection:
+		s.Rail, s.RelatedObjectID = RailACH, src.ACHTransferRejection.TransferID
+		return message("ach_transfer_rejection")
+	case increase.TransactionSourceCategoryACHTransferReturn:
+		t := src.ACHTransferReturn
+		s.Rail, s.RelatedObjectID = RailACH, t.TransferID
+		return reason("ach_transfer_return", string(t.ReturnReasonCode))
+	case increase.TransactionSourceCategoryCashbackPayment:
+		s.Rail, s.RelatedObjectID = RailCard, src.CashbackPayment.AccruedOnCardID
+		return message("cashback_payment", "period", month(src.CashbackPayment.PeriodStart))
+	case increase.TransactionSourceCategoryCardDisputeAcceptance:
+		s.Rail, s.RelatedObjectID = RailCard, src.CardDisputeAcceptance.TransactionID
+		return message("card_dispute_acceptance")
+	case increase.TransactionSourceCategoryCardDisputeFinancial:
+		s.Rail, s.RelatedObjectID = RailCard, src.CardDisputeFinancial.TransactionID
+		return message("card_dispute_financial")
+	case increase.TransactionSourceCategoryCardDisputeLoss:
+		s.Rail, s.RelatedObjectID = RailCard, src.CardDisputeLoss.TransactionID
+		return message("card_dispute_loss")
+	case increase.TransactionSourceCategoryCardRefund:
+		t := src.CardRefund
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailCard, t.CardPaymentID, strings.TrimSpace(t.MerchantName)
+		return named("card_refund", s.Counterparty, "city", t.MerchantCity)
+	case increase.TransactionSourceCategoryCardSettlement:
+		t := src.CardSettlement
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailCard, t.CardPaymentID, strings.TrimSpace(t.MerchantName)
+		return named("card_settlement", s.Counterparty, "city", t.MerchantCity)
+	case increase.TransactionSourceCategoryCardFinancial:
+		t := src.CardFinancial
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailCard, t.CardPaymentID, strings.TrimSpace(t.MerchantDescriptor)
+		return named("card_financial", s.Counterparty, "city", t.MerchantCity)
+	case increase.TransactionSourceCategoryCardRevenuePayment:
+		s.Rail, s.RelatedObjectID = RailInternal, src.CardRevenuePayment.TransactedOnAccountID
+		return message("card_revenue_payment", "period", month(src.CardRevenuePayment.PeriodStart))
+	case increase.TransactionSourceCategoryCheckDepositAcceptance:
+		t := src.CheckDepositAcceptance
+		s.Rail, s.RelatedObjectID = RailCheck, t.CheckDepositID
+		if t.SerialNumber == "" {
+			return message("check_deposit_acceptance.unnumbered")
+		}
+		return message("check_deposit_acceptance", "serial", t.SerialNumber)
+	case increase.TransactionSourceCategoryCheckDepositReturn:
+		t := src.CheckDepositReturn
+		s.Rail, s.RelatedObjectID = RailCheck, t.CheckDepositID
+		return reason("check_deposit_return", string(t.ReturnReason))
+	case increase.TransactionSourceCategoryCheckTransferDeposit:
+		s.Rail, s.RelatedObjectID = RailCheck, src.CheckTransferDeposit.TransferID
+		return message("check_transfer_deposit")
+	case increase.TransactionSourceCategoryFednowTransferAcknowledgement:
+		s.Rail, s.RelatedObjectID = RailFednow, src.FednowTransferAcknowledgement.TransferID
+		return message("fednow_transfer_acknowledgement")
+	case increase.TransactionSourceCategoryFeePayment:
+		s.Rail, s.RelatedObjectID = RailInternal, src.FeePayment.ProgramID
+		return message("fee_payment", "period", month(src.FeePayment.FeePeriodStart))
+	case increase.TransactionSourceCategoryInboundACHTransfer:
+		t := src.InboundACHTransfer
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailACH, t.TransferID, strings.TrimSpace(t.OriginatorCompanyName)
+		key := "inbound_ach_transfer.credit"
+		if debit {
+			key = "inbound_ach_transfer.debit"
+		}
+		return named(key, s.Counterparty, "entry_description", strings.TrimSpace(t.OriginatorCompanyEntryDescription))
+	case increase.TransactionSourceCategoryInboundACHTransferReturnIntention:
+		s.Rail, s.RelatedObjectID = RailACH, src.InboundACHTransferReturnIntention.InboundACHTransferID
+		return message("inbound_ach_transfer_return_intention")
+	case increase.TransactionSourceCategoryInboundCheckDepositReturnIntention:
+		s.Rail, s.RelatedObjectID = RailCheck, src.InboundCheckDepositReturnIntention.InboundCheckDepositID
+		return message("inbound_check_deposit_return_intention")
+	case increase.TransactionSourceCategoryInboundCheckAdjustment:
+		t := src.InboundCheckAdjustment
+		s.Rail, s.RelatedObjectID = RailCheck, t.AdjustedTransactionID
+		return reason("inbound_check_adjustment", string(t.Reason))
+	case increase.TransactionSourceCategoryInboundFednowTransferConfirmation:
+		s.Rail, s.RelatedObjectID = RailFednow, src.InboundFednowTransferConfirmation.TransferID
+		return message("inbound_fednow_transfer_confirmation")
+	case increase.TransactionSourceCategoryInboundRealTimePaymentsTransferConfirmation:
+		t := src.InboundRealTimePaymentsTransferConfirmation
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailRealTimePayments, t.TransferID, strings.TrimSpace(t.DebtorName)
+		return named("inbound_real_time_payments_transfer_confirmation", s.Counterparty, "memo", t.UnstructuredRemittanceInformation)
+	case increase.TransactionSourceCategoryInboundWireReversal:
+		t := src.InboundWireReversal
+		s.Rail, s.RelatedObjectID = RailWire, t.WireTransferID
+		return message("inbound_wire_reversal", "reason", t.ReturnReasonCodeDescription)
+	case increase.TransactionSourceCategoryInboundWireTransfer:
+		t := src.InboundWireTransfer
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailWire, t.TransferID, strings.TrimSpace(t.DebtorName)
+		return named("inbound_wire_transfer", s.Counterparty, "memo", t.UnstructuredRemittanceInformation)
+	case increase.TransactionSourceCategoryInboundWireTransferReversal:
+		s.Rail, s.RelatedObjectID = RailWire, src.InboundWireTransferReversal.InboundWireTransferID
+		return message("inbound_wire_transfer_reversal")
+	case increase.TransactionSourceCategoryInterestPayment:
+		s.Rail, s.RelatedObjectID = RailInternal, src.InterestPayment.AccruedOnAccountID
+		return message("interest_payment", "period", month(src.InterestPayment.PeriodStart))
+	case increase.TransactionSourceCategoryInternalSource:
+		s.Rail = RailInternal
+		return reason("internal_source", string(src.InternalSource.Reason))
+	case increase.TransactionSourceCategoryRealTimePaymentsTransferAcknowledgement:
+		t := src.RealTimePaymentsTransferAcknowledgement
+		s.Rail, s.RelatedObjectID = RailRealTimePayments, t.TransferID
+		return message("real_time_payments_transfer_acknowledgement", "last4", last4(t.AccountNumber), "memo", t.UnstructuredRemittanceInformation)
+	case increase.TransactionSourceCategorySampleFunds:
+		s.Rail, s.Counterparty = RailInternal, strings.TrimSpace(src.SampleFunds.Originator)
+		return named("sample_funds", s.Counterparty)
+	case increase.TransactionSourceCategoryWireTransferIntention:
+		t := src.WireTransferIntention
+		s.Rail, s.RelatedObjectID = RailWire, t.TransferID
+		return message("wire_transfer_intention", "last4", last4(t.AccountNumber), "memo", t.MessageToRecipient)
+	case increase.TransactionSourceCategorySwiftTransferIntention:
+		s.Rail, s.RelatedObjectID = RailSwift, src.SwiftTransferIntention.TransferID
+		return message("swift_transfer_intention")
+	case increase.TransactionSourceCategorySwiftTransferReturn:
+		s.Rail, s.RelatedObjectID = RailSwift, src.SwiftTransferReturn.TransferID
+		return message("swift_transfer_return")
+	case increase.TransactionSourceCategoryCardPushTransferAcceptance:
+		s.Rail, s.RelatedObjectID = RailCardPush, src.CardPushTransferAcceptance.TransferID
+		return message("card_push_transfer_acceptance")
+	case increase.TransactionSourceCategoryAccountRevenuePayment:
+		s.Rail, s.RelatedObjectID = RailInternal, src.AccountRevenuePayment.AccruedOnAccountID
+		return message("account_revenue_payment", "period", month(src.AccountRevenuePayment.PeriodStart))
+	case increase.TransactionSourceCategoryBlockchainOnrampTransferIntention:
+		t := src.BlockchainOnrampTransferIntention
+		s.Rail, s.RelatedObjectID, s.Counterparty = RailBlockchain, t.TransferID, t.DestinationBlockchainAddress
+		return named("blockchain_onramp_transfer_intention", s.Counterparty)
+	case increase.TransactionSourceCategoryBlockchainOfframpTransferSettlement:
+		s.Rail, s.RelatedObjectID = RailBlockchain, src.BlockchainOfframpTransferSettlement.TransferID
+		return message("blockchain_offramp_transfer_settlement")
+	}
+	return message("other", "description", tx.Description)
+}
+
+// message returns a message with arguments given as name, value pairs. Empty
+// values are left out.
+func message(key string, args ...string) Message {
+	m := Message{Key: key, Args: map[string]string{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		if v := strings.TrimSpace(args[i+1]); v != "" {
+			m.Args[args[i]] = v
+		}
+	}
+	return m
+}
+
+// named returns the message of key, or of its ".unnamed" variant when the
+// counterparty is unknown.
+func named(key, counterparty string, args ...string) Message {
+	if counterparty == "" {
+		key += ".unnamed"
+	}
+	return message(key, args...)
+}
+
+// reason returns a message with a reason code and its words.
+func reason(key, code string) Message {
+	return message(key, "reason_code", code, "reason", strings.ReplaceAll(code, "_", " "))
+}
+
+func last4(accountNumber string) string {
+	if len(accountNumber) > 4 {
+		return accountNumber[len(accountNumber)-4:]
+	}
+	return accountNumber
+}
+
+func month(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("January 2006")
+}
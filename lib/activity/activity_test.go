@@ -0,0 +1,98 @@
+package activity_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/activity"
+)
+
+func transaction(t *testing.T, body string) increase.Transaction {
+	t.Helper()
+	var tx increase.Transaction
+	if err := json.Unmarshal([]byte(body), &tx); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return tx
+}
+
+func TestDescribe(t *testing.T) {
+	for _, tc := range []struct {
+		tx                                       string
+		description, counterparty, relatedObject string
+		rail                                     activity.Rail
+		direction                                activity.Direction
+	}{
+		{
+			`{"id": "tx_1", "amount": -2550, "currency": "USD", "source": {"category": "card_settlement", "card_settlement": {"card_payment_id": "card_payment_1", "merchant_name": "Coffee Shop ", "merchant_city": "New York"}}}`,
+			"Purchase at Coffee Shop", "Coffee Shop", "card_payment_1", activity.RailCard, activity.DirectionDebit,
+		},
+		{
+			`{"id": "tx_2", "amount": -2550, "source": {"category": "card_settlement", "card_settlement": {"card_payment_id": "card_payment_2"}}}`,
+			"Card purchase", "", "card_payment_2", activity.RailCard, activity.DirectionDebit,
+		},
+		{
+			`{"id": "tx_3", "amount": -10000, "source": {"category": "inbound_ach_transfer", "inbound_ach_transfer": {"transfer_id": "inbound_ach_transfer_1", "originator_company_name": "ACME UTILITIES", "originator_company_entry_description": "BILLPAY"}}}`,
+			"ACH debit by ACME UTILITIES: BILLPAY", "ACME UTILITIES", "inbound_ach_transfer_1", activity.RailACH, activity.DirectionDebit,
+		},
+		{
+			`{"id": "tx_4", "amount": 10000, "source": {"category": "inbound_ach_transfer", "inbound_ach_transfer": {"transfer_id": "inbound_ach_transfer_2", "originator_company_name": "ACME CORP"}}}`,
+			"ACH deposit from ACME CORP", "ACME CORP", "inbound_ach_transfer_2", activity.RailACH, activity.DirectionCredit,
+		},
+		{
+			`{"id": "tx_5", "amount": -5000, "source": {"category": "account_transfer_intention", "account_transfer_intention": {"transfer_id": "account_transfer_1", "source_account_id": "account_1", "destination_account_id": "account_2", "description": "Savings"}}}`,
+			"Transfer to account_2: Savings", "account_2", "account_transfer_1", activity.RailAccountTransfer, activity.DirectionDebit,
+		},
+		{
+			`{"id": "tx_6", "amount": 5000, "source": {"category": "ach_transfer_return", "ach_transfer_return": {"transfer_id": "ach_transfer_1", "return_reason_code": "insufficient_fund"}}}`,
+			"ACH transfer returned (insufficient fund)", "", "ach_transfer_1", activity.RailACH, activity.DirectionCredit,
+		},
+		{
+			`{"id": "tx_7", "amount": -100, "source": {"category": "ach_transfer_intention", "ach_transfer_intention": {"transfer_id": "ach_transfer_2", "account_number": "987654321"}}}`,
+			"ACH transfer to account ending in 4321", "", "ach_transfer_2", activity.RailACH, activity.DirectionDebit,
+		},
+		{
+			`{"id": "tx_8", "amount": 125, "source": {"category": "interest_payment", "interest_payment": {"accrued_on_account_id": "account_1", "period_start": "2024-03-01T00:00:00Z"}}}`,
+			"Interest for March 2024", "", "account_1", activity.RailInternal, activity.DirectionCredit,
+		},
+		{
+			`{"id": "tx_9", "amount": -1000, "description": "Manual adjustment", "source": {"category": "other"}}`,
+			"Manual adjustment", "", "", "", activity.DirectionDebit,
+		},
+	} {
+		tx := transaction(t, tc.tx)
+		s := activity.Describe(tx)
+		if s.TransactionID != tx.ID || s.Amount != tx.Amount || s.Category != tx.Source.Category {
+			t.Errorf("%s: unexpected summary %+v", tx.ID, s)
+		}
+		if s.Description != tc.description {
+			t.Errorf("%s: expected description %q, got %q", tx.ID, tc.description, s.Description)
+		}
+		if s.Counterparty != tc.counterparty || s.RelatedObjectID != tc.relatedObject || s.Rail != tc.rail || s.Direction != tc.direction {
+			t.Errorf("%s: unexpected summary %+v", tx.ID, s)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	tx := transaction(t, `{"id": "tx_1", "amount": -2550, "description": "Card", "source": {"category": "card_settlement", "card_settlement": {"merchant_name": "Café", "merchant_city": "Paris"}}}`)
+	d := activity.New(activity.Templates(map[string]string{
+		"card_settlement": "Achat chez {counterparty}[ à {city}]",
+	}))
+	if s := d.Describe(tx); s.Description != "Achat chez Café à Paris" || s.Message.Key != "card_settlement" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	// Keys missing from the templates fall back to English.
+	interest := transaction(t, `{"id": "tx_2", "amount": 125, "source": {"category": "interest_payment", "interest_payment": {}}}`)
+	if s := d.Describe(interest); s.Description != "Interest" {
+		t.Errorf("expected %q, got %q", "Interest", s.Description)
+	}
+
+	// Empty localizations fall back to the Transaction's description.
+	d = activity.New(func(m activity.Message) string { return "" })
+	if s := d.Describe(tx); s.Description != "Card" {
+		t.Errorf("expected %q, got %q", "Card", s.Description)
+	}
+}
@@ -0,0 +1,124 @@
+package activity
+
+import "strings"
+
+// English is the default wording of every message. In templates, {name} is
+// replaced by an argument, and a [bracketed] part is left out unless all of
+// its arguments are present.
+var English = map[string]string{
+	"account_transfer_intention.debit":                         "Transfer to {counterparty}[: {memo}]",
+	"account_transfer_intention.debit.unnamed":                 "Transfer out[: {memo}]",
+	"account_transfer_intention.credit":                        "Transfer from {counterparty}[: {memo}]",
+	"account_transfer_intention.credit.unnamed":                "Transfer in[: {memo}]",
+	"ach_transfer_intention":                                   "ACH transfer[ to account ending in {last4}]",
+	"ach_transfer_rejection":                                   "ACH transfer rejected",
+	"ach_transfer_return":                                      "ACH transfer returned[ ({reason})]",
+	"cashback_payment":                                         "Cashback[ for {period}]",
+	"card_dispute_acceptance":                                  "Card dispute accepted",
+	"card_dispute_financial":                                   "Card dispute adjustment",
+	"card_dispute_loss":                                        "Card dispute lost",
+	"card_refund":                                              "Refund from {counterparty}",
+	"card_refund.unnamed":                                      "Card refund",
+	"card_settlement":                                          "Purchase at {counterparty}",
+	"card_settlement.unnamed":                                  "Card purchase",
+	"card_financial":                                           "Card transaction at {counterparty}",
+	"card_financial.unnamed":                                   "Card transaction",
+	"card_revenue_payment":                                     "Card revenue[ for {period}]",
+	"check_deposit_acceptance":                                 "Deposit of check #{serial}",
+	"check_deposit_acceptance.unnumbered":                      "Check deposit",
+	"check_deposit_return":                                     "Deposited check returned[ ({reason})]",
+	"check_transfer_deposit":                                   "Check cashed",
+	"fednow_transfer_acknowledgement":                          "FedNow transfer sent",
+	"fee_payment":                                              "Fee[ for {period}]",
+	"inbound_ach_transfer.credit":                              "ACH deposit from {counterparty}[: {entry_description}]",
+	"inbound_ach_transfer.credit.unnamed":                      "ACH deposit[: {entry_description}]",
+	"inbound_ach_transfer.debit":                               "ACH debit by {counterparty}[: {entry_description}]",
+	"inbound_ach_transfer.debit.unnamed":                       "ACH debit[: {entry_description}]",
+	"inbound_ach_transfer_return_intention":                    "ACH transfer returned to sender",
+	"inbound_check_deposit_return_intention":                   "Check returned to depositor",
+	"inbound_check_adjustment":                                 "Check adjustment[ ({reason})]",
+	"inbound_fednow_transfer_confirmation":                     "FedNow transfer received",
+	"inbound_real_time_payments_transfer_confirmation":         "Real-Time Payment from {counterparty}",
+	"inbound_real_time_payments_transfer_confirmation.unnamed": "Real-Time Payment received",
+	"inbound_wire_reversal":                                    "Wire transfer reversed[ ({reason})]",
+	"inbound_wire_transfer":                                    "Wire from {counterparty}",
+	"inbound_wire_transfer.unnamed":                            "Wire received",
+	"inbound_wire_transfer_reversal":                           "Incoming wire reversed",
+	"interest_payment":                                         "Interest[ for {period}]",
+	"internal_source":                                          "Adjustment[ ({reason})]",
+	"real_time_payments_transfer_acknowledgement":              "Real-Time Payment[ to account ending in {last4}]",
+	"sample_funds":                                             "Sample funds from {counterparty}",
+	"sample_funds.unnamed":                                     "Sample funds",
+	"wire_transfer_intention":                                  "Wire[ to account ending in {last4}]",
+	"swift_transfer_intention":                                 "International wire sent",
+	"swift_transfer_return":                                    "International wire returned",
+	"card_push_transfer_acceptance":                            "Card push transfer",
+	"account_revenue_payment":                                  "Account revenue[ for {period}]",
+	"blockchain_onramp_transfer_intention":                     "Transfer to blockchain address {counterparty}",
+	"blockchain_onramp_transfer_intention.unnamed":             "Blockchain transfer sent",
+	"blockchain_offramp_transfer_settlement":                   "Blockchain transfer received",
+	"other":                                                    "{description}",
+}
+
+// Templates returns a Localizer that formats messages with templates, written
+// like those of [English], falling back to English for keys it lacks.
+func Templates(templates map[string]string) Localizer {
+	return func(m Message) string {
+		t, ok := templates[m.Key]
+		if !ok {
+			t = English[m.Key]
+		}
+		return Format(t, m.Args)
+	}
+}
+
+// Format expands a template with args. An argument missing outside brackets
+// expands to "".
+func Format(template string, args map[string]string) string {
+	var b strings.Builder
+	for template != "" {
+		open := strings.IndexByte(template, '[')
+		if open == -1 {
+			b.WriteString(expand(template, args, nil))
+			break
+		}
+		b.WriteString(expand(template[:open], args, nil))
+		end := strings.IndexByte(template[open:], ']')
+		if end == -1 {
+			b.WriteString(expand(template[open:], args, nil))
+			break
+		}
+		missing := false
+		part := expand(template[open+1:open+end], args, &missing)
+		if !missing {
+			b.WriteString(part)
+		}
+		template = template[open+end+1:]
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// expand replaces the {placeholders} of s, recording in missing whether any
+// argument was absent.
+func expand(s string, args map[string]string, missing *bool) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(s, '{')
+		if open == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := strings.IndexByte(s[open:], '}')
+		if end == -1 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:open])
+		v, ok := args[s[open+1:open+end]]
+		if !ok && missing != nil {
+			*missing = true
+		}
+		b.WriteString(v)
+		s = s[open+end+1:]
+	}
+}
@@ -0,0 +1,328 @@
+// Package approvals drives a maker-checker flow for transfers created with
+// `RequireApproval`.
+//
+// [Approvals.Pending] lists the transfers waiting for approval across every
+// outbound rail. [Approvals.Approve] records an approver's sign-off after
+// checking it against a [Policy], and approves the transfer in Increase once
+// the required number of distinct approvers have signed off.
+// [Approvals.Cancel] cancels a transfer. Every decision, including refused
+// ones, is recorded in an [AuditLog].
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+var (
+	// ErrNotPending is returned when a transfer is no longer pending approval.
+	ErrNotPending = errors.New("approvals: transfer is not pending approval")
+	// ErrSelfApproval is returned when the creator of a transfer approves it.
+	ErrSelfApproval = errors.New("approvals: the creator of a transfer cannot approve it")
+	// ErrAlreadyApproved is returned when an approver signs off twice.
+	ErrAlreadyApproved = errors.New("approvals: approver has already approved this transfer")
+	// ErrOutsideWindow is returned outside the policy's approval windows.
+	ErrOutsideWindow = errors.New("approvals: outside of the approval window")
+)
+
+// Tier requires a number of approvals for transfers of at least an amount.
+type Tier struct {
+	// The amount in USD cents.
+	MinAmount int64
+	Approvals int
+}
+
+// Window is a time of day during which transfers may be approved.
+type Window struct {
+	// The days the window applies to. Empty means every day.
+	Days []time.Weekday
+	// The start and end of the window as offsets from midnight. End is
+	// exclusive.
+	Start, End time.Duration
+}
+
+func (w Window) contains(t time.Time) bool {
+	if len(w.Days) > 0 {
+		found := false
+		for _, d := range w.Days {
+			found = found || d == t.Weekday()
+		}
+		if !found {
+			return false
+		}
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	return offset >= w.Start && offset < w.End
+}
+
+// Weekdays returns a window from start to end, Monday to Friday.
+func Weekdays(start, end time.Duration) Window {
+	return Window{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Start: start, End: end}
+}
+
+// Policy decides who may approve a transfer and when.
+type Policy struct {
+	// The approvals required by amount. The tier with the highest MinAmount not
+	// above the absolute transfer amount applies. Transfers below every tier
+	// need one approval, and transfers whose amount is unknown need the
+	// approvals of the highest tier.
+	Tiers []Tier
+	// Allows the creator of a transfer to approve it.
+	AllowSelfApproval bool
+	// Approvals are only accepted inside one of the windows. Empty means any
+	// time.
+	Windows []Window
+	// The time zone of the windows. Defaults to UTC.
+	Location *time.Location
+}
+
+// DualApproval returns a policy that requires two approvers for transfers of
+// at least threshold, and one below it.
+func DualApproval(threshold int64) Policy {
+	return Policy{Tiers: []Tier{{MinAmount: threshold, Approvals: 2}}}
+}
+
+// RequiredApprovals returns the number of approvals the transfer needs.
+func (p Policy) RequiredApprovals(t Transfer) int {
+	amount := t.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	if t.AmountErr != nil {
+		amount = math.MaxInt64
+	}
+	required, best := 1, int64(math.MinInt64)
+	for _, tier := range p.Tiers {
+		if amount >= tier.MinAmount && tier.MinAmount > best {
+			required, best = max(tier.Approvals, 1), tier.MinAmount
+		}
+	}
+	return required
+}
+
+func (p Policy) inWindow(t time.Time) bool {
+	if len(p.Windows) == 0 {
+		return true
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	for _, w := range p.Windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Status is the approval state of a transfer.
+type Status struct {
+	Transfer Transfer
+	// The distinct approvers who have signed off, in order.
+	Approvers []string
+	Required  int
+	// Whether the transfer was approved in Increase by this call.
+	Approved bool
+}
+
+// Approvals applies a policy to transfers pending approval.
+type Approvals struct {
+	client *increase.Client
+	audit  AuditLog
+	policy Policy
+	// Overrides the policy per rail.
+	RailPolicies map[Rail]Policy
+	now          func() time.Time
+}
+
+// New returns an Approvals that applies the policy and records to the audit
+// log.
+func New(client *increase.Client, audit AuditLog, policy Policy) *Approvals {
+	return &Approvals{client: client, audit: audit, policy: policy, now: time.Now}
+}
+
+// PolicyFor returns the policy that applies to a transfer.
+func (a *Approvals) PolicyFor(t Transfer) Policy {
+	if p, ok := a.RailPolicies[t.Rail]; ok {
+		return p
+	}
+	return a.policy
+}
+
+// Pending lists transfers pending approval on the given rails, or on every
+// rail if none are given, oldest first.
+func (a *Approvals) Pending(ctx context.Context, only []Rail, opts ...option.RequestOption) ([]Transfer, error) {
+	if len(only) == 0 {
+		only = Rails
+	}
+	var res []Transfer
+	for _, r := range only {
+		impl, err := lookup(r)
+		if err != nil {
+			return nil, err
+		}
+		transfers, err := impl.pending(ctx, a.client, opts)
+		if err != nil {
+			return nil, fmt.Errorf("approvals: listing %s transfers: %w", r, err)
+		}
+		res = append(res, transfers...)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].CreatedAt.Before(res[j].CreatedAt) })
+	return res, nil
+}
+
+// Get retrieves a transfer.
+func (a *Approvals) Get(ctx context.Context, r Rail, transferID string, opts ...option.RequestOption) (Transfer, error) {
+	impl, err := lookup(r)
+	if err != nil {
+		return Transfer{}, err
+	}
+	return impl.get(ctx, a.client, transferID, opts)
+}
+
+// Status returns the approvals collected for a transfer.
+func (a *Approvals) Status(ctx context.Context, r Rail, transferID string, opts ...option.RequestOption) (*Status, error) {
+	t, err := a.Get(ctx, r, transferID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	approvers, err := a.approvers(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{Transfer: t, Approvers: approvers, Required: a.PolicyFor(t).RequiredApprovals(t)}, nil
+}
+
+func (a *Approvals) approvers(ctx context.Context, t Transfer) ([]string, error) {
+	events, err := a.audit.Events(ctx, t.Rail, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	seen := map[string]bool{}
+	for _, e := range events {
+		if e.Type == EventApproval && !seen[e.Actor] {
+			seen[e.Actor] = true
+			res = append(res, e.Actor)
+		}
+	}
+	return res, nil
+}
+
+// Approve records the approver's sign-off on a transfer. The approver is an
+// identity in the same form as [Creator.Identity], such as
+// "user:ian@example.com". When the transfer has collected the approvals its
+// policy requires, it is approved in Increase.
+//
+// Approvals refused by the policy are recorded and return one of the errors
+// of this package.
+func (a *Approvals) Approve(ctx context.Context, r Rail, transferID string, approver string, opts ...option.RequestOption) (*Status, error) {
+	if approver == "" {
+		return nil, errors.New("approvals: missing approver")
+	}
+	t, err := a.Get(ctx, r, transferID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	policy := a.PolicyFor(t)
+	status := &Status{Transfer: t, Required: policy.RequiredApprovals(t)}
+	if status.Approvers, err = a.approvers(ctx, t); err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	var denied error
+	switch {
+	case !t.PendingApproval():
+		denied = ErrNotPending
+	case !policy.AllowSelfApproval && approver == t.CreatedBy.Identity():
+		denied = ErrSelfApproval
+	case contains(status.Approvers, approver):
+		denied = ErrAlreadyApproved
+	case !policy.inWindow(now):
+		denied = ErrOutsideWindow
+	}
+	if denied != nil {
+		if err := a.record(ctx, t, EventDenied, approver, denied.Error()); err != nil {
+			return nil, err
+		}
+		return status, denied
+	}
+
+	if err := a.record(ctx, t, EventApproval, approver, ""); err != nil {
+		return nil, err
+	}
+	status.Approvers = append(status.Approvers, approver)
+	if len(status.Approvers) < status.Required {
+		return status, nil
+	}
+
+	impl, _ := lookup(r)
+	approved, err := impl.approve(ctx, a.client, t.ID, opts)
+	if err != nil {
+		if recordErr := a.record(ctx, t, EventFailed, approver, err.Error()); recordErr != nil {
+			return nil, errors.Join(err, recordErr)
+		}
+		return status, err
+	}
+	status.Transfer, status.Approved = approved, true
+	reason := fmt.Sprintf("approved by %d of %d", len(status.Approvers), status.Required)
+	return status, a.record(ctx, t, EventApproved, approver, reason)
+}
+
+// Cancel cancels a transfer pending approval in Increase.
+func (a *Approvals) Cancel(ctx context.Context, r Rail, transferID string, actor string, reason string, opts ...option.RequestOption) (Transfer, error) {
+	if actor == "" {
+		return Transfer{}, errors.New("approvals: missing actor")
+	}
+	t, err := a.Get(ctx, r, transferID, opts...)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if !t.PendingApproval() {
+		if err := a.record(ctx, t, EventDenied, actor, ErrNotPending.Error()); err != nil {
+			return t, err
+		}
+		return t, ErrNotPending
+	}
+	impl, _ := lookup(r)
+	canceled, err := impl.cancel(ctx, a.client, t.ID, opts)
+	if err != nil {
+		if recordErr := a.record(ctx, t, EventFailed, actor, err.Error()); recordErr != nil {
+			return t, errors.Join(err, recordErr)
+		}
+		return t, err
+	}
+	return canceled, a.record(ctx, t, EventCanceled, actor, reason)
+}
+
+func (a *Approvals) record(ctx context.Context, t Transfer, typ EventType, actor string, reason string) error {
+	return a.audit.Record(ctx, Event{
+		At:         a.now(),
+		Type:       typ,
+		Rail:       t.Rail,
+		TransferID: t.ID,
+		Amount:     t.Amount,
+		Actor:      actor,
+		Reason:     reason,
+	})
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package approvals_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/approvals"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+type fakeAPI struct {
+	mu sync.Mutex
+	// Transfers by resource, such as "ach_transfers", then by ID.
+	transfers map[string]map[string]map[string]any
+	queries   []string
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{transfers: map[string]map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{resource}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.queries = append(api.queries, r.URL.Path+"?"+r.URL.RawQuery)
+		data := []map[string]any{}
+		for _, v := range api.transfers[r.PathValue("resource")] {
+			data = append(data, v)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data, "next_cursor": nil})
+	})
+	mux.HandleFunc("GET /{resource}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(api.transfers[r.PathValue("resource")][r.PathValue("id")])
+	})
+	mux.HandleFunc("POST /{resource}/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		v := api.transfers[r.PathValue("resource")][r.PathValue("id")]
+		if r.PathValue("action") == "approve" {
+			v["status"] = "pending_submission"
+		} else {
+			v["status"] = "canceled"
+		}
+		json.NewEncoder(w).Encode(v)
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func (a *fakeAPI) add(resource string, v map[string]any) {
+	if a.transfers[resource] == nil {
+		a.transfers[resource] = map[string]map[string]any{}
+	}
+	a.transfers[resource][v["id"].(string)] = v
+}
+
+func createdBy(email string) map[string]any {
+	return map[string]any{"category": "user", "user": map[string]any{"email": email}}
+}
+
+func TestPending(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.add("ach_transfers", map[string]any{"id": "ach_transfer_1", "amount": 1000, "status": "pending_approval", "created_at": "2026-10-19T10:00:00Z", "created_by": createdBy("maker@example.com")})
+	api.add("account_transfers", map[string]any{"id": "account_transfer_1", "amount": 500, "status": "pending_approval", "created_at": "2026-10-19T09:00:00Z"})
+	api.add("account_transfers", map[string]any{"id": "account_transfer_2", "amount": 500, "status": "complete", "created_at": "2026-10-19T08:00:00Z"})
+	api.add("card_push_transfers", map[string]any{"id": "outbound_card_push_transfer_1", "presentment_amount": map[string]any{"currency": "USD", "value": "12.5"}, "status": "pending_approval", "created_at": "2026-10-19T11:00:00Z"})
+
+	a := approvals.New(client, approvals.NewMemoryAuditLog(), approvals.Policy{})
+	pending, err := a.Pending(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending transfers, got %+v", pending)
+	}
+	if pending[0].ID != "account_transfer_1" || pending[1].Rail != approvals.RailACH || pending[1].CreatedBy.Identity() != "user:maker@example.com" {
+		t.Errorf("unexpected pending transfers: %+v", pending)
+	}
+	if pending[2].Rail != approvals.RailCardPush || pending[2].Amount != 1250 {
+		t.Errorf("unexpected card push transfer: %+v", pending[2])
+	}
+	if len(api.queries) != len(approvals.Rails) {
+		t.Errorf("expected every rail to be listed, got %v", api.queries)
+	}
+}
+
+func TestDualApproval(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	api.add("wire_transfers", map[string]any{"id": "wire_transfer_1", "amount": 5000000, "status": "pending_approval", "created_by": createdBy("maker@example.com")})
+	api.add("wire_transfers", map[string]any{"id": "wire_transfer_2", "amount": 10000, "status": "pending_approval", "created_by": createdBy("maker@example.com")})
+	audit := approvals.NewMemoryAuditLog()
+	a := approvals.New(client, audit, approvals.DualApproval(1000000))
+
+	_, err := a.Approve(ctx, approvals.RailWire, "wire_transfer_1", "user:maker@example.com")
+	if !errors.Is(err, approvals.ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+	status, err := a.Approve(ctx, approvals.RailWire, "wire_transfer_1", "user:checker1@example.com")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if status.Approved || status.Required != 2 || api.transfers["wire_transfers"]["wire_transfer_1"]["status"] != "pending_approval" {
+		t.Fatalf("one approval should not approve the transfer: %+v", status)
+	}
+	if _, err := a.Approve(ctx, approvals.RailWire, "wire_transfer_1", "user:checker1@example.com"); !errors.Is(err, approvals.ErrAlreadyApproved) {
+		t.Fatalf("expected ErrAlreadyApproved, got %v", err)
+	}
+	status, err = a.Approve(ctx, approvals.RailWire, "wire_transfer_1", "user:checker2@example.com")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !status.Approved || status.Transfer.Status != "pending_submission" {
+		t.Errorf("expected the transfer to be approved, got %+v", status)
+	}
+
+	status, err = a.Approve(ctx, approvals.RailWire, "wire_transfer_2", "user:checker1@example.com")
+	if err != nil || !status.Approved {
+		t.Errorf("transfers below the threshold need one approval, got %+v, %v", status, err)
+	}
+
+	var types []approvals.EventType
+	for _, e := range audit.All() {
+		types = append(types, e.Type)
+	}
+	want := []approvals.EventType{
+		approvals.EventDenied, approvals.EventApproval, approvals.EventDenied, approvals.EventApproval, approvals.EventApproved,
+		approvals.EventApproval, approvals.EventApproved,
+	}
+	if len(types) != len(want) {
+		t.Fatalf("unexpected audit trail %v", types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("unexpected audit trail %v", types)
+			break
+		}
+	}
+}
+
+func TestWindowsAndCancel(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	api.add("check_transfers", map[string]any{"id": "check_transfer_1", "amount": 100, "status": "pending_approval"})
+	a := approvals.New(client, approvals.NewMemoryAuditLog(), approvals.Policy{
+		Windows: []approvals.Window{{Start: 0, End: 0}},
+	})
+	if _, err := a.Approve(ctx, approvals.RailCheck, "check_transfer_1", "user:checker@example.com"); !errors.Is(err, approvals.ErrOutsideWindow) {
+		t.Fatalf("expected ErrOutsideWindow, got %v", err)
+	}
+	canceled, err := a.Cancel(ctx, approvals.RailCheck, "check_transfer_1", "user:checker@example.com", "duplicate")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if canceled.Status != "canceled" {
+		t.Errorf("expected the transfer to be canceled, got %s", canceled.Status)
+	}
+	if _, err := a.Approve(ctx, approvals.RailCheck, "check_transfer_1", "user:checker@example.com"); !errors.Is(err, approvals.ErrNotPending) {
+		t.Errorf("expected ErrNotPending, got %v", err)
+	}
+}
+
+func TestRequiredApprovals(t *testing.T) {
+	p := approvals.Policy{Tiers: []approvals.Tier{{MinAmount: 1000000, Approvals: 2}, {MinAmount: 10000000, Approvals: 3}}}
+	for _, tc := range []struct {
+		transfer approvals.Transfer
+		required int
+	}{
+		{approvals.Transfer{Amount: 5000}, 1},
+		{approvals.Transfer{Amount: 1000000}, 2},
+		{approvals.Transfer{Amount: -100000000}, 3},
+		{approvals.Transfer{AmountErr: errors.New("invalid amount")}, 3},
+	} {
+		if required := p.RequiredApprovals(tc.transfer); required != tc.required {
+			t.Errorf("%+v: expected %d approvals, got %d", tc.transfer, tc.required, required)
+		}
+	}
+
+	// Card pushes in other currencies need the approvals of the highest tier,
+	// since tiers are in USD.
+	for _, tc := range []struct {
+		currency, value string
+		amount          int64
+		required        int
+	}{
+		{"USD", "12.5", 1250, 1},
+		{"USD", "20000.00", 2000000, 2},
+		{"JPY", "1500", 1500, 3},
+		{"KWD", "1.250", 1250, 3},
+		{"CAD", "5.00", 500, 3},
+		{"USD", "12.345", 0, 3},
+		{"USD", "twelve", 0, 3},
+	} {
+		api, client := newFakeAPI(t)
+		api.add("card_push_transfers", map[string]any{"id": "outbound_card_push_transfer_1", "presentment_amount": map[string]any{"currency": tc.currency, "value": tc.value}, "status": "pending_approval"})
+		a := approvals.New(client, approvals.NewMemoryAuditLog(), p)
+		pending, err := a.Pending(context.Background(), []approvals.Rail{approvals.RailCardPush})
+		if err != nil || len(pending) != 1 {
+			t.Fatalf("unexpected pending transfers %+v, %v", pending, err)
+		}
+		if transfer := pending[0]; transfer.Amount != tc.amount || p.RequiredApprovals(transfer) != tc.required {
+			t.Errorf("%s %s: unexpected transfer %+v", tc.value, tc.currency, transfer)
+		}
+	}
+}
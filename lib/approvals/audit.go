@@ -0,0 +1,81 @@
+package approvals
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of an audit event.
+type EventType string
+
+const (
+	// An approver signed off on a transfer.
+	EventApproval EventType = "approval"
+	// An approval or cancellation was refused by the policy.
+	EventDenied EventType = "denied"
+	// The transfer was approved in Increase after collecting enough approvals.
+	EventApproved EventType = "approved"
+	// The transfer was canceled in Increase.
+	EventCanceled EventType = "canceled"
+	// Calling Increase failed.
+	EventFailed EventType = "failed"
+)
+
+// Event is an entry of the audit trail.
+type Event struct {
+	At         time.Time
+	Type       EventType
+	Rail       Rail
+	TransferID string
+	Amount     int64
+	// The identity of the approver or canceler.
+	Actor  string
+	Reason string
+}
+
+// AuditLog stores the audit trail. It is also the source of truth for the
+// approvals collected so far, so it must be durable in production.
+// Implementations must be safe for concurrent use.
+type AuditLog interface {
+	Record(ctx context.Context, event Event) error
+	// Events returns the events of a transfer in the order they were recorded.
+	Events(ctx context.Context, rail Rail, transferID string) ([]Event, error)
+}
+
+// MemoryAuditLog is an in-memory [AuditLog].
+type MemoryAuditLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryAuditLog returns an empty in-memory audit log.
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+func (l *MemoryAuditLog) Record(ctx context.Context, event Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	return nil
+}
+
+func (l *MemoryAuditLog) Events(ctx context.Context, rail Rail, transferID string) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var res []Event
+	for _, e := range l.events {
+		if e.Rail == rail && e.TransferID == transferID {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// All returns every recorded event.
+func (l *MemoryAuditLog) All() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
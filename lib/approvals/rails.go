@@ -0,0 +1,398 @@
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// Rail is a kind of outbound transfer that can require approval.
+type Rail string
+
+const (
+	RailACH              Rail = "ach"
+	RailWire             Rail = "wire"
+	RailRealTimePayments Rail = "real_time_payments"
+	RailFedNow           Rail = "fednow"
+	RailCheck            Rail = "check"
+	RailSwift            Rail = "swift"
+	RailAccount          Rail = "account"
+	RailCardPush         Rail = "card_push"
+)
+
+// Rails lists every rail, in the order pending transfers are listed.
+var Rails = []Rail{RailACH, RailWire, RailRealTimePayments, RailFedNow, RailCheck, RailSwift, RailAccount, RailCardPush}
+
+// Creator is who created a transfer.
+type Creator struct {
+	// One of `api_key`, `oauth_application` or `user`, or empty if unknown.
+	Category string
+	// The email address of a user, the description of an API key or the name of
+	// an OAuth application.
+	Name string
+}
+
+// Identity returns a string that identifies the creator, such as
+// "user:ian@example.com", for comparison with approver identities.
+func (c Creator) Identity() string {
+	if c.Category == "" {
+		return ""
+	}
+	return c.Category + ":" + c.Name
+}
+
+// Transfer is a transfer of any rail in a common shape.
+type Transfer struct {
+	Rail      Rail
+	ID        string
+	AccountID string
+	// The amount in the minor unit of Currency.
+	Amount       int64
+	Currency     string
+	Counterparty string
+	Status       string
+	CreatedAt    time.Time
+	CreatedBy    Creator
+	// Why Amount could not be determined, if it could not. Policies then
+	// require the approvals of their highest tier.
+	AmountErr error
+	// The transfer as returned by the API, such as an [*increase.ACHTransfer].
+	Object any
+}
+
+// PendingApproval reports whether the transfer is waiting for approval.
+func (t Transfer) PendingApproval() bool {
+	return t.Status == "pending_approval"
+}
+
+func creator(category, apiKey, oauthApplication, user string) Creator {
+	switch category {
+	case "api_key":
+		return Creator{Category: category, Name: apiKey}
+	case "oauth_application":
+		return Creator{Category: category, Name: oauthApplication}
+	case "user":
+		return Creator{Category: category, Name: user}
+	}
+	return Creator{}
+}
+
+// rail adapts the service of a rail to the common Transfer shape.
+type rail struct {
+	pending func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error)
+	get     func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error)
+	approve func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error)
+	cancel  func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error)
+}
+
+// collect drains an auto-pager, converting and keeping the pending transfers.
+func collect[T any](iter interface {
+	Next() bool
+	Current() T
+	Err() error
+}, convert func(*T) Transfer) ([]Transfer, error) {
+	var res []Transfer
+	for iter.Next() {
+		v := iter.Current()
+		if t := convert(&v); t.PendingApproval() {
+			res = append(res, t)
+		}
+	}
+	return res, iter.Err()
+}
+
+// wrap converts the result of a service call.
+func wrap[T any](v *T, err error, convert func(*T) Transfer) (Transfer, error) {
+	if err != nil {
+		return Transfer{}, err
+	}
+	return convert(v), nil
+}
+
+var rails = map[Rail]rail{
+	RailACH: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.ACHTransfers.ListAutoPaging(ctx, increase.ACHTransferListParams{
+				Status: increase.F(increase.ACHTransferListParamsStatus{In: increase.F([]increase.ACHTransferListParamsStatusIn{increase.ACHTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromACH)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.ACHTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromACH)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.ACHTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromACH)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.ACHTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromACH)
+		},
+	},
+	RailWire: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.WireTransfers.ListAutoPaging(ctx, increase.WireTransferListParams{
+				Status: increase.F(increase.WireTransferListParamsStatus{In: increase.F([]increase.WireTransferListParamsStatusIn{increase.WireTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromWire)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.WireTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromWire)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.WireTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromWire)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.WireTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromWire)
+		},
+	},
+	RailRealTimePayments: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.RealTimePaymentsTransfers.ListAutoPaging(ctx, increase.RealTimePaymentsTransferListParams{
+				Status: increase.F(increase.RealTimePaymentsTransferListParamsStatus{In: increase.F([]increase.RealTimePaymentsTransferListParamsStatusIn{increase.RealTimePaymentsTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromRealTimePayments)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.RealTimePaymentsTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromRealTimePayments)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.RealTimePaymentsTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromRealTimePayments)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.RealTimePaymentsTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromRealTimePayments)
+		},
+	},
+	RailFedNow: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.FednowTransfers.ListAutoPaging(ctx, increase.FednowTransferListParams{
+				Status: increase.F(increase.FednowTransferListParamsStatus{In: increase.F([]increase.FednowTransferListParamsStatusIn{increase.FednowTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromFedNow)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.FednowTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromFedNow)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.FednowTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromFedNow)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.FednowTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromFedNow)
+		},
+	},
+	RailCheck: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.CheckTransfers.ListAutoPaging(ctx, increase.CheckTransferListParams{
+				Status: increase.F(increase.CheckTransferListParamsStatus{In: increase.F([]increase.CheckTransferListParamsStatusIn{increase.CheckTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromCheck)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CheckTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromCheck)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CheckTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromCheck)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CheckTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromCheck)
+		},
+	},
+	RailSwift: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.SwiftTransfers.ListAutoPaging(ctx, increase.SwiftTransferListParams{
+				Status: increase.F(increase.SwiftTransferListParamsStatus{In: increase.F([]increase.SwiftTransferListParamsStatusIn{increase.SwiftTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromSwift)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.SwiftTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromSwift)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.SwiftTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromSwift)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.SwiftTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromSwift)
+		},
+	},
+	RailAccount: {
+		// Account Transfers cannot be filtered by status, so every transfer is
+		// listed and filtered here.
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.AccountTransfers.ListAutoPaging(ctx, increase.AccountTransferListParams{}, opts...), fromAccount)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.AccountTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromAccount)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.AccountTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromAccount)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.AccountTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromAccount)
+		},
+	},
+	RailCardPush: {
+		pending: func(ctx context.Context, c *increase.Client, opts []option.RequestOption) ([]Transfer, error) {
+			return collect(c.CardPushTransfers.ListAutoPaging(ctx, increase.CardPushTransferListParams{
+				Status: increase.F(increase.CardPushTransferListParamsStatus{In: increase.F([]increase.CardPushTransferListParamsStatusIn{increase.CardPushTransferListParamsStatusInPendingApproval})}),
+			}, opts...), fromCardPush)
+		},
+		get: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CardPushTransfers.Get(ctx, id, opts...)
+			return wrap(v, err, fromCardPush)
+		},
+		approve: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CardPushTransfers.Approve(ctx, id, opts...)
+			return wrap(v, err, fromCardPush)
+		},
+		cancel: func(ctx context.Context, c *increase.Client, id string, opts []option.RequestOption) (Transfer, error) {
+			v, err := c.CardPushTransfers.Cancel(ctx, id, opts...)
+			return wrap(v, err, fromCardPush)
+		},
+	},
+}
+
+func fromACH(v *increase.ACHTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailACH, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: v.StatementDescriptor, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func fromWire(v *increase.WireTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailWire, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: v.Creditor.Name, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func fromRealTimePayments(v *increase.RealTimePaymentsTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailRealTimePayments, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: v.CreditorName, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func fromFedNow(v *increase.FednowTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailFedNow, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: v.CreditorName, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func fromCheck(v *increase.CheckTransfer) Transfer {
+	b := v.CreatedBy
+	payee := v.PhysicalCheck.RecipientName
+	if v.FulfillmentMethod == increase.CheckTransferFulfillmentMethodThirdParty {
+		payee = v.ThirdParty.RecipientName
+	}
+	return Transfer{
+		Rail: RailCheck, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: payee, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+// fromSwift uses the amount debited from the account in USD, rather than the
+// instructed amount, so that thresholds compare like amounts.
+func fromSwift(v *increase.SwiftTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailSwift, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: "USD",
+		Counterparty: v.CreditorName, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func fromAccount(v *increase.AccountTransfer) Transfer {
+	b := v.CreatedBy
+	return Transfer{
+		Rail: RailAccount, ID: v.ID, AccountID: v.AccountID, Amount: v.Amount, Currency: string(v.Currency),
+		Counterparty: v.DestinationAccountID, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+// fromCardPush parses the presentment amount, which card push transfers
+// report as a decimal string. Tiers are in USD, so amounts in other currencies
+// are treated as unknown.
+func fromCardPush(v *increase.CardPushTransfer) Transfer {
+	b := v.CreatedBy
+	amount, err := parseAmount(v.PresentmentAmount.Value, string(v.PresentmentAmount.Currency))
+	if err == nil && v.PresentmentAmount.Currency != "USD" {
+		err = fmt.Errorf("approvals: %s amount cannot be compared with tiers in USD", v.PresentmentAmount.Currency)
+	}
+	return Transfer{
+		Rail: RailCardPush, ID: v.ID, AccountID: v.AccountID, Amount: amount, AmountErr: err, Currency: string(v.PresentmentAmount.Currency),
+		Counterparty: v.RecipientName, Status: string(v.Status), CreatedAt: v.CreatedAt,
+		CreatedBy: creator(string(b.Category), b.APIKey.Description, b.OAuthApplication.Name, b.User.Email),
+		Object:    v,
+	}
+}
+
+func lookup(r Rail) (rail, error) {
+	impl, ok := rails[r]
+	if !ok {
+		return rail{}, fmt.Errorf("approvals: unknown rail %q", r)
+	}
+	return impl, nil
+}
+
+// parseAmount converts a decimal amount such as "12.50" to the minor unit of
+// currency.
+func parseAmount(s, currency string) (int64, error) {
+	digits := exponent(currency)
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > digits || whole == "" && frac == "" {
+		return 0, fmt.Errorf("approvals: invalid %s amount %q", currency, s)
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("approvals: invalid %s amount %q", currency, s)
+	}
+	return n, nil
+}
+
+// exponent returns the number of decimal places of an ISO 4217 currency.
+func exponent(currency string) int {
+	switch currency {
+	case "BIF", "CLP", "DJF", "GNF", "ISK", "JPY", "KMF", "KRW", "PYG", "RWF", "UGX", "UYI", "VND", "VUV", "XAF", "XOF", "XPF":
+		return 0
+	case "BHD", "IQD", "JOD", "KWD", "LYD", "OMR", "TND":
+		return 3
+	}
+	return 2
+}
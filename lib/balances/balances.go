@@ -0,0 +1,254 @@
+// Package balances reconstructs the running balance of an Account from its
+// Transactions.
+//
+// AccountService.Balance returns the balance at a single moment, so a daily
+// balance report takes one call per day. A [Series] instead starts from an
+// Account Statement's starting balance, or a balance looked up once, and
+// replays the Transactions of the period. It answers the balance at any
+// moment, summarizes each day, and checks itself against the ending balance of
+// every statement it covers.
+//
+//	statements, err := client.AccountStatements.List(ctx, increase.AccountStatementListParams{AccountID: increase.F(accountID)})
+//	if err != nil {
+//		return err
+//	}
+//	s, err := balances.FromStatements(ctx, client, statements.Data)
+//	if err != nil {
+//		return err
+//	}
+//	for _, d := range s.Discrepancies {
+//		log.Printf("statement %s is off by %d", d.StatementID, d.Difference())
+//	}
+//	for _, day := range s.Daily(time.UTC) {
+//		fmt.Println(day.Date.Format(time.DateOnly), day.Closing)
+//	}
+package balances
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNoStatements is returned by [FromStatements] without statements.
+var ErrNoStatements = errors.New("balances: no statements")
+
+// Point is the balance of an Account after a Transaction.
+type Point struct {
+	Time          time.Time
+	TransactionID string
+	Amount        int64
+	Balance       int64
+}
+
+// Day summarizes the balance of an Account over a calendar day.
+type Day struct {
+	// Midnight at the start of the day.
+	Date    time.Time
+	Opening int64
+	Closing int64
+	// The sum of the day's positive Transactions.
+	Credits int64
+	// The sum of the day's negative Transactions, as a negative number.
+	Debits int64
+	Count  int
+}
+
+// Discrepancy is a statement balance that the replayed Transactions do not
+// reproduce.
+type Discrepancy struct {
+	StatementID string
+	// The statement boundary the balance was checked at.
+	At time.Time
+	// The balance on the statement.
+	Expected int64
+	// The balance the Transactions add up to.
+	Computed int64
+}
+
+// Difference returns how much the computed balance is off by.
+func (d Discrepancy) Difference() int64 {
+	return d.Computed - d.Expected
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("statement %s: balance at %s is %d, computed %d", d.StatementID, d.At.Format(time.RFC3339), d.Expected, d.Computed)
+}
+
+// Series is the running balance of an Account over [Start, End). A Transaction
+// changes the balance at the moment it was created.
+type Series struct {
+	AccountID       string
+	Start           time.Time
+	End             time.Time
+	StartingBalance int64
+	// The balance after each Transaction, oldest first.
+	Points []Point
+	// The statement balances that disagree with Points, filled by Verify.
+	Discrepancies []Discrepancy
+}
+
+// Replay returns the running balance of an Account over [start, end) that
+// starts at startingBalance. Transactions of other Accounts, or created
+// outside the window, are ignored; a zero end leaves the window open.
+func Replay(accountID string, startingBalance int64, start, end time.Time, txs []increase.Transaction) *Series {
+	s := &Series{AccountID: accountID, Start: start, End: end, StartingBalance: startingBalance}
+	var in []increase.Transaction
+	for _, tx := range txs {
+		if accountID != "" && tx.AccountID != accountID {
+			continue
+		}
+		if tx.CreatedAt.Before(start) || !end.IsZero() && !tx.CreatedAt.Before(end) {
+			continue
+		}
+		in = append(in, tx)
+	}
+	sort.SliceStable(in, func(i, j int) bool { return in[i].CreatedAt.Before(in[j].CreatedAt) })
+	balance := startingBalance
+	for _, tx := range in {
+		balance += tx.Amount
+		s.Points = append(s.Points, Point{Time: tx.CreatedAt, TransactionID: tx.ID, Amount: tx.Amount, Balance: balance})
+	}
+	return s
+}
+
+// Ending returns the balance after the last Transaction.
+func (s *Series) Ending() int64 {
+	if len(s.Points) == 0 {
+		return s.StartingBalance
+	}
+	return s.Points[len(s.Points)-1].Balance
+}
+
+// At returns the balance after the Transactions created before t. Before the
+// start of the series it returns the starting balance.
+func (s *Series) At(t time.Time) int64 {
+	i := sort.Search(len(s.Points), func(i int) bool { return !s.Points[i].Time.Before(t) })
+	if i == 0 {
+		return s.StartingBalance
+	}
+	return s.Points[i-1].Balance
+}
+
+// Daily summarizes each calendar day of the series in loc, from the day of
+// Start through the day of End, or of the last Transaction when End is zero.
+func (s *Series) Daily(loc *time.Location) []Day {
+	last := s.End
+	if last.IsZero() {
+		last = s.Start
+		if len(s.Points) > 0 {
+			last = s.Points[len(s.Points)-1].Time.Add(time.Nanosecond)
+		}
+	}
+	var days []Day
+	i := 0
+	balance := s.StartingBalance
+	for day := midnight(s.Start, loc); day.Before(last); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		d := Day{Date: day, Opening: balance}
+		for ; i < len(s.Points) && s.Points[i].Time.Before(next); i++ {
+			p := s.Points[i]
+			if p.Amount < 0 {
+				d.Debits += p.Amount
+			} else {
+				d.Credits += p.Amount
+			}
+			d.Count++
+			balance = p.Balance
+		}
+		d.Closing = balance
+		days = append(days, d)
+	}
+	return days
+}
+
+// Verify checks the starting and ending balances of the statements that fall
+// within the series against the replayed Transactions. It records and returns
+// the balances that disagree.
+func (s *Series) Verify(statements ...increase.AccountStatement) []Discrepancy {
+	var found []Discrepancy
+	check := func(id string, at time.Time, expected int64) {
+		if at.Before(s.Start) || !s.End.IsZero() && at.After(s.End) {
+			return
+		}
+		if computed := s.At(at); computed != expected {
+			found = append(found, Discrepancy{StatementID: id, At: at, Expected: expected, Computed: computed})
+		}
+	}
+	for _, st := range statements {
+		if s.AccountID != "" && st.AccountID != s.AccountID {
+			continue
+		}
+		check(st.ID, st.StatementPeriodStart, st.StartingBalance)
+		check(st.ID, st.StatementPeriodEnd, st.EndingBalance)
+	}
+	s.Discrepancies = append(s.Discrepancies, found...)
+	return found
+}
+
+// FromStatements replays the Transactions of the periods covered by
+// statements of a single Account, starting from the starting balance of the
+// earliest, and verifies every statement boundary.
+func FromStatements(ctx context.Context, client *increase.Client, statements []increase.AccountStatement, opts ...option.RequestOption) (*Series, error) {
+	if len(statements) == 0 {
+		return nil, ErrNoStatements
+	}
+	sorted := append([]increase.AccountStatement{}, statements...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StatementPeriodStart.Before(sorted[j].StatementPeriodStart) })
+	first, end := sorted[0], sorted[0].StatementPeriodEnd
+	for _, st := range sorted[1:] {
+		if st.AccountID != first.AccountID {
+			return nil, fmt.Errorf("balances: statements %s and %s belong to different accounts", first.ID, st.ID)
+		}
+		if st.StatementPeriodEnd.After(end) {
+			end = st.StatementPeriodEnd
+		}
+	}
+	txs, err := transactions(ctx, client, first.AccountID, first.StatementPeriodStart, end, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := Replay(first.AccountID, first.StartingBalance, first.StatementPeriodStart, end, txs)
+	s.Verify(sorted...)
+	return s, nil
+}
+
+// FromBalance looks up the balance of an Account at start and replays the
+// Transactions created in [start, end).
+func FromBalance(ctx context.Context, client *increase.Client, accountID string, start, end time.Time, opts ...option.RequestOption) (*Series, error) {
+	balance, err := client.Accounts.Balance(ctx, accountID, increase.AccountBalanceParams{AtTime: increase.F(start)}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("balances: looking up the balance of %s: %w", accountID, err)
+	}
+	txs, err := transactions(ctx, client, accountID, start, end, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Replay(accountID, balance.CurrentBalance, start, end, txs), nil
+}
+
+func transactions(ctx context.Context, client *increase.Client, accountID string, start, end time.Time, opts ...option.RequestOption) ([]increase.Transaction, error) {
+	createdAt := increase.TransactionListParamsCreatedAt{OnOrAfter: increase.F(start)}
+	if !end.IsZero() {
+		createdAt.Before = increase.F(end)
+	}
+	iter := client.Transactions.ListAutoPaging(ctx, increase.TransactionListParams{AccountID: increase.F(accountID), CreatedAt: increase.F(createdAt)}, opts...)
+	var txs []increase.Transaction
+	for iter.Next() {
+		txs = append(txs, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("balances: listing transactions of %s: %w", accountID, err)
+	}
+	return txs, nil
+}
+
+func midnight(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
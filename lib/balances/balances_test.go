@@ -0,0 +1,112 @@
+package balances_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/balances"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+const transactions = `{"data": [
+	{"id": "tx_3", "account_id": "account_1", "amount": -2500, "created_at": "2024-04-02T09:00:00Z", "source": {"category": "card_settlement"}},
+	{"id": "tx_1", "account_id": "account_1", "amount": 10000, "created_at": "2024-03-01T09:00:00Z", "source": {"category": "inbound_ach_transfer"}},
+	{"id": "tx_2", "account_id": "account_1", "amount": -4000, "created_at": "2024-03-01T15:00:00Z", "source": {"category": "ach_transfer_intention"}},
+	{"id": "tx_4", "account_id": "account_1", "amount": 300, "created_at": "2024-04-03T09:00:00Z", "source": {"category": "interest_payment"}}
+], "next_cursor": null}`
+
+func client(t *testing.T) *increase.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /transactions", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("account_id") != "account_1" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(transactions))
+	})
+	mux.HandleFunc("GET /accounts/account_1/balance", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("at_time") != "2024-03-01T12:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"account_id": "account_1", "current_balance": 60000, "available_balance": 60000, "type": "balance_lookup"}`))
+	})
+	return testapi.NewClient(t, mux)
+}
+
+func statement(t *testing.T, body string) increase.AccountStatement {
+	var st increase.AccountStatement
+	if err := json.Unmarshal([]byte(body), &st); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return st
+}
+
+func TestFromStatements(t *testing.T) {
+	statements := []increase.AccountStatement{
+		statement(t, `{"id": "statement_april", "account_id": "account_1", "starting_balance": 56000, "ending_balance": 53500, "statement_period_start": "2024-04-01T00:00:00Z", "statement_period_end": "2024-05-01T00:00:00Z"}`),
+		statement(t, `{"id": "statement_march", "account_id": "account_1", "starting_balance": 50000, "ending_balance": 56000, "statement_period_start": "2024-03-01T00:00:00Z", "statement_period_end": "2024-04-01T00:00:00Z"}`),
+	}
+	s, err := balances.FromStatements(context.Background(), client(t), statements)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(s.Points) != 4 || s.Points[0].TransactionID != "tx_1" || s.Ending() != 53800 {
+		t.Errorf("unexpected series %+v", s)
+	}
+	if at := s.At(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)); at != 60000 {
+		t.Errorf("expected 60000 at noon, got %d", at)
+	}
+	if at := s.At(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)); at != 50000 {
+		t.Errorf("expected Transactions created at the moment to be excluded, got %d", at)
+	}
+
+	if len(s.Discrepancies) != 1 {
+		t.Fatalf("expected 1 discrepancy, got %+v", s.Discrepancies)
+	}
+	d := s.Discrepancies[0]
+	if d.StatementID != "statement_april" || d.Expected != 53500 || d.Computed != 53800 || d.Difference() != 300 {
+		t.Errorf("unexpected discrepancy %+v", d)
+	}
+
+	days := s.Daily(time.UTC)
+	if len(days) != 61 {
+		t.Fatalf("expected 61 days, got %d", len(days))
+	}
+	if day := days[0]; day.Opening != 50000 || day.Closing != 56000 || day.Credits != 10000 || day.Debits != -4000 || day.Count != 2 {
+		t.Errorf("unexpected first day %+v", day)
+	}
+	if day := days[1]; day.Opening != 56000 || day.Closing != 56000 || day.Count != 0 {
+		t.Errorf("unexpected quiet day %+v", day)
+	}
+	if day := days[32]; !day.Date.Equal(time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)) || day.Closing != 53500 {
+		t.Errorf("unexpected day %+v", day)
+	}
+
+	// Days in another time zone split the Transactions differently.
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	if days := s.Daily(ny); days[0].Count != 0 || days[1].Count != 2 {
+		t.Errorf("unexpected days %+v", days[:2])
+	}
+
+	if _, err := balances.FromStatements(context.Background(), client(t), nil); !errors.Is(err, balances.ErrNoStatements) {
+		t.Errorf("expected ErrNoStatements, got %v", err)
+	}
+}
+
+func TestFromBalance(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, err := balances.FromBalance(context.Background(), client(t), "account_1", start, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(s.Points) != 1 || s.Ending() != 56000 {
+		t.Errorf("unexpected series %+v", s)
+	}
+}
@@ -0,0 +1,587 @@
+package bankfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypeCode is a BAI2 type code, which classifies a balance, summary or
+// transaction.
+type TypeCode int
+
+// Class is the range a BAI2 type code falls in.
+type Class string
+
+const (
+	ClassStatus  Class = "status"
+	ClassCredit  Class = "credit"
+	ClassDebit   Class = "debit"
+	ClassLoan    Class = "loan"
+	ClassUnknown Class = "unknown"
+)
+
+// Class returns whether the type code is a balance, a credit, a debit or a
+// loan code, following the ranges of the BAI2 specification, including its
+// customized 900-999 ranges.
+func (c TypeCode) Class() Class {
+	switch {
+	case c >= 10 && c <= 99, c >= 900 && c <= 919:
+		return ClassStatus
+	case c >= 100 && c <= 399, c >= 920 && c <= 959:
+		return ClassCredit
+	case c >= 400 && c <= 699, c >= 960 && c <= 999:
+		return ClassDebit
+	case c >= 700 && c <= 799:
+		return ClassLoan
+	}
+	return ClassUnknown
+}
+
+// Description returns the name of common type codes, or "".
+func (c TypeCode) Description() string {
+	return typeCodes[c]
+}
+
+func (c TypeCode) String() string {
+	return fmt.Sprintf("%03d", int(c))
+}
+
+var typeCodes = map[TypeCode]string{
+	10:  "Opening Ledger",
+	15:  "Closing Ledger",
+	40:  "Opening Available",
+	45:  "Closing Available",
+	100: "Total Credits",
+	108: "Credit Reversal",
+	115: "Lockbox Deposit",
+	142: "ACH Credit Received",
+	165: "Preauthorized ACH Credit",
+	169: "Miscellaneous ACH Credit",
+	175: "Check Deposit Package",
+	195: "Incoming Money Transfer",
+	208: "Incoming Internal Money Transfer",
+	275: "ZBA Credit",
+	301: "Commercial Deposit",
+	354: "Interest Credit",
+	399: "Miscellaneous Credit",
+	400: "Total Debits",
+	408: "Float Adjustment",
+	451: "ACH Debit Received",
+	455: "Preauthorized ACH Debit",
+	469: "Miscellaneous ACH Debit",
+	475: "Check Paid",
+	495: "Outgoing Money Transfer",
+	508: "Outgoing Internal Money Transfer",
+	555: "Deposited Item Returned",
+	575: "ZBA Debit",
+	661: "Account Analysis Fee",
+	699: "Miscellaneous Debit",
+}
+
+// Funds is the availability of the funds of a BAI2 summary or transaction.
+type Funds struct {
+	// "0", "1" and "2" for funds available immediately, in one day and in two
+	// or more days; "S", "V" and "D" for the distributions below; "Z" or ""
+	// when unknown.
+	Type string
+	// For type "V".
+	ValueDate time.Time
+	// For type "S".
+	Immediate     int64
+	OneDay        int64
+	TwoOrMoreDays int64
+	// For type "D".
+	Distribution []Availability
+}
+
+// Availability is an amount available after a number of days.
+type Availability struct {
+	Days   int
+	Amount int64
+}
+
+// BAI2 is a BAI2 cash management balance reporting file.
+type BAI2 struct {
+	SenderID   string
+	ReceiverID string
+	Created    time.Time
+	FileID     string
+	Version    int
+	Groups     []BAI2Group
+	// The sum of the group control totals, from the file trailer.
+	ControlTotal int64
+}
+
+// BAI2Group is a group of accounts reported as of the same date.
+type BAI2Group struct {
+	UltimateReceiverID string
+	OriginatorID       string
+	Status             int
+	AsOf               time.Time
+	Currency           string
+	AsOfDateModifier   int
+	Accounts           []BAI2Account
+	ControlTotal       int64
+}
+
+// BAI2Account is the balances and transactions of one account.
+type BAI2Account struct {
+	Number string
+	// Defaults to the currency of the group.
+	Currency     string
+	Summaries    []BAI2Summary
+	Transactions []BAI2Transaction
+	ControlTotal int64
+}
+
+// Summary returns the summary of a type code.
+func (a BAI2Account) Summary(code TypeCode) (BAI2Summary, bool) {
+	for _, s := range a.Summaries {
+		if s.TypeCode == code {
+			return s, true
+		}
+	}
+	return BAI2Summary{}, false
+}
+
+// BAI2Summary is a balance or activity summary of an account.
+type BAI2Summary struct {
+	TypeCode TypeCode
+	// In the minor unit of the currency. Balances may be negative.
+	Amount    int64
+	ItemCount int
+	Funds     Funds
+}
+
+// BAI2Transaction is a transaction detail record.
+type BAI2Transaction struct {
+	TypeCode TypeCode
+	// In the minor unit of the currency, unsigned: the type code says whether
+	// it is a credit or a debit.
+	Amount            int64
+	Funds             Funds
+	BankReference     string
+	CustomerReference string
+	// Text continued over several physical records is joined with spaces.
+	Text string
+}
+
+// SignedAmount returns the amount, negative for debits.
+func (t BAI2Transaction) SignedAmount() int64 {
+	if t.TypeCode.Class() == ClassDebit {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// TransactionID returns the Increase Transaction ID in the references or text
+// of the transaction, or "".
+func (t BAI2Transaction) TransactionID() string {
+	return findTransactionID(t.BankReference, t.CustomerReference, t.Text)
+}
+
+// bai2Record is a logical record: a physical record and its continuations.
+type bai2Record struct {
+	code string
+	line int
+	// The fields of each physical record, without the record code and the
+	// trailing slash.
+	segs []string
+}
+
+// ParseBAI2 parses a BAI2 file, checking its structure, record counts and
+// control totals. Dates and times are read in UTC.
+//
+// A single trailing slash is removed from every physical record, including
+// transaction details, whose text field is not slash-delimited.
+func ParseBAI2(r io.Reader) (*BAI2, error) {
+	records, err := readBAI2Records(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &bai2Parser{records: records}
+	return p.file()
+}
+
+func readBAI2Records(r io.Reader) ([]bai2Record, error) {
+	var records []bai2Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), " \r")
+		if text == "" {
+			continue
+		}
+		code, rest, _ := strings.Cut(text, ",")
+		rest = strings.TrimSuffix(rest, "/")
+		if code == "88" {
+			if len(records) == 0 {
+				return nil, fmt.Errorf("bankfile: BAI2 line %d: continuation without a record", line)
+			}
+			last := &records[len(records)-1]
+			last.segs = append(last.segs, rest)
+			continue
+		}
+		records = append(records, bai2Record{code: code, line: line, segs: []string{rest}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bankfile: reading BAI2: %w", err)
+	}
+	return records, nil
+}
+
+type bai2Parser struct {
+	records []bai2Record
+	i       int
+}
+
+func (p *bai2Parser) peek() string {
+	if p.i >= len(p.records) {
+		return ""
+	}
+	return p.records[p.i].code
+}
+
+func (p *bai2Parser) expect(code string) (bai2Record, *fieldReader, error) {
+	if p.i >= len(p.records) {
+		return bai2Record{}, nil, fmt.Errorf("bankfile: BAI2 ends before a %s record", code)
+	}
+	rec := p.records[p.i]
+	if rec.code != code {
+		return rec, nil, fmt.Errorf("bankfile: BAI2 line %d: expected a %s record, got %s", rec.line, code, rec.code)
+	}
+	p.i++
+	return rec, newFieldReader(rec.segs), nil
+}
+
+func (p *bai2Parser) file() (*BAI2, error) {
+	header, f, err := p.expect("01")
+	if err != nil {
+		return nil, err
+	}
+	file := &BAI2{SenderID: f.field(), ReceiverID: f.field()}
+	n := &numbers{line: header.line}
+	file.Created = n.dateTime(f.field(), f.field())
+	file.FileID = f.field()
+	f.field() // Physical record length.
+	f.field() // Block size.
+	file.Version = n.int(f.field())
+	if n.err != nil {
+		return nil, n.err
+	}
+	if file.Version != 0 && file.Version != 2 {
+		return nil, fmt.Errorf("bankfile: BAI2 line %d: unsupported version %d", header.line, file.Version)
+	}
+	records := len(header.segs)
+
+	for p.peek() == "02" {
+		group, count, err := p.group()
+		if err != nil {
+			return nil, err
+		}
+		file.Groups = append(file.Groups, group)
+		records += count
+	}
+
+	trailer, f, err := p.expect("99")
+	if err != nil {
+		return nil, err
+	}
+	records += len(trailer.segs)
+	n = &numbers{line: trailer.line}
+	file.ControlTotal = n.amount(f.field())
+	groups, count := n.int(f.field()), n.int(f.field())
+	if n.err != nil {
+		return nil, n.err
+	}
+	var total int64
+	for _, g := range file.Groups {
+		total += g.ControlTotal
+	}
+	if err := check(trailer.line, "file", total, file.ControlTotal, len(file.Groups), groups, records, count); err != nil {
+		return nil, err
+	}
+	if p.i < len(p.records) {
+		return nil, fmt.Errorf("bankfile: BAI2 line %d: record after the file trailer", p.records[p.i].line)
+	}
+	return file, nil
+}
+
+func (p *bai2Parser) group() (BAI2Group, int, error) {
+	header, f, _ := p.expect("02")
+	n := &numbers{line: header.line}
+	g := BAI2Group{UltimateReceiverID: f.field(), OriginatorID: f.field()}
+	g.Status = n.int(f.field())
+	g.AsOf = n.dateTime(f.field(), f.field())
+	g.Currency = f.field()
+	g.AsOfDateModifier = n.int(f.field())
+	if n.err != nil {
+		return g, 0, n.err
+	}
+	records := len(header.segs)
+
+	for p.peek() == "03" {
+		account, count, err := p.account(g.Currency)
+		if err != nil {
+			return g, 0, err
+		}
+		g.Accounts = append(g.Accounts, account)
+		records += count
+	}
+
+	trailer, f, err := p.expect("98")
+	if err != nil {
+		return g, 0, err
+	}
+	records += len(trailer.segs)
+	n = &numbers{line: trailer.line}
+	g.ControlTotal = n.amount(f.field())
+	accounts, count := n.int(f.field()), n.int(f.field())
+	if n.err != nil {
+		return g, 0, n.err
+	}
+	var total int64
+	for _, a := range g.Accounts {
+		total += a.ControlTotal
+	}
+	if err := check(trailer.line, "group", total, g.ControlTotal, len(g.Accounts), accounts, records, count); err != nil {
+		return g, 0, err
+	}
+	return g, records, nil
+}
+
+func (p *bai2Parser) account(currency string) (BAI2Account, int, error) {
+	header, f, _ := p.expect("03")
+	n := &numbers{line: header.line}
+	a := BAI2Account{Number: f.field(), Currency: f.field()}
+	if a.Currency == "" {
+		a.Currency = currency
+	}
+	var total int64
+	// A summary ends with its physical record: fields left out before the
+	// slash take their defaults, and the continuation starts a new summary.
+	for f.open || f.advance() {
+		code := f.next()
+		if code == "" {
+			continue
+		}
+		s := BAI2Summary{TypeCode: TypeCode(n.int(code))}
+		s.Amount = n.amount(f.next())
+		s.ItemCount = n.int(f.next())
+		s.Funds = n.funds(f.next, f.left)
+		a.Summaries = append(a.Summaries, s)
+		total += s.Amount
+	}
+	if n.err != nil {
+		return a, 0, n.err
+	}
+	records := len(header.segs)
+
+	for p.peek() == "16" {
+		rec, f, _ := p.expect("16")
+		n := &numbers{line: rec.line}
+		t := BAI2Transaction{TypeCode: TypeCode(n.int(f.field()))}
+		t.Amount = n.amount(f.field())
+		t.Funds = n.funds(f.field, f.left)
+		t.BankReference = f.field()
+		t.CustomerReference = f.field()
+		t.Text = f.rest()
+		if n.err != nil {
+			return a, 0, n.err
+		}
+		a.Transactions = append(a.Transactions, t)
+		total += t.Amount
+		records += len(rec.segs)
+	}
+
+	trailer, f, err := p.expect("49")
+	if err != nil {
+		return a, 0, err
+	}
+	records += len(trailer.segs)
+	n = &numbers{line: trailer.line}
+	a.ControlTotal = n.amount(f.field())
+	count := n.int(f.field())
+	if n.err != nil {
+		return a, 0, n.err
+	}
+	if err := check(trailer.line, "account "+a.Number, total, a.ControlTotal, 0, 0, records, count); err != nil {
+		return a, 0, err
+	}
+	return a, records, nil
+}
+
+// check compares the totals and counts of a trailer with those of the records
+// it closes.
+func check(line int, what string, total, controlTotal int64, items, itemCount, records, recordCount int) error {
+	switch {
+	case total != controlTotal:
+		return fmt.Errorf("bankfile: BAI2 line %d: %s control total is %d, records add up to %d", line, what, controlTotal, total)
+	case items != itemCount:
+		return fmt.Errorf("bankfile: BAI2 line %d: %s trailer counts %d items, found %d", line, what, itemCount, items)
+	case records != recordCount:
+		return fmt.Errorf("bankfile: BAI2 line %d: %s trailer counts %d records, found %d", line, what, recordCount, records)
+	}
+	return nil
+}
+
+// fieldReader reads the comma-separated fields of a logical record.
+type fieldReader struct {
+	segs []string
+	cur  string
+	// Whether cur has fields left.
+	open bool
+}
+
+func newFieldReader(segs []string) *fieldReader {
+	r := &fieldReader{segs: segs}
+	r.advance()
+	return r
+}
+
+// advance moves to the next physical record.
+func (r *fieldReader) advance() bool {
+	if len(r.segs) == 0 {
+		r.open = false
+		return false
+	}
+	r.cur, r.segs, r.open = r.segs[0], r.segs[1:], true
+	return true
+}
+
+// next returns the next field of the current physical record, or "" when it
+// has none left.
+func (r *fieldReader) next() string {
+	if !r.open {
+		return ""
+	}
+	field, rest, ok := strings.Cut(r.cur, ",")
+	r.cur, r.open = rest, ok
+	return field
+}
+
+// field returns the next field, moving to the next physical record when the
+// current one has none left.
+func (r *fieldReader) field() string {
+	if !r.open {
+		r.advance()
+	}
+	return r.next()
+}
+
+// left returns how many fields the logical record has left, at most.
+func (r *fieldReader) left() int {
+	n := 0
+	if r.open {
+		n += strings.Count(r.cur, ",") + 1
+	}
+	for _, seg := range r.segs {
+		n += strings.Count(seg, ",") + 1
+	}
+	return n
+}
+
+// rest returns the remaining text, joining physical records with spaces.
+func (r *fieldReader) rest() string {
+	var parts []string
+	if r.open {
+		parts = append(parts, r.cur)
+	}
+	parts = append(parts, r.segs...)
+	r.segs, r.open = nil, false
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// numbers parses the fields of a record, keeping the first error.
+type numbers struct {
+	line int
+	err  error
+}
+
+func (n *numbers) fail(format string, args ...any) {
+	if n.err == nil {
+		n.err = fmt.Errorf("bankfile: BAI2 line %d: "+format, append([]any{n.line}, args...)...)
+	}
+}
+
+func (n *numbers) int(s string) int {
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		n.fail("invalid number %q", s)
+	}
+	return v
+}
+
+// amount parses an amount in the minor unit, which may carry a sign.
+func (n *numbers) amount(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		n.fail("invalid amount %q", s)
+	}
+	return v
+}
+
+// dateTime parses a YYMMDD date and an optional HHMM time, where 2400 and
+// 9999 mean the end of the day.
+func (n *numbers) dateTime(date, clock string) time.Time {
+	if date == "" {
+		return time.Time{}
+	}
+	d, err := time.Parse("060102", date)
+	if err != nil {
+		n.fail("invalid date %q", date)
+		return time.Time{}
+	}
+	switch clock {
+	case "", "0000":
+		return d
+	case "2400", "9999":
+		return d.Add(24*time.Hour - time.Second)
+	}
+	c, err := time.Parse("1504", clock)
+	if err != nil {
+		n.fail("invalid time %q", clock)
+		return d
+	}
+	return d.Add(time.Duration(c.Hour())*time.Hour + time.Duration(c.Minute())*time.Minute)
+}
+
+// funds parses a funds type and the fields that follow it. left bounds the
+// fields a distribution may claim to have.
+func (n *numbers) funds(next func() string, left func() int) Funds {
+	f := Funds{Type: next()}
+	switch f.Type {
+	case "S":
+		f.Immediate = n.amount(next())
+		f.OneDay = n.amount(next())
+		f.TwoOrMoreDays = n.amount(next())
+	case "V":
+		f.ValueDate = n.dateTime(next(), next())
+	case "D":
+		count := n.int(next())
+		if count < 0 || count > left()/2 {
+			n.fail("availability count %d exceeds the fields of the record", count)
+			break
+		}
+		for range count {
+			days := n.int(next())
+			f.Distribution = append(f.Distribution, Availability{Days: days, Amount: n.amount(next())})
+		}
+	case "", "Z", "0", "1", "2":
+	default:
+		n.fail("invalid funds type %q", f.Type)
+	}
+	return f
+}
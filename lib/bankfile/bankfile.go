@@ -0,0 +1,156 @@
+// Package bankfile parses the BAI2 and OFX account statements produced by
+// `account_statement_bai2` and `account_statement_ofx` Exports, and checks
+// their entries against the Increase Transactions they refer to.
+//
+//	f, err := bankfile.FetchBAI2(ctx, client, export.Result.FileID)
+//	if err != nil {
+//		return err
+//	}
+//	mismatches, err := bankfile.Verify(ctx, client, f.Entries())
+//	if err != nil {
+//		return err
+//	}
+//	for _, m := range mismatches {
+//		log.Printf("%s: %s", m.Entry.TransactionID, m.Reason)
+//	}
+package bankfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+var transactionIDPattern = regexp.MustCompile(`\btransaction_[a-z0-9]+\b`)
+
+// findTransactionID returns the first Increase Transaction ID in fields.
+func findTransactionID(fields ...string) string {
+	for _, f := range fields {
+		if id := transactionIDPattern.FindString(f); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// Entry is a transaction of a statement file, independent of its format.
+type Entry struct {
+	// The Increase Transaction ID the entry refers to, or "".
+	TransactionID string
+	AccountNumber string
+	// In the minor unit of the currency, negative for debits.
+	Amount      int64
+	Currency    string
+	Date        time.Time
+	Description string
+}
+
+// Entries returns the transaction details of every account of the file. Their
+// date is the as-of date of their group.
+func (f *BAI2) Entries() []Entry {
+	var entries []Entry
+	for _, g := range f.Groups {
+		for _, a := range g.Accounts {
+			for _, t := range a.Transactions {
+				description := t.Text
+				if description == "" {
+					description = t.TypeCode.Description()
+				}
+				entries = append(entries, Entry{
+					TransactionID: t.TransactionID(),
+					AccountNumber: a.Number,
+					Amount:        t.SignedAmount(),
+					Currency:      a.Currency,
+					Date:          g.AsOf,
+					Description:   description,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// Entries returns the transactions of every statement of the file. Their date
+// is the date they posted.
+func (o *OFX) Entries() []Entry {
+	var entries []Entry
+	for _, s := range o.Statements {
+		for _, t := range s.Transactions {
+			description := t.Name
+			if description == "" {
+				description = t.Memo
+			}
+			entries = append(entries, Entry{
+				TransactionID: t.TransactionID(),
+				AccountNumber: s.AccountID,
+				Amount:        t.Amount,
+				Currency:      s.Currency,
+				Date:          t.Posted,
+				Description:   description,
+			})
+		}
+	}
+	return entries
+}
+
+// Mismatch is an entry that disagrees with its Increase Transaction.
+type Mismatch struct {
+	Entry Entry
+	// Nil when the Transaction was not found.
+	Transaction *increase.Transaction
+	Reason      string
+}
+
+// Verify retrieves the Transaction of every entry that refers to one and
+// returns the entries whose Transaction is missing or has another amount or
+// currency. Entries without a Transaction ID are skipped.
+func Verify(ctx context.Context, client *increase.Client, entries []Entry, opts ...option.RequestOption) ([]Mismatch, error) {
+	var mismatches []Mismatch
+	for _, e := range entries {
+		if e.TransactionID == "" {
+			continue
+		}
+		tx, err := client.Transactions.Get(ctx, e.TransactionID, opts...)
+		if err != nil {
+			var apierr *increase.Error
+			if errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound {
+				mismatches = append(mismatches, Mismatch{Entry: e, Reason: "transaction not found"})
+				continue
+			}
+			return nil, fmt.Errorf("bankfile: retrieving %s: %w", e.TransactionID, err)
+		}
+		switch {
+		case tx.Amount != e.Amount:
+			mismatches = append(mismatches, Mismatch{Entry: e, Transaction: tx, Reason: fmt.Sprintf("amount is %d, transaction has %d", e.Amount, tx.Amount)})
+		case e.Currency != "" && string(tx.Currency) != e.Currency:
+			mismatches = append(mismatches, Mismatch{Entry: e, Transaction: tx, Reason: fmt.Sprintf("currency is %s, transaction has %s", e.Currency, tx.Currency)})
+		}
+	}
+	return mismatches, nil
+}
+
+// FetchBAI2 downloads a File and parses it as BAI2.
+func FetchBAI2(ctx context.Context, client *increase.Client, fileID string, opts ...option.RequestOption) (*BAI2, error) {
+	res, err := client.Files.Contents(ctx, fileID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bankfile: downloading %s: %w", fileID, err)
+	}
+	defer res.Body.Close()
+	return ParseBAI2(res.Body)
+}
+
+// FetchOFX downloads a File and parses it as OFX.
+func FetchOFX(ctx context.Context, client *increase.Client, fileID string, opts ...option.RequestOption) (*OFX, error) {
+	res, err := client.Files.Contents(ctx, fileID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bankfile: downloading %s: %w", fileID, err)
+	}
+	defer res.Body.Close()
+	return ParseOFX(res.Body)
+}
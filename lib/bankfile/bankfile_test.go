@@ -0,0 +1,214 @@
+package bankfile_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go/lib/bankfile"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+const bai2 = `01,INCREASE,CUSTOMER,240301,0800,1,,,2/
+02,CUSTOMER,INCREASE,1,240229,2400,USD,2/
+03,1234567890,USD,010,500000,,,015,/
+88,100,150000,2,,400,60000,1,/
+16,165,100000,0,ach_transfer_1,transaction_abc123,ACME PAYROLL/
+16,142,50000,S,30000,20000,0,,,INBOUND ACH FROM VENDOR
+88,INVOICE 42/
+16,495,60000,V,240229,1200,wire_transfer_1,,WIRE TO LANDLORD transaction_def456/
+49,920000,7/
+98,920000,1,9/
+99,920000,1,11/
+`
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20240301080000<LANGUAGE>ENG</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM><BANKID>074920909<ACCTID>1234567890<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240201<DTEND>20240301
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240205120000.000[-5:EST]<TRNAMT>1000.00<FITID>transaction_abc123<NAME>ACME PAYROLL</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240210<TRNAMT>-12.5<FITID>transaction_ghi789<NAME>Coffee &amp; Co<MEMO>Card purchase</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>5987.50<DTASOF>20240301</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <BANKMSGSRSV1>
+    <STMTTRNRS>
+      <STMTRS>
+        <CURDEF>USD</CURDEF>
+        <BANKACCTFROM><BANKID>074920909</BANKID><ACCTID>1234567890</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+        <BANKTRANLIST>
+          <DTSTART>20240201</DTSTART>
+          <DTEND>20240301</DTEND>
+          <STMTTRN>
+            <TRNTYPE>XFER</TRNTYPE>
+            <DTPOSTED>20240215</DTPOSTED>
+            <TRNAMT>-600.00</TRNAMT>
+            <FITID>1</FITID>
+            <PAYEE><NAME>Landlord</NAME></PAYEE>
+            <MEMO>Rent transaction_def456</MEMO>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>5387.50</BALAMT><DTASOF>20240301</DTASOF></LEDGERBAL>
+        <AVAILBAL><BALAMT>5000</BALAMT><DTASOF>20240301</DTASOF></AVAILBAL>
+      </STMTRS>
+    </STMTTRNRS>
+  </BANKMSGSRSV1>
+</OFX>
+`
+
+func TestParseBAI2(t *testing.T) {
+	f, err := bankfile.ParseBAI2(strings.NewReader(bai2))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if f.SenderID != "INCREASE" || f.Version != 2 || !f.Created.Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)) || len(f.Groups) != 1 {
+		t.Fatalf("unexpected file %+v", f)
+	}
+	g := f.Groups[0]
+	if !g.AsOf.Equal(time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)) || len(g.Accounts) != 1 {
+		t.Fatalf("unexpected group %+v", g)
+	}
+	a := g.Accounts[0]
+	if len(a.Summaries) != 4 {
+		t.Fatalf("expected 4 summaries, got %+v", a.Summaries)
+	}
+	if s, ok := a.Summary(100); !ok || s.Amount != 150000 || s.ItemCount != 2 || s.TypeCode.Class() != bankfile.ClassCredit {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if s, ok := a.Summary(15); !ok || s.Amount != 0 || s.TypeCode.String() != "015" || s.TypeCode.Description() != "Closing Ledger" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	if len(a.Transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %+v", a.Transactions)
+	}
+	if tx := a.Transactions[0]; tx.BankReference != "ach_transfer_1" || tx.TransactionID() != "transaction_abc123" || tx.Text != "ACME PAYROLL" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if tx := a.Transactions[1]; tx.Text != "INBOUND ACH FROM VENDOR INVOICE 42" || tx.Funds.Type != "S" || tx.Funds.Immediate != 30000 || tx.Funds.OneDay != 20000 || tx.TransactionID() != "" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if tx := a.Transactions[2]; tx.SignedAmount() != -60000 || !tx.Funds.ValueDate.Equal(time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)) || tx.TransactionID() != "transaction_def456" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+
+	entries := f.Entries()
+	if len(entries) != 3 || entries[2].Amount != -60000 || entries[2].AccountNumber != "1234567890" || entries[2].Currency != "USD" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestParseBAI2Errors(t *testing.T) {
+	for name, body := range map[string]string{
+		"control total":  strings.Replace(bai2, "49,920000,7/", "49,920001,7/", 1),
+		"records":        strings.Replace(bai2, "98,920000,1,9/", "98,920000,1,8/", 1),
+		"expected a 49":  strings.Replace(bai2, "49,920000,7/\n", "", 1),
+		"invalid amount": strings.Replace(bai2, "16,165,100000,", "16,165,1000X0,", 1),
+		"continuation":   "88,1/\n" + bai2,
+		"availability":   strings.Replace(bai2, "16,165,100000,0,", "16,165,100000,D,999999999999,", 1),
+	} {
+		if _, err := bankfile.ParseBAI2(strings.NewReader(body)); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("expected an error containing %q, got %v", name, err)
+		}
+	}
+}
+
+func TestParseOFX(t *testing.T) {
+	o, err := bankfile.ParseOFX(strings.NewReader(ofxSGML))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(o.Statements) != 1 {
+		t.Fatalf("expected 1 statement, got %+v", o)
+	}
+	s := o.Statements[0]
+	if s.Currency != "USD" || s.AccountID != "1234567890" || s.AccountType != "CHECKING" || s.Ledger.Amount != 598750 || s.Available != nil {
+		t.Errorf("unexpected statement %+v", s)
+	}
+	if !s.Start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || len(s.Transactions) != 2 {
+		t.Fatalf("unexpected statement %+v", s)
+	}
+	if tx := s.Transactions[0]; tx.Amount != 100000 || !tx.Posted.Equal(time.Date(2024, 2, 5, 17, 0, 0, 0, time.UTC)) || tx.TransactionID() != "transaction_abc123" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if tx := s.Transactions[1]; tx.Amount != -1250 || tx.Name != "Coffee & Co" || tx.Memo != "Card purchase" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+
+	o, err = bankfile.ParseOFX(strings.NewReader(ofxXML))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	s = o.Statements[0]
+	if s.Available == nil || s.Available.Amount != 500000 || len(s.Transactions) != 1 {
+		t.Fatalf("unexpected statement %+v", s)
+	}
+	if tx := s.Transactions[0]; tx.Amount != -60000 || tx.Name != "Landlord" || tx.TransactionID() != "transaction_def456" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+
+	if _, err := bankfile.ParseOFX(strings.NewReader(strings.Replace(ofxSGML, "-12.5", "-12.505", 1))); err == nil {
+		t.Errorf("expected amounts with more than two decimals to be rejected")
+	}
+	if _, err := bankfile.ParseOFX(strings.NewReader("OFXHEADER:100")); err == nil {
+		t.Errorf("expected files without an OFX element to be rejected")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/file_1/contents", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte(ofxSGML))
+	})
+	mux.HandleFunc("GET /transactions/transaction_abc123", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "transaction_abc123", "amount": 100000, "currency": "USD"}`))
+	})
+	mux.HandleFunc("GET /transactions/transaction_ghi789", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "transaction_ghi789", "amount": -1200, "currency": "USD"}`))
+	})
+	mux.HandleFunc("GET /transactions/transaction_def456", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status": 404, "type": "object_not_found_error", "title": "Not found"}`))
+	})
+	client := testapi.NewClient(t, mux)
+
+	o, err := bankfile.FetchOFX(context.Background(), client, "file_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	f, err := bankfile.ParseBAI2(strings.NewReader(bai2))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	mismatches, err := bankfile.Verify(context.Background(), client, append(o.Entries(), f.Entries()...))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("expected 2 mismatches, got %+v", mismatches)
+	}
+	if m := mismatches[0]; m.Entry.TransactionID != "transaction_ghi789" || m.Transaction == nil || !strings.Contains(m.Reason, "amount is -1250") {
+		t.Errorf("unexpected mismatch %+v", m)
+	}
+	if m := mismatches[1]; m.Entry.TransactionID != "transaction_def456" || m.Transaction != nil || m.Reason != "transaction not found" {
+		t.Errorf("unexpected mismatch %+v", m)
+	}
+}
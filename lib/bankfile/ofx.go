@@ -0,0 +1,313 @@
+package bankfile
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OFX is the bank statements of an OFX response.
+type OFX struct {
+	Statements []OFXStatement
+}
+
+// OFXStatement is a bank statement response (STMTRS).
+type OFXStatement struct {
+	Currency    string
+	BankID      string
+	BranchID    string
+	AccountID   string
+	AccountType string
+	// The period of the transaction list.
+	Start        time.Time
+	End          time.Time
+	Transactions []OFXTransaction
+	Ledger       OFXBalance
+	// Nil when the statement has no available balance.
+	Available *OFXBalance
+}
+
+// OFXBalance is a balance as of a moment.
+type OFXBalance struct {
+	// In the minor unit of the currency.
+	Amount int64
+	AsOf   time.Time
+}
+
+// OFXTransaction is a statement transaction (STMTTRN).
+type OFXTransaction struct {
+	// Such as "CREDIT", "DEBIT", "CHECK" or "XFER".
+	Type      string
+	Posted    time.Time
+	User      time.Time
+	Available time.Time
+	// In the minor unit of the currency, negative for debits.
+	Amount int64
+	// The financial institution's ID of the transaction.
+	FITID           string
+	ServerID        string
+	CheckNumber     string
+	ReferenceNumber string
+	Name            string
+	Memo            string
+}
+
+// TransactionID returns the Increase Transaction ID the transaction refers
+// to, or "".
+func (t OFXTransaction) TransactionID() string {
+	return findTransactionID(t.FITID, t.ServerID, t.ReferenceNumber, t.Memo, t.Name)
+}
+
+// ParseOFX parses the bank statements of an OFX file. Both the SGML syntax of
+// OFX 1, where elements holding values are not closed, and the XML syntax of
+// OFX 2 are accepted. Amounts are read with two decimal places.
+func ParseOFX(r io.Reader) (*OFX, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("bankfile: reading OFX: %w", err)
+	}
+	root, err := parseOFXTree(string(data))
+	if err != nil {
+		return nil, err
+	}
+	o := &OFX{}
+	for _, node := range root.all("STMTRS") {
+		s, err := ofxStatement(node)
+		if err != nil {
+			return nil, err
+		}
+		o.Statements = append(o.Statements, s)
+	}
+	return o, nil
+}
+
+func ofxStatement(node *ofxNode) (OFXStatement, error) {
+	v := &ofxValues{}
+	s := OFXStatement{
+		Currency:    node.value("CURDEF"),
+		BankID:      node.value("BANKACCTFROM", "BANKID"),
+		BranchID:    node.value("BANKACCTFROM", "BRANCHID"),
+		AccountID:   node.value("BANKACCTFROM", "ACCTID"),
+		AccountType: node.value("BANKACCTFROM", "ACCTTYPE"),
+		Start:       v.time(node.value("BANKTRANLIST", "DTSTART")),
+		End:         v.time(node.value("BANKTRANLIST", "DTEND")),
+		Ledger: OFXBalance{
+			Amount: v.amount(node.value("LEDGERBAL", "BALAMT")),
+			AsOf:   v.time(node.value("LEDGERBAL", "DTASOF")),
+		},
+	}
+	if node.child("AVAILBAL") != nil {
+		s.Available = &OFXBalance{
+			Amount: v.amount(node.value("AVAILBAL", "BALAMT")),
+			AsOf:   v.time(node.value("AVAILBAL", "DTASOF")),
+		}
+	}
+	if list := node.child("BANKTRANLIST"); list != nil {
+		for _, tx := range list.children {
+			if tx.name != "STMTTRN" {
+				continue
+			}
+			name := tx.value("NAME")
+			if name == "" {
+				name = tx.value("PAYEE", "NAME")
+			}
+			s.Transactions = append(s.Transactions, OFXTransaction{
+				Type:            tx.value("TRNTYPE"),
+				Posted:          v.time(tx.value("DTPOSTED")),
+				User:            v.time(tx.value("DTUSER")),
+				Available:       v.time(tx.value("DTAVAIL")),
+				Amount:          v.amount(tx.value("TRNAMT")),
+				FITID:           tx.value("FITID"),
+				ServerID:        tx.value("SRVRTID"),
+				CheckNumber:     tx.value("CHECKNUM"),
+				ReferenceNumber: tx.value("REFNUM"),
+				Name:            name,
+				Memo:            tx.value("MEMO"),
+			})
+		}
+	}
+	if v.err != nil {
+		return s, fmt.Errorf("bankfile: OFX statement of account %s: %w", s.AccountID, v.err)
+	}
+	return s, nil
+}
+
+// ofxNode is an element of an OFX document.
+type ofxNode struct {
+	name     string
+	text     string
+	children []*ofxNode
+}
+
+func (n *ofxNode) child(name string) *ofxNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// value returns the text of the descendant at path, or "".
+func (n *ofxNode) value(path ...string) string {
+	for _, name := range path {
+		if n = n.child(name); n == nil {
+			return ""
+		}
+	}
+	return n.text
+}
+
+// all returns the descendants named name, in document order.
+func (n *ofxNode) all(name string) []*ofxNode {
+	var res []*ofxNode
+	for _, c := range n.children {
+		if c.name == name {
+			res = append(res, c)
+			continue
+		}
+		res = append(res, c.all(name)...)
+	}
+	return res
+}
+
+// parseOFXTree parses the elements of an OFX document into a tree. An element
+// followed by text holds a value; in SGML it is closed by the next tag.
+func parseOFXTree(data string) (*ofxNode, error) {
+	start := strings.Index(data, "<OFX>")
+	if start == -1 {
+		return nil, errors.New("bankfile: OFX has no OFX element")
+	}
+	root := &ofxNode{}
+	stack := []*ofxNode{root}
+	rest := data[start:]
+	for rest != "" {
+		open := strings.IndexByte(rest, '<')
+		if open == -1 {
+			break
+		}
+		if text := strings.TrimSpace(rest[:open]); text != "" {
+			top := stack[len(stack)-1]
+			if top == root || len(top.children) > 0 {
+				return nil, fmt.Errorf("bankfile: OFX has text %q outside an element", text)
+			}
+			top.text = html.UnescapeString(text)
+		}
+		end := strings.IndexByte(rest[open:], '>')
+		if end == -1 {
+			return nil, errors.New("bankfile: OFX has an unterminated tag")
+		}
+		tag := strings.TrimSpace(rest[open+1 : open+end])
+		rest = rest[open+end+1:]
+
+		switch {
+		case tag == "", strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+		case strings.HasPrefix(tag, "/"):
+			name := strings.TrimSpace(tag[1:])
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			// A value element left open by SGML ends where the next tag starts.
+			if top := stack[len(stack)-1]; top.text != "" {
+				stack = stack[:len(stack)-1]
+			}
+			name, _, _ := strings.Cut(strings.TrimSuffix(tag, "/"), " ")
+			node := &ofxNode{name: name}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			if !strings.HasSuffix(tag, "/") {
+				stack = append(stack, node)
+			}
+		}
+	}
+	return root, nil
+}
+
+// ofxValues parses the values of an OFX document, keeping the first error.
+type ofxValues struct {
+	err error
+}
+
+// amount parses a decimal amount into the minor unit.
+func (v *ofxValues) amount(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	minor, err := parseDecimal(s, 2)
+	if err != nil && v.err == nil {
+		v.err = err
+	}
+	return minor
+}
+
+func (v *ofxValues) time(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := parseOFXTime(s)
+	if err != nil && v.err == nil {
+		v.err = err
+	}
+	return t
+}
+
+// parseDecimal parses a decimal amount, with a period or a comma as the
+// decimal separator, into an integer with places decimal places.
+func parseDecimal(s string, places int) (int64, error) {
+	value := strings.TrimSpace(s)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimLeft(value, "+-")
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	whole, frac, _ := strings.Cut(value, ".")
+	if len(frac) > places || whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	digits := whole + frac + strings.Repeat("0", places-len(frac))
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || strings.ContainsAny(digits, "+-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// parseOFXTime parses an OFX date and time, such as 20240301 or
+// 20240301120000.000[-5:EST]. Without a time zone it is read in UTC.
+func parseOFXTime(s string) (time.Time, error) {
+	value, zone, _ := strings.Cut(s, "[")
+	value, _, _ = strings.Cut(value, ".")
+	layout := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}[len(value)]
+	if layout == "" {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	loc := time.UTC
+	if zone != "" {
+		zone = strings.TrimSuffix(zone, "]")
+		offset, name, _ := strings.Cut(zone, ":")
+		hours, err := strconv.ParseFloat(offset, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", s)
+		}
+		if name == "" {
+			name = offset
+		}
+		loc = time.FixedZone(name, int(hours*3600))
+	}
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,233 @@
+// Package carddetails retrieves the sensitive details of a Card, such as the
+// primary account number, verification code and PIN, without exposing them
+// to logs, panics or JSON responses.
+//
+// [Retrieve] returns a [SensitiveCardDetails] whose String, GoString,
+// MarshalJSON and LogValue methods mask everything but the last four digits
+// of the card number. The details are only available through an explicit
+// call to [SensitiveCardDetails.Reveal], and are zeroed by
+// [SensitiveCardDetails.Close]. [SensitiveCardDetails.Seal] encrypts the
+// details with a caller-supplied key before they leave your process.
+//
+//	details, err := carddetails.Retrieve(ctx, client, "card_oubs0hwk5rn6knuecxg2")
+//	if err != nil {
+//		return err
+//	}
+//	defer details.Close()
+//	slog.Info("retrieved card details", "details", details) // masked
+package carddetails
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrClosed is returned when revealing or sealing details after Close.
+var ErrClosed = errors.New("carddetails: details have been closed")
+
+// SensitiveCardDetails holds the sensitive details of a Card. The zero value
+// is closed. Copies share the secrets, and print, marshal and log them masked
+// like the original.
+type SensitiveCardDetails struct {
+	CardID          string
+	ExpirationMonth int64
+	ExpirationYear  int64
+	// The last four digits of the primary account number.
+	Last4 string
+
+	secrets *secrets
+}
+
+// secrets are kept behind a pointer so that copies of SensitiveCardDetails
+// share them and fmt, which does not follow nested pointers, never prints
+// them. Its methods redact it wherever else it is reached.
+type secrets struct {
+	mu sync.Mutex
+	// The buffers the secrets were read into, zeroed on Close.
+	buffers [][]byte
+	pan     []byte
+	cvv     []byte
+	pin     []byte
+	open    bool
+}
+
+func (*secrets) String() string                { return "[redacted]" }
+func (*secrets) GoString() string              { return "[redacted]" }
+func (*secrets) Format(f fmt.State, verb rune) { f.Write([]byte("[redacted]")) }
+func (*secrets) MarshalJSON() ([]byte, error)  { return []byte(`"[redacted]"`), nil }
+func (*secrets) LogValue() slog.Value          { return slog.StringValue("[redacted]") }
+
+func newSecrets(buffers [][]byte, pan, cvv, pin []byte) *secrets {
+	return &secrets{buffers: buffers, pan: pan, cvv: cvv, pin: pin, open: true}
+}
+
+// Revealed is the plain text of the sensitive details. Go strings cannot be
+// zeroed, so keep it in scope for as short a time as possible.
+type Revealed struct {
+	PrimaryAccountNumber string
+	VerificationCode     string
+	Pin                  string
+}
+
+// response mirrors [increase.CardDetails], keeping the secrets as raw JSON so
+// that they are never copied into strings.
+type response struct {
+	CardID               string          `json:"card_id"`
+	ExpirationMonth      int64           `json:"expiration_month"`
+	ExpirationYear       int64           `json:"expiration_year"`
+	Pin                  json.RawMessage `json:"pin"`
+	PrimaryAccountNumber json.RawMessage `json:"primary_account_number"`
+	VerificationCode     json.RawMessage `json:"verification_code"`
+}
+
+// Retrieve calls [increase.CardService.Details] and wraps the result. The
+// response body is read into buffers that Close zeroes, rather than into an
+// [increase.CardDetails].
+func Retrieve(ctx context.Context, client *increase.Client, cardID string, opts ...option.RequestOption) (*SensitiveCardDetails, error) {
+	var body []byte
+	detailsOpts := append(append([]option.RequestOption{}, opts...), option.WithResponseBodyInto(&body))
+	if _, err := client.Cards.Details(ctx, cardID, detailsOpts...); err != nil {
+		return nil, err
+	}
+	defer clear(body)
+	var r response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("carddetails: parsing card details: %w", err)
+	}
+	s := &SensitiveCardDetails{
+		CardID:          r.CardID,
+		ExpirationMonth: r.ExpirationMonth,
+		ExpirationYear:  r.ExpirationYear,
+		secrets: newSecrets(
+			[][]byte{r.PrimaryAccountNumber, r.VerificationCode, r.Pin},
+			unquote(r.PrimaryAccountNumber), unquote(r.VerificationCode), unquote(r.Pin),
+		),
+	}
+	s.Last4 = last4(s.secrets.pan)
+	return s, nil
+}
+
+// Wrap wraps details that have already been retrieved. The strings of d
+// cannot be zeroed; prefer [Retrieve].
+func Wrap(d increase.CardDetails) *SensitiveCardDetails {
+	pan, cvv, pin := []byte(d.PrimaryAccountNumber), []byte(d.VerificationCode), []byte(d.Pin)
+	return &SensitiveCardDetails{
+		CardID:          d.CardID,
+		ExpirationMonth: d.ExpirationMonth,
+		ExpirationYear:  d.ExpirationYear,
+		Last4:           last4(pan),
+		secrets:         newSecrets([][]byte{pan, cvv, pin}, pan, cvv, pin),
+	}
+}
+
+// unquote returns the contents of a JSON string of digits without copying it.
+func unquote(raw json.RawMessage) []byte {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		return raw[1 : len(raw)-1]
+	}
+	return nil
+}
+
+func last4(pan []byte) string {
+	if len(pan) < 4 {
+		return ""
+	}
+	return string(pan[len(pan)-4:])
+}
+
+// Reveal returns the plain text of the details.
+func (s *SensitiveCardDetails) Reveal() (Revealed, error) {
+	x := s.secrets
+	if x == nil {
+		return Revealed{}, ErrClosed
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.open {
+		return Revealed{}, ErrClosed
+	}
+	return Revealed{
+		PrimaryAccountNumber: string(x.pan),
+		VerificationCode:     string(x.cvv),
+		Pin:                  string(x.pin),
+	}, nil
+}
+
+// Close zeroes the details, and those of every copy. It is safe to call more
+// than once.
+func (s *SensitiveCardDetails) Close() error {
+	x := s.secrets
+	if x == nil {
+		return nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, b := range x.buffers {
+		clear(b)
+	}
+	x.buffers, x.pan, x.cvv, x.pin, x.open = nil, nil, nil, nil, false
+	return nil
+}
+
+// MaskedPrimaryAccountNumber returns the card number with every digit but the
+// last four masked.
+func (s SensitiveCardDetails) MaskedPrimaryAccountNumber() string {
+	n := 16
+	if x := s.secrets; x != nil {
+		x.mu.Lock()
+		if len(x.pan) > 4 {
+			n = len(x.pan)
+		}
+		x.mu.Unlock()
+	}
+	if s.Last4 == "" {
+		return strings.Repeat("*", n)
+	}
+	return strings.Repeat("*", n-4) + s.Last4
+}
+
+func (s SensitiveCardDetails) expiration() string {
+	return fmt.Sprintf("%02d/%04d", s.ExpirationMonth, s.ExpirationYear)
+}
+
+// String returns the masked details.
+func (s SensitiveCardDetails) String() string {
+	return fmt.Sprintf("%s %s exp %s", s.CardID, s.MaskedPrimaryAccountNumber(), s.expiration())
+}
+
+// GoString returns the masked details, for the %#v verb.
+func (s SensitiveCardDetails) GoString() string {
+	return fmt.Sprintf("carddetails.SensitiveCardDetails{CardID:%q, PrimaryAccountNumber:%q, ExpirationMonth:%d, ExpirationYear:%d, VerificationCode:\"***\", Pin:\"****\"}",
+		s.CardID, s.MaskedPrimaryAccountNumber(), s.ExpirationMonth, s.ExpirationYear)
+}
+
+// MarshalJSON returns the masked details in the shape of
+// [increase.CardDetails].
+func (s SensitiveCardDetails) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"card_id":                s.CardID,
+		"expiration_month":       s.ExpirationMonth,
+		"expiration_year":        s.ExpirationYear,
+		"primary_account_number": s.MaskedPrimaryAccountNumber(),
+		"verification_code":      "***",
+		"pin":                    "****",
+		"type":                   "card_details",
+	})
+}
+
+// LogValue returns the masked details for [log/slog].
+func (s SensitiveCardDetails) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("card_id", s.CardID),
+		slog.String("primary_account_number", s.MaskedPrimaryAccountNumber()),
+		slog.String("expiration", s.expiration()),
+	)
+}
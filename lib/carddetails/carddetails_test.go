@@ -0,0 +1,152 @@
+package carddetails_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Increase/increase-go/lib/carddetails"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+func retrieve(t *testing.T) *carddetails.SensitiveCardDetails {
+	t.Helper()
+	client := testapi.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cards/card_1/details" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"card_id":"card_1","expiration_month":7,"expiration_year":2029,"pin":"1234","primary_account_number":"4242424242424242","verification_code":"987","type":"card_details"}`))
+	}))
+	details, err := carddetails.Retrieve(context.Background(), client, "card_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return details
+}
+
+func TestMasking(t *testing.T) {
+	details := retrieve(t)
+	defer details.Close()
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("details", "details", details)
+	marshaled, err := json.Marshal(details)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	for _, out := range []string{
+		details.String(),
+		fmt.Sprintf("%v %+v %#v %s", details, details, details, details),
+		string(marshaled),
+		logs.String(),
+	} {
+		if strings.Contains(out, "4242424242424242") || strings.Contains(out, "987") || strings.Contains(out, "1234") {
+			t.Errorf("expected the details to be masked, got %s", out)
+		}
+		if !strings.Contains(out, "************4242") {
+			t.Errorf("expected the last four digits, got %s", out)
+		}
+	}
+
+	revealed, err := details.Reveal()
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if revealed.PrimaryAccountNumber != "4242424242424242" || revealed.VerificationCode != "987" || revealed.Pin != "1234" {
+		t.Errorf("unexpected revealed details %+v", revealed)
+	}
+
+	details.Close()
+	if _, err := details.Reveal(); !errors.Is(err, carddetails.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	if !strings.Contains(details.String(), "4242") {
+		t.Errorf("expected the last four digits to survive Close, got %s", details)
+	}
+}
+
+// secret matches the fixture's secrets, as text or as the bytes of a []byte.
+var secret = regexp.MustCompile(`\b(4242424242424242|987|1234)\b|\[(52|57|49) `)
+
+func TestMaskingCopies(t *testing.T) {
+	details := retrieve(t)
+	defer details.Close()
+
+	value := *details
+	embedded := struct {
+		carddetails.SensitiveCardDetails
+		Note string
+	}{value, "note"}
+	named := struct {
+		Details carddetails.SensitiveCardDetails
+		details carddetails.SensitiveCardDetails
+	}{value, value}
+
+	var logs bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logs, nil)).Info("details", "value", value, "embedded", embedded, "named", named)
+	marshaled, err := json.Marshal([]any{value, embedded, named})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	for _, out := range []string{
+		fmt.Sprintf("%v %+v %#v %s", value, value, value, value),
+		fmt.Sprintf("%v %+v %#v", embedded, embedded, embedded),
+		fmt.Sprintf("%v %+v %#v", named, named, named),
+		fmt.Sprintf("%v %+v %#v", []carddetails.SensitiveCardDetails{value}, []carddetails.SensitiveCardDetails{value}, []carddetails.SensitiveCardDetails{value}),
+		string(marshaled),
+		logs.String(),
+	} {
+		if secret.MatchString(out) {
+			t.Errorf("expected the details to be masked, got %s", out)
+		}
+	}
+
+	// Copies share the secrets, so closing one closes them all.
+	details.Close()
+	if _, err := value.Reveal(); !errors.Is(err, carddetails.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestSeal(t *testing.T) {
+	details := retrieve(t)
+	defer details.Close()
+
+	kek := bytes.Repeat([]byte{1}, 32)
+	wrapper, err := carddetails.AESKeyWrapper(kek)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	envelope, err := details.Seal(wrapper)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	stored, _ := json.Marshal(envelope)
+	if bytes.Contains(stored, []byte("4242424242424242")) {
+		t.Fatalf("expected the envelope to be encrypted")
+	}
+
+	var decoded carddetails.Envelope
+	json.Unmarshal(stored, &decoded)
+	opened, err := carddetails.Open(&decoded, wrapper)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	defer opened.Close()
+	revealed, _ := opened.Reveal()
+	if revealed.PrimaryAccountNumber != "4242424242424242" || opened.ExpirationYear != 2029 {
+		t.Errorf("unexpected opened details %+v", revealed)
+	}
+
+	other, _ := carddetails.AESKeyWrapper(bytes.Repeat([]byte{2}, 32))
+	if _, err := carddetails.Open(&decoded, other); err == nil {
+		t.Errorf("expected opening with another key to fail")
+	}
+}
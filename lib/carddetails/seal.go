@@ -0,0 +1,165 @@
+package carddetails
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// KeyWrapper encrypts and decrypts the data keys of envelopes with a key
+// encryption key that you hold, for example in a KMS.
+type KeyWrapper interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// AESKeyWrapper returns a KeyWrapper that wraps data keys with AES-GCM under a
+// 16, 24 or 32 byte key encryption key.
+func AESKeyWrapper(kek []byte) (KeyWrapper, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("carddetails: invalid key encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesKeyWrapper{aead}, nil
+}
+
+type aesKeyWrapper struct {
+	aead cipher.AEAD
+}
+
+func (w aesKeyWrapper) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(w.aead, dataKey)
+}
+
+func (w aesKeyWrapper) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return open(w.aead, wrapped)
+}
+
+// Envelope is card details encrypted with a random data key, itself wrapped by
+// a [KeyWrapper]. It is safe to store and to marshal to JSON.
+type Envelope struct {
+	CardID string `json:"card_id"`
+	// The data key, wrapped by the KeyWrapper.
+	WrappedKey []byte `json:"wrapped_key"`
+	// The nonce followed by the AES-256-GCM ciphertext of the details.
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// plaintext is the sealed form of the details. The secrets are []byte so that
+// they are encoded without being copied into strings.
+type plaintext struct {
+	CardID               string `json:"card_id"`
+	ExpirationMonth      int64  `json:"expiration_month"`
+	ExpirationYear       int64  `json:"expiration_year"`
+	PrimaryAccountNumber []byte `json:"primary_account_number"`
+	VerificationCode     []byte `json:"verification_code"`
+	Pin                  []byte `json:"pin"`
+}
+
+// Seal encrypts the details into an envelope whose data key is wrapped by w.
+// The details stay open; Close them once they are no longer needed.
+func (s *SensitiveCardDetails) Seal(w KeyWrapper) (*Envelope, error) {
+	x := s.secrets
+	if x == nil {
+		return nil, ErrClosed
+	}
+	x.mu.Lock()
+	if !x.open {
+		x.mu.Unlock()
+		return nil, ErrClosed
+	}
+	data, err := json.Marshal(plaintext{
+		CardID:               s.CardID,
+		ExpirationMonth:      s.ExpirationMonth,
+		ExpirationYear:       s.ExpirationYear,
+		PrimaryAccountNumber: x.pan,
+		VerificationCode:     x.cvv,
+		Pin:                  x.pin,
+	})
+	x.mu.Unlock()
+	defer clear(data)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 32)
+	defer clear(key)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, data)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := w.WrapKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("carddetails: wrapping data key: %w", err)
+	}
+	return &Envelope{CardID: s.CardID, WrappedKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open decrypts an envelope sealed by [SensitiveCardDetails.Seal].
+func Open(e *Envelope, w KeyWrapper) (*SensitiveCardDetails, error) {
+	key, err := w.UnwrapKey(e.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("carddetails: unwrapping data key: %w", err)
+	}
+	defer clear(key)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := open(aead, e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("carddetails: decrypting envelope: %w", err)
+	}
+	defer clear(data)
+	var p plaintext
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("carddetails: parsing envelope: %w", err)
+	}
+	return &SensitiveCardDetails{
+		CardID:          p.CardID,
+		ExpirationMonth: p.ExpirationMonth,
+		ExpirationYear:  p.ExpirationYear,
+		Last4:           last4(p.PrimaryAccountNumber),
+		secrets: newSecrets(
+			[][]byte{p.PrimaryAccountNumber, p.VerificationCode, p.Pin},
+			p.PrimaryAccountNumber, p.VerificationCode, p.Pin,
+		),
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
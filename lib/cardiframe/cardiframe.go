@@ -0,0 +1,280 @@
+// Package cardiframe serves Card details iframes to your end users.
+//
+// [Handler] authenticates the caller with a pluggable hook, checks that the
+// Card belongs to one of the caller's Entities or Accounts, creates an iframe
+// with [increase.CardService.NewDetailsIframe], and responds with a redirect
+// to it or with a JSON payload. Requests are rate limited per user and Card.
+//
+//	handler := cardiframe.NewHandler(client, func(r *http.Request) (*cardiframe.Principal, error) {
+//		user, err := sessions.User(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &cardiframe.Principal{ID: user.ID, EntityIDs: []string{user.EntityID}}, nil
+//	})
+//	mux.Handle("GET /cards/{card_id}/iframe", handler)
+package cardiframe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+)
+
+var (
+	// ErrForbidden is reported when the Card does not belong to the caller.
+	ErrForbidden = errors.New("cardiframe: card does not belong to the caller")
+	// ErrRateLimited is reported when a Card has had too many iframes
+	// requested.
+	ErrRateLimited = errors.New("cardiframe: too many iframes requested for the card")
+	// ErrExpired is reported when the iframe returned by the API has already
+	// expired.
+	ErrExpired = errors.New("cardiframe: iframe url has expired")
+)
+
+// Principal is an authenticated end user and what they may access.
+type Principal struct {
+	ID string
+	// The Entities whose Cards the user may view.
+	EntityIDs []string
+	// The Accounts whose Cards the user may view.
+	AccountIDs []string
+}
+
+// Authenticator authenticates the caller of a request. Returning an error
+// responds with 401 Unauthorized.
+type Authenticator func(r *http.Request) (*Principal, error)
+
+// Response is how the iframe is returned.
+type Response string
+
+const (
+	// Respond with {"iframe_url": ..., "expires_at": ...}.
+	ResponseJSON Response = "json"
+	// Respond with a 303 See Other redirect to the iframe.
+	ResponseRedirect Response = "redirect"
+)
+
+// Handler is an [http.Handler] that issues Card details iframes. The Card is
+// read from the `card_id` path value or query parameter, and an optional
+// Physical Card from the `physical_card_id` query parameter. It is safe for
+// concurrent use.
+type Handler struct {
+	client       *increase.Client
+	authenticate Authenticator
+	// How the iframe is returned. Defaults to ResponseJSON; a request may ask
+	// for a redirect with `?response=redirect`.
+	Response Response
+	// At most Limit iframes are requested per user and Card within Window.
+	// Defaults to 5 per minute. A Limit of zero or less disables rate
+	// limiting.
+	Limit  int
+	Window time.Duration
+	// Called with every error that results in an error response, for logging.
+	OnError func(r *http.Request, err error)
+
+	mu sync.Mutex
+	// Recent requests by principal and Card, and when expired ones were last
+	// removed.
+	requests map[limitKey][]time.Time
+	swept    time.Time
+	now      func() time.Time
+}
+
+// NewHandler returns a handler that authenticates callers with authenticate.
+func NewHandler(client *increase.Client, authenticate Authenticator) *Handler {
+	return &Handler{
+		client:       client,
+		authenticate: authenticate,
+		Response:     ResponseJSON,
+		Limit:        5,
+		Window:       time.Minute,
+		requests:     map[limitKey][]time.Time{},
+		now:          time.Now,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		h.fail(w, r, http.StatusMethodNotAllowed, fmt.Errorf("cardiframe: method %s not allowed", r.Method))
+		return
+	}
+	principal, err := h.authenticate(r)
+	if err != nil || principal == nil {
+		if err == nil {
+			err = errors.New("cardiframe: no principal")
+		}
+		h.fail(w, r, http.StatusUnauthorized, err)
+		return
+	}
+	cardID := r.PathValue("card_id")
+	if cardID == "" {
+		cardID = r.URL.Query().Get("card_id")
+	}
+	if cardID == "" {
+		h.fail(w, r, http.StatusBadRequest, errors.New("cardiframe: missing card_id"))
+		return
+	}
+	// Limiting per principal keeps one user from using up the limit on
+	// another's Card.
+	if retry, ok := h.allow(limitKey{principal.ID, cardID}); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry.Round(time.Second)/time.Second)+1))
+		h.fail(w, r, http.StatusTooManyRequests, ErrRateLimited)
+		return
+	}
+
+	ctx := r.Context()
+	card, err := h.client.Cards.Get(ctx, cardID)
+	if err != nil {
+		var apierr *increase.Error
+		if errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound {
+			// Unknown cards look the same as other users' cards.
+			h.fail(w, r, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		h.fail(w, r, http.StatusBadGateway, err)
+		return
+	}
+	owned, err := h.owns(r, principal, card)
+	if err != nil {
+		h.fail(w, r, http.StatusBadGateway, err)
+		return
+	}
+	if !owned {
+		h.fail(w, r, http.StatusForbidden, ErrForbidden)
+		return
+	}
+
+	params := increase.CardNewDetailsIframeParams{}
+	if id := r.URL.Query().Get("physical_card_id"); id != "" {
+		physical, err := h.client.PhysicalCards.Get(ctx, id)
+		if err != nil {
+			var apierr *increase.Error
+			if errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound {
+				h.fail(w, r, http.StatusForbidden, ErrForbidden)
+				return
+			}
+			h.fail(w, r, http.StatusBadGateway, err)
+			return
+		}
+		if physical.CardID != card.ID {
+			h.fail(w, r, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		params.PhysicalCardID = increase.F(physical.ID)
+	}
+	iframe, err := h.client.Cards.NewDetailsIframe(ctx, card.ID, params)
+	if err != nil {
+		h.fail(w, r, http.StatusBadGateway, err)
+		return
+	}
+	if !iframe.ExpiresAt.After(h.now()) {
+		h.fail(w, r, http.StatusBadGateway, ErrExpired)
+		return
+	}
+
+	response := h.Response
+	if q := Response(r.URL.Query().Get("response")); q == ResponseJSON || q == ResponseRedirect {
+		response = q
+	}
+	if response == ResponseRedirect {
+		http.Redirect(w, r, iframe.IframeURL, http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"card_id":    card.ID,
+		"iframe_url": iframe.IframeURL,
+		"expires_at": iframe.ExpiresAt,
+	})
+}
+
+// owns reports whether the Card belongs to one of the principal's Accounts or
+// Entities. Cards without an Entity are checked against the Entity of their
+// Account.
+func (h *Handler) owns(r *http.Request, p *Principal, card *increase.Card) (bool, error) {
+	if slices.Contains(p.AccountIDs, card.AccountID) {
+		return true, nil
+	}
+	if len(p.EntityIDs) == 0 {
+		return false, nil
+	}
+	entityID := card.EntityID
+	if entityID == "" {
+		account, err := h.client.Accounts.Get(r.Context(), card.AccountID)
+		if err != nil {
+			return false, fmt.Errorf("cardiframe: retrieving account %s: %w", card.AccountID, err)
+		}
+		entityID = account.EntityID
+	}
+	return entityID != "" && slices.Contains(p.EntityIDs, entityID), nil
+}
+
+// limitKey identifies whose requests for which Card are rate limited.
+type limitKey struct {
+	principalID string
+	cardID      string
+}
+
+// allow records a request, and returns whether it is within the limit or else
+// how long until it would be.
+func (h *Handler) allow(key limitKey) (time.Duration, bool) {
+	if h.Limit <= 0 {
+		return 0, true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	if now.Sub(h.swept) >= h.Window {
+		h.sweep(now)
+	}
+	recent := h.recent(key, now)
+	if len(recent) >= h.Limit {
+		h.requests[key] = recent
+		return h.Window - now.Sub(recent[0]), false
+	}
+	h.requests[key] = append(recent, now)
+	return 0, true
+}
+
+// recent returns the requests for key within the window.
+func (h *Handler) recent(key limitKey, now time.Time) []time.Time {
+	recent := h.requests[key][:0]
+	for _, at := range h.requests[key] {
+		if now.Sub(at) < h.Window {
+			recent = append(recent, at)
+		}
+	}
+	return recent
+}
+
+// sweep forgets the keys without requests in the window, so that the requests
+// for arbitrary card IDs do not accumulate.
+func (h *Handler) sweep(now time.Time) {
+	for key := range h.requests {
+		if recent := h.recent(key, now); len(recent) == 0 {
+			delete(h.requests, key)
+		} else {
+			h.requests[key] = recent
+		}
+	}
+	h.swept = now
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"status": status, "title": http.StatusText(status)})
+}
@@ -0,0 +1,130 @@
+package cardiframe_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go/lib/cardiframe"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+func newHandler(t *testing.T) *cardiframe.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch id := r.PathValue("id"); id {
+		case "card_mine", "card_other":
+			json.NewEncoder(w).Encode(map[string]any{"id": id, "account_id": "account_" + id[len("card_"):], "entity_id": nil})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"status": 404, "type": "object_not_found_error"})
+		}
+	})
+	mux.HandleFunc("GET /accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"id": r.PathValue("id"), "entity_id": "entity_" + r.PathValue("id")[len("account_"):]})
+	})
+	mux.HandleFunc("GET /physical_cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "card_id": "card_" + id[len("physical_card_"):]})
+	})
+	mux.HandleFunc("POST /cards/{id}/create_details_iframe", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"iframe_url": "https://increase.com/card_details_iframe/" + r.PathValue("id"), "expires_at": time.Now().Add(time.Minute), "type": "card_iframe_url"})
+	})
+	client := testapi.NewClient(t, mux)
+	return cardiframe.NewHandler(client, func(r *http.Request) (*cardiframe.Principal, error) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer user":
+			return &cardiframe.Principal{ID: "user", EntityIDs: []string{"entity_mine"}}, nil
+		case "Bearer other":
+			return &cardiframe.Principal{ID: "other", EntityIDs: []string{"entity_other"}}, nil
+		}
+		return nil, errors.New("unauthenticated")
+	})
+}
+
+// serve makes a request as a user, or unauthenticated if user is "".
+func serve(h http.Handler, target string, user string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.Handle("GET /cards/{card_id}/iframe", h)
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if user != "" {
+		r.Header.Set("Authorization", "Bearer "+user)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := newHandler(t)
+
+	w := serve(h, "/cards/card_mine/iframe", "user")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		IframeURL string    `json:"iframe_url"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	json.NewDecoder(w.Body).Decode(&body)
+	if body.IframeURL != "https://increase.com/card_details_iframe/card_mine" || body.ExpiresAt.IsZero() {
+		t.Errorf("unexpected body %+v", body)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("expected the response not to be cached")
+	}
+
+	w = serve(h, "/cards/card_mine/iframe?response=redirect", "user")
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "https://increase.com/card_details_iframe/card_mine" {
+		t.Errorf("expected a redirect, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+
+	for target, want := range map[string]int{
+		"/cards/card_other/iframe":                                     http.StatusForbidden,
+		"/cards/card_missing/iframe":                                   http.StatusForbidden,
+		"/cards/card_mine/iframe?physical_card_id=physical_card_mine":  http.StatusOK,
+		"/cards/card_mine/iframe?physical_card_id=physical_card_other": http.StatusForbidden,
+	} {
+		if w := serve(h, target, "user"); w.Code != want {
+			t.Errorf("%s: expected %d, got %d", target, want, w.Code)
+		}
+	}
+	if w := serve(h, "/cards/card_mine/iframe", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	h := newHandler(t)
+	h.Limit = 2
+	var errs []error
+	h.OnError = func(r *http.Request, err error) { errs = append(errs, err) }
+	for i := 0; i < 2; i++ {
+		if w := serve(h, "/cards/card_mine/iframe", "user"); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+	w := serve(h, "/cards/card_mine/iframe", "user")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After, got %d", w.Code)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], cardiframe.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", errs)
+	}
+	if w := serve(h, "/cards/card_other/iframe", "user"); w.Code != http.StatusForbidden {
+		t.Errorf("expected other cards to have their own limit, got %d", w.Code)
+	}
+
+	// Other users' requests for a Card do not count toward its owner's limit.
+	h = newHandler(t)
+	h.Limit = 2
+	for i := 0; i < 3; i++ {
+		serve(h, "/cards/card_mine/iframe", "other")
+	}
+	if w := serve(h, "/cards/card_mine/iframe", "user"); w.Code != http.StatusOK {
+		t.Errorf("expected the owner to be within their limit, got %d", w.Code)
+	}
+}
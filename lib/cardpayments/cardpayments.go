@@ -0,0 +1,409 @@
+// Package cardpayments replays the elements of a Card Payment through an
+// explicit state machine.
+//
+// A Card Payment is a list of elements, such as authorizations, increments,
+// reversals and settlements, plus a summary in [increase.CardPaymentState].
+// [Replay] folds the elements in order into a [Payment], which tracks a
+// [Hold] per Card Authorization and exposes derived facts such as the amount
+// still held and whether the payment settled for more or less than was
+// authorized. [Payment.Validate] checks the computed totals against the state
+// reported by Increase. [Tracker] keeps payments up to date from
+// `card_payment.created` and `card_payment.updated` webhooks, folding in only
+// the elements it has not seen.
+package cardpayments
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+)
+
+// Status summarizes where a Card Payment is in its lifecycle.
+type Status string
+
+const (
+	// No authorization, settlement or decline has been seen.
+	StatusNew Status = "new"
+	// Every authorization attempt was declined.
+	StatusDeclined Status = "declined"
+	// Funds are held and nothing has settled.
+	StatusAuthorized Status = "authorized"
+	// Part of the payment has settled and funds are still held.
+	StatusPartiallySettled Status = "partially_settled"
+	// The payment settled and no funds are held.
+	StatusSettled Status = "settled"
+	// Every authorization was reversed or expired without settling.
+	StatusReversed Status = "reversed"
+	// The settled amount was refunded in full.
+	StatusRefunded Status = "refunded"
+)
+
+// State holds the totals of a Card Payment, computed the same way as
+// [increase.CardPaymentState].
+type State struct {
+	AuthorizedAmount       int64
+	FuelConfirmedAmount    int64
+	IncrementedAmount      int64
+	RefundAuthorizedAmount int64
+	RefundedAmount         int64
+	ReversedAmount         int64
+	SettledAmount          int64
+}
+
+func stateOf(s increase.CardPaymentState) State {
+	return State{
+		AuthorizedAmount:       s.AuthorizedAmount,
+		FuelConfirmedAmount:    s.FuelConfirmedAmount,
+		IncrementedAmount:      s.IncrementedAmount,
+		RefundAuthorizedAmount: s.RefundAuthorizedAmount,
+		RefundedAmount:         s.RefundedAmount,
+		ReversedAmount:         s.ReversedAmount,
+		SettledAmount:          s.SettledAmount,
+	}
+}
+
+// Anomaly is an element that the state machine did not expect, such as an
+// increment on an expired authorization or a reversal of an unknown one.
+type Anomaly struct {
+	Index    int
+	Category increase.CardPaymentElementsCategory
+	// The Card Authorization the element refers to, if any.
+	AuthorizationID string
+	Reason          string
+}
+
+// Payment is the state of a Card Payment after replaying its elements.
+type Payment struct {
+	ID        string
+	AccountID string
+	CardID    string
+	// The computed totals.
+	State State
+	// The totals reported by Increase the last time the Card Payment was
+	// retrieved.
+	Reported State
+	// The holds by Card Authorization ID, and their IDs in the order the
+	// authorizations were seen.
+	Holds            map[string]*Hold
+	AuthorizationIDs []string
+	// The Transactions posted by settlements, refunds and financials, in
+	// order.
+	TransactionIDs []string
+	Anomalies      []Anomaly
+	// The number of elements folded in so far.
+	Applied int
+	// The time of the last element folded in.
+	UpdatedAt time.Time
+
+	declines      int
+	settlements   int
+	unmatched     int64
+	refundedAfter bool
+}
+
+// New returns an empty payment for the Card Payment.
+func New(cardPaymentID string) *Payment {
+	return &Payment{ID: cardPaymentID, Holds: map[string]*Hold{}}
+}
+
+// Replay folds the elements of a Card Payment in order.
+func Replay(cp increase.CardPayment) *Payment {
+	p := New(cp.ID)
+	p.Update(cp)
+	return p
+}
+
+// Update folds in the elements of the Card Payment that have not been applied
+// yet, and records its reported state. Elements are only ever appended to a
+// Card Payment, so the elements before [Payment.Applied] are skipped.
+func (p *Payment) Update(cp increase.CardPayment) {
+	if cp.ID != "" {
+		p.ID = cp.ID
+	}
+	p.AccountID, p.CardID = cp.AccountID, cp.CardID
+	for _, e := range cp.Elements[min(p.Applied, len(cp.Elements)):] {
+		p.Apply(e)
+	}
+	p.Reported = stateOf(cp.State)
+}
+
+// Apply folds a single element into the payment.
+func (p *Payment) Apply(e increase.CardPaymentElement) {
+	index := p.Applied
+	p.Applied++
+	if e.CreatedAt.After(p.UpdatedAt) {
+		p.UpdatedAt = e.CreatedAt
+	}
+
+	switch e.Category {
+	case increase.CardPaymentElementsCategoryCardAuthorization:
+		a := e.CardAuthorization
+		if _, ok := p.Holds[a.ID]; ok {
+			p.anomaly(index, e.Category, a.ID, "duplicate authorization")
+			return
+		}
+		p.Holds[a.ID] = &Hold{
+			AuthorizationID:  a.ID,
+			Direction:        a.Direction,
+			State:            HoldOpen,
+			OriginalAmount:   a.Amount,
+			AuthorizedAmount: a.Amount,
+			HeldAmount:       a.Amount,
+		}
+		p.AuthorizationIDs = append(p.AuthorizationIDs, a.ID)
+		if a.Direction == increase.CardPaymentElementsCardAuthorizationDirectionRefund {
+			p.State.RefundAuthorizedAmount += a.Amount
+		} else {
+			p.State.AuthorizedAmount += a.Amount
+		}
+
+	case increase.CardPaymentElementsCategoryCardIncrement:
+		inc := e.CardIncrement
+		if h := p.transition(index, e.Category, inc.CardAuthorizationID); h != nil {
+			h.adjust(inc.UpdatedAuthorizationAmount)
+		}
+		p.State.IncrementedAmount += inc.Amount
+
+	case increase.CardPaymentElementsCategoryCardReversal:
+		r := e.CardReversal
+		if h := p.transition(index, e.Category, r.CardAuthorizationID); h != nil {
+			if h.Settlements > 0 {
+				// A reversal after a partial settlement releases the rest of
+				// the hold without changing what was authorized.
+				h.HeldAmount = r.UpdatedAuthorizationAmount
+				if h.HeldAmount == 0 {
+					h.close(HoldSettled)
+				}
+			} else {
+				h.adjust(r.UpdatedAuthorizationAmount)
+			}
+		}
+		p.State.ReversedAmount += r.ReversalAmount
+
+	case increase.CardPaymentElementsCategoryCardFuelConfirmation:
+		f := e.CardFuelConfirmation
+		if h := p.transition(index, e.Category, f.CardAuthorizationID); h != nil {
+			p.State.FuelConfirmedAmount += f.UpdatedAuthorizationAmount - h.HeldAmount
+			h.adjust(f.UpdatedAuthorizationAmount)
+		}
+
+	case increase.CardPaymentElementsCategoryCardAuthorizationExpiration:
+		x := e.CardAuthorizationExpiration
+		if h := p.transition(index, e.Category, x.CardAuthorizationID); h != nil {
+			h.close(HoldExpired)
+		}
+
+	case increase.CardPaymentElementsCategoryCardSettlement:
+		s := e.CardSettlement
+		p.settlements++
+		p.State.SettledAmount += s.Amount
+		p.TransactionIDs = append(p.TransactionIDs, s.TransactionID)
+		if s.CardAuthorization == "" {
+			// A force post, settled without an authorization.
+			p.unmatched += s.Amount
+			return
+		}
+		if h := p.transition(index, e.Category, s.CardAuthorization); h != nil {
+			h.settle(s.Amount)
+		} else {
+			p.unmatched += s.Amount
+		}
+
+	case increase.CardPaymentElementsCategoryCardRefund:
+		r := e.CardRefund
+		p.State.RefundedAmount += r.Amount
+		p.TransactionIDs = append(p.TransactionIDs, r.TransactionID)
+		if p.settlements > 0 {
+			p.refundedAfter = true
+		}
+
+	case increase.CardPaymentElementsCategoryCardFinancial:
+		// A single message authorization and settlement.
+		f := e.CardFinancial
+		p.TransactionIDs = append(p.TransactionIDs, f.TransactionID)
+		if f.Direction == increase.CardPaymentElementsCardFinancialDirectionRefund {
+			p.State.RefundAuthorizedAmount += f.Amount
+			p.State.RefundedAmount += f.Amount
+			if p.settlements > 0 {
+				p.refundedAfter = true
+			}
+			return
+		}
+		p.settlements++
+		p.State.AuthorizedAmount += f.Amount
+		p.State.SettledAmount += f.Amount
+
+	case increase.CardPaymentElementsCategoryCardDecline:
+		p.declines++
+	}
+}
+
+// transition returns the hold the element applies to, or records an anomaly
+// and returns nil if the hold is unknown or does not accept the element.
+func (p *Payment) transition(index int, category increase.CardPaymentElementsCategory, authorizationID string) *Hold {
+	h, ok := p.Holds[authorizationID]
+	if !ok {
+		p.anomaly(index, category, authorizationID, "unknown authorization")
+		return nil
+	}
+	if !transitions[h.State][category] {
+		p.anomaly(index, category, authorizationID, fmt.Sprintf("unexpected %s on a hold that is %s", category, h.State))
+		return nil
+	}
+	return h
+}
+
+func (p *Payment) anomaly(index int, category increase.CardPaymentElementsCategory, authorizationID string, reason string) {
+	p.Anomalies = append(p.Anomalies, Anomaly{Index: index, Category: category, AuthorizationID: authorizationID, Reason: reason})
+}
+
+func (p *Payment) settlementHolds() []*Hold {
+	var res []*Hold
+	for _, id := range p.AuthorizationIDs {
+		if h := p.Holds[id]; h.Direction != increase.CardPaymentElementsCardAuthorizationDirectionRefund {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// Status returns where the payment is in its lifecycle.
+func (p *Payment) Status() Status {
+	settled := p.State.SettledAmount
+	switch {
+	case settled > 0 && p.State.RefundedAmount >= settled:
+		return StatusRefunded
+	case settled > 0 && p.OpenHold() > 0:
+		return StatusPartiallySettled
+	case settled > 0:
+		return StatusSettled
+	case p.OpenHold() > 0:
+		return StatusAuthorized
+	case len(p.settlementHolds()) > 0:
+		return StatusReversed
+	case p.declines > 0:
+		return StatusDeclined
+	}
+	return StatusNew
+}
+
+// OpenHold returns the amount still held for the merchant.
+func (p *Payment) OpenHold() int64 {
+	var res int64
+	for _, h := range p.settlementHolds() {
+		res += h.HeldAmount
+	}
+	return res
+}
+
+// SettlementDelta returns the amount settled against authorizations less the
+// amount authorized for them. Settlements without an authorization are not
+// included.
+func (p *Payment) SettlementDelta() int64 {
+	var res int64
+	for _, h := range p.settlementHolds() {
+		if h.Settlements > 0 {
+			res += h.Delta()
+		}
+	}
+	return res
+}
+
+// OverSettled reports whether an authorization settled for more than was
+// authorized, or something settled without an authorization.
+func (p *Payment) OverSettled() bool {
+	if p.unmatched > 0 {
+		return true
+	}
+	for _, h := range p.settlementHolds() {
+		if h.OverSettled() {
+			return true
+		}
+	}
+	return false
+}
+
+// UnderSettled reports whether an authorization closed after settling for
+// less than was authorized.
+func (p *Payment) UnderSettled() bool {
+	for _, h := range p.settlementHolds() {
+		if h.UnderSettled() {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmatchedSettledAmount returns the amount settled without a known
+// authorization.
+func (p *Payment) UnmatchedSettledAmount() int64 {
+	return p.unmatched
+}
+
+// RefundAfterSettlement reports whether a refund followed a settlement.
+func (p *Payment) RefundAfterSettlement() bool {
+	return p.refundedAfter
+}
+
+// MultipleClearings reports whether an authorization settled more than once.
+func (p *Payment) MultipleClearings() bool {
+	for _, h := range p.Holds {
+		if h.Settlements > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Mismatch is a total whose computed value differs from the reported one.
+type Mismatch struct {
+	Field    string
+	Computed int64
+	Reported int64
+}
+
+// MismatchError is returned by [Payment.Validate].
+type MismatchError struct {
+	CardPaymentID string
+	Mismatches    []Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	var fields []string
+	for _, m := range e.Mismatches {
+		fields = append(fields, fmt.Sprintf("%s computed %d, reported %d", m.Field, m.Computed, m.Reported))
+	}
+	return fmt.Sprintf("cardpayments: %s does not match its reported state: %s", e.CardPaymentID, strings.Join(fields, "; "))
+}
+
+// Validate checks the computed totals against the state reported by Increase
+// and returns a [*MismatchError] if they differ.
+func (p *Payment) Validate() error {
+	return p.Check(p.Reported)
+}
+
+// Check compares the computed totals with the given state.
+func (p *Payment) Check(reported State) error {
+	c := p.State
+	fields := []Mismatch{
+		{"authorized_amount", c.AuthorizedAmount, reported.AuthorizedAmount},
+		{"fuel_confirmed_amount", c.FuelConfirmedAmount, reported.FuelConfirmedAmount},
+		{"incremented_amount", c.IncrementedAmount, reported.IncrementedAmount},
+		{"refund_authorized_amount", c.RefundAuthorizedAmount, reported.RefundAuthorizedAmount},
+		{"refunded_amount", c.RefundedAmount, reported.RefundedAmount},
+		{"reversed_amount", c.ReversedAmount, reported.ReversedAmount},
+		{"settled_amount", c.SettledAmount, reported.SettledAmount},
+	}
+	var mismatches []Mismatch
+	for _, m := range fields {
+		if m.Computed != m.Reported {
+			mismatches = append(mismatches, m)
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return &MismatchError{CardPaymentID: p.ID, Mismatches: mismatches}
+}
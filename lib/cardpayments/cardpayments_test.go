@@ -0,0 +1,169 @@
+package cardpayments_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/cardpayments"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+func authorization(id string, amount int64) map[string]any {
+	return map[string]any{"category": "card_authorization", "card_authorization": map[string]any{"id": id, "amount": amount, "direction": "settlement"}}
+}
+
+func increment(authorizationID string, amount, updated int64) map[string]any {
+	return map[string]any{"category": "card_increment", "card_increment": map[string]any{"card_authorization_id": authorizationID, "amount": amount, "updated_authorization_amount": updated}}
+}
+
+func reversal(authorizationID string, amount, updated int64) map[string]any {
+	return map[string]any{"category": "card_reversal", "card_reversal": map[string]any{"card_authorization_id": authorizationID, "reversal_amount": amount, "updated_authorization_amount": updated}}
+}
+
+func expiration(authorizationID string) map[string]any {
+	return map[string]any{"category": "card_authorization_expiration", "card_authorization_expiration": map[string]any{"card_authorization_id": authorizationID}}
+}
+
+func settlement(authorizationID string, amount int64, transactionID string) map[string]any {
+	return map[string]any{"category": "card_settlement", "card_settlement": map[string]any{"card_authorization": authorizationID, "amount": amount, "transaction_id": transactionID}}
+}
+
+func refund(amount int64, transactionID string) map[string]any {
+	return map[string]any{"category": "card_refund", "card_refund": map[string]any{"amount": amount, "transaction_id": transactionID}}
+}
+
+func cardPayment(t *testing.T, state map[string]any, elements ...map[string]any) map[string]any {
+	t.Helper()
+	return map[string]any{"id": "card_payment_1", "account_id": "account_1", "card_id": "card_1", "elements": elements, "state": state}
+}
+
+func decode(t *testing.T, v map[string]any) increase.CardPayment {
+	t.Helper()
+	b, _ := json.Marshal(v)
+	var cp increase.CardPayment
+	if err := json.Unmarshal(b, &cp); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return cp
+}
+
+func TestReplay(t *testing.T) {
+	cp := decode(t, cardPayment(t,
+		map[string]any{"authorized_amount": 5000, "incremented_amount": 2000, "reversed_amount": 1000, "settled_amount": 6500, "refunded_amount": 500},
+		authorization("card_authorization_1", 5000),
+		increment("card_authorization_1", 2000, 7000),
+		reversal("card_authorization_1", 1000, 6000),
+		settlement("card_authorization_1", 4000, "transaction_1"),
+		settlement("card_authorization_1", 2500, "transaction_2"),
+		refund(500, "transaction_3"),
+	))
+	p := cardpayments.Replay(cp)
+	if err := p.Validate(); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	h := p.Holds["card_authorization_1"]
+	if h.State != cardpayments.HoldSettled || h.AuthorizedAmount != 6000 || h.SettledAmount != 6500 || h.Settlements != 2 {
+		t.Errorf("unexpected hold: %+v", h)
+	}
+	if p.Status() != cardpayments.StatusSettled || p.OpenHold() != 0 {
+		t.Errorf("expected the payment to be settled, got %s with %d held", p.Status(), p.OpenHold())
+	}
+	if p.SettlementDelta() != 500 || !p.OverSettled() || p.UnderSettled() {
+		t.Errorf("expected the payment to be over settled by 500, got %d", p.SettlementDelta())
+	}
+	if !p.MultipleClearings() || !p.RefundAfterSettlement() {
+		t.Errorf("expected multiple clearings and a refund after settlement")
+	}
+	if len(p.TransactionIDs) != 3 || len(p.Anomalies) != 0 {
+		t.Errorf("unexpected transactions %v or anomalies %+v", p.TransactionIDs, p.Anomalies)
+	}
+}
+
+func TestUnderSettlementAndAnomalies(t *testing.T) {
+	cp := decode(t, cardPayment(t,
+		map[string]any{"authorized_amount": 10000, "settled_amount": 8000},
+		authorization("card_authorization_1", 10000),
+		settlement("card_authorization_1", 8000, "transaction_1"),
+		expiration("card_authorization_1"),
+		increment("card_authorization_1", 100, 2100),
+		reversal("card_authorization_unknown", 0, 0),
+	))
+	p := cardpayments.Replay(cp)
+	h := p.Holds["card_authorization_1"]
+	if h.State != cardpayments.HoldSettled || !p.UnderSettled() || p.SettlementDelta() != -2000 {
+		t.Errorf("expected an under settled hold, got %+v", h)
+	}
+	if len(p.Anomalies) != 2 || p.Anomalies[0].Index != 3 || p.Anomalies[1].Reason != "unknown authorization" {
+		t.Errorf("unexpected anomalies: %+v", p.Anomalies)
+	}
+
+	var mismatch *cardpayments.MismatchError
+	if err := p.Validate(); !errors.As(err, &mismatch) {
+		t.Fatalf("expected a mismatch, got %v", err)
+	}
+	if len(mismatch.Mismatches) != 1 || mismatch.Mismatches[0].Field != "incremented_amount" || mismatch.Mismatches[0].Computed != 100 {
+		t.Errorf("unexpected mismatches: %+v", mismatch.Mismatches)
+	}
+}
+
+func TestOpenHold(t *testing.T) {
+	p := cardpayments.Replay(decode(t, cardPayment(t,
+		map[string]any{"authorized_amount": 3000},
+		authorization("card_authorization_1", 3000),
+		settlement("card_authorization_1", 1000, "transaction_1"),
+	)))
+	if p.Status() != cardpayments.StatusPartiallySettled || p.OpenHold() != 2000 {
+		t.Errorf("expected 2000 to be held, got %s with %d held", p.Status(), p.OpenHold())
+	}
+	p = cardpayments.Replay(decode(t, cardPayment(t,
+		map[string]any{"authorized_amount": 3000, "reversed_amount": 3000},
+		authorization("card_authorization_1", 3000),
+		reversal("card_authorization_1", 3000, 0),
+	)))
+	if p.Status() != cardpayments.StatusReversed || p.Holds["card_authorization_1"].State != cardpayments.HoldReversed {
+		t.Errorf("expected the payment to be reversed, got %s", p.Status())
+	}
+}
+
+func TestTrackerFoldsNewElements(t *testing.T) {
+	elements := []map[string]any{authorization("card_authorization_1", 5000)}
+	state := map[string]any{"authorized_amount": 5000}
+	client := testapi.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(cardPayment(t, state, elements...))
+	}))
+
+	tracker := cardpayments.NewTracker(client)
+	var updates []error
+	tracker.OnUpdate = func(p *cardpayments.Payment, err error) { updates = append(updates, err) }
+	event := increase.Event{Category: increase.EventCategoryCardPaymentCreated, AssociatedObjectID: "card_payment_1"}
+	p, err := tracker.HandleEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if p.Status() != cardpayments.StatusAuthorized || p.Applied != 1 {
+		t.Fatalf("unexpected payment: %+v", p)
+	}
+
+	elements = append(elements, settlement("card_authorization_1", 5000, "transaction_1"))
+	state["settled_amount"] = 5000
+	event.Category = increase.EventCategoryCardPaymentUpdated
+	p, err = tracker.HandleEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if p != tracker.Payment("card_payment_1") || p.Applied != 2 || p.Status() != cardpayments.StatusSettled {
+		t.Errorf("expected the settlement to be folded in, got %+v", p)
+	}
+	if len(updates) != 2 || updates[0] != nil || updates[1] != nil {
+		t.Errorf("unexpected validation results: %v", updates)
+	}
+
+	_, err = tracker.HandleEvent(context.Background(), increase.Event{Category: increase.EventCategoryCardCreated})
+	if !errors.Is(err, cardpayments.ErrNotCardPaymentEvent) {
+		t.Errorf("expected ErrNotCardPaymentEvent, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package cardpayments
+
+import (
+	"github.com/Increase/increase-go"
+)
+
+// HoldState is the state of a Card Authorization within a Card Payment.
+type HoldState string
+
+const (
+	// Funds are held and nothing has settled against the authorization.
+	HoldOpen HoldState = "open"
+	// Part of the authorization has settled and funds are still held for the
+	// rest.
+	HoldPartiallySettled HoldState = "partially_settled"
+	// The authorization has settled and no funds are held.
+	HoldSettled HoldState = "settled"
+	// The authorization was reversed before settling.
+	HoldReversed HoldState = "reversed"
+	// The authorization expired before settling.
+	HoldExpired HoldState = "expired"
+)
+
+// Closed reports whether the hold no longer holds funds.
+func (s HoldState) Closed() bool {
+	return s == HoldSettled || s == HoldReversed || s == HoldExpired
+}
+
+// transitions lists the elements each hold state accepts. An element that is
+// not listed is recorded as an [Anomaly] and otherwise ignored. Settlements are
+// accepted in every state: networks clear late, after a reversal or an
+// expiration, and more than once against the same authorization.
+var transitions = map[HoldState]map[increase.CardPaymentElementsCategory]bool{
+	HoldOpen: {
+		increase.CardPaymentElementsCategoryCardIncrement:               true,
+		increase.CardPaymentElementsCategoryCardReversal:                true,
+		increase.CardPaymentElementsCategoryCardFuelConfirmation:        true,
+		increase.CardPaymentElementsCategoryCardAuthorizationExpiration: true,
+		increase.CardPaymentElementsCategoryCardSettlement:              true,
+	},
+	HoldPartiallySettled: {
+		increase.CardPaymentElementsCategoryCardIncrement:               true,
+		increase.CardPaymentElementsCategoryCardReversal:                true,
+		increase.CardPaymentElementsCategoryCardAuthorizationExpiration: true,
+		increase.CardPaymentElementsCategoryCardSettlement:              true,
+	},
+	HoldSettled: {
+		increase.CardPaymentElementsCategoryCardSettlement: true,
+	},
+	HoldReversed: {
+		increase.CardPaymentElementsCategoryCardSettlement: true,
+	},
+	HoldExpired: {
+		increase.CardPaymentElementsCategoryCardSettlement: true,
+	},
+}
+
+// Hold follows a single Card Authorization through its increments,
+// reversals, expiration and settlements.
+type Hold struct {
+	AuthorizationID string
+	Direction       increase.CardPaymentElementsCardAuthorizationDirection
+	State           HoldState
+	// The amount originally authorized.
+	OriginalAmount int64
+	// The authorized amount after increments, reversals and fuel
+	// confirmations, before settlement.
+	AuthorizedAmount int64
+	// The amount still held.
+	HeldAmount int64
+	// The amount settled against the authorization.
+	SettledAmount int64
+	// The number of settlements against the authorization.
+	Settlements int
+}
+
+// Delta is the settled amount less the authorized amount. It is positive
+// when the authorization settled for more than was authorized.
+func (h *Hold) Delta() int64 {
+	return h.SettledAmount - h.AuthorizedAmount
+}
+
+// OverSettled reports whether more settled than was authorized.
+func (h *Hold) OverSettled() bool {
+	return h.Settlements > 0 && h.Delta() > 0
+}
+
+// UnderSettled reports whether the authorization is closed and settled for
+// less than was authorized.
+func (h *Hold) UnderSettled() bool {
+	return h.Settlements > 0 && h.State.Closed() && h.Delta() < 0
+}
+
+// adjust sets the authorized amount after an increment, reversal or fuel
+// confirmation, given the updated authorization amount reported by the
+// network.
+func (h *Hold) adjust(updated int64) {
+	h.AuthorizedAmount += updated - h.HeldAmount
+	h.HeldAmount = updated
+	if h.HeldAmount == 0 {
+		h.close(HoldReversed)
+	}
+}
+
+func (h *Hold) settle(amount int64) {
+	h.Settlements++
+	h.SettledAmount += amount
+	if h.State.Closed() {
+		return
+	}
+	h.HeldAmount = max(h.HeldAmount-amount, 0)
+	if h.HeldAmount == 0 {
+		h.State = HoldSettled
+	} else {
+		h.State = HoldPartiallySettled
+	}
+}
+
+// close releases the remaining hold. A hold that has partially settled is
+// settled, whatever released the rest of it.
+func (h *Hold) close(s HoldState) {
+	h.HeldAmount = 0
+	if h.SettledAmount > 0 {
+		s = HoldSettled
+	}
+	h.State = s
+}
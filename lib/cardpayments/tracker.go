@@ -0,0 +1,69 @@
+package cardpayments
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNotCardPaymentEvent is returned for events about other objects.
+var ErrNotCardPaymentEvent = errors.New("cardpayments: not a card payment event")
+
+// Tracker keeps the state of Card Payments up to date from events. It is safe
+// for concurrent use.
+type Tracker struct {
+	client *increase.Client
+	// Called with the payment after each update, and the result of
+	// [Payment.Validate].
+	OnUpdate func(p *Payment, err error)
+
+	mu       sync.Mutex
+	payments map[string]*Payment
+}
+
+// NewTracker returns a tracker that retrieves Card Payments with the client.
+func NewTracker(client *increase.Client) *Tracker {
+	return &Tracker{client: client, payments: map[string]*Payment{}}
+}
+
+// Payment returns the tracked payment, or nil if it has not been seen.
+func (t *Tracker) Payment(cardPaymentID string) *Payment {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.payments[cardPaymentID]
+}
+
+// HandleEvent folds a `card_payment.created` or `card_payment.updated` event
+// into the tracked payment. Events carry no payload, so the Card Payment is
+// retrieved and only the elements added since the last event are applied.
+// Events for the same Card Payment should be handled one at a time.
+func (t *Tracker) HandleEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*Payment, error) {
+	if event.Category != increase.EventCategoryCardPaymentCreated && event.Category != increase.EventCategoryCardPaymentUpdated {
+		return nil, ErrNotCardPaymentEvent
+	}
+	return t.Refresh(ctx, event.AssociatedObjectID, opts...)
+}
+
+// Refresh retrieves a Card Payment and folds in its new elements.
+func (t *Tracker) Refresh(ctx context.Context, cardPaymentID string, opts ...option.RequestOption) (*Payment, error) {
+	cp, err := t.client.CardPayments.Get(ctx, cardPaymentID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	p, ok := t.payments[cp.ID]
+	if !ok {
+		p = New(cp.ID)
+		t.payments[cp.ID] = p
+	}
+	p.Update(*cp)
+	t.mu.Unlock()
+
+	if t.OnUpdate != nil {
+		t.OnUpdate(p, p.Validate())
+	}
+	return p, nil
+}
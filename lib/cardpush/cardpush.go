@@ -0,0 +1,257 @@
+// Package cardpush sends money to debit cards with Card Push Transfers.
+//
+// [Send] looks up the capabilities of the Card Token to check that the card
+// can receive push transfers, optionally validates the cardholder's name with
+// a Card Validation, fills the merchant fields from a stored [MerchantProfile],
+// and creates the Card Push Transfer.
+//
+//	res, err := cardpush.Send(ctx, client, profile, cardpush.Request{
+//		CardTokenID:           "outbound_card_token_zlt0ml6youq3q7vcdlg0",
+//		SourceAccountNumberID: "account_number_v18nkfqm6afpsrvy82b2",
+//		Amount:                "100.00",
+//		Sender:                cardpush.Party{Name: "Ian Crease", Line1: "33 Liberty Street", City: "New York", State: "NY", PostalCode: "10045"},
+//		Recipient:             cardpush.Party{Name: "Ada Lovelace"},
+//	})
+//	if errors.Is(err, cardpush.ErrPushNotSupported) {
+//		// Fall back to another rail.
+//	}
+package cardpush
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/internal/param"
+	"github.com/Increase/increase-go/option"
+)
+
+var (
+	// ErrPushNotSupported is matched by the [*UnsupportedError] returned when
+	// no network route of the card supports push transfers.
+	ErrPushNotSupported = errors.New("cardpush: card does not support push transfers")
+	// ErrValidationFailed is matched by the [*ValidationError] returned when
+	// the Card Validation was declined or the cardholder name did not match.
+	ErrValidationFailed = errors.New("cardpush: card validation failed")
+)
+
+// MerchantProfile is the merchant (generally your business) that sends
+// transfers. Store one per program and reuse it for every transfer.
+type MerchantProfile struct {
+	BusinessApplicationIdentifier increase.CardPushTransferNewParamsBusinessApplicationIdentifier
+	CategoryCode                  string
+	Name                          string
+	NamePrefix                    string
+	City                          string
+	State                         string
+	PostalCode                    string
+	// Required for cards issued in Canada.
+	LegalBusinessName string
+	StreetAddress     string
+}
+
+// Party is the sender or the recipient of a transfer.
+type Party struct {
+	Name       string
+	Line1      string
+	City       string
+	State      string
+	PostalCode string
+}
+
+// Validation asks for the recipient's name to be checked with a Card
+// Validation before the transfer is created.
+type Validation struct {
+	// The Account the Card Validation is made from.
+	AccountID         string
+	FirstName         string
+	MiddleName        string
+	LastName          string
+	PostalCode        string
+	StreetAddress     string
+	AllowPartialMatch bool
+	// How often the Card Validation is polled until it completes. Defaults to
+	// two seconds; bound the wait with the context.
+	PollInterval time.Duration
+}
+
+// Request is a transfer to send.
+type Request struct {
+	CardTokenID           string
+	SourceAccountNumberID string
+	// The amount, as a decimal string in the currency's major unit.
+	Amount string
+	// Defaults to USD.
+	Currency        increase.CardPushTransferNewParamsPresentmentAmountCurrency
+	Sender          Party
+	Recipient       Party
+	Validation      *Validation
+	RequireApproval bool
+	IdempotencyKey  string
+}
+
+// Result is a sent transfer and what was checked before sending it.
+type Result struct {
+	Capabilities *increase.CardTokenCapabilities
+	// The route that supports the transfer.
+	Route      increase.CardTokenCapabilitiesRoute
+	Validation *increase.CardValidation
+	Transfer   *increase.CardPushTransfer
+}
+
+// UnsupportedError is returned when no route of the card supports push
+// transfers.
+type UnsupportedError struct {
+	CardTokenID string
+	Routes      []increase.CardTokenCapabilitiesRoute
+}
+
+func (e *UnsupportedError) Error() string {
+	var routes []string
+	for _, r := range e.Routes {
+		routes = append(routes, fmt.Sprintf("%s (%s): domestic %s, cross-border %s", r.Route, r.IssuerCountry, r.DomesticPushTransfers, r.CrossBorderPushTransfers))
+	}
+	if len(routes) == 0 {
+		routes = []string{"no routes"}
+	}
+	return fmt.Sprintf("cardpush: card token %s does not support push transfers: %s", e.CardTokenID, strings.Join(routes, "; "))
+}
+
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrPushNotSupported
+}
+
+// ValidationError is returned when the recipient's card could not be
+// validated.
+type ValidationError struct {
+	Validation *increase.CardValidation
+	Reason     string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("cardpush: card validation %s: %s", e.Validation.ID, e.Reason)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidationFailed
+}
+
+// Send checks that the card supports push transfers, validates the recipient
+// if asked to, and creates the Card Push Transfer. The returned Result holds
+// what was done before any error.
+func Send(ctx context.Context, client *increase.Client, profile MerchantProfile, req Request, opts ...option.RequestOption) (*Result, error) {
+	res := &Result{}
+	capabilities, err := client.CardTokens.Capabilities(ctx, req.CardTokenID, opts...)
+	if err != nil {
+		return res, fmt.Errorf("cardpush: retrieving capabilities: %w", err)
+	}
+	res.Capabilities = capabilities
+	route, ok := Supported(*capabilities)
+	if !ok {
+		return res, &UnsupportedError{CardTokenID: req.CardTokenID, Routes: capabilities.Routes}
+	}
+	res.Route = route
+	if route.IssuerCountry == "CA" {
+		if err := canadianFields(profile, req.Recipient); err != nil {
+			return res, err
+		}
+	}
+
+	if v := req.Validation; v != nil {
+		res.Validation, err = validate(ctx, client, profile, req.CardTokenID, *v, opts...)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	transferOpts := opts
+	if req.IdempotencyKey != "" {
+		transferOpts = append(append([]option.RequestOption{}, opts...), option.WithHeader("Idempotency-Key", req.IdempotencyKey))
+	}
+	res.Transfer, err = client.CardPushTransfers.New(ctx, Params(profile, req), transferOpts...)
+	return res, err
+}
+
+// Supported returns the first route that supports push transfers to the card.
+// Cards issued in the United States need domestic push transfers, and others
+// cross-border push transfers.
+func Supported(c increase.CardTokenCapabilities) (increase.CardTokenCapabilitiesRoute, bool) {
+	for _, r := range c.Routes {
+		if r.IssuerCountry == "US" && r.DomesticPushTransfers == increase.CardTokenCapabilitiesRoutesDomesticPushTransfersSupported {
+			return r, true
+		}
+		if r.IssuerCountry != "US" && r.CrossBorderPushTransfers == increase.CardTokenCapabilitiesRoutesCrossBorderPushTransfersSupported {
+			return r, true
+		}
+	}
+	return increase.CardTokenCapabilitiesRoute{}, false
+}
+
+func canadianFields(profile MerchantProfile, recipient Party) error {
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"merchant legal business name", profile.LegalBusinessName},
+		{"merchant street address", profile.StreetAddress},
+		{"recipient address line 1", recipient.Line1},
+		{"recipient city", recipient.City},
+		{"recipient state", recipient.State},
+		{"recipient postal code", recipient.PostalCode},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("cardpush: cards issued in Canada require the %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Params returns the parameters of the Card Push Transfer of a request.
+func Params(profile MerchantProfile, req Request) increase.CardPushTransferNewParams {
+	currency := req.Currency
+	if currency == "" {
+		currency = increase.CardPushTransferNewParamsPresentmentAmountCurrencyUsd
+	}
+	params := increase.CardPushTransferNewParams{
+		BusinessApplicationIdentifier: increase.F(profile.BusinessApplicationIdentifier),
+		CardTokenID:                   increase.F(req.CardTokenID),
+		MerchantCategoryCode:          increase.F(profile.CategoryCode),
+		MerchantCityName:              increase.F(profile.City),
+		MerchantName:                  increase.F(profile.Name),
+		MerchantNamePrefix:            increase.F(profile.NamePrefix),
+		MerchantPostalCode:            increase.F(profile.PostalCode),
+		MerchantState:                 increase.F(profile.State),
+		PresentmentAmount: increase.F(increase.CardPushTransferNewParamsPresentmentAmount{
+			Currency: increase.F(currency),
+			Value:    increase.F(req.Amount),
+		}),
+		RecipientName:           increase.F(req.Recipient.Name),
+		SenderAddressCity:       increase.F(req.Sender.City),
+		SenderAddressLine1:      increase.F(req.Sender.Line1),
+		SenderAddressPostalCode: increase.F(req.Sender.PostalCode),
+		SenderAddressState:      increase.F(req.Sender.State),
+		SenderName:              increase.F(req.Sender.Name),
+		SourceAccountNumberID:   increase.F(req.SourceAccountNumberID),
+	}
+	optional(&params.MerchantLegalBusinessName, profile.LegalBusinessName)
+	optional(&params.MerchantStreetAddress, profile.StreetAddress)
+	optional(&params.RecipientAddressLine1, req.Recipient.Line1)
+	optional(&params.RecipientAddressCity, req.Recipient.City)
+	optional(&params.RecipientAddressState, req.Recipient.State)
+	optional(&params.RecipientAddressPostalCode, req.Recipient.PostalCode)
+	if req.RequireApproval {
+		params.RequireApproval = increase.F(true)
+	}
+	return params
+}
+
+// optional sets a field when the value is not empty.
+func optional(f *param.Field[string], value string) {
+	if value != "" {
+		*f = increase.F(value)
+	}
+}
@@ -0,0 +1,136 @@
+package cardpush_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/cardpush"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+type fakeAPI struct {
+	mu          sync.Mutex
+	routes      []map[string]any
+	validations int
+	nameResult  string
+	transfer    map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{nameResult: "match"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /card_tokens/{id}/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"routes": api.routes, "type": "card_token_capabilities"})
+	})
+	validation := func(status string) map[string]any {
+		return map[string]any{"id": "card_validation_1", "status": status, "acceptance": map[string]any{"cardholder_full_name_result": api.nameResult}}
+	}
+	mux.HandleFunc("POST /card_validations", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(validation("pending_submission"))
+	})
+	mux.HandleFunc("GET /card_validations/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.validations++
+		json.NewEncoder(w).Encode(validation("complete"))
+	})
+	mux.HandleFunc("POST /card_push_transfers", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&api.transfer)
+		json.NewEncoder(w).Encode(map[string]any{"id": "outbound_card_push_transfer_1", "status": "pending_submission"})
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+var profile = cardpush.MerchantProfile{
+	BusinessApplicationIdentifier: increase.CardPushTransferNewParamsBusinessApplicationIdentifierFundsDisbursement,
+	CategoryCode:                  "1234",
+	Name:                          "Acme Corp",
+	NamePrefix:                    "Acme",
+	City:                          "New York",
+	State:                         "NY",
+	PostalCode:                    "10045",
+}
+
+func request() cardpush.Request {
+	return cardpush.Request{
+		CardTokenID:           "outbound_card_token_1",
+		SourceAccountNumberID: "account_number_1",
+		Amount:                "100.00",
+		Sender:                cardpush.Party{Name: "Ian Crease", Line1: "33 Liberty Street", City: "New York", State: "NY", PostalCode: "10045"},
+		Recipient:             cardpush.Party{Name: "Ada Lovelace"},
+		Validation:            &cardpush.Validation{AccountID: "account_1", FirstName: "Ada", LastName: "Lovelace", PollInterval: time.Millisecond},
+	}
+}
+
+func TestSend(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.routes = []map[string]any{{"route": "visa", "issuer_country": "US", "domestic_push_transfers": "supported", "cross_border_push_transfers": "not_supported"}}
+
+	res, err := cardpush.Send(context.Background(), client, profile, request())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if res.Transfer.ID != "outbound_card_push_transfer_1" || res.Validation.Status != increase.CardValidationStatusComplete || api.validations != 1 {
+		t.Errorf("unexpected result %+v after %d polls", res, api.validations)
+	}
+	amount := api.transfer["presentment_amount"].(map[string]any)
+	if api.transfer["merchant_name"] != "Acme Corp" || api.transfer["sender_name"] != "Ian Crease" || amount["currency"] != "USD" || amount["value"] != "100.00" {
+		t.Errorf("unexpected transfer %+v", api.transfer)
+	}
+	if _, ok := api.transfer["recipient_address_city"]; ok {
+		t.Errorf("expected empty optional fields to be omitted")
+	}
+}
+
+func TestSendUnsupported(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.routes = []map[string]any{{"route": "visa", "issuer_country": "GB", "domestic_push_transfers": "supported", "cross_border_push_transfers": "not_supported"}}
+
+	_, err := cardpush.Send(context.Background(), client, profile, request())
+	var unsupported *cardpush.UnsupportedError
+	if !errors.Is(err, cardpush.ErrPushNotSupported) || !errors.As(err, &unsupported) || !strings.Contains(err.Error(), "cross-border not_supported") {
+		t.Fatalf("expected ErrPushNotSupported, got %v", err)
+	}
+	if api.transfer != nil {
+		t.Errorf("expected no transfer to be created")
+	}
+
+	api.routes = []map[string]any{{"route": "visa", "issuer_country": "CA", "cross_border_push_transfers": "supported"}}
+	if _, err := cardpush.Send(context.Background(), client, profile, request()); err == nil || !strings.Contains(err.Error(), "merchant legal business name") {
+		t.Errorf("expected the Canadian fields to be required, got %v", err)
+	}
+}
+
+func TestSendValidationFailed(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.routes = []map[string]any{{"route": "visa", "issuer_country": "US", "domestic_push_transfers": "supported"}}
+	api.nameResult = "partial_match"
+
+	_, err := cardpush.Send(context.Background(), client, profile, request())
+	var verr *cardpush.ValidationError
+	if !errors.Is(err, cardpush.ErrValidationFailed) || !errors.As(err, &verr) || verr.Reason != "cardholder full name only partially matches" {
+		t.Fatalf("expected ErrValidationFailed, got %v", err)
+	}
+	if api.transfer != nil {
+		t.Errorf("expected no transfer to be created")
+	}
+
+	req := request()
+	req.Validation.AllowPartialMatch = true
+	if _, err := cardpush.Send(context.Background(), client, profile, req); err != nil {
+		t.Errorf("expected partial matches to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cardpush
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// validate creates a Card Validation and waits for it to complete.
+func validate(ctx context.Context, client *increase.Client, profile MerchantProfile, cardTokenID string, v Validation, opts ...option.RequestOption) (*increase.CardValidation, error) {
+	params := increase.CardValidationNewParams{
+		AccountID:            increase.F(v.AccountID),
+		CardTokenID:          increase.F(cardTokenID),
+		MerchantCategoryCode: increase.F(profile.CategoryCode),
+		MerchantCityName:     increase.F(profile.City),
+		MerchantName:         increase.F(profile.Name),
+		MerchantPostalCode:   increase.F(profile.PostalCode),
+		MerchantState:        increase.F(profile.State),
+	}
+	optional(&params.CardholderFirstName, v.FirstName)
+	optional(&params.CardholderMiddleName, v.MiddleName)
+	optional(&params.CardholderLastName, v.LastName)
+	optional(&params.CardholderPostalCode, v.PostalCode)
+	optional(&params.CardholderStreetAddress, v.StreetAddress)
+	validation, err := client.CardValidations.New(ctx, params, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cardpush: creating card validation: %w", err)
+	}
+
+	interval := v.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	for !done(validation.Status) {
+		select {
+		case <-ctx.Done():
+			return validation, fmt.Errorf("cardpush: waiting for card validation %s: %w", validation.ID, ctx.Err())
+		case <-time.After(interval):
+		}
+		if validation, err = client.CardValidations.Get(ctx, validation.ID, opts...); err != nil {
+			return nil, fmt.Errorf("cardpush: retrieving card validation: %w", err)
+		}
+	}
+	if reason := check(*validation, v.AllowPartialMatch); reason != "" {
+		return validation, &ValidationError{Validation: validation, Reason: reason}
+	}
+	return validation, nil
+}
+
+func done(status increase.CardValidationStatus) bool {
+	switch status {
+	case increase.CardValidationStatusComplete, increase.CardValidationStatusDeclined, increase.CardValidationStatusRequiresAttention:
+		return true
+	}
+	return false
+}
+
+// check returns why a completed Card Validation does not allow the transfer,
+// or an empty string.
+func check(v increase.CardValidation, allowPartial bool) string {
+	switch v.Status {
+	case increase.CardValidationStatusDeclined:
+		return fmt.Sprintf("declined: %s", v.Decline.Reason)
+	case increase.CardValidationStatusRequiresAttention:
+		return "requires attention"
+	}
+	a := v.Acceptance
+	for _, r := range []struct{ field, result string }{
+		{"full name", string(a.CardholderFullNameResult)},
+		{"first name", string(a.CardholderFirstNameResult)},
+		{"middle name", string(a.CardholderMiddleNameResult)},
+		{"last name", string(a.CardholderLastNameResult)},
+		{"postal code", string(a.CardholderPostalCodeResult)},
+		{"street address", string(a.CardholderStreetAddressResult)},
+	} {
+		switch r.result {
+		case "no_match":
+			return fmt.Sprintf("cardholder %s does not match", r.field)
+		case "partial_match":
+			if !allowPartial {
+				return fmt.Sprintf("cardholder %s only partially matches", r.field)
+			}
+		}
+	}
+	return ""
+}
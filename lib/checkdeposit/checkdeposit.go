@@ -0,0 +1,225 @@
+// Package checkdeposit deposits checks from images captured by a scanner or a
+// phone camera.
+//
+// [Deposit] normalizes the front and back images, uploads them as Files,
+// creates the Check Deposit and waits until Increase has either submitted or
+// rejected it. Rejections are reported as a [*RejectionError] carrying the
+// reason and whether taking new pictures may help.
+package checkdeposit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrPending is returned with the deposit when it is still pending after the
+// wait timeout. The deposit has been created and will be processed; look it up
+// later instead of depositing the check again.
+var ErrPending = errors.New("checkdeposit: deposit is still pending")
+
+// errWaitTimeout is the cause of the context of Wait when WaitTimeout passes.
+var errWaitTimeout = errors.New("checkdeposit: wait timeout")
+
+// Depositor deposits checks into Increase accounts.
+type Depositor struct {
+	client *increase.Client
+	// How images are normalized before upload.
+	Image ImageOptions
+	// How often the deposit is retrieved while waiting. Defaults to 5 seconds.
+	PollInterval time.Duration
+	// How long to wait for the outcome. Defaults to 10 minutes. A negative value
+	// waits until the context is done.
+	WaitTimeout time.Duration
+	// Return as soon as the deposit is created, without waiting for the
+	// outcome.
+	NoWait bool
+}
+
+// NewDepositor returns a Depositor with the default options.
+func NewDepositor(client *increase.Client) *Depositor {
+	return &Depositor{
+		client:       client,
+		Image:        DefaultImageOptions(),
+		PollInterval: 5 * time.Second,
+		WaitTimeout:  10 * time.Minute,
+	}
+}
+
+// Deposit deposits a check with a [Depositor] using the default options.
+func Deposit(ctx context.Context, client *increase.Client, accountID string, amount int64, frontImage io.Reader, backImage io.Reader, opts ...option.RequestOption) (*increase.CheckDeposit, error) {
+	return NewDepositor(client).Deposit(ctx, accountID, amount, frontImage, backImage, opts...)
+}
+
+// Deposit normalizes and uploads the check images, creates a Check Deposit for
+// the amount in USD cents and waits for its outcome. It returns the deposit
+// once it has been submitted. If the deposit was rejected or returned, the
+// deposit is returned together with a [*RejectionError].
+func (d *Depositor) Deposit(ctx context.Context, accountID string, amount int64, frontImage io.Reader, backImage io.Reader, opts ...option.RequestOption) (*increase.CheckDeposit, error) {
+	if accountID == "" {
+		return nil, errors.New("missing required account_id parameter")
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("checkdeposit: amount must be positive, got %d", amount)
+	}
+	front, err := Normalize(frontImage, d.Image)
+	if err != nil {
+		return nil, fmt.Errorf("checkdeposit: front image: %w", err)
+	}
+	back, err := Normalize(backImage, d.Image)
+	if err != nil {
+		return nil, fmt.Errorf("checkdeposit: back image: %w", err)
+	}
+	frontFile, err := d.upload(ctx, front, "front", increase.FileNewParamsPurposeCheckImageFront, opts...)
+	if err != nil {
+		return nil, err
+	}
+	backFile, err := d.upload(ctx, back, "back", increase.FileNewParamsPurposeCheckImageBack, opts...)
+	if err != nil {
+		return nil, err
+	}
+	deposit, err := d.client.CheckDeposits.New(ctx, increase.CheckDepositNewParams{
+		AccountID:        increase.F(accountID),
+		Amount:           increase.F(amount),
+		FrontImageFileID: increase.F(frontFile.ID),
+		BackImageFileID:  increase.F(backFile.ID),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if d.NoWait {
+		return deposit, outcome(deposit)
+	}
+	return d.Wait(ctx, deposit.ID, opts...)
+}
+
+func (d *Depositor) upload(ctx context.Context, img *Image, side string, purpose increase.FileNewParamsPurpose, opts ...option.RequestOption) (*increase.File, error) {
+	file, err := d.client.Files.New(ctx, increase.FileNewParams{
+		File:    increase.FileParam(bytes.NewReader(img.Data), img.Filename(side), img.ContentType()),
+		Purpose: increase.F(purpose),
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("checkdeposit: uploading %s image: %w", side, err)
+	}
+	return file, nil
+}
+
+// Wait retrieves the Check Deposit until it is no longer pending, the wait
+// timeout passes or the context is done. It returns [ErrPending] when the wait
+// timeout passes, and the error of the context when it is done.
+func (d *Depositor) Wait(ctx context.Context, checkDepositID string, opts ...option.RequestOption) (*increase.CheckDeposit, error) {
+	interval := d.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	timeout := d.WaitTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Minute
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errWaitTimeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last *increase.CheckDeposit
+	for {
+		deposit, err := d.client.CheckDeposits.Get(ctx, checkDepositID, opts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return last, done(ctx)
+			}
+			return last, err
+		}
+		last = deposit
+		if deposit.Status != increase.CheckDepositStatusPending {
+			return deposit, outcome(deposit)
+		}
+		select {
+		case <-ctx.Done():
+			return deposit, done(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// done returns the error of Wait once its context is done.
+func done(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), errWaitTimeout) {
+		return ErrPending
+	}
+	return ctx.Err()
+}
+
+func outcome(deposit *increase.CheckDeposit) error {
+	switch deposit.Status {
+	case increase.CheckDepositStatusPending:
+		return ErrPending
+	case increase.CheckDepositStatusRejected:
+		return &RejectionError{
+			CheckDepositID:  deposit.ID,
+			Status:          deposit.Status,
+			RejectionReason: deposit.DepositRejection.Reason,
+		}
+	case increase.CheckDepositStatusReturned:
+		return &RejectionError{
+			CheckDepositID: deposit.ID,
+			Status:         deposit.Status,
+			ReturnReason:   deposit.DepositReturn.ReturnReason,
+		}
+	}
+	return nil
+}
+
+// RejectionError reports a Check Deposit that Increase rejected, or that the
+// paying bank returned.
+type RejectionError struct {
+	CheckDepositID string
+	// Either `rejected` or `returned`.
+	Status increase.CheckDepositStatus
+	// Why Increase rejected the deposit, when Status is `rejected`.
+	RejectionReason increase.CheckDepositDepositRejectionReason
+	// Why the paying bank returned the deposit, when Status is `returned`.
+	ReturnReason increase.CheckDepositDepositReturnReturnReason
+}
+
+func (e *RejectionError) Error() string {
+	if e.Status == increase.CheckDepositStatusReturned {
+		return fmt.Sprintf("checkdeposit: check deposit %s was returned: %s", e.CheckDepositID, e.ReturnReason)
+	}
+	return fmt.Sprintf("checkdeposit: check deposit %s was rejected: %s", e.CheckDepositID, e.RejectionReason)
+}
+
+// Reason returns the rejection or return reason.
+func (e *RejectionError) Reason() string {
+	if e.Status == increase.CheckDepositStatusReturned {
+		return string(e.ReturnReason)
+	}
+	return string(e.RejectionReason)
+}
+
+// Retake reports whether depositing the check again with new pictures may
+// succeed, because the reason concerns the images or the entered amount.
+func (e *RejectionError) Retake() bool {
+	switch e.RejectionReason {
+	case increase.CheckDepositDepositRejectionReasonIncompleteImage,
+		increase.CheckDepositDepositRejectionReasonPoorImageQuality,
+		increase.CheckDepositDepositRejectionReasonIncorrectAmount,
+		increase.CheckDepositDepositRejectionReasonMissingRequiredDataElements:
+		return true
+	}
+	switch e.ReturnReason {
+	case increase.CheckDepositDepositReturnReturnReasonUnreadableImage,
+		increase.CheckDepositDepositReturnReturnReasonUnusableImage,
+		increase.CheckDepositDepositReturnReturnReasonEndorsementMissing:
+		return true
+	}
+	return false
+}
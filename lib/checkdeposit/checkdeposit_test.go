@@ -0,0 +1,190 @@
+package checkdeposit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/checkdeposit"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+func checkImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNormalizeJPEG(t *testing.T) {
+	img, err := checkdeposit.Normalize(bytes.NewReader(checkImage(t, 2400, 1100)), checkdeposit.ImageOptions{})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if img.Width != 1200 || img.Height != 550 || img.DPI != 200 {
+		t.Errorf("unexpected image: %dx%d at %d dpi", img.Width, img.Height, img.DPI)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if _, ok := decoded.(*image.Gray); !ok {
+		t.Errorf("expected a grayscale jpeg, got %T", decoded)
+	}
+	if string(img.Data[6:11]) != "JFIF\x00" || img.Data[13] != 1 || binary.BigEndian.Uint16(img.Data[14:]) != 200 {
+		t.Errorf("expected a JFIF header with 200 dpi, got % x", img.Data[:20])
+	}
+}
+
+func TestNormalizeTIFF(t *testing.T) {
+	img, err := checkdeposit.Normalize(bytes.NewReader(checkImage(t, 1000, 450)), checkdeposit.ImageOptions{Format: checkdeposit.FormatTIFF})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if img.Width != 1000 || img.Height != 450 || img.ContentType() != "image/tiff" {
+		t.Errorf("smaller images should not be upscaled, got %dx%d", img.Width, img.Height)
+	}
+	if string(img.Data[:4]) != "II*\x00" || len(img.Data) < 1000*450 {
+		t.Errorf("unexpected tiff: % x, %d bytes", img.Data[:8], len(img.Data))
+	}
+}
+
+func TestNormalizeLimits(t *testing.T) {
+	_, err := checkdeposit.Normalize(bytes.NewReader(checkImage(t, 400, 200)), checkdeposit.ImageOptions{})
+	if !errors.Is(err, checkdeposit.ErrResolutionTooLow) {
+		t.Errorf("expected ErrResolutionTooLow, got %v", err)
+	}
+	_, err = checkdeposit.Normalize(bytes.NewReader(checkImage(t, 1000, 450)), checkdeposit.ImageOptions{Format: checkdeposit.FormatTIFF, MaxBytes: 1000})
+	if !errors.Is(err, checkdeposit.ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+}
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	purposes []string
+	gets     int
+	final    map[string]any
+}
+
+func newFakeAPI(t *testing.T, final map[string]any) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{final: final}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Errorf("err should be nil: %s", err)
+		}
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		data, _ := io.ReadAll(f)
+		if h.Header.Get("Content-Type") != "image/jpeg" || len(data) == 0 {
+			t.Errorf("unexpected upload %s: %d bytes", h.Header.Get("Content-Type"), len(data))
+		}
+		api.mu.Lock()
+		api.purposes = append(api.purposes, r.FormValue("purpose"))
+		id := "file_" + r.FormValue("purpose")
+		api.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"id": id, "purpose": r.FormValue("purpose")})
+	})
+	mux.HandleFunc("POST /check_deposits", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["front_image_file_id"] != "file_check_image_front" || body["back_image_file_id"] != "file_check_image_back" {
+			t.Errorf("unexpected check deposit parameters: %v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": "check_deposit_1", "amount": body["amount"], "status": "pending"})
+	})
+	mux.HandleFunc("GET /check_deposits/check_deposit_1", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.gets++
+		if api.gets < 2 {
+			json.NewEncoder(w).Encode(map[string]any{"id": "check_deposit_1", "status": "pending"})
+			return
+		}
+		json.NewEncoder(w).Encode(api.final)
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func TestDepositSubmitted(t *testing.T) {
+	api, client := newFakeAPI(t, map[string]any{"id": "check_deposit_1", "status": "submitted"})
+	d := checkdeposit.NewDepositor(client)
+	d.PollInterval = time.Millisecond
+	deposit, err := d.Deposit(context.Background(), "account_1", 1500, bytes.NewReader(checkImage(t, 1200, 550)), bytes.NewReader(checkImage(t, 1200, 550)))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if deposit.Status != increase.CheckDepositStatusSubmitted || api.gets != 2 {
+		t.Errorf("unexpected deposit %s after %d polls", deposit.Status, api.gets)
+	}
+	if len(api.purposes) != 2 || api.purposes[0] != "check_image_front" || api.purposes[1] != "check_image_back" {
+		t.Errorf("unexpected file purposes: %v", api.purposes)
+	}
+}
+
+func TestDepositRejected(t *testing.T) {
+	_, client := newFakeAPI(t, map[string]any{
+		"id":                "check_deposit_1",
+		"status":            "rejected",
+		"deposit_rejection": map[string]any{"reason": "poor_image_quality"},
+	})
+	d := checkdeposit.NewDepositor(client)
+	d.PollInterval = time.Millisecond
+	deposit, err := d.Deposit(context.Background(), "account_1", 1500, bytes.NewReader(checkImage(t, 1200, 550)), bytes.NewReader(checkImage(t, 1200, 550)))
+	var rejection *checkdeposit.RejectionError
+	if !errors.As(err, &rejection) {
+		t.Fatalf("expected a RejectionError, got %v", err)
+	}
+	if deposit == nil || rejection.Reason() != "poor_image_quality" || !rejection.Retake() {
+		t.Errorf("unexpected rejection: %+v", rejection)
+	}
+}
+
+func TestWaitPending(t *testing.T) {
+	_, client := newFakeAPI(t, map[string]any{"id": "check_deposit_1", "status": "pending"})
+	d := checkdeposit.NewDepositor(client)
+	d.PollInterval = time.Millisecond
+	d.WaitTimeout = 20 * time.Millisecond
+	deposit, err := d.Wait(context.Background(), "check_deposit_1")
+	if !errors.Is(err, checkdeposit.ErrPending) || deposit == nil {
+		t.Errorf("expected ErrPending with the deposit, got %v", err)
+	}
+
+	// Canceling the caller's context is reported as such, even without a
+	// wait timeout.
+	d.WaitTimeout = -1
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.Wait(ctx, "check_deposit_1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+
+	d.NoWait = true
+	deposit, err = d.Deposit(context.Background(), "account_1", 1500, bytes.NewReader(checkImage(t, 1200, 550)), bytes.NewReader(checkImage(t, 1200, 550)))
+	if !errors.Is(err, checkdeposit.ErrPending) || deposit.Status != increase.CheckDepositStatusPending {
+		t.Errorf("expected the pending deposit without waiting, got %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package checkdeposit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+)
+
+// Format is the file format check images are uploaded in.
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	// Uncompressed 8-bit grayscale TIFF.
+	FormatTIFF Format = "tiff"
+)
+
+// ImageOptions configures how check images are normalized before upload. Zero
+// values are replaced with the defaults documented on each field.
+type ImageOptions struct {
+	// The format images are converted to. Defaults to [FormatJPEG].
+	Format Format
+	// The resolution of the uploaded image, in dots per inch. Defaults to 200.
+	DPI int
+	// The width of the check in inches, used with DPI to size the image. Defaults
+	// to 6, the width of a personal check. Use 8.5 for business checks.
+	WidthInches float64
+	// The narrowest image accepted, in pixels. Images that are narrower are
+	// rejected with [ErrResolutionTooLow] rather than upscaled. Defaults to 800.
+	MinWidth int
+	// The largest encoded image, in bytes. JPEG quality is lowered until the
+	// image fits. Defaults to 5 MB.
+	MaxBytes int
+	// The starting JPEG quality. Defaults to 85.
+	JPEGQuality int
+}
+
+// DefaultImageOptions returns the options used when none are given.
+func DefaultImageOptions() ImageOptions {
+	return ImageOptions{
+		Format:      FormatJPEG,
+		DPI:         200,
+		WidthInches: 6,
+		MinWidth:    800,
+		MaxBytes:    5 << 20,
+		JPEGQuality: 85,
+	}
+}
+
+func (o ImageOptions) withDefaults() ImageOptions {
+	d := DefaultImageOptions()
+	if o.Format == "" {
+		o.Format = d.Format
+	}
+	if o.DPI <= 0 {
+		o.DPI = d.DPI
+	}
+	if o.WidthInches <= 0 {
+		o.WidthInches = d.WidthInches
+	}
+	if o.MinWidth <= 0 {
+		o.MinWidth = d.MinWidth
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = d.MaxBytes
+	}
+	if o.JPEGQuality <= 0 || o.JPEGQuality > 100 {
+		o.JPEGQuality = d.JPEGQuality
+	}
+	return o
+}
+
+var (
+	// ErrResolutionTooLow is returned for images narrower than the minimum width.
+	ErrResolutionTooLow = errors.New("checkdeposit: image resolution is too low")
+	// ErrImageTooLarge is returned when an image cannot be encoded within the
+	// size limit.
+	ErrImageTooLarge = errors.New("checkdeposit: image is too large")
+)
+
+// Image is a normalized check image ready for upload.
+type Image struct {
+	Data   []byte
+	Format Format
+	Width  int
+	Height int
+	DPI    int
+}
+
+// Filename returns a file name with the extension for the image format.
+func (i *Image) Filename(side string) string {
+	if i.Format == FormatTIFF {
+		return side + ".tiff"
+	}
+	return side + ".jpg"
+}
+
+// ContentType returns the MIME type of the image.
+func (i *Image) ContentType() string {
+	if i.Format == FormatTIFF {
+		return "image/tiff"
+	}
+	return "image/jpeg"
+}
+
+// Normalize decodes a JPEG, PNG or GIF image, converts it to grayscale, scales
+// it down to the configured resolution and encodes it in the configured format.
+func Normalize(r io.Reader, opts ImageOptions) (*Image, error) {
+	opts = opts.withDefaults()
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("checkdeposit: decoding image: %w", err)
+	}
+	b := src.Bounds()
+	if b.Dx() < opts.MinWidth {
+		return nil, fmt.Errorf("%w: %d pixels wide, at least %d required", ErrResolutionTooLow, b.Dx(), opts.MinWidth)
+	}
+	gray := grayscale(src)
+	width := int(math.Round(float64(opts.DPI) * opts.WidthInches))
+	if width < b.Dx() {
+		height := int(math.Round(float64(b.Dy()) * float64(width) / float64(b.Dx())))
+		gray = downscale(gray, width, height)
+	}
+
+	img := &Image{Format: opts.Format, Width: gray.Rect.Dx(), Height: gray.Rect.Dy(), DPI: opts.DPI}
+	switch opts.Format {
+	case FormatJPEG:
+		for quality := opts.JPEGQuality; ; quality -= 10 {
+			img.Data, err = encodeJPEG(gray, quality, opts.DPI)
+			if err != nil {
+				return nil, err
+			}
+			if len(img.Data) <= opts.MaxBytes {
+				break
+			}
+			if quality <= 35 {
+				return nil, fmt.Errorf("%w: %d bytes at quality %d, limit %d", ErrImageTooLarge, len(img.Data), quality, opts.MaxBytes)
+			}
+		}
+	case FormatTIFF:
+		img.Data = encodeTIFF(gray, opts.DPI)
+		if len(img.Data) > opts.MaxBytes {
+			return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrImageTooLarge, len(img.Data), opts.MaxBytes)
+		}
+	default:
+		return nil, fmt.Errorf("checkdeposit: unknown image format %q", opts.Format)
+	}
+	return img, nil
+}
+
+func grayscale(src image.Image) *image.Gray {
+	if g, ok := src.(*image.Gray); ok {
+		return g
+	}
+	b := src.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.SetGray(x-b.Min.X, y-b.Min.Y, color.GrayModel.Convert(src.At(x, y)).(color.Gray))
+		}
+	}
+	return dst
+}
+
+// downscale resizes by averaging the source pixels that fall into each
+// destination pixel, which keeps thin strokes such as the MICR line legible.
+func downscale(src *image.Gray, width, height int) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := max(b.Min.X+(x+1)*b.Dx()/width, x0+1)
+			var sum, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):src.PixOffset(x1, sy)]
+				for _, v := range row {
+					sum += int(v)
+				}
+				n += len(row)
+			}
+			dst.Pix[dst.PixOffset(x, y)] = uint8(sum / n)
+		}
+	}
+	return dst
+}
+
+// encodeJPEG encodes the image and inserts a JFIF header carrying the
+// resolution, which the standard library encoder does not write.
+func encodeJPEG(img *image.Gray, quality int, dpi int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("checkdeposit: encoding jpeg: %w", err)
+	}
+	data := buf.Bytes()
+	app0 := []byte{
+		0xFF, 0xE0, 0x00, 0x10,
+		'J', 'F', 'I', 'F', 0x00,
+		0x01, 0x01, // version 1.1
+		0x01, // density in dots per inch
+		byte(dpi >> 8), byte(dpi), byte(dpi >> 8), byte(dpi),
+		0x00, 0x00, // no thumbnail
+	}
+	out := make([]byte, 0, len(data)+len(app0))
+	out = append(out, data[:2]...)
+	out = append(out, app0...)
+	return append(out, data[2:]...), nil
+}
+
+// encodeTIFF writes a little-endian, single-strip, uncompressed 8-bit
+// grayscale TIFF.
+func encodeTIFF(img *image.Gray, dpi int) []byte {
+	const entries = 11
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	ifdOffset := 8
+	rationalOffset := ifdOffset + 2 + entries*12 + 4
+	dataOffset := rationalOffset + 16
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.Write([]byte{'I', 'I', 42, 0})
+	binary.Write(&buf, le, uint32(ifdOffset))
+	binary.Write(&buf, le, uint16(entries))
+	entry := func(tag, typ uint16, value uint32) {
+		binary.Write(&buf, le, tag)
+		binary.Write(&buf, le, typ)
+		binary.Write(&buf, le, uint32(1))
+		if typ == 3 {
+			binary.Write(&buf, le, uint16(value))
+			binary.Write(&buf, le, uint16(0))
+		} else {
+			binary.Write(&buf, le, value)
+		}
+	}
+	const short, long, rational = 3, 4, 5
+	entry(256, long, uint32(w))                      // ImageWidth
+	entry(257, long, uint32(h))                      // ImageLength
+	entry(258, short, 8)                             // BitsPerSample
+	entry(259, short, 1)                             // Compression: none
+	entry(262, short, 1)                             // PhotometricInterpretation: BlackIsZero
+	entry(273, long, uint32(dataOffset))             // StripOffsets
+	entry(277, short, 1)                             // SamplesPerPixel
+	entry(278, long, uint32(h))                      // RowsPerStrip
+	entry(279, long, uint32(w*h))                    // StripByteCounts
+	entry(282, rational, uint32(rationalOffset))     // XResolution
+	entry(283, rational, uint32(rationalOffset+8))   // YResolution
+	binary.Write(&buf, le, uint32(0))                // no further IFDs
+	binary.Write(&buf, le, []uint32{uint32(dpi), 1}) // XResolution
+	binary.Write(&buf, le, []uint32{uint32(dpi), 1}) // YResolution
+	for y := 0; y < h; y++ {
+		buf.Write(img.Pix[img.PixOffset(0, y) : img.PixOffset(0, y)+w])
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,301 @@
+// Package checkprint renders Check Transfers as printable PDF checks.
+//
+// Check Transfers with the `third_party` fulfillment method are printed by you
+// rather than by Increase. [Render] lays out a US letter page with the check at
+// the top, followed by two voucher stubs, and prints the MICR line from the
+// transfer's routing number, account number and check number so that the
+// presented check matches the transfer.
+//
+// The MICR line must be printed with an E-13B font and magnetic toner. The
+// font is not bundled; pass its TrueType data in [Options.MICRFont].
+package checkprint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/pdf"
+)
+
+// Check is the content of a printed check.
+type Check struct {
+	CheckNumber string
+	Date        time.Time
+	// The amount in USD cents.
+	Amount int64
+	Payee  string
+	// The payee's mailing address, printed in the window envelope area.
+	PayeeAddress []string
+	// The name and address of the account holder, printed at the top left.
+	Payer         []string
+	BankName      string
+	RoutingNumber string
+	AccountNumber string
+	Memo          string
+	// The note printed on the voucher stubs.
+	Note string
+	// A signature image. If nil, SignatureText is printed instead.
+	Signature     image.Image
+	SignatureText string
+	// The last day the check may be deposited, printed as "Void after".
+	ValidUntil time.Time
+}
+
+// FromCheckTransfer returns the check for a Check Transfer. The details of
+// physical checks are copied from the transfer; for third-party checks only
+// the payee is known to Increase, and the memo, note, addresses and signature
+// must be set by the caller.
+func FromCheckTransfer(t increase.CheckTransfer) Check {
+	c := Check{
+		CheckNumber:   t.CheckNumber,
+		Date:          t.CreatedAt,
+		Amount:        t.Amount,
+		RoutingNumber: t.RoutingNumber,
+		AccountNumber: t.AccountNumber,
+		ValidUntil:    t.ValidUntilDate,
+	}
+	if t.FulfillmentMethod == increase.CheckTransferFulfillmentMethodThirdParty {
+		c.Payee = t.ThirdParty.RecipientName
+		return c
+	}
+	pc := t.PhysicalCheck
+	c.Payee = pc.RecipientName
+	c.Memo = pc.Memo
+	c.Note = pc.Note
+	c.SignatureText = pc.Signature.Text
+	a := pc.MailingAddress
+	c.PayeeAddress = nonEmpty(a.Name, a.Line1, a.Line2, strings.TrimSpace(fmt.Sprintf("%s, %s %s", a.City, a.State, a.PostalCode)))
+	if len(c.PayeeAddress) > 0 && c.PayeeAddress[0] != c.Payee && a.Name == "" {
+		c.PayeeAddress = append([]string{c.Payee}, c.PayeeAddress...)
+	}
+	for _, p := range pc.Payer {
+		c.Payer = append(c.Payer, p.Contents)
+	}
+	return c
+}
+
+// DecodeSignature decodes a PNG or JPEG signature image, such as the contents
+// of the File referenced by a physical check's signature.
+func DecodeSignature(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("checkprint: decoding signature: %w", err)
+	}
+	return img, nil
+}
+
+// Options configures rendering.
+type Options struct {
+	// TrueType data of an E-13B MICR font that maps the special symbols as
+	// described for [MICRTransit]. If nil, the font is referenced by
+	// MICRFontName and must be installed on the printing system.
+	MICRFont []byte
+	// The PostScript name of the MICR font. Defaults to "GnuMICR".
+	MICRFontName string
+	// The font size at which the MICR font's digits are 0.117 inches tall.
+	// Defaults to 12.
+	MICRFontSize float64
+	// Defaults to [MICRLayoutBusiness].
+	MICRLayout MICRLayout
+	// Omits the voucher stubs.
+	NoVoucher bool
+}
+
+// Dimensions of the check on a letter page, in points.
+const (
+	checkHeight   = 252.0 // 3.5 inches
+	margin        = 22.5  // 5/16 inch
+	micrPitch     = 9.0   // 8 characters per inch
+	micrBaseline  = 18.0  // from the bottom of the check
+	voucherHeight = (pdf.LetterHeight - checkHeight) / 2
+)
+
+// Render writes the check as a one-page PDF.
+func Render(w io.Writer, c Check, opts Options) error {
+	if c.Amount <= 0 {
+		return errors.New("checkprint: amount must be positive")
+	}
+	if c.Payee == "" {
+		return errors.New("checkprint: missing payee")
+	}
+	if opts.MICRFontName == "" {
+		opts.MICRFontName = "GnuMICR"
+	}
+	if opts.MICRFontSize <= 0 {
+		opts.MICRFontSize = 12
+	}
+	if opts.MICRLayout == "" {
+		opts.MICRLayout = MICRLayoutBusiness
+	}
+	micr, err := MICRLine(c.RoutingNumber, c.AccountNumber, c.CheckNumber, opts.MICRLayout)
+	if err != nil {
+		return err
+	}
+
+	doc := pdf.New()
+	doc.Title = "Check " + c.CheckNumber
+	micrFont := doc.TrueType(opts.MICRFontName, opts.MICRFont, int(micrPitch*1000/opts.MICRFontSize))
+	page := doc.AddPage(pdf.LetterWidth, pdf.LetterHeight)
+	renderCheck(page, c, micr, micrFont, opts.MICRFontSize)
+	if !opts.NoVoucher {
+		for i := 0; i < 2; i++ {
+			renderVoucher(page, c, pdf.LetterHeight-checkHeight-float64(i)*voucherHeight)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func renderCheck(p *pdf.Page, c Check, micr string, micrFont *pdf.Font, micrSize float64) {
+	bottom := pdf.LetterHeight - checkHeight
+	top := pdf.LetterHeight
+	left, right := margin+13.5, pdf.LetterWidth-margin-13.5
+	regular, bold := pdf.Helvetica, pdf.HelveticaBold
+
+	y := top - 36
+	for i, line := range c.Payer {
+		f := regular
+		if i == 0 {
+			f = bold
+		}
+		p.Text(f, 9, left, y, line)
+		y -= 11
+	}
+	if c.BankName != "" {
+		p.TextCenter(bold, 9, pdf.LetterWidth/2, top-36, c.BankName)
+	}
+	p.TextRight(bold, 12, right, top-36, c.CheckNumber)
+	p.Text(regular, 8, right-126, top-66, "DATE")
+	p.Text(regular, 10, right-100, top-66, c.Date.Format("01/02/2006"))
+	p.Line(right-102, top-69, right, top-69, 0.5)
+
+	// Payee and courtesy amount.
+	p.Text(regular, 7, left, top-102, "PAY TO THE")
+	p.Text(regular, 7, left, top-110, "ORDER OF")
+	p.Text(regular, 11, left+48, top-108, c.Payee)
+	p.Line(left+46, top-112, right-110, top-112, 0.5)
+	p.Text(bold, 11, right-100, top-108, "$")
+	p.Rect(right-92, top-116, 92, 20, 0.75)
+	p.TextRight(bold, 11, right-6, top-108, "**"+FormatAmount(c.Amount))
+
+	// Legal amount, padded with asterisks so it cannot be extended.
+	words := AmountInWords(c.Amount) + " "
+	width := right - 54 - left
+	for pdf.Helvetica.Width(words+"*", 10) < width {
+		words += "*"
+	}
+	p.Text(regular, 10, left, top-136, words)
+	p.Line(left, top-140, right-54, top-140, 0.5)
+	p.Text(regular, 8, right-48, top-136, "DOLLARS")
+
+	y = top - 160
+	for _, line := range c.PayeeAddress {
+		p.Text(regular, 9, left+48, y, line)
+		y -= 11
+	}
+
+	if !c.ValidUntil.IsZero() {
+		p.Text(regular, 7, right-220, top-160, "VOID AFTER "+strings.ToUpper(c.ValidUntil.Format("January 2, 2006")))
+	}
+
+	// Memo and signature.
+	p.Text(regular, 7, left, bottom+54, "MEMO")
+	p.Text(regular, 10, left+28, bottom+56, c.Memo)
+	p.Line(left+26, bottom+52, left+230, bottom+52, 0.5)
+	sigLeft, sigRight := right-220, right
+	if c.Signature != nil {
+		b := c.Signature.Bounds()
+		h := 36.0
+		w := h * float64(b.Dx()) / float64(b.Dy())
+		if w > sigRight-sigLeft {
+			w = sigRight - sigLeft
+			h = w * float64(b.Dy()) / float64(b.Dx())
+		}
+		p.Image(c.Signature, sigRight-w, bottom+54, w, h)
+	} else if c.SignatureText != "" {
+		p.TextCenter(regular, 14, (sigLeft+sigRight)/2, bottom+58, c.SignatureText)
+	}
+	p.Line(sigLeft, bottom+52, sigRight, bottom+52, 0.5)
+	p.Text(regular, 6, sigLeft, bottom+45, "AUTHORIZED SIGNATURE")
+
+	// The MICR line, right-aligned so that its last character is position 1.
+	p.TextRight(micrFont, micrSize, pdf.LetterWidth-margin, bottom+micrBaseline, micr)
+
+	// Perforation.
+	p.SetGray(0.6)
+	p.Line(0, bottom, pdf.LetterWidth, bottom, 0.25)
+	p.SetGray(0)
+}
+
+func renderVoucher(p *pdf.Page, c Check, top float64) {
+	left, right := margin+13.5, pdf.LetterWidth-margin-13.5
+	regular, bold := pdf.Helvetica, pdf.HelveticaBold
+
+	p.Text(bold, 10, left, top-30, c.Payee)
+	p.TextRight(bold, 10, right, top-30, "Check "+c.CheckNumber)
+	p.Text(regular, 9, left, top-44, c.Date.Format("January 2, 2006"))
+	p.TextRight(regular, 9, right, top-44, "$"+FormatAmount(c.Amount))
+	p.Line(left, top-52, right, top-52, 0.5)
+	y := top - 70
+	if c.Memo != "" {
+		p.Text(regular, 9, left, y, "Memo: "+c.Memo)
+		y -= 14
+	}
+	for _, line := range wrap(c.Note, regular, 9, right-left) {
+		if y < top-voucherHeight+24 {
+			break
+		}
+		p.Text(regular, 9, left, y, line)
+		y -= 12
+	}
+	p.SetGray(0.6)
+	p.Line(0, top-voucherHeight, pdf.LetterWidth, top-voucherHeight, 0.25)
+	p.SetGray(0)
+}
+
+// wrap splits text into lines no wider than width, keeping explicit newlines.
+func wrap(text string, f *pdf.Font, size float64, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && f.Width(line+" "+word, size) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func nonEmpty(values ...string) []string {
+	var res []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" && strings.TrimSpace(v) != "," {
+			res = append(res, v)
+		}
+	}
+	return res
+}
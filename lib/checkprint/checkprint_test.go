@@ -0,0 +1,148 @@
+package checkprint_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/checkprint"
+)
+
+func TestAmountInWords(t *testing.T) {
+	tests := map[int64]string{
+		0:           "Zero and 00/100",
+		7:           "Zero and 07/100",
+		100:         "One and 00/100",
+		123456:      "One thousand two hundred thirty-four and 56/100",
+		1500000:     "Fifteen thousand and 00/100",
+		10000000001: "One hundred million and 01/100",
+		9099099:     "Ninety thousand nine hundred ninety and 99/100",
+	}
+	for cents, want := range tests {
+		if got := checkprint.AmountInWords(cents); got != want {
+			t.Errorf("AmountInWords(%d) = %q, want %q", cents, got, want)
+		}
+	}
+	if got := checkprint.FormatAmount(123456789); got != "1,234,567.89" {
+		t.Errorf("unexpected amount %q", got)
+	}
+}
+
+func TestMICRLine(t *testing.T) {
+	line, err := checkprint.MICRLine("101050001", "987654321", "1001", checkprint.MICRLayoutBusiness)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	// Positions are counted from the right, so index len-p is position p.
+	at := func(position int, s string) string {
+		end := len(line) - position + 1
+		return line[end-len(s) : end]
+	}
+	if got := at(33, "A101050001A"); got != "A101050001A" {
+		t.Errorf("unexpected transit field %q in %q", got, line)
+	}
+	if got := at(14, "987654321C"); got != "987654321C" {
+		t.Errorf("unexpected on-us field %q in %q", got, line)
+	}
+	if got := at(45, "C1001C"); got != "C1001C" {
+		t.Errorf("unexpected auxiliary on-us field %q in %q", got, line)
+	}
+	if !strings.HasSuffix(line, strings.Repeat(" ", 13)) {
+		t.Errorf("the amount field should be blank: %q", line)
+	}
+
+	line, err = checkprint.MICRLine("101050001", "9876-54", "1001", checkprint.MICRLayoutPersonal)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !strings.HasPrefix(line, "A101050001A") || !strings.HasSuffix(line, " 9876D54C1001"+strings.Repeat(" ", 13)) {
+		t.Errorf("unexpected personal MICR line %q", line)
+	}
+
+	if _, err := checkprint.MICRLine("101050002x", "1", "1", checkprint.MICRLayoutBusiness); err == nil {
+		t.Errorf("expected an error for an invalid routing number")
+	}
+}
+
+func TestRender(t *testing.T) {
+	transfer := increase.CheckTransfer{
+		CheckNumber:       "1001",
+		CreatedAt:         time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Amount:            123456,
+		RoutingNumber:     "101050001",
+		AccountNumber:     "987654321",
+		FulfillmentMethod: increase.CheckTransferFulfillmentMethodPhysicalCheck,
+		PhysicalCheck: increase.CheckTransferPhysicalCheck{
+			RecipientName: "Ian Crease",
+			Memo:          "Invoice 1001",
+			Note:          "Thank you for your business.",
+			MailingAddress: increase.CheckTransferPhysicalCheckMailingAddress{
+				Line1:      "33 Liberty Street",
+				City:       "New York",
+				State:      "NY",
+				PostalCode: "10045",
+			},
+		},
+	}
+	c := checkprint.FromCheckTransfer(transfer)
+	if c.Payee != "Ian Crease" || len(c.PayeeAddress) != 3 || c.PayeeAddress[2] != "New York, NY 10045" {
+		t.Fatalf("unexpected check: %+v", c)
+	}
+	sig := image.NewGray(image.Rect(0, 0, 60, 20))
+	for x := 0; x < 60; x++ {
+		sig.SetGray(x, 10, color.Gray{})
+	}
+	c.Signature = sig
+
+	var buf bytes.Buffer
+	if err := checkprint.Render(&buf, c, checkprint.Options{}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("output is not a PDF")
+	}
+	if !strings.Contains(out, "/BaseFont /GnuMICR") || !strings.Contains(out, "/Subtype /Image") {
+		t.Errorf("expected a MICR font and a signature image")
+	}
+	content := streams(t, out)
+	for _, want := range []string{
+		"(Ian Crease)",
+		"(One thousand two hundred thirty-four and 56/100 *",
+		"(**1,234.56)",
+		"(Invoice 1001)",
+		"(Thank you for your business.)",
+		"(C1001C A101050001A         987654321C             )",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected page content to contain %q", want)
+		}
+	}
+}
+
+// streams returns the decompressed contents of every stream in a PDF.
+func streams(t *testing.T, doc string) string {
+	t.Helper()
+	var res strings.Builder
+	for {
+		i := strings.Index(doc, "stream\n")
+		if i < 0 {
+			return res.String()
+		}
+		doc = doc[i+len("stream\n"):]
+		j := strings.Index(doc, "\nendstream")
+		r, err := zlib.NewReader(strings.NewReader(doc[:j]))
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		data, _ := io.ReadAll(r)
+		res.Write(data)
+		doc = doc[j+len("\nendstream"):]
+	}
+}
@@ -0,0 +1,72 @@
+package checkprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ones = []string{
+	"", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var tens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var scales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion"}
+
+// AmountInWords spells out an amount in USD cents the way it is written on the
+// legal amount line of a check, for example "One thousand two hundred
+// thirty-four and 56/100".
+func AmountInWords(cents int64) string {
+	if cents < 0 {
+		cents = -cents
+	}
+	dollars, rest := cents/100, cents%100
+	words := "zero"
+	if dollars > 0 {
+		var groups []string
+		for scale := 0; dollars > 0; scale++ {
+			if group := dollars % 1000; group > 0 {
+				g := hundreds(int(group))
+				if scales[scale] != "" {
+					g += " " + scales[scale]
+				}
+				groups = append([]string{g}, groups...)
+			}
+			dollars /= 1000
+		}
+		words = strings.Join(groups, " ")
+	}
+	return strings.ToUpper(words[:1]) + words[1:] + fmt.Sprintf(" and %02d/100", rest)
+}
+
+func hundreds(n int) string {
+	var parts []string
+	if n >= 100 {
+		parts = append(parts, ones[n/100]+" hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		parts = append(parts, tens[n/10]+"-"+ones[n%10])
+	case n >= 20:
+		parts = append(parts, tens[n/10])
+	case n > 0:
+		parts = append(parts, ones[n])
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatAmount formats an amount in USD cents with thousands separators, for
+// example "1,234.56".
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	s := fmt.Sprintf("%d", cents/100)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, s, cents%100)
+}
@@ -0,0 +1,384 @@
+// Package disputes builds and submits Card Disputes.
+//
+// Each Visa dispute reason has its own set of required fields and supporting
+// documents. [Requirements] lists them for a reason, deriving the fields from
+// the request parameters of this SDK so that they stay current with the API.
+// A [Draft] collects the details and attachments of a dispute and validates
+// them locally, before anything is sent. [Builder.Submit] uploads the
+// attachments as Files with the `card_dispute_attachment` purpose and creates
+// the dispute; [Builder.SubmitUserSubmission] does the same for the follow-up
+// submissions a dispute may require. [Builder.Deadlines] lists the disputes
+// that are waiting on you, soonest deadline first.
+//
+// A [Tracker] follows disputes once they are submitted. It keeps a timeline
+// of each dispute's network events, works out who must act next and by when,
+// and calls back as deadlines approach and when disputes are won or lost.
+package disputes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// Reason is the Visa dispute category.
+type Reason = increase.CardDisputeNewParamsVisaCategory
+
+// Document is a supporting document for a dispute reason.
+type Document struct {
+	Name     string
+	Required bool
+}
+
+// Evidence lists the supporting documents for each reason. Reasons that are
+// not listed need none. Replace or extend it to match your dispute policy.
+var Evidence = map[Reason][]Document{
+	increase.CardDisputeNewParamsVisaCategoryProcessingError: {
+		{Name: "receipt", Required: true},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerMerchandiseNotReceived: {
+		{Name: "proof_of_purchase", Required: true},
+		{Name: "merchant_correspondence"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerServicesNotReceived: {
+		{Name: "proof_of_purchase", Required: true},
+		{Name: "merchant_correspondence"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerCanceledRecurringTransaction: {
+		{Name: "cancellation_confirmation"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerCanceledMerchandise: {
+		{Name: "cancellation_confirmation"},
+		{Name: "proof_of_return"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerCanceledServices: {
+		{Name: "cancellation_confirmation"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerCreditNotProcessed: {
+		{Name: "credit_voucher", Required: true},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerCounterfeitMerchandise: {
+		{Name: "counterfeit_opinion", Required: true},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerDamagedOrDefectiveMerchandise: {
+		{Name: "merchandise_photos", Required: true},
+		{Name: "proof_of_return"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerMerchandiseMisrepresentation: {
+		{Name: "merchant_description", Required: true},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerMerchandiseNotAsDescribed: {
+		{Name: "merchant_description", Required: true},
+		{Name: "proof_of_return"},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerQualityMerchandise: {
+		{Name: "merchandise_photos", Required: true},
+	},
+	increase.CardDisputeNewParamsVisaCategoryConsumerServicesMisrepresentation: {
+		{Name: "merchant_description", Required: true},
+	},
+}
+
+// Requirements are the fields and documents a dispute reason needs.
+type Requirements struct {
+	Reason Reason
+	// The fields of the request, including the conditional fields of the
+	// reason's details.
+	Fields    []Field
+	Documents []Document
+}
+
+// RequirementsFor returns the requirements of a dispute reason.
+func RequirementsFor(reason Reason) (Requirements, error) {
+	if !reason.IsKnown() {
+		return Requirements{}, fmt.Errorf("disputes: unknown reason %q", reason)
+	}
+	req := Requirements{Reason: reason, Documents: Evidence[reason]}
+	for _, f := range describe(reflect.TypeOf(increase.CardDisputeNewParams{}), "") {
+		// The details of other reasons do not apply.
+		if f.Path != "visa" && strings.HasPrefix(f.Path, "visa.") && !strings.HasPrefix(f.Path, "visa.category") {
+			continue
+		}
+		req.Fields = append(req.Fields, f)
+	}
+	for _, f := range paramFields(reflect.TypeOf(increase.CardDisputeNewParamsVisa{})) {
+		if f.name == string(reason) {
+			path := "visa." + f.name
+			req.Fields = append(req.Fields, Field{Path: path, Required: true})
+			req.Fields = append(req.Fields, describe(f.typ, path+".")...)
+		}
+	}
+	return req, nil
+}
+
+// Attachment is a file to attach to a dispute. Either Reader or FileID is
+// set; files that are already uploaded must have the `card_dispute_attachment`
+// purpose.
+type Attachment struct {
+	// The supporting document this file provides, from [Evidence].
+	Document    string
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+	FileID      string
+}
+
+// Draft is a Card Dispute being prepared.
+type Draft struct {
+	Transaction increase.Transaction
+	Reason      Reason
+	// The disputed amount. Defaults to the full amount of the transaction.
+	Amount      int64
+	Explanation string
+	// The Visa details. Category is set from Reason; fill in the sub-object
+	// named after the reason.
+	Visa        increase.CardDisputeNewParamsVisa
+	Attachments []Attachment
+}
+
+// NewDraft starts a dispute of a transaction for a reason.
+func NewDraft(tx increase.Transaction, reason Reason) *Draft {
+	return &Draft{Transaction: tx, Reason: reason}
+}
+
+// Requirements returns the requirements of the draft's reason.
+func (d *Draft) Requirements() (Requirements, error) {
+	return RequirementsFor(d.Reason)
+}
+
+// Params returns the parameters to create the dispute with, without the
+// attachments that are yet to be uploaded.
+func (d *Draft) Params() increase.CardDisputeNewParams {
+	visa := d.Visa
+	visa.Category = increase.F(d.Reason)
+	params := increase.CardDisputeNewParams{
+		DisputedTransactionID: increase.F(d.Transaction.ID),
+		Network:               increase.F(increase.CardDisputeNewParamsNetworkVisa),
+		Visa:                  increase.F(visa),
+	}
+	if d.Amount != 0 {
+		params.Amount = increase.F(d.Amount)
+	}
+	if d.Explanation != "" {
+		params.Explanation = increase.F(d.Explanation)
+	}
+	var files []increase.CardDisputeNewParamsAttachmentFile
+	for _, a := range d.Attachments {
+		if a.FileID != "" {
+			files = append(files, increase.CardDisputeNewParamsAttachmentFile{FileID: increase.F(a.FileID)})
+		}
+	}
+	if len(files) > 0 {
+		params.AttachmentFiles = increase.F(files)
+	}
+	return params
+}
+
+// Validate checks the draft locally and returns a [*ValidationError] listing
+// every problem found.
+func (d *Draft) Validate() error {
+	var problems []Problem
+	if d.Transaction.ID == "" {
+		problems = append(problems, Problem{"disputed_transaction_id", "is required"})
+	} else if d.Transaction.Source.Category != increase.TransactionSourceCategoryCardSettlement {
+		problems = append(problems, Problem{"disputed_transaction_id", fmt.Sprintf("must be a card settlement, not %s", d.Transaction.Source.Category)})
+	}
+	if d.Amount < 0 || d.Amount > abs(d.Transaction.Amount) {
+		problems = append(problems, Problem{"amount", fmt.Sprintf("must be between 0 and the transaction amount of %d", abs(d.Transaction.Amount))})
+	}
+	problems = append(problems, validate(reflect.ValueOf(d.Params()), "")...)
+	problems = append(problems, checkDocuments(Evidence[d.Reason], d.Attachments)...)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func checkDocuments(documents []Document, attachments []Attachment) []Problem {
+	var problems []Problem
+	for i, a := range attachments {
+		if (a.Reader == nil) == (a.FileID == "") {
+			problems = append(problems, Problem{fmt.Sprintf("attachment_files[%d]", i), "must have either a reader or a file ID"})
+		}
+	}
+	for _, doc := range documents {
+		found := false
+		for _, a := range attachments {
+			found = found || a.Document == doc.Name
+		}
+		if doc.Required && !found {
+			problems = append(problems, Problem{"attachment_files", fmt.Sprintf("missing %s", doc.Name)})
+		}
+	}
+	return problems
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Builder submits disputes.
+type Builder struct {
+	client *increase.Client
+	now    func() time.Time
+}
+
+// New returns a builder that submits disputes with the client.
+func New(client *increase.Client) *Builder {
+	return &Builder{client: client, now: time.Now}
+}
+
+// Submit validates the draft, uploads its attachments and creates the
+// dispute. Uploaded attachments are recorded on the draft, so a failed
+// submission can be retried without uploading them again.
+func (b *Builder) Submit(ctx context.Context, d *Draft, opts ...option.RequestOption) (*increase.CardDispute, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.upload(ctx, d.Attachments, opts); err != nil {
+		return nil, err
+	}
+	return b.client.CardDisputes.New(ctx, d.Params(), opts...)
+}
+
+// Submission is a follow-up submission to a dispute, such as a chargeback or
+// a pre-arbitration.
+type Submission struct {
+	CardDisputeID string
+	// The adjusted disputed amount, if any.
+	Amount      int64
+	Explanation string
+	// The Visa details. Fill in Category and the sub-object named after it.
+	Visa        increase.CardDisputeSubmitUserSubmissionParamsVisa
+	Attachments []Attachment
+}
+
+// Params returns the parameters of the submission, without the attachments
+// that are yet to be uploaded.
+func (s *Submission) Params() increase.CardDisputeSubmitUserSubmissionParams {
+	params := increase.CardDisputeSubmitUserSubmissionParams{
+		Network: increase.F(increase.CardDisputeSubmitUserSubmissionParamsNetworkVisa),
+		Visa:    increase.F(s.Visa),
+	}
+	if s.Amount != 0 {
+		params.Amount = increase.F(s.Amount)
+	}
+	if s.Explanation != "" {
+		params.Explanation = increase.F(s.Explanation)
+	}
+	var files []increase.CardDisputeSubmitUserSubmissionParamsAttachmentFile
+	for _, a := range s.Attachments {
+		if a.FileID != "" {
+			files = append(files, increase.CardDisputeSubmitUserSubmissionParamsAttachmentFile{FileID: increase.F(a.FileID)})
+		}
+	}
+	if len(files) > 0 {
+		params.AttachmentFiles = increase.F(files)
+	}
+	return params
+}
+
+// Validate checks the submission locally.
+func (s *Submission) Validate() error {
+	var problems []Problem
+	if s.CardDisputeID == "" {
+		problems = append(problems, Problem{"card_dispute_id", "is required"})
+	}
+	if s.Amount < 0 {
+		problems = append(problems, Problem{"amount", "must not be negative"})
+	}
+	problems = append(problems, validate(reflect.ValueOf(s.Params()), "")...)
+	problems = append(problems, checkDocuments(nil, s.Attachments)...)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// SubmitUserSubmission validates the submission, uploads its attachments and
+// submits it.
+func (b *Builder) SubmitUserSubmission(ctx context.Context, s *Submission, opts ...option.RequestOption) (*increase.CardDispute, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.upload(ctx, s.Attachments, opts); err != nil {
+		return nil, err
+	}
+	return b.client.CardDisputes.SubmitUserSubmission(ctx, s.CardDisputeID, s.Params(), opts...)
+}
+
+func (b *Builder) upload(ctx context.Context, attachments []Attachment, opts []option.RequestOption) error {
+	for i := range attachments {
+		a := &attachments[i]
+		if a.FileID != "" {
+			continue
+		}
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/pdf"
+		}
+		params := increase.FileNewParams{
+			File:    increase.FileParam(a.Reader, a.Filename, contentType),
+			Purpose: increase.F(increase.FileNewParamsPurposeCardDisputeAttachment),
+		}
+		if a.Document != "" {
+			params.Description = increase.F(a.Document)
+		}
+		file, err := b.client.Files.New(ctx, params, opts...)
+		if err != nil {
+			return fmt.Errorf("disputes: uploading %s: %w", a.Filename, err)
+		}
+		a.FileID, a.Reader = file.ID, nil
+	}
+	return nil
+}
+
+// Deadline is a dispute waiting on a user submission.
+type Deadline struct {
+	Dispute increase.CardDispute
+	Due     time.Time
+	// The time left until the deadline. Negative when it has passed.
+	Remaining time.Duration
+}
+
+// DeadlineOf returns the deadline of the next user submission, if the dispute
+// is waiting on one.
+func DeadlineOf(d increase.CardDispute) (time.Time, bool) {
+	if d.Status != increase.CardDisputeStatusUserSubmissionRequired || d.UserSubmissionRequiredBy.IsZero() {
+		return time.Time{}, false
+	}
+	return d.UserSubmissionRequiredBy, true
+}
+
+// Deadlines lists the disputes waiting on a user submission, soonest deadline
+// first.
+func (b *Builder) Deadlines(ctx context.Context, opts ...option.RequestOption) ([]Deadline, error) {
+	iter := b.client.CardDisputes.ListAutoPaging(ctx, increase.CardDisputeListParams{
+		Status: increase.F(increase.CardDisputeListParamsStatus{
+			In: increase.F([]increase.CardDisputeListParamsStatusIn{increase.CardDisputeListParamsStatusInUserSubmissionRequired}),
+		}),
+	}, opts...)
+	now := b.now()
+	var res []Deadline
+	for iter.Next() {
+		d := iter.Current()
+		if due, ok := DeadlineOf(d); ok {
+			res = append(res, Deadline{Dispute: d, Due: due, Remaining: due.Sub(now)})
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Due.Before(res[j].Due) })
+	return res, nil
+}
@@ -0,0 +1,205 @@
+package disputes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/disputes"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	files    []string
+	disputes []map[string]any
+	created  map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /files", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("err should be nil: %s", err)
+		}
+		api.files = append(api.files, r.FormValue("purpose"))
+		json.NewEncoder(w).Encode(map[string]any{"id": "file_" + r.FormValue("description"), "purpose": r.FormValue("purpose")})
+	})
+	mux.HandleFunc("POST /card_disputes", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&api.created)
+		json.NewEncoder(w).Encode(map[string]any{"id": "card_dispute_1", "status": "pending_user_submission_reviewing"})
+	})
+	mux.HandleFunc("GET /card_disputes", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"data": api.disputes, "next_cursor": nil})
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func settlement() increase.Transaction {
+	var tx increase.Transaction
+	json.Unmarshal([]byte(`{"id":"transaction_1","amount":-5000,"source":{"category":"card_settlement"}}`), &tx)
+	return tx
+}
+
+func TestRequirements(t *testing.T) {
+	req, err := disputes.RequirementsFor(increase.CardDisputeNewParamsVisaCategoryConsumerMerchandiseNotReceived)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	fields := map[string]disputes.Field{}
+	for _, f := range req.Fields {
+		fields[f.Path] = f
+	}
+	if f := fields["visa.consumer_merchandise_not_received.delivery_issue"]; !f.Required {
+		t.Errorf("expected delivery_issue to be required, got %+v", f)
+	}
+	if f := fields["visa.consumer_merchandise_not_received.delayed"]; f.When != "visa.consumer_merchandise_not_received.delivery_issue" || f.WhenValue != "delayed" {
+		t.Errorf("expected delayed to depend on delivery_issue, got %+v", f)
+	}
+	if f := fields["visa.consumer_merchandise_not_received.delayed.explanation"]; !f.Required {
+		t.Errorf("expected the fields of delayed to be listed, got %+v", f)
+	}
+	if _, ok := fields["visa.fraud"]; ok {
+		t.Errorf("the details of other reasons should not be listed")
+	}
+	if !fields["disputed_transaction_id"].Required || !fields["visa.category"].Required {
+		t.Errorf("expected the top-level fields to be required: %+v", req.Fields)
+	}
+	if len(req.Documents) == 0 || !req.Documents[0].Required {
+		t.Errorf("expected a required document, got %+v", req.Documents)
+	}
+	if _, err := disputes.RequirementsFor("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown reason")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := disputes.NewDraft(settlement(), increase.CardDisputeNewParamsVisaCategoryConsumerMerchandiseNotReceived)
+	d.Amount = 6000
+	d.Visa.ConsumerMerchandiseNotReceived = increase.F(increase.CardDisputeNewParamsVisaConsumerMerchandiseNotReceived{
+		CancellationOutcome:         increase.F(increase.CardDisputeNewParamsVisaConsumerMerchandiseNotReceivedCancellationOutcomeNoCancellation),
+		DeliveryIssue:               increase.F(increase.CardDisputeNewParamsVisaConsumerMerchandiseNotReceivedDeliveryIssueDelayed),
+		LastExpectedReceiptAt:       increase.F(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)),
+		MerchantResolutionAttempted: increase.F(increase.CardDisputeNewParamsVisaConsumerMerchandiseNotReceivedMerchantResolutionAttemptedAttempted),
+		PurchaseInfoAndExplanation:  increase.F("Ordered a desk, never arrived."),
+		DeliveredToWrongLocation:    increase.F(increase.CardDisputeNewParamsVisaConsumerMerchandiseNotReceivedDeliveredToWrongLocation{}),
+	})
+	d.Visa.Fraud = increase.F(increase.CardDisputeNewParamsVisaFraud{})
+
+	var verr *disputes.ValidationError
+	if err := d.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	var problems []string
+	for _, p := range verr.Problems {
+		problems = append(problems, p.String())
+	}
+	got := strings.Join(problems, "\n")
+	for _, want := range []string{
+		"amount: must be between 0 and the transaction amount of 5000",
+		"visa.consumer_merchandise_not_received.delayed: is required",
+		"visa.consumer_merchandise_not_received.delivered_to_wrong_location: is only allowed when visa.consumer_merchandise_not_received.delivery_issue is delivered_to_wrong_location",
+		"visa.consumer_merchandise_not_received.no_cancellation: is required",
+		"visa.fraud: is only allowed when visa.category is fraud",
+		"attachment_files: missing proof_of_purchase",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected problem %q, got:\n%s", want, got)
+		}
+	}
+	if len(problems) != 6 {
+		t.Errorf("expected 6 problems, got:\n%s", got)
+	}
+
+	var tx increase.Transaction
+	json.Unmarshal([]byte(`{"id":"transaction_2","amount":100,"source":{"category":"ach_transfer_intention"}}`), &tx)
+	d = disputes.NewDraft(tx, increase.CardDisputeNewParamsVisaCategoryFraud)
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "must be a card settlement") {
+		t.Errorf("expected an error for a transaction that is not a card settlement, got %v", err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	api, client := newFakeAPI(t)
+	d := disputes.NewDraft(settlement(), increase.CardDisputeNewParamsVisaCategoryProcessingError)
+	d.Visa.ProcessingError = increase.F(increase.CardDisputeNewParamsVisaProcessingError{})
+	if err := d.Validate(); err == nil {
+		t.Fatalf("expected the processing error details to be incomplete")
+	}
+	var details increase.CardDisputeNewParamsVisaProcessingError
+	for _, f := range mustRequirements(t, d).Fields {
+		if f.Path == "visa.processing_error.error_reason" && !f.Required {
+			t.Errorf("expected error_reason to be required")
+		}
+	}
+	details.ErrorReason = increase.F(increase.CardDisputeNewParamsVisaProcessingErrorErrorReasonDuplicateTransaction)
+	details.MerchantResolutionAttempted = increase.F(increase.CardDisputeNewParamsVisaProcessingErrorMerchantResolutionAttemptedAttempted)
+	details.DuplicateTransaction = increase.F(increase.CardDisputeNewParamsVisaProcessingErrorDuplicateTransaction{
+		OtherTransactionID: increase.F("transaction_0"),
+	})
+	d.Visa.ProcessingError = increase.F(details)
+	d.Attachments = []disputes.Attachment{{Document: "receipt", Filename: "receipt.pdf", Reader: strings.NewReader("%PDF-1.4")}}
+
+	dispute, err := disputes.New(client).Submit(context.Background(), d)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if dispute.ID != "card_dispute_1" || len(api.files) != 1 || api.files[0] != "card_dispute_attachment" {
+		t.Fatalf("unexpected dispute %+v or files %v", dispute, api.files)
+	}
+	files := api.created["attachment_files"].([]any)
+	visa := api.created["visa"].(map[string]any)
+	if files[0].(map[string]any)["file_id"] != "file_receipt" || visa["category"] != "processing_error" || api.created["network"] != "visa" {
+		t.Errorf("unexpected request: %+v", api.created)
+	}
+	if d.Attachments[0].FileID != "file_receipt" {
+		t.Errorf("expected the uploaded file to be recorded on the draft")
+	}
+}
+
+func mustRequirements(t *testing.T, d *disputes.Draft) disputes.Requirements {
+	t.Helper()
+	req, err := d.Requirements()
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return req
+}
+
+func TestSubmissionValidation(t *testing.T) {
+	_, client := newFakeAPI(t)
+	_, err := disputes.New(client).SubmitUserSubmission(context.Background(), &disputes.Submission{CardDisputeID: "card_dispute_1"})
+	var verr *disputes.ValidationError
+	if !errors.As(err, &verr) || verr.Problems[0].Path != "visa.category" {
+		t.Errorf("expected the category to be required, got %v", err)
+	}
+}
+
+func TestDeadlines(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.disputes = []map[string]any{
+		{"id": "card_dispute_1", "status": "user_submission_required", "user_submission_required_by": "2026-11-10T00:00:00Z"},
+		{"id": "card_dispute_2", "status": "user_submission_required", "user_submission_required_by": "2026-11-01T00:00:00Z"},
+		{"id": "card_dispute_3", "status": "pending_response", "user_submission_required_by": nil},
+	}
+	deadlines, err := disputes.New(client).Deadlines(context.Background())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(deadlines) != 2 || deadlines[0].Dispute.ID != "card_dispute_2" || deadlines[1].Due.Day() != 10 {
+		t.Errorf("unexpected deadlines: %+v", deadlines)
+	}
+}
@@ -0,0 +1,188 @@
+package disputes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go/internal/param"
+)
+
+// Field describes a field of the dispute parameters.
+type Field struct {
+	// The path of the field in the request body, such as
+	// "visa.consumer_merchandise_not_received.delivery_issue".
+	Path string
+	// Whether the field is always required.
+	Required bool
+	// For fields that are only required, and only allowed, when another field
+	// has a value: the path of that field and the value.
+	When      string
+	WhenValue string
+}
+
+// Problem is a reason the dispute parameters would be rejected.
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError lists the problems found by local validation.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var problems []string
+	for _, p := range e.Problems {
+		problems = append(problems, p.String())
+	}
+	return "disputes: invalid dispute: " + strings.Join(problems, "; ")
+}
+
+type knowable interface{ IsKnown() bool }
+
+var (
+	fieldLike    = reflect.TypeOf((*param.FieldLike)(nil)).Elem()
+	knowableType = reflect.TypeOf((*knowable)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+type structField struct {
+	index    int
+	name     string
+	required bool
+	// The type of the field's value.
+	typ reflect.Type
+}
+
+// paramFields returns the param.Field fields of a params struct type.
+func paramFields(t reflect.Type) []structField {
+	var res []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.Type.Implements(fieldLike) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		value, _ := f.Type.FieldByName("Value")
+		res = append(res, structField{
+			index:    i,
+			name:     name,
+			required: strings.Contains(f.Tag.Get("api"), "required"),
+			typ:      value.Type,
+		})
+	}
+	return res
+}
+
+// isKnownValue reports whether v is a known value of the enum type t.
+func isKnownValue(t reflect.Type, v string) bool {
+	if t.Kind() != reflect.String || !t.Implements(knowableType) {
+		return false
+	}
+	e := reflect.New(t).Elem()
+	e.SetString(v)
+	return e.Interface().(knowable).IsKnown()
+}
+
+// condition returns the sibling enum field that a field depends on. The
+// generated params follow a convention: a field that is "required if and only
+// if `x` is `y`" is named after the value y of the enum x, and a free-form
+// explanation required when x is `other` is named `other_explanation`.
+func condition(fields []structField, f structField) (structField, string, bool) {
+	for _, sibling := range fields {
+		if sibling.index == f.index || sibling.typ.Kind() != reflect.String {
+			continue
+		}
+		if isKnownValue(sibling.typ, f.name) {
+			return sibling, f.name, true
+		}
+		if value, ok := strings.CutSuffix(f.name, "_explanation"); ok && value == "other" && isKnownValue(sibling.typ, value) {
+			return sibling, value, true
+		}
+	}
+	return structField{}, "", false
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// describe lists the fields of a params struct type. Fields nested under an
+// optional field are only listed if the optional field is conditional.
+func describe(t reflect.Type, prefix string) []Field {
+	var res []Field
+	fields := paramFields(t)
+	for _, f := range fields {
+		field := Field{Path: prefix + f.name, Required: f.required}
+		if sibling, value, ok := condition(fields, f); ok {
+			field.When, field.WhenValue = prefix+sibling.name, value
+		}
+		if !field.Required && field.When == "" {
+			continue
+		}
+		res = append(res, field)
+		inner := f.typ
+		if inner.Kind() == reflect.Slice {
+			inner = inner.Elem()
+			field.Path += "[]"
+		}
+		if isStruct(inner) {
+			res = append(res, describe(inner, field.Path+".")...)
+		}
+	}
+	return res
+}
+
+// validate checks the params struct v against the required and conditional
+// fields of its type, and the values of its enums.
+func validate(v reflect.Value, prefix string) []Problem {
+	var res []Problem
+	fields := paramFields(v.Type())
+	for _, f := range fields {
+		path := prefix + f.name
+		field := v.Field(f.index)
+		value := field.FieldByName("Value")
+		present := field.FieldByName("Present").Bool() && !field.FieldByName("Null").Bool()
+		if present && value.Kind() == reflect.String && value.Len() == 0 && field.FieldByName("Raw").IsNil() {
+			present = false
+		}
+
+		required := f.required
+		if sibling, want, ok := condition(fields, f); ok {
+			s := v.Field(sibling.index)
+			got := s.FieldByName("Value").String()
+			switch {
+			case s.FieldByName("Present").Bool() && got == want:
+				required = true
+			case present:
+				res = append(res, Problem{path, fmt.Sprintf("is only allowed when %s is %s", prefix+sibling.name, want)})
+				continue
+			}
+		}
+		if !present {
+			if required {
+				res = append(res, Problem{path, "is required"})
+			}
+			continue
+		}
+
+		switch {
+		case value.Type().Implements(knowableType) && !isKnownValue(value.Type(), value.String()):
+			res = append(res, Problem{path, fmt.Sprintf("has an unknown value %q", value.String())})
+		case isStruct(value.Type()):
+			res = append(res, validate(value, path+".")...)
+		case value.Kind() == reflect.Slice && isStruct(value.Type().Elem()):
+			for i := 0; i < value.Len(); i++ {
+				res = append(res, validate(value.Index(i), fmt.Sprintf("%s[%d].", path, i))...)
+			}
+		}
+	}
+	return res
+}
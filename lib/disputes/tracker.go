@@ -0,0 +1,421 @@
+package disputes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNotCardDisputeEvent is returned for events about other objects.
+var ErrNotCardDisputeEvent = errors.New("disputes: not a card dispute event")
+
+// Party is who must act next on a dispute.
+type Party string
+
+const (
+	// You must submit, for example a chargeback or a pre-arbitration.
+	PartyUser Party = "user"
+	// Increase is reviewing or submitting your submission.
+	PartyIncrease Party = "increase"
+	// The network is waiting on the merchant's response.
+	PartyMerchant Party = "merchant"
+	// The dispute is closed.
+	PartyNone Party = "none"
+)
+
+// Outcome is how a dispute ended.
+type Outcome string
+
+const (
+	OutcomeWon       Outcome = "won"
+	OutcomeLost      Outcome = "lost"
+	OutcomeWithdrawn Outcome = "withdrawn"
+	OutcomeRejected  Outcome = "rejected"
+)
+
+// TimelineEntry is something that happened to a dispute.
+type TimelineEntry struct {
+	At time.Time
+	// The network event or user submission category, or the dispute status for
+	// the creation and the outcome of the dispute.
+	Category string
+	// Whether the entry is a network event, as opposed to a user submission or
+	// a change of the dispute itself.
+	Network bool
+	// The Transaction that moved funds, for network events that did and for
+	// the loss of a dispute.
+	TransactionID string
+}
+
+// Case is the tracked state of a dispute.
+type Case struct {
+	Dispute  increase.CardDispute
+	Timeline []TimelineEntry
+	// Who must act next, and by when. Due is zero when there is no deadline.
+	// Merchant deadlines are estimated from [Tracker.MerchantResponseWindow].
+	NextActor Party
+	Due       time.Time
+	// Set once the dispute has ended.
+	Outcome Outcome
+	// The `card_dispute_financial` and `card_dispute_loss` Transactions of the
+	// dispute, by ID.
+	Financials map[string]increase.Transaction
+
+	accountID string
+	alerted   map[time.Duration]time.Time
+	ended     bool
+}
+
+// clone returns a copy of the case that later updates do not change.
+func (c *Case) clone() *Case {
+	res := *c
+	res.Timeline = append([]TimelineEntry(nil), c.Timeline...)
+	res.Financials = make(map[string]increase.Transaction, len(c.Financials))
+	for id, tx := range c.Financials {
+		res.Financials[id] = tx
+	}
+	res.alerted = nil
+	return &res
+}
+
+// Loss returns the `card_dispute_loss` Transaction that debited the disputed
+// funds, or nil if there is none yet.
+func (c *Case) Loss() *increase.Transaction {
+	for _, tx := range c.Financials {
+		if tx.Source.Category == increase.TransactionSourceCategoryCardDisputeLoss {
+			return &tx
+		}
+	}
+	return nil
+}
+
+// NetAmount returns the sum of the dispute's financial and loss transactions:
+// positive when funds were returned to you.
+func (c *Case) NetAmount() int64 {
+	var res int64
+	for _, tx := range c.Financials {
+		res += tx.Amount
+	}
+	return res
+}
+
+// Tracker follows disputes through their network stages from
+// `card_dispute.created` and `card_dispute.updated` events and raises
+// callbacks for approaching deadlines and outcomes. It is safe for concurrent
+// use: the cases it returns and passes to callbacks are copies.
+type Tracker struct {
+	client *increase.Client
+	// Deadline alerts fire once per lead time as a deadline approaches.
+	// Defaults to 72 and 24 hours.
+	AlertBefore []time.Duration
+	// How long the merchant has to respond after a chargeback or a
+	// pre-arbitration, used to estimate merchant deadlines. Defaults to 30
+	// days.
+	MerchantResponseWindow time.Duration
+	// Called when a deadline is within one of the AlertBefore lead times.
+	OnDeadline func(c *Case, remaining time.Duration)
+	// Called once when a dispute ends.
+	OnOutcome func(c *Case)
+
+	mu    sync.Mutex
+	cases map[string]*Case
+	now   func() time.Time
+}
+
+// NewTracker returns a tracker that retrieves disputes with the client.
+func NewTracker(client *increase.Client) *Tracker {
+	return &Tracker{
+		client:                 client,
+		AlertBefore:            []time.Duration{72 * time.Hour, 24 * time.Hour},
+		MerchantResponseWindow: 30 * 24 * time.Hour,
+		cases:                  map[string]*Case{},
+		now:                    time.Now,
+	}
+}
+
+// Case returns the tracked case, or nil if the dispute has not been seen.
+func (t *Tracker) Case(cardDisputeID string) *Case {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.cases[cardDisputeID]
+	if !ok {
+		return nil
+	}
+	return c.clone()
+}
+
+// Cases returns the open cases, soonest deadline first. Cases without a
+// deadline come last.
+func (t *Tracker) Cases() []*Case {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := t.open()
+	for i, c := range res {
+		res[i] = c.clone()
+	}
+	return res
+}
+
+// open returns the tracked open cases, soonest deadline first. t.mu must be
+// held.
+func (t *Tracker) open() []*Case {
+	var res []*Case
+	for _, c := range t.cases {
+		if c.Outcome == "" {
+			res = append(res, c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].Due, res[j].Due
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return res[i].Dispute.ID < res[j].Dispute.ID
+	})
+	return res
+}
+
+// HandleEvent refreshes the dispute of a `card_dispute.created` or
+// `card_dispute.updated` event.
+func (t *Tracker) HandleEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*Case, error) {
+	if event.Category != increase.EventCategoryCardDisputeCreated && event.Category != increase.EventCategoryCardDisputeUpdated {
+		return nil, ErrNotCardDisputeEvent
+	}
+	return t.Refresh(ctx, event.AssociatedObjectID, opts...)
+}
+
+// Refresh retrieves a dispute and its financial and loss transactions,
+// updates its case, and raises the callbacks that are due.
+func (t *Tracker) Refresh(ctx context.Context, cardDisputeID string, opts ...option.RequestOption) (*Case, error) {
+	d, err := t.client.CardDisputes.Get(ctx, cardDisputeID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	c, ok := t.cases[d.ID]
+	if !ok {
+		c = &Case{Financials: map[string]increase.Transaction{}, alerted: map[time.Duration]time.Time{}}
+		t.cases[d.ID] = c
+	}
+	t.mu.Unlock()
+
+	if err := t.transactions(ctx, c, *d, opts); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.update(c, *d)
+	ended := c.Outcome != "" && !c.ended
+	c.ended = c.Outcome != ""
+	res := c.clone()
+	t.mu.Unlock()
+
+	if ended && t.OnOutcome != nil {
+		t.OnOutcome(res.clone())
+	}
+	t.alert(c)
+	return res, nil
+}
+
+// transactions lists the `card_dispute_financial` and `card_dispute_loss`
+// Transactions of the dispute's Account into its case, if any are missing.
+func (t *Tracker) transactions(ctx context.Context, c *Case, d increase.CardDispute, opts []option.RequestOption) error {
+	t.mu.Lock()
+	financials := map[string]bool{}
+	missing := d.Status == increase.CardDisputeStatusLost && c.Loss() == nil
+	for _, e := range d.Visa.NetworkEvents {
+		if id := e.DisputeFinancialTransactionID; id != "" {
+			financials[id] = true
+			if _, seen := c.Financials[id]; !seen {
+				missing = true
+			}
+		}
+	}
+	accountID := c.accountID
+	t.mu.Unlock()
+	if !missing {
+		return nil
+	}
+
+	if accountID == "" {
+		card, err := t.client.Cards.Get(ctx, d.CardID, opts...)
+		if err != nil {
+			return fmt.Errorf("disputes: retrieving card %s: %w", d.CardID, err)
+		}
+		accountID = card.AccountID
+	}
+	iter := t.client.Transactions.ListAutoPaging(ctx, increase.TransactionListParams{
+		AccountID: increase.F(accountID),
+		Category: increase.F(increase.TransactionListParamsCategory{In: increase.F([]increase.TransactionListParamsCategoryIn{
+			increase.TransactionListParamsCategoryInCardDisputeFinancial,
+			increase.TransactionListParamsCategoryInCardDisputeLoss,
+		})}),
+		CreatedAt: increase.F(increase.TransactionListParamsCreatedAt{OnOrAfter: increase.F(d.CreatedAt)}),
+	}, opts...)
+	found := map[string]increase.Transaction{}
+	for iter.Next() {
+		if tx := iter.Current(); belongs(tx, d, financials) {
+			found[tx.ID] = tx
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("disputes: listing transactions of account %s: %w", accountID, err)
+	}
+
+	t.mu.Lock()
+	c.accountID = accountID
+	for id, tx := range found {
+		c.Financials[id] = tx
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// belongs reports whether a `card_dispute_financial` or `card_dispute_loss`
+// Transaction is of the dispute. Financial Transactions are named by the
+// dispute's network events; the loss is matched by the time the dispute was
+// lost and by its Card.
+func belongs(tx increase.Transaction, d increase.CardDispute, financials map[string]bool) bool {
+	switch tx.Source.Category {
+	case increase.TransactionSourceCategoryCardDisputeFinancial:
+		return financials[tx.ID]
+	case increase.TransactionSourceCategoryCardDisputeLoss:
+		lostAt := tx.Source.CardDisputeLoss.LostAt
+		return d.Status == increase.CardDisputeStatusLost && !lostAt.IsZero() && lostAt.Equal(d.Loss.LostAt) &&
+			(tx.RouteID == "" || tx.RouteID == d.CardID)
+	}
+	return false
+}
+
+func (t *Tracker) update(c *Case, d increase.CardDispute) {
+	c.Dispute = d
+	c.Timeline = Timeline(d)
+	if loss := c.Loss(); loss != nil {
+		for i, e := range c.Timeline {
+			if e.Category == string(increase.CardDisputeStatusLost) {
+				c.Timeline[i].TransactionID = loss.ID
+			}
+		}
+	}
+	c.NextActor, c.Due = t.nextAction(d)
+	c.Outcome = outcome(d)
+}
+
+// Timeline returns the network events, user submissions, creation and outcome
+// of a dispute in chronological order.
+func Timeline(d increase.CardDispute) []TimelineEntry {
+	res := []TimelineEntry{{At: d.CreatedAt, Category: "created"}}
+	for _, e := range d.Visa.NetworkEvents {
+		res = append(res, TimelineEntry{At: e.CreatedAt, Category: string(e.Category), Network: true, TransactionID: e.DisputeFinancialTransactionID})
+	}
+	for _, s := range d.Visa.UserSubmissions {
+		res = append(res, TimelineEntry{At: s.CreatedAt, Category: string(s.Category)})
+	}
+	switch {
+	case !d.Win.WonAt.IsZero():
+		res = append(res, TimelineEntry{At: d.Win.WonAt, Category: string(increase.CardDisputeStatusWon)})
+	case !d.Loss.LostAt.IsZero():
+		res = append(res, TimelineEntry{At: d.Loss.LostAt, Category: string(increase.CardDisputeStatusLost)})
+	case !d.Rejection.RejectedAt.IsZero():
+		res = append(res, TimelineEntry{At: d.Rejection.RejectedAt, Category: string(increase.CardDisputeStatusRejected)})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].At.Before(res[j].At) })
+	return res
+}
+
+func outcome(d increase.CardDispute) Outcome {
+	switch d.Status {
+	case increase.CardDisputeStatusWon:
+		return OutcomeWon
+	case increase.CardDisputeStatusLost:
+		if d.Loss.Reason == increase.CardDisputeLossReasonUserWithdrawn {
+			return OutcomeWithdrawn
+		}
+		return OutcomeLost
+	case increase.CardDisputeStatusRejected:
+		return OutcomeRejected
+	}
+	return ""
+}
+
+// nextAction returns who must act next on a dispute, and by when.
+func (t *Tracker) nextAction(d increase.CardDispute) (Party, time.Time) {
+	switch d.Status {
+	case increase.CardDisputeStatusUserSubmissionRequired:
+		return PartyUser, d.UserSubmissionRequiredBy
+	case increase.CardDisputeStatusPendingUserSubmissionReviewing,
+		increase.CardDisputeStatusPendingUserSubmissionSubmitting,
+		increase.CardDisputeStatusPendingUserWithdrawalSubmitting:
+		return PartyIncrease, time.Time{}
+	case increase.CardDisputeStatusPendingResponse:
+		var last time.Time
+		for _, e := range d.Visa.NetworkEvents {
+			switch e.Category {
+			case increase.CardDisputeVisaNetworkEventsCategoryChargebackSubmitted,
+				increase.CardDisputeVisaNetworkEventsCategoryUserPrearbitrationSubmitted:
+				if e.CreatedAt.After(last) {
+					last = e.CreatedAt
+				}
+			}
+		}
+		if last.IsZero() || t.MerchantResponseWindow <= 0 {
+			return PartyMerchant, time.Time{}
+		}
+		return PartyMerchant, last.Add(t.MerchantResponseWindow)
+	}
+	return PartyNone, time.Time{}
+}
+
+// CheckDeadlines raises deadline alerts for every open case. Call it
+// periodically, since deadlines approach without any event being sent.
+func (t *Tracker) CheckDeadlines() {
+	t.mu.Lock()
+	open := t.open()
+	t.mu.Unlock()
+	for _, c := range open {
+		t.alert(c)
+	}
+}
+
+// alert raises the deadline alerts of a case that are due and have not been
+// raised for its current deadline.
+func (t *Tracker) alert(c *Case) {
+	if t.OnDeadline == nil {
+		return
+	}
+	t.mu.Lock()
+	due, party := c.Due, c.NextActor
+	remaining := due.Sub(t.now())
+	// A copy of the case if an alert is due.
+	var res *Case
+	// Only the shortest lead time that has been reached is raised.
+	lead := time.Duration(-1)
+	for _, before := range t.AlertBefore {
+		if remaining <= before && (lead < 0 || before < lead) {
+			lead = before
+		}
+	}
+	if !due.IsZero() && party != PartyNone && lead >= 0 && !c.alerted[lead].Equal(due) {
+		for _, before := range t.AlertBefore {
+			if before >= lead {
+				c.alerted[before] = due
+			}
+		}
+		res = c.clone()
+	}
+	t.mu.Unlock()
+
+	if res != nil {
+		t.OnDeadline(res, remaining)
+	}
+}
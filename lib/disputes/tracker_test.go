@@ -0,0 +1,170 @@
+package disputes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/disputes"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+func newDisputeServer(t *testing.T, dispute map[string]any, transactions ...map[string]any) (*sync.Mutex, *increase.Client) {
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /card_disputes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(dispute)
+	})
+	mux.HandleFunc("GET /cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"id": r.PathValue("id"), "account_id": "account_1"})
+	})
+	mux.HandleFunc("GET /transactions", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("account_id") != "account_1" || q.Get("category.in") != "card_dispute_financial,card_dispute_loss" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": transactions, "next_cursor": nil})
+	})
+	return &mu, testapi.NewClient(t, mux)
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	created := now.Add(-10 * 24 * time.Hour)
+	dispute := map[string]any{
+		"id":                          "card_dispute_1",
+		"card_id":                     "card_1",
+		"status":                      "user_submission_required",
+		"created_at":                  created,
+		"user_submission_required_by": now.Add(48 * time.Hour),
+		"visa":                        map[string]any{"network_events": []any{}, "user_submissions": []any{}},
+	}
+	mu, client := newDisputeServer(t, dispute,
+		map[string]any{"id": "transaction_credit", "amount": 5000, "source": map[string]any{"category": "card_dispute_financial"}},
+		map[string]any{"id": "transaction_other", "amount": 700, "source": map[string]any{"category": "card_dispute_financial"}},
+	)
+
+	tracker := disputes.NewTracker(client)
+	var alerts []time.Duration
+	var outcomes []disputes.Outcome
+	tracker.OnDeadline = func(c *disputes.Case, remaining time.Duration) { alerts = append(alerts, remaining) }
+	tracker.OnOutcome = func(c *disputes.Case) { outcomes = append(outcomes, c.Outcome) }
+
+	event := increase.Event{Category: increase.EventCategoryCardDisputeUpdated, AssociatedObjectID: "card_dispute_1"}
+	c, err := tracker.HandleEvent(ctx, event)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if c.NextActor != disputes.PartyUser || c.Due.IsZero() {
+		t.Errorf("expected the user to act next, got %s by %s", c.NextActor, c.Due)
+	}
+	first := c
+	tracker.CheckDeadlines()
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert within 72 hours, got %v", alerts)
+	}
+
+	mu.Lock()
+	dispute["user_submission_required_by"] = now.Add(12 * time.Hour)
+	mu.Unlock()
+	if _, err := tracker.Refresh(ctx, "card_dispute_1"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(alerts) != 2 || alerts[1] > 24*time.Hour {
+		t.Fatalf("expected a second alert within 24 hours, got %v", alerts)
+	}
+
+	submitted := now.Add(-time.Hour)
+	mu.Lock()
+	dispute["status"] = "pending_response"
+	dispute["user_submission_required_by"] = nil
+	dispute["visa"] = map[string]any{
+		"network_events":   []any{map[string]any{"category": "chargeback_submitted", "created_at": submitted, "dispute_financial_transaction_id": "transaction_credit"}},
+		"user_submissions": []any{map[string]any{"category": "chargeback", "created_at": submitted.Add(-time.Hour)}},
+	}
+	mu.Unlock()
+	if c, err = tracker.Refresh(ctx, "card_dispute_1"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if c.NextActor != disputes.PartyMerchant || !c.Due.Equal(submitted.Add(30*24*time.Hour)) {
+		t.Errorf("expected the merchant to respond within 30 days, got %s by %s", c.NextActor, c.Due)
+	}
+	if len(tracker.Cases()) != 1 {
+		t.Errorf("expected one open case")
+	}
+	// Cases are copies that later updates leave alone.
+	if first.NextActor != disputes.PartyUser || len(first.Timeline) != 1 || len(first.Financials) != 0 {
+		t.Errorf("expected the first case to be unchanged, got %+v", first)
+	}
+
+	mu.Lock()
+	dispute["status"] = "won"
+	dispute["win"] = map[string]any{"won_at": now}
+	mu.Unlock()
+	for i := 0; i < 2; i++ {
+		if c, err = tracker.Refresh(ctx, "card_dispute_1"); err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+	}
+	if len(outcomes) != 1 || outcomes[0] != disputes.OutcomeWon || c.NextActor != disputes.PartyNone {
+		t.Errorf("expected a single won outcome, got %v", outcomes)
+	}
+	if c.NetAmount() != 5000 {
+		t.Errorf("expected the financial transaction to be tracked, got %d", c.NetAmount())
+	}
+	var categories []string
+	for _, e := range c.Timeline {
+		categories = append(categories, e.Category)
+	}
+	want := []string{"created", "chargeback", "chargeback_submitted", "won"}
+	if len(categories) != len(want) {
+		t.Fatalf("unexpected timeline %v", categories)
+	}
+	for i := range want {
+		if categories[i] != want[i] {
+			t.Errorf("unexpected timeline %v", categories)
+			break
+		}
+	}
+	if len(tracker.Cases()) != 0 {
+		t.Errorf("won cases should not be open")
+	}
+
+	if _, err := tracker.HandleEvent(ctx, increase.Event{Category: increase.EventCategoryCardCreated}); !errors.Is(err, disputes.ErrNotCardDisputeEvent) {
+		t.Errorf("expected ErrNotCardDisputeEvent, got %v", err)
+	}
+}
+
+func TestTrackerLoss(t *testing.T) {
+	lostAt := time.Now().UTC().Truncate(time.Second)
+	dispute := map[string]any{
+		"id":         "card_dispute_1",
+		"card_id":    "card_1",
+		"status":     "lost",
+		"created_at": lostAt.Add(-40 * 24 * time.Hour),
+		"loss":       map[string]any{"lost_at": lostAt, "reason": "lost"},
+		"visa":       map[string]any{"network_events": []any{}, "user_submissions": []any{}},
+	}
+	_, client := newDisputeServer(t, dispute,
+		map[string]any{"id": "transaction_loss", "amount": -5000, "route_id": "card_1", "source": map[string]any{"category": "card_dispute_loss", "card_dispute_loss": map[string]any{"lost_at": lostAt}}},
+		map[string]any{"id": "transaction_other_loss", "amount": -900, "route_id": "card_2", "source": map[string]any{"category": "card_dispute_loss", "card_dispute_loss": map[string]any{"lost_at": lostAt}}},
+	)
+
+	c, err := disputes.NewTracker(client).Refresh(context.Background(), "card_dispute_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if loss := c.Loss(); loss == nil || loss.ID != "transaction_loss" || c.NetAmount() != -5000 || c.Outcome != disputes.OutcomeLost {
+		t.Fatalf("expected the loss transaction to be tracked, got %+v", c.Financials)
+	}
+	if last := c.Timeline[len(c.Timeline)-1]; last.Category != "lost" || last.TransactionID != "transaction_loss" {
+		t.Errorf("expected the loss in the timeline, got %+v", last)
+	}
+}
@@ -0,0 +1,289 @@
+// Package fulfillment orders Physical Cards in bulk and tracks their
+// shipments.
+//
+// Orders are read from a CSV of cardholders with [ParseOrders], which
+// validates their shipping addresses, and placed with [Fulfillment.Order].
+// The shipments are then followed from `physical_card.created` and
+// `physical_card.updated` events, and [Fulfillment.Report] lists the ones that
+// are stuck or were returned.
+//
+//	orders, err := fulfillment.ParseOrders(file)
+//	if err != nil {
+//		return err // every invalid line is reported
+//	}
+//	f := fulfillment.New(client)
+//	results, err := f.Order(ctx, orders)
+package fulfillment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNotPhysicalCardEvent is returned for events about other objects.
+var ErrNotPhysicalCardEvent = errors.New("fulfillment: not a physical card event")
+
+// Result is the outcome of an order.
+type Result struct {
+	Order        Order
+	PhysicalCard *increase.PhysicalCard
+	// The Physical Card created by an earlier attempt with the same
+	// idempotency key.
+	ExistingPhysicalCardID string
+	Err                    error
+}
+
+// Shipment is the tracked state of a Physical Card's shipment.
+type Shipment struct {
+	PhysicalCard increase.PhysicalCard
+	// When the shipment was last seen to progress: a change of status or a
+	// new tracking update. Shipments first seen already under way start from
+	// their latest tracking update, or else from when the card was created.
+	Since time.Time
+	// The statuses the shipment has been seen in, oldest first.
+	History []StatusChange
+}
+
+// StatusChange is a change of a shipment's status.
+type StatusChange struct {
+	Status increase.PhysicalCardShipmentStatus
+	At     time.Time
+}
+
+// clone returns a copy of the shipment that later tracking does not change.
+func (s *Shipment) clone() *Shipment {
+	res := *s
+	res.History = append([]StatusChange(nil), s.History...)
+	return &res
+}
+
+// Status returns the status of the shipment.
+func (s *Shipment) Status() increase.PhysicalCardShipmentStatus {
+	return s.PhysicalCard.Shipment.Status
+}
+
+// LastUpdate returns the latest tracking update from the carrier, if any.
+func (s *Shipment) LastUpdate() (increase.PhysicalCardShipmentTrackingUpdate, bool) {
+	updates := s.PhysicalCard.Shipment.Tracking.Updates
+	if len(updates) == 0 {
+		return increase.PhysicalCardShipmentTrackingUpdate{}, false
+	}
+	last := updates[0]
+	for _, u := range updates[1:] {
+		if u.CreatedAt.After(last.CreatedAt) {
+			last = u
+		}
+	}
+	return last, true
+}
+
+// Delivered reports whether the carrier has delivered the card.
+func (s *Shipment) Delivered() bool {
+	u, ok := s.LastUpdate()
+	return ok && u.Category == increase.PhysicalCardShipmentTrackingUpdatesCategoryDelivered
+}
+
+// Returned reports whether the card was, or is being, returned to the sender.
+func (s *Shipment) Returned() bool {
+	if s.Status() == increase.PhysicalCardShipmentStatusReturned {
+		return true
+	}
+	u, ok := s.LastUpdate()
+	return ok && (u.Category == increase.PhysicalCardShipmentTrackingUpdatesCategoryReturningToSender ||
+		u.Category == increase.PhysicalCardShipmentTrackingUpdatesCategoryReturnedToSender)
+}
+
+// Fulfillment orders Physical Cards and tracks their shipments. It is safe for
+// concurrent use: the shipments it returns and passes to OnChange are copies.
+type Fulfillment struct {
+	client *increase.Client
+	// The Physical Card Profile of orders that do not name one. When empty,
+	// the default profile of the program is used.
+	DefaultPhysicalCardProfileID string
+	// A shipment that has not progressed for this long, and has been neither
+	// delivered nor returned, is stuck. Defaults to 7 days.
+	StuckAfter time.Duration
+	// Called when a shipment is first seen or its status changes. prev is
+	// empty for new shipments.
+	OnChange func(s *Shipment, prev increase.PhysicalCardShipmentStatus)
+
+	mu        sync.Mutex
+	shipments map[string]*Shipment
+	now       func() time.Time
+}
+
+// New returns a Fulfillment that orders and retrieves Physical Cards with the
+// client.
+func New(client *increase.Client) *Fulfillment {
+	return &Fulfillment{
+		client:     client,
+		StuckAfter: 7 * 24 * time.Hour,
+		shipments:  map[string]*Shipment{},
+		now:        time.Now,
+	}
+}
+
+// Order creates a Physical Card for every valid order and tracks its
+// shipment. Invalid orders are not sent. Every order is attempted; the
+// returned error joins the errors of the orders that failed.
+func (f *Fulfillment) Order(ctx context.Context, orders []Order, opts ...option.RequestOption) ([]Result, error) {
+	results := make([]Result, 0, len(orders))
+	var errs []error
+	for _, o := range orders {
+		res := Result{Order: o}
+		if res.Err = o.Validate(); res.Err == nil {
+			orderOpts := append(append([]option.RequestOption{}, opts...), option.WithHeader("Idempotency-Key", o.idempotencyKey()))
+			res.PhysicalCard, res.Err = f.client.PhysicalCards.New(ctx, o.params(f.DefaultPhysicalCardProfileID), orderOpts...)
+		}
+		var apiErr *increase.Error
+		if errors.As(res.Err, &apiErr) && apiErr.Type == increase.ErrorTypeIdempotencyKeyAlreadyUsedError {
+			res.ExistingPhysicalCardID, res.Err = apiErr.ResourceID, nil
+		}
+		if res.PhysicalCard != nil {
+			f.Track(*res.PhysicalCard)
+		}
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("fulfillment: card %s: %w", o.CardID, res.Err))
+		}
+		results = append(results, res)
+	}
+	return results, errors.Join(errs...)
+}
+
+// Shipment returns the tracked shipment of a Physical Card, or nil if it has
+// not been seen.
+func (f *Fulfillment) Shipment(physicalCardID string) *Shipment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.shipments[physicalCardID]
+	if !ok {
+		return nil
+	}
+	return s.clone()
+}
+
+// HandleEvent refreshes the Physical Card of a `physical_card.created` or
+// `physical_card.updated` event.
+func (f *Fulfillment) HandleEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*Shipment, error) {
+	if event.Category != increase.EventCategoryPhysicalCardCreated && event.Category != increase.EventCategoryPhysicalCardUpdated {
+		return nil, ErrNotPhysicalCardEvent
+	}
+	return f.Refresh(ctx, event.AssociatedObjectID, opts...)
+}
+
+// Refresh retrieves a Physical Card and tracks its shipment.
+func (f *Fulfillment) Refresh(ctx context.Context, physicalCardID string, opts ...option.RequestOption) (*Shipment, error) {
+	card, err := f.client.PhysicalCards.Get(ctx, physicalCardID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return f.Track(*card), nil
+}
+
+// Track records the current state of a Physical Card's shipment.
+func (f *Fulfillment) Track(card increase.PhysicalCard) *Shipment {
+	f.mu.Lock()
+	now := f.now()
+	s, ok := f.shipments[card.ID]
+	var prev increase.PhysicalCardShipmentStatus
+	if ok {
+		prev = s.Status()
+	} else {
+		s = &Shipment{}
+		f.shipments[card.ID] = s
+	}
+	status := card.Shipment.Status
+	var lastUpdate time.Time
+	if u, ok := s.LastUpdate(); ok {
+		lastUpdate = u.CreatedAt
+	}
+	s.PhysicalCard = card
+	changed := !ok || status != prev
+	switch {
+	case !ok:
+		// The process may have restarted since the shipment progressed, so its
+		// progress is read from the card rather than from now.
+		s.Since = card.CreatedAt
+		if u, ok := s.LastUpdate(); ok && u.CreatedAt.After(s.Since) {
+			s.Since = u.CreatedAt
+		}
+		if s.Since.IsZero() || s.Since.After(now) {
+			s.Since = now
+		}
+		s.History = append(s.History, StatusChange{Status: status, At: now})
+	case changed:
+		s.Since = now
+		s.History = append(s.History, StatusChange{Status: status, At: now})
+	default:
+		if u, ok := s.LastUpdate(); ok && u.CreatedAt.After(lastUpdate) {
+			s.Since = now
+		}
+	}
+	res := s.clone()
+	f.mu.Unlock()
+
+	if changed && f.OnChange != nil {
+		f.OnChange(res.clone(), prev)
+	}
+	return res
+}
+
+// Report lists the shipments that need attention.
+type Report struct {
+	// Shipments that have not progressed within StuckAfter.
+	Stuck []*Shipment
+	// Shipments returned, or being returned, to the sender.
+	Returned []*Shipment
+	// Shipments that were rejected or require attention before they can be
+	// shipped.
+	RequiresAttention []*Shipment
+}
+
+// Report returns the shipments that are stuck, returned, or require attention,
+// oldest first.
+func (f *Fulfillment) Report() Report {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	var res Report
+	for _, s := range f.shipments {
+		switch status := s.Status(); {
+		case s.Returned():
+			res.Returned = append(res.Returned, s.clone())
+		case status == increase.PhysicalCardShipmentStatusRejected || status == increase.PhysicalCardShipmentStatusRequiresAttention:
+			res.RequiresAttention = append(res.RequiresAttention, s.clone())
+		case status == increase.PhysicalCardShipmentStatusCanceled || s.Delivered():
+		case f.StuckAfter > 0 && now.Sub(s.Since) >= f.StuckAfter:
+			res.Stuck = append(res.Stuck, s.clone())
+		}
+	}
+	for _, list := range [][]*Shipment{res.Stuck, res.Returned, res.RequiresAttention} {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].Since.Equal(list[j].Since) {
+				return list[i].Since.Before(list[j].Since)
+			}
+			return list[i].PhysicalCard.ID < list[j].PhysicalCard.ID
+		})
+	}
+	return res
+}
+
+// Advance moves a Physical Card's shipment to a status in the sandbox with
+// [increase.SimulationPhysicalCardService.AdvanceShipment], and tracks the
+// result.
+func (f *Fulfillment) Advance(ctx context.Context, physicalCardID string, status increase.SimulationPhysicalCardAdvanceShipmentParamsShipmentStatus, opts ...option.RequestOption) (*Shipment, error) {
+	card, err := f.client.Simulations.PhysicalCards.AdvanceShipment(ctx, physicalCardID, increase.SimulationPhysicalCardAdvanceShipmentParams{
+		ShipmentStatus: increase.F(status),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return f.Track(*card), nil
+}
@@ -0,0 +1,212 @@
+package fulfillment_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/fulfillment"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+)
+
+type fakeAPI struct {
+	mu      sync.Mutex
+	cards   map[string]map[string]any
+	created []map[string]any
+	keys    []string
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{cards: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /physical_cards", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		key := r.Header.Get("Idempotency-Key")
+		for _, k := range api.keys {
+			if k == key {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]any{"status": 409, "type": "idempotency_key_already_used_error", "resource_id": "physical_card_" + body["card_id"].(string)})
+				return
+			}
+		}
+		api.created = append(api.created, body)
+		api.keys = append(api.keys, key)
+		card := map[string]any{
+			"id":       "physical_card_" + body["card_id"].(string),
+			"card_id":  body["card_id"],
+			"shipment": map[string]any{"status": "pending", "tracking": nil},
+		}
+		api.cards[card["id"].(string)] = card
+		json.NewEncoder(w).Encode(card)
+	})
+	mux.HandleFunc("GET /physical_cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(api.cards[r.PathValue("id")])
+	})
+	mux.HandleFunc("POST /simulations/physical_cards/{id}/advance_shipment", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		card := api.cards[r.PathValue("id")]
+		shipment := map[string]any{"status": body["shipment_status"], "tracking": nil}
+		switch body["shipment_status"] {
+		case "shipped":
+			shipment["tracking"] = map[string]any{"number": "9400", "updates": []any{
+				map[string]any{"category": "delivered", "created_at": time.Now()},
+			}}
+		case "returned":
+			shipment["tracking"] = map[string]any{"number": "9401", "return_reason": "Address not found", "updates": []any{
+				map[string]any{"category": "returned_to_sender", "created_at": time.Now()},
+			}}
+		}
+		card["shipment"] = shipment
+		json.NewEncoder(w).Encode(card)
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+const cardholders = `card_id,first_name,last_name,line1,line2,city,state,postal_code,method
+card_1,Ian,Crease,33 Liberty Street,,New York,NY,10045,
+card_2,Ada,Lovelace,PO Box 12,,Chicago,IL,60601,fedex_2_day
+card_3,Grace,Hopper,1 Main St,,Springfield,ZZ,1234,
+card_4,Alan,Turing,2 Main St,Apt 4,Boston,ma,02110,usps
+card_1,Ian,Crease,33 Liberty Street,,New York,NY,10045,
+`
+
+func TestParseOrders(t *testing.T) {
+	orders, err := fulfillment.ParseOrders(strings.NewReader(cardholders))
+	if len(orders) != 5 {
+		t.Fatalf("expected every order to be returned, got %d", len(orders))
+	}
+	var lines []int
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var lerr *fulfillment.LineError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("expected a line error, got %v", err)
+		}
+		lines = append(lines, lerr.Line)
+	}
+	if len(lines) != 3 || lines[0] != 3 || lines[1] != 4 || lines[2] != 6 {
+		t.Errorf("expected lines 3, 4 and 6 to be invalid, got %v: %s", lines, err)
+	}
+	for _, want := range []string{"fedex_2_day cannot deliver to a PO box", `state "ZZ"`, `postal_code "1234"`, "already ordered on line 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %s", want, err)
+		}
+	}
+	if orders[0].Address.Name != "Ian Crease" || orders[0].Method != increase.PhysicalCardNewParamsShipmentMethodUsps {
+		t.Errorf("expected defaults to be applied, got %+v", orders[0])
+	}
+
+	if _, err := fulfillment.ParseOrders(strings.NewReader("card_id,first_name\n")); err == nil {
+		t.Errorf("expected an error for missing columns")
+	}
+}
+
+func TestFulfillment(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	orders, _ := fulfillment.ParseOrders(strings.NewReader(cardholders))
+
+	f := fulfillment.New(client)
+	f.DefaultPhysicalCardProfileID = "physical_card_profile_1"
+	var changes []string
+	f.OnChange = func(s *fulfillment.Shipment, prev increase.PhysicalCardShipmentStatus) {
+		changes = append(changes, string(prev)+">"+string(s.Status()))
+	}
+	results, err := f.Order(ctx, orders)
+	if err == nil || len(results) != 5 {
+		t.Fatalf("expected the invalid orders to fail, got %d results and %v", len(results), err)
+	}
+	if len(api.created) != 2 || !strings.HasPrefix(api.keys[0], "fulfillment-card_1-") || api.created[0]["physical_card_profile_id"] != "physical_card_profile_1" {
+		t.Fatalf("expected only the valid orders to be sent, got %+v %v", api.created, api.keys)
+	}
+	if results[4].Err != nil || results[4].ExistingPhysicalCardID != "physical_card_card_1" {
+		t.Errorf("expected the repeated order to find the existing card, got %+v", results[4])
+	}
+	// Reshipping to another address is a new order.
+	reship := orders[0]
+	reship.Address.Line2 = "Floor 2"
+	if results, err := f.Order(ctx, []fulfillment.Order{reship}); err != nil || results[0].PhysicalCard == nil || len(api.created) != 3 {
+		t.Errorf("expected the reship to be ordered, got %+v, %v", results, err)
+	}
+	if address := api.created[1]["shipment"].(map[string]any)["address"].(map[string]any); address["state"] != "MA" || address["line2"] != "Apt 4" {
+		t.Errorf("unexpected address %+v", address)
+	}
+
+	if _, err := f.Advance(ctx, "physical_card_card_1", increase.SimulationPhysicalCardAdvanceShipmentParamsShipmentStatusShipped); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if _, err := f.Advance(ctx, "physical_card_card_4", increase.SimulationPhysicalCardAdvanceShipmentParamsShipmentStatusReturned); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	s, err := f.HandleEvent(ctx, increase.Event{Category: increase.EventCategoryPhysicalCardUpdated, AssociatedObjectID: "physical_card_card_1"})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !s.Delivered() || len(s.History) != 2 {
+		t.Errorf("expected the card to be delivered, got %+v", s)
+	}
+	if strings.Join(changes, " ") != ">pending >pending pending>shipped pending>returned" {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	report := f.Report()
+	if len(report.Returned) != 1 || report.Returned[0].PhysicalCard.Shipment.Tracking.ReturnReason != "Address not found" {
+		t.Errorf("expected the returned card to be reported, got %+v", report.Returned)
+	}
+	if len(report.Stuck) != 0 {
+		t.Errorf("expected no stuck shipments, got %+v", report.Stuck)
+	}
+
+	f.StuckAfter = time.Nanosecond
+	api.mu.Lock()
+	api.cards["physical_card_card_1"]["shipment"] = map[string]any{"status": "submitted"}
+	api.mu.Unlock()
+	f.Refresh(ctx, "physical_card_card_1")
+	time.Sleep(time.Millisecond)
+	if report := f.Report(); len(report.Stuck) != 1 || report.Stuck[0].PhysicalCard.ID != "physical_card_card_1" {
+		t.Errorf("expected the submitted card to be stuck, got %+v", report.Stuck)
+	}
+	// Shipments are copies that later tracking leaves alone.
+	if !s.Delivered() || len(s.History) != 2 {
+		t.Errorf("expected the earlier shipment to be unchanged, got %+v", s)
+	}
+
+	if _, err := f.HandleEvent(ctx, increase.Event{Category: increase.EventCategoryCardCreated}); !errors.Is(err, fulfillment.ErrNotPhysicalCardEvent) {
+		t.Errorf("expected ErrNotPhysicalCardEvent, got %v", err)
+	}
+}
+
+func TestTrackAfterRestart(t *testing.T) {
+	var card increase.PhysicalCard
+	created := time.Now().Add(-10 * 24 * time.Hour)
+	body, _ := json.Marshal(map[string]any{"id": "physical_card_1", "created_at": created, "shipment": map[string]any{"status": "shipped", "tracking": map[string]any{"updates": []any{
+		map[string]any{"category": "in_transit", "created_at": created.Add(24 * time.Hour)},
+	}}}})
+	if err := json.Unmarshal(body, &card); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	// A process that starts tracking a shipment under way picks up where it
+	// last progressed.
+	f := fulfillment.New(nil)
+	s := f.Track(card)
+	if !s.Since.Equal(created.Add(24 * time.Hour)) {
+		t.Errorf("expected the shipment to date from its latest update, got %s", s.Since)
+	}
+	if report := f.Report(); len(report.Stuck) != 1 {
+		t.Errorf("expected the shipment to be stuck, got %+v", report)
+	}
+}
@@ -0,0 +1,286 @@
+package fulfillment
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Increase/increase-go"
+)
+
+// Address is where a Physical Card is shipped.
+type Address struct {
+	Name        string
+	Line1       string
+	Line2       string
+	Line3       string
+	City        string
+	State       string
+	PostalCode  string
+	Country     string
+	PhoneNumber string
+}
+
+var (
+	usPostalCode = regexp.MustCompile(`^\d{5}(-?\d{4})?$`)
+	poBox        = regexp.MustCompile(`(?i)\bp\.?\s*o\.?\s*box\b|\bpost office box\b`)
+	usStates     = []string{
+		"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "DC", "FL", "GA", "HI", "ID", "IL", "IN", "IA",
+		"KS", "KY", "LA", "ME", "MD", "MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ", "NM",
+		"NY", "NC", "ND", "OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VT", "VA", "WA",
+		"WV", "WI", "WY", "AS", "GU", "MP", "PR", "VI", "AA", "AE", "AP",
+	}
+)
+
+// Validate checks that the address can be shipped to with the method. Every
+// problem is reported.
+func (a Address) Validate(method increase.PhysicalCardNewParamsShipmentMethod) error {
+	var errs []error
+	for _, f := range []struct{ name, value string }{
+		{"name", a.Name}, {"line1", a.Line1}, {"city", a.City}, {"state", a.State}, {"postal_code", a.PostalCode},
+	} {
+		if strings.TrimSpace(f.value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", f.name))
+		}
+	}
+	country := strings.ToUpper(a.Country)
+	if country != "" && len(country) != 2 {
+		errs = append(errs, fmt.Errorf("country %q must be a two-letter ISO 3166 code", a.Country))
+	}
+	if country == "" || country == "US" {
+		if a.State != "" && !slices.Contains(usStates, strings.ToUpper(a.State)) {
+			errs = append(errs, fmt.Errorf("state %q is not a US state code", a.State))
+		}
+		if a.PostalCode != "" && !usPostalCode.MatchString(a.PostalCode) {
+			errs = append(errs, fmt.Errorf("postal_code %q is not a US ZIP code", a.PostalCode))
+		}
+	}
+	if method != increase.PhysicalCardNewParamsShipmentMethodUsps {
+		for _, line := range []string{a.Line1, a.Line2, a.Line3} {
+			if poBox.MatchString(line) {
+				errs = append(errs, fmt.Errorf("%s cannot deliver to a PO box", method))
+				break
+			}
+		}
+	}
+	if method == increase.PhysicalCardNewParamsShipmentMethodDhlWorldwideExpress && a.PhoneNumber == "" {
+		errs = append(errs, errors.New("phone_number is required for dhl_worldwide_express"))
+	}
+	return errors.Join(errs...)
+}
+
+func (a Address) params() increase.PhysicalCardNewParamsShipmentAddress {
+	res := increase.PhysicalCardNewParamsShipmentAddress{
+		Name:       increase.F(a.Name),
+		Line1:      increase.F(a.Line1),
+		City:       increase.F(a.City),
+		State:      increase.F(strings.ToUpper(a.State)),
+		PostalCode: increase.F(a.PostalCode),
+	}
+	if a.Line2 != "" {
+		res.Line2 = increase.F(a.Line2)
+	}
+	if a.Line3 != "" {
+		res.Line3 = increase.F(a.Line3)
+	}
+	if a.Country != "" {
+		res.Country = increase.F(strings.ToUpper(a.Country))
+	}
+	if a.PhoneNumber != "" {
+		res.PhoneNumber = increase.F(a.PhoneNumber)
+	}
+	return res
+}
+
+// Order is a Physical Card to create for a Card.
+type Order struct {
+	// The line of the CSV the order was read from, or zero.
+	Line                  int
+	CardID                string
+	FirstName             string
+	LastName              string
+	Address               Address
+	Method                increase.PhysicalCardNewParamsShipmentMethod
+	Schedule              increase.PhysicalCardNewParamsShipmentSchedule
+	PhysicalCardProfileID string
+	// Defaults to one derived from the Card and the details of the order, so
+	// that an order is placed once however many times the CSV is processed.
+	// Reorders with the same details, such as to reship a returned or lost
+	// card, need a key of their own.
+	IdempotencyKey string
+}
+
+// Validate checks the order and its address.
+func (o Order) Validate() error {
+	var errs []error
+	if o.CardID == "" {
+		errs = append(errs, errors.New("card_id is required"))
+	}
+	if o.FirstName == "" {
+		errs = append(errs, errors.New("first_name is required"))
+	}
+	if o.LastName == "" {
+		errs = append(errs, errors.New("last_name is required"))
+	}
+	if !o.Method.IsKnown() {
+		errs = append(errs, fmt.Errorf("unknown method %q", o.Method))
+	}
+	if o.Schedule != "" && !o.Schedule.IsKnown() {
+		errs = append(errs, fmt.Errorf("unknown schedule %q", o.Schedule))
+	}
+	if err := o.Address.Validate(o.Method); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (o Order) idempotencyKey() string {
+	if o.IdempotencyKey != "" {
+		return o.IdempotencyKey
+	}
+	a := o.Address
+	h := sha256.New()
+	for _, v := range []string{
+		o.FirstName, o.LastName, a.Name, a.Line1, a.Line2, a.Line3, a.City, strings.ToUpper(a.State), a.PostalCode,
+		strings.ToUpper(a.Country), a.PhoneNumber, string(o.Method), string(o.Schedule), o.PhysicalCardProfileID,
+	} {
+		// Values are NUL-separated so that they cannot run into each other.
+		io.WriteString(h, v+"\x00")
+	}
+	return "fulfillment-" + o.CardID + "-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func (o Order) params(defaultProfileID string) increase.PhysicalCardNewParams {
+	shipment := increase.PhysicalCardNewParamsShipment{
+		Address: increase.F(o.Address.params()),
+		Method:  increase.F(o.Method),
+	}
+	if o.Schedule != "" {
+		shipment.Schedule = increase.F(o.Schedule)
+	}
+	res := increase.PhysicalCardNewParams{
+		CardID: increase.F(o.CardID),
+		Cardholder: increase.F(increase.PhysicalCardNewParamsCardholder{
+			FirstName: increase.F(o.FirstName),
+			LastName:  increase.F(o.LastName),
+		}),
+		Shipment: increase.F(shipment),
+	}
+	if profile := o.PhysicalCardProfileID; profile != "" || defaultProfileID != "" {
+		if profile == "" {
+			profile = defaultProfileID
+		}
+		res.PhysicalCardProfileID = increase.F(profile)
+	}
+	return res
+}
+
+// LineError is a problem with a line of a CSV.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("fulfillment: line %d: %s", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Columns are the columns of an orders CSV. The first line is a header naming
+// them, in any order; card_id, first_name, last_name, line1, city, state and
+// postal_code are required. name defaults to the cardholder's name and method
+// to usps.
+var Columns = []string{
+	"card_id", "first_name", "last_name", "name", "line1", "line2", "line3", "city", "state",
+	"postal_code", "country", "phone_number", "method", "schedule", "physical_card_profile_id", "idempotency_key",
+}
+
+var requiredColumns = []string{"card_id", "first_name", "last_name", "line1", "city", "state", "postal_code"}
+
+// ParseOrders reads orders from a CSV. Every order is returned; the returned
+// error joins a [*LineError] for each order that fails validation, and for
+// repeated Cards.
+func ParseOrders(r io.Reader) ([]Order, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("fulfillment: reading header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("fulfillment: missing column %s", name)
+		}
+	}
+	cr.FieldsPerRecord = len(header)
+
+	var orders []Order
+	var errs []error
+	seen := map[string]int{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return orders, fmt.Errorf("fulfillment: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := index[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		o := Order{
+			Line:      line,
+			CardID:    get("card_id"),
+			FirstName: get("first_name"),
+			LastName:  get("last_name"),
+			Address: Address{
+				Name:        get("name"),
+				Line1:       get("line1"),
+				Line2:       get("line2"),
+				Line3:       get("line3"),
+				City:        get("city"),
+				State:       get("state"),
+				PostalCode:  get("postal_code"),
+				Country:     get("country"),
+				PhoneNumber: get("phone_number"),
+			},
+			Method:                increase.PhysicalCardNewParamsShipmentMethod(get("method")),
+			Schedule:              increase.PhysicalCardNewParamsShipmentSchedule(get("schedule")),
+			PhysicalCardProfileID: get("physical_card_profile_id"),
+			IdempotencyKey:        get("idempotency_key"),
+		}
+		if o.Address.Name == "" {
+			o.Address.Name = strings.TrimSpace(o.FirstName + " " + o.LastName)
+		}
+		if o.Method == "" {
+			o.Method = increase.PhysicalCardNewParamsShipmentMethodUsps
+		}
+		if err := o.Validate(); err != nil {
+			errs = append(errs, &LineError{Line: line, Err: err})
+		}
+		if first, ok := seen[o.CardID]; ok && o.CardID != "" {
+			errs = append(errs, &LineError{Line: line, Err: fmt.Errorf("card %s is already ordered on line %d", o.CardID, first)})
+		} else {
+			seen[o.CardID] = line
+		}
+		orders = append(orders, o)
+	}
+	return orders, errors.Join(errs...)
+}
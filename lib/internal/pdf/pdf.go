@@ -0,0 +1,332 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// fonts or an embedded TrueType font, lines, rectangles and grayscale images.
+// It supports just enough of PDF 1.4 for the printable documents in lib.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// Page sizes in points.
+const (
+	LetterWidth  = 612.0
+	LetterHeight = 792.0
+)
+
+// Font is a font that can be used on the pages of a Document.
+type Font struct {
+	resource string
+	base     string
+	widths   *[95]int
+	// The advance width of every glyph, for monospaced embedded fonts.
+	mono int
+	data []byte
+}
+
+var (
+	Helvetica     = &Font{resource: "F1", base: "Helvetica", widths: &helveticaWidths}
+	HelveticaBold = &Font{resource: "F2", base: "Helvetica-Bold", widths: &helveticaBoldWidths}
+)
+
+// Width returns the width in points of s set in the font at the given size.
+func (f *Font) Width(s string, size float64) float64 {
+	var w int
+	for _, r := range s {
+		switch {
+		case f.mono > 0:
+			w += f.mono
+		case r >= 32 && r <= 126:
+			w += f.widths[r-32]
+		default:
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
+
+// Document is a PDF document under construction.
+type Document struct {
+	pages  []*Page
+	fonts  []*Font
+	images []*pdfImage
+	Title  string
+}
+
+// New returns an empty document.
+func New() *Document {
+	return &Document{fonts: []*Font{Helvetica, HelveticaBold}}
+}
+
+// TrueType embeds a TrueType font whose glyphs all advance by the given width,
+// in thousandths of the font size. If data is nil, the font is referenced by
+// name only and must be installed where the document is printed.
+func (d *Document) TrueType(name string, data []byte, width int) *Font {
+	f := &Font{resource: fmt.Sprintf("F%d", len(d.fonts)+1), base: name, mono: width, data: data}
+	d.fonts = append(d.fonts, f)
+	return f
+}
+
+type pdfImage struct {
+	resource      string
+	width, height int
+	pix           []byte
+}
+
+// Page is a page of a Document. Coordinates are in points from the bottom-left
+// corner.
+type Page struct {
+	doc           *Document
+	Width, Height float64
+	content       bytes.Buffer
+	images        []*pdfImage
+}
+
+// AddPage appends a page of the given size.
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{doc: d, Width: width, Height: height}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (p *Page) Text(f *Font, size float64, x, y float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", f.resource, num(size), num(x), num(y), escape(s))
+}
+
+// TextRight draws s with its baseline ending at (x, y).
+func (p *Page) TextRight(f *Font, size float64, x, y float64, s string) {
+	p.Text(f, size, x-f.Width(s, size), y, s)
+}
+
+// TextCenter draws s with its baseline centered on (x, y).
+func (p *Page) TextCenter(f *Font, size float64, x, y float64, s string) {
+	p.Text(f, size, x-f.Width(s, size)/2, y, s)
+}
+
+// Line draws a line of the given width.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect strokes a rectangle.
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n", num(width), num(x), num(y), num(w), num(h))
+}
+
+// FillRect fills a rectangle with a gray level from 0 (black) to 1 (white).
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "q %s g %s %s %s %s re f Q\n", num(gray), num(x), num(y), num(w), num(h))
+}
+
+// SetGray sets the gray level used for text and strokes, from 0 (black) to 1
+// (white).
+func (p *Page) SetGray(gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s G\n", num(gray), num(gray))
+}
+
+// Image draws img in grayscale, scaled into the rectangle.
+func (p *Page) Image(img image.Image, x, y, w, h float64) {
+	b := img.Bounds()
+	im := &pdfImage{
+		resource: fmt.Sprintf("Im%d", len(p.doc.images)+1),
+		width:    b.Dx(),
+		height:   b.Dy(),
+		pix:      make([]byte, 0, b.Dx()*b.Dy()),
+	}
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			c := img.At(px, py)
+			g := color.GrayModel.Convert(c).(color.Gray).Y
+			// Composite transparent pixels onto white paper.
+			if _, _, _, a := c.RGBA(); a < 0xffff {
+				g = uint8(255 - (uint32(255-g) * a / 0xffff))
+			}
+			im.pix = append(im.pix, g)
+		}
+	}
+	p.doc.images = append(p.doc.images, im)
+	p.images = append(p.images, im)
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n", num(w), num(h), num(x), num(y), im.resource)
+}
+
+// WriteTo writes the document.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	ww := &writer{w: w}
+	ww.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree, followed by fonts, images,
+	// then a page and content stream for each page.
+	next := 3
+	fontIDs := map[*Font]int{}
+	for _, f := range d.fonts {
+		fontIDs[f] = next
+		next++
+		if f.mono > 0 {
+			next++ // font descriptor
+			if f.data != nil {
+				next++ // font file
+			}
+		}
+	}
+	imageIDs := map[*pdfImage]int{}
+	for _, im := range d.images {
+		imageIDs[im] = next
+		next++
+	}
+	pageIDs := make([]int, len(d.pages))
+	for i := range d.pages {
+		pageIDs[i] = next
+		next += 2
+	}
+	infoID := next
+
+	ww.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pageIDs))
+	for i, id := range pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	ww.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageIDs)))
+
+	var fontResources strings.Builder
+	for _, f := range d.fonts {
+		id := fontIDs[f]
+		fmt.Fprintf(&fontResources, "/%s %d 0 R ", f.resource, id)
+		if f.mono == 0 {
+			ww.object(id, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+			continue
+		}
+		widths := strings.TrimSpace(strings.Repeat(fmt.Sprintf("%d ", f.mono), 95))
+		ww.object(id, fmt.Sprintf("<< /Type /Font /Subtype /TrueType /BaseFont /%s /FirstChar 32 /LastChar 126 /Widths [%s] /FontDescriptor %d 0 R >>", f.base, widths, id+1))
+		descriptor := fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 5 /FontBBox [0 -250 1000 750] /ItalicAngle 0 /Ascent 750 /Descent -250 /CapHeight 700 /StemV 80", f.base)
+		if f.data != nil {
+			ww.object(id+1, descriptor+fmt.Sprintf(" /FontFile2 %d 0 R >>", id+2))
+			ww.stream(id+2, fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+		} else {
+			ww.object(id+1, descriptor+" >>")
+		}
+	}
+	for _, im := range d.images {
+		ww.stream(imageIDs[im], fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8", im.width, im.height), im.pix)
+	}
+	for i, p := range d.pages {
+		var xobjects strings.Builder
+		for _, im := range p.images {
+			fmt.Fprintf(&xobjects, "/%s %d 0 R ", im.resource, imageIDs[im])
+		}
+		resources := fmt.Sprintf("/Font << %s>>", fontResources.String())
+		if xobjects.Len() > 0 {
+			resources += fmt.Sprintf(" /XObject << %s>>", xobjects.String())
+		}
+		ww.object(pageIDs[i], fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << %s >> /Contents %d 0 R >>", num(p.Width), num(p.Height), resources, pageIDs[i]+1))
+		ww.stream(pageIDs[i]+1, "", p.content.Bytes())
+	}
+	ww.object(infoID, fmt.Sprintf("<< /Title (%s) /Producer (increase-go) >>", escape(d.Title)))
+
+	xref := ww.n
+	ww.printf("xref\n0 %d\n0000000000 65535 f \n", infoID+1)
+	for id := 1; id <= infoID; id++ {
+		ww.printf("%010d 00000 n \n", ww.offsets[id])
+	}
+	ww.printf("trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", infoID+1, infoID, xref)
+	return ww.n, ww.err
+}
+
+type writer struct {
+	w       io.Writer
+	n       int64
+	err     error
+	offsets map[int]int64
+}
+
+func (w *writer) printf(format string, args ...any) {
+	w.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+func (w *writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	w.err = err
+}
+
+func (w *writer) object(id int, body string) {
+	w.begin(id)
+	w.printf("%s\nendobj\n", body)
+}
+
+func (w *writer) stream(id int, dict string, data []byte) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	w.begin(id)
+	w.printf("<< %s /Filter /FlateDecode /Length %d >>\nstream\n", strings.TrimSpace(dict), buf.Len())
+	w.write(buf.Bytes())
+	w.printf("\nendstream\nendobj\n")
+}
+
+func (w *writer) begin(id int) {
+	if w.offsets == nil {
+		w.offsets = map[int]int64{}
+	}
+	w.offsets[id] = w.n
+	w.printf("%d 0 obj\n", id)
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// escape encodes s as the contents of a PDF literal string in WinAnsi
+// encoding. Characters outside Latin-1 are replaced with '?'.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 32 || r > 255 || (r >= 127 && r < 160):
+			b.WriteByte('?')
+		case r < 128:
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\%03o", r)
+		}
+	}
+	return b.String()
+}
+
+// Glyph widths of the printable ASCII characters from the Adobe font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,28 @@
+// Package testapi serves a fake Increase API for the tests of the packages in
+// lib. Tests register only the routes they exercise on their own handler.
+package testapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// NewClient serves handler until the test finishes, and returns a client for
+// it that does not retry. Responses are JSON unless handler says otherwise.
+func NewClient(t testing.TB, handler http.Handler) *increase.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return increase.NewClient(
+		option.WithBaseURL(server.URL),
+		option.WithAPIKey("My API Key"),
+		option.WithMaxRetries(0),
+	)
+}
@@ -0,0 +1,397 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Increase/increase-go"
+)
+
+// CreditDebitIndicator is whether an entry credits or debits the account.
+type CreditDebitIndicator string
+
+const (
+	Credit CreditDebitIndicator = "CRDT"
+	Debit  CreditDebitIndicator = "DBIT"
+)
+
+// EntryStatus is the ISO 20022 status of a notification entry.
+type EntryStatus string
+
+const (
+	// The entry has been posted to the account.
+	EntryStatusBooked EntryStatus = "BOOK"
+	// The entry is in flight and may still change.
+	EntryStatusPending EntryStatus = "PDNG"
+	// The entry is informational only, for example a rejected or canceled
+	// transfer, and did not move funds.
+	EntryStatusInformation EntryStatus = "INFO"
+)
+
+// Bank transaction sub-family codes used for entries. Every entry is in the
+// Payments domain, in the Issued or Received Credit Transfers family.
+const (
+	SubFamilyDomesticCreditTransfer = "DMCT"
+	SubFamilyInstantCreditTransfer  = "ICCT"
+)
+
+// Entry is one debit or credit of a notification, built from an Increase
+// transfer.
+type Entry struct {
+	// The Increase identifier of the account that was debited or credited.
+	AccountID string
+	// The Increase identifier of the transfer, used as the entry reference.
+	Reference string
+	Amount    int64
+	Currency  string
+	Indicator CreditDebitIndicator
+	Status    EntryStatus
+	// When the entry was booked, or when the transfer was created if it is not
+	// booked yet.
+	BookingDate time.Time
+	SubFamily   string
+	// The Increase identifier of the Transaction that moved funds, if any.
+	TransactionID string
+
+	EndToEndID                 string
+	UETR                       string
+	Debtor                     Party
+	DebtorAccount              string
+	DebtorAgentRoutingNumber   string
+	Creditor                   Party
+	CreditorAccount            string
+	CreditorAgentRoutingNumber string
+	RemittanceInformation      string
+}
+
+// Notification is a bank to customer debit/credit notification (camt.054).
+type Notification struct {
+	MessageID string
+	CreatedAt time.Time
+	// Entries are grouped into one notification block per account.
+	Entries []Entry
+}
+
+// EntryFromWireTransfer builds a debit entry from an outbound wire transfer.
+func EntryFromWireTransfer(t increase.WireTransfer) Entry {
+	e := Entry{
+		AccountID:                  t.AccountID,
+		Reference:                  t.ID,
+		Amount:                     t.Amount,
+		Currency:                   string(t.Currency),
+		Indicator:                  Debit,
+		BookingDate:                t.CreatedAt,
+		SubFamily:                  SubFamilyDomesticCreditTransfer,
+		TransactionID:              t.TransactionID,
+		UETR:                       t.UniqueEndToEndTransactionReference,
+		Creditor:                   Party{Name: t.Creditor.Name},
+		CreditorAccount:            t.AccountNumber,
+		CreditorAgentRoutingNumber: t.RoutingNumber,
+		Debtor:                     Party{Name: t.Debtor.Name},
+	}
+	if u := t.Creditor.Address.Unstructured; u.Line1 != "" {
+		e.Creditor.Address.AddressLines = nonEmpty(u.Line1, u.Line2, u.Line3)
+	}
+	if u := t.Debtor.Address.Unstructured; u.Line1 != "" {
+		e.Debtor.Address.AddressLines = nonEmpty(u.Line1, u.Line2, u.Line3)
+	}
+	if t.Remittance.Category == increase.WireTransferRemittanceCategoryUnstructured {
+		e.RemittanceInformation = t.Remittance.Unstructured.Message
+	}
+	switch t.Status {
+	case increase.WireTransferStatusComplete, increase.WireTransferStatusSubmitted:
+		e.Status = EntryStatusBooked
+	case increase.WireTransferStatusCanceled, increase.WireTransferStatusRejected, increase.WireTransferStatusReversed:
+		e.Status = EntryStatusInformation
+	default:
+		e.Status = EntryStatusPending
+	}
+	if e.Status == EntryStatusBooked && !t.Submission.SubmittedAt.IsZero() {
+		e.BookingDate = t.Submission.SubmittedAt
+	}
+	return e
+}
+
+// EntryFromFednowTransfer builds a debit entry from an outbound FedNow
+// transfer.
+func EntryFromFednowTransfer(t increase.FednowTransfer) Entry {
+	e := Entry{
+		AccountID:                  t.AccountID,
+		Reference:                  t.ID,
+		Amount:                     t.Amount,
+		Currency:                   string(t.Currency),
+		Indicator:                  Debit,
+		BookingDate:                t.CreatedAt,
+		SubFamily:                  SubFamilyInstantCreditTransfer,
+		TransactionID:              t.TransactionID,
+		UETR:                       t.UniqueEndToEndTransactionReference,
+		Creditor:                   Party{Name: t.CreditorName},
+		CreditorAccount:            t.AccountNumber,
+		CreditorAgentRoutingNumber: t.RoutingNumber,
+		Debtor:                     Party{Name: t.DebtorName},
+		RemittanceInformation:      t.UnstructuredRemittanceInformation,
+	}
+	if a := t.CreditorAddress; a.City != "" {
+		e.Creditor.Address = PostalAddress{StreetName: a.Line1, TownName: a.City, PostalCode: a.PostalCode, CountrySubDivision: a.State, Country: "US"}
+	}
+	if a := t.DebtorAddress; a.City != "" {
+		e.Debtor.Address = PostalAddress{StreetName: a.Line1, TownName: a.City, PostalCode: a.PostalCode, CountrySubDivision: a.State, Country: "US"}
+	}
+	switch t.Status {
+	case increase.FednowTransferStatusComplete:
+		e.Status = EntryStatusBooked
+	case increase.FednowTransferStatusCanceled, increase.FednowTransferStatusRejected, increase.FednowTransferStatusReviewingRejected:
+		e.Status = EntryStatusInformation
+	default:
+		e.Status = EntryStatusPending
+	}
+	return e
+}
+
+// EntryFromRealTimePaymentsTransfer builds a debit entry from an outbound
+// Real-Time Payments transfer.
+func EntryFromRealTimePaymentsTransfer(t increase.RealTimePaymentsTransfer) Entry {
+	e := Entry{
+		AccountID:                  t.AccountID,
+		Reference:                  t.ID,
+		Amount:                     t.Amount,
+		Currency:                   string(t.Currency),
+		Indicator:                  Debit,
+		BookingDate:                t.CreatedAt,
+		SubFamily:                  SubFamilyInstantCreditTransfer,
+		TransactionID:              t.TransactionID,
+		Creditor:                   Party{Name: t.CreditorName},
+		CreditorAccount:            t.AccountNumber,
+		CreditorAgentRoutingNumber: t.RoutingNumber,
+		Debtor:                     Party{Name: t.DebtorName},
+		RemittanceInformation:      t.UnstructuredRemittanceInformation,
+	}
+	switch t.Status {
+	case increase.RealTimePaymentsTransferStatusComplete:
+		e.Status = EntryStatusBooked
+	case increase.RealTimePaymentsTransferStatusCanceled, increase.RealTimePaymentsTransferStatusRejected:
+		e.Status = EntryStatusInformation
+	default:
+		e.Status = EntryStatusPending
+	}
+	return e
+}
+
+// EntryFromInboundWireTransfer builds a credit entry from an inbound wire
+// transfer. Inbound wires are always in USD.
+func EntryFromInboundWireTransfer(t increase.InboundWireTransfer) Entry {
+	e := Entry{
+		AccountID:                t.AccountID,
+		Reference:                t.ID,
+		Amount:                   t.Amount,
+		Currency:                 "USD",
+		Indicator:                Credit,
+		BookingDate:              t.CreatedAt,
+		SubFamily:                SubFamilyDomesticCreditTransfer,
+		EndToEndID:               t.EndToEndIdentification,
+		UETR:                     t.UniqueEndToEndTransactionReference,
+		Debtor:                   Party{Name: t.DebtorName},
+		DebtorAccount:            t.DebtorAccountNumber,
+		DebtorAgentRoutingNumber: t.DebtorRoutingNumber,
+		Creditor:                 Party{Name: t.CreditorName},
+		RemittanceInformation:    t.UnstructuredRemittanceInformation,
+	}
+	e.Debtor.Address.AddressLines = nonEmpty(t.DebtorAddressLine1, t.DebtorAddressLine2, t.DebtorAddressLine3)
+	e.Creditor.Address.AddressLines = nonEmpty(t.CreditorAddressLine1, t.CreditorAddressLine2, t.CreditorAddressLine3)
+	if e.RemittanceInformation == "" {
+		e.RemittanceInformation = t.Description
+	}
+	switch t.Status {
+	case increase.InboundWireTransferStatusAccepted:
+		e.Status = EntryStatusBooked
+	case increase.InboundWireTransferStatusDeclined, increase.InboundWireTransferStatusReversed:
+		e.Status = EntryStatusInformation
+	default:
+		e.Status = EntryStatusPending
+	}
+	return e
+}
+
+// EntryFromInboundFednowTransfer builds a credit entry from an inbound FedNow
+// transfer.
+func EntryFromInboundFednowTransfer(t increase.InboundFednowTransfer) Entry {
+	e := Entry{
+		AccountID:                t.AccountID,
+		Reference:                t.ID,
+		Amount:                   t.Amount,
+		Currency:                 string(t.Currency),
+		Indicator:                Credit,
+		BookingDate:              t.CreatedAt,
+		SubFamily:                SubFamilyInstantCreditTransfer,
+		TransactionID:            t.TransactionID,
+		UETR:                     t.UniqueEndToEndTransactionReference,
+		Debtor:                   Party{Name: t.DebtorName},
+		DebtorAccount:            t.DebtorAccountNumber,
+		DebtorAgentRoutingNumber: t.DebtorRoutingNumber,
+		Creditor:                 Party{Name: t.CreditorName},
+		RemittanceInformation:    t.UnstructuredRemittanceInformation,
+	}
+	switch t.Status {
+	case increase.InboundFednowTransferStatusConfirmed:
+		e.Status = EntryStatusBooked
+	case increase.InboundFednowTransferStatusDeclined, increase.InboundFednowTransferStatusTimedOut:
+		e.Status = EntryStatusInformation
+	default:
+		e.Status = EntryStatusPending
+	}
+	return e
+}
+
+type camt054Document struct {
+	XMLName      xml.Name                 `xml:"Document"`
+	Namespace    string                   `xml:"xmlns,attr,omitempty"`
+	Notification camt054BankToCustomerXML `xml:"BkToCstmrDbtCdtNtfctn"`
+}
+
+type camt054BankToCustomerXML struct {
+	GroupHeader struct {
+		MessageID string `xml:"MsgId"`
+		CreatedAt string `xml:"CreDtTm"`
+	} `xml:"GrpHdr"`
+	Notifications []camt054NotificationXML `xml:"Ntfctn"`
+}
+
+type camt054NotificationXML struct {
+	ID        string            `xml:"Id"`
+	CreatedAt string            `xml:"CreDtTm"`
+	Account   accountXML        `xml:"Acct"`
+	Entries   []camt054EntryXML `xml:"Ntry"`
+}
+
+type camt054EntryXML struct {
+	Reference       string                 `xml:"NtryRef,omitempty"`
+	Amount          amountXML              `xml:"Amt"`
+	Indicator       CreditDebitIndicator   `xml:"CdtDbtInd"`
+	Status          EntryStatus            `xml:"Sts>Cd"`
+	BookingDate     dateXML                `xml:"BookgDt"`
+	TransactionCode bankTransactionCodeXML `xml:"BkTxCd"`
+	Details         camt054DetailsXML      `xml:"NtryDtls"`
+}
+
+type bankTransactionCodeXML struct {
+	Domain    string `xml:"Domn>Cd"`
+	Family    string `xml:"Domn>Fmly>Cd"`
+	SubFamily string `xml:"Domn>Fmly>SubFmlyCd"`
+}
+
+type camt054DetailsXML struct {
+	Transaction camt054TransactionXML `xml:"TxDtls"`
+}
+
+type camt054TransactionXML struct {
+	References struct {
+		EndToEndID    string `xml:"EndToEndId,omitempty"`
+		UETR          string `xml:"UETR,omitempty"`
+		TransactionID string `xml:"TxId,omitempty"`
+	} `xml:"Refs"`
+	Amount         amountXML            `xml:"Amt"`
+	Indicator      CreditDebitIndicator `xml:"CdtDbtInd"`
+	RelatedParties *relatedPartiesXML   `xml:"RltdPties,omitempty"`
+	RelatedAgents  *relatedAgentsXML    `xml:"RltdAgts,omitempty"`
+	Remittance     *remittanceXML       `xml:"RmtInf,omitempty"`
+}
+
+type relatedPartiesXML struct {
+	Debtor          *partyChoiceXML `xml:"Dbtr,omitempty"`
+	DebtorAccount   *accountXML     `xml:"DbtrAcct,omitempty"`
+	Creditor        *partyChoiceXML `xml:"Cdtr,omitempty"`
+	CreditorAccount *accountXML     `xml:"CdtrAcct,omitempty"`
+}
+
+type partyChoiceXML struct {
+	Party *partyXML `xml:"Pty"`
+}
+
+type relatedAgentsXML struct {
+	DebtorAgent   *agentXML `xml:"DbtrAgt,omitempty"`
+	CreditorAgent *agentXML `xml:"CdtrAgt,omitempty"`
+}
+
+// MarshalCamt054 renders a debit/credit notification with one notification
+// block per account, in the order the accounts first appear in the entries.
+func MarshalCamt054(n Notification) ([]byte, error) {
+	doc := camt054Document{Namespace: NamespaceCamt054}
+	created := formatDateTime(n.CreatedAt)
+	doc.Notification.GroupHeader.MessageID = n.MessageID
+	doc.Notification.GroupHeader.CreatedAt = created
+	blocks := map[string]int{}
+	for _, e := range n.Entries {
+		if e.AccountID == "" {
+			return nil, fmt.Errorf("iso20022: entry %s has no account", e.Reference)
+		}
+		i, ok := blocks[e.AccountID]
+		if !ok {
+			i = len(doc.Notification.Notifications)
+			blocks[e.AccountID] = i
+			doc.Notification.Notifications = append(doc.Notification.Notifications, camt054NotificationXML{
+				ID:        n.MessageID + "-" + strconv.Itoa(i+1),
+				CreatedAt: created,
+				Account:   *newAccountXML(e.AccountID),
+			})
+		}
+		block := &doc.Notification.Notifications[i]
+		block.Entries = append(block.Entries, e.xml())
+	}
+	return marshalDocument(doc)
+}
+
+func (e Entry) xml() camt054EntryXML {
+	currency := e.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	amount := e.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	x := camt054EntryXML{
+		Reference:   e.Reference,
+		Amount:      amountXML{Currency: currency, Value: FormatAmount(amount)},
+		Indicator:   e.Indicator,
+		Status:      e.Status,
+		BookingDate: dateXML{DateTime: formatDateTime(e.BookingDate)},
+		TransactionCode: bankTransactionCodeXML{
+			Domain:    "PMNT",
+			Family:    "ICDT",
+			SubFamily: e.SubFamily,
+		},
+	}
+	if e.Indicator == Credit {
+		x.TransactionCode.Family = "RCDT"
+	}
+	tx := &x.Details.Transaction
+	tx.References.EndToEndID = e.EndToEndID
+	tx.References.UETR = e.UETR
+	tx.References.TransactionID = e.TransactionID
+	tx.Amount = x.Amount
+	tx.Indicator = e.Indicator
+	parties := relatedPartiesXML{
+		DebtorAccount:   newAccountXML(e.DebtorAccount),
+		CreditorAccount: newAccountXML(e.CreditorAccount),
+	}
+	if p := newPartyXML(e.Debtor); p != nil {
+		parties.Debtor = &partyChoiceXML{Party: p}
+	}
+	if p := newPartyXML(e.Creditor); p != nil {
+		parties.Creditor = &partyChoiceXML{Party: p}
+	}
+	if parties != (relatedPartiesXML{}) {
+		tx.RelatedParties = &parties
+	}
+	agents := relatedAgentsXML{
+		DebtorAgent:   newAgentXML(e.DebtorAgentRoutingNumber),
+		CreditorAgent: newAgentXML(e.CreditorAgentRoutingNumber),
+	}
+	if agents != (relatedAgentsXML{}) {
+		tx.RelatedAgents = &agents
+	}
+	tx.Remittance = newRemittanceXML(e.RemittanceInformation)
+	return x
+}
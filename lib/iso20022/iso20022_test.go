@@ -0,0 +1,269 @@
+package iso20022_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/iso20022"
+)
+
+const pain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2026-10-19T09:30:00</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <InitgPty><Nm>Acme ERP</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><Dt>2026-10-20</Dt></ReqdExctnDt>
+      <Dbtr><Nm>Acme Corp</Nm></Dbtr>
+      <DbtrAcct><Id><Othr><Id>987654321</Id></Othr></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><ClrSysMmbId><ClrSysId><Cd>USABA</Cd></ClrSysId><MmbId>101050001</MmbId></ClrSysMmbId></FinInstnId></DbtrAgt>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>INV-1001</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1250.5</InstdAmt></Amt>
+        <CdtrAgt><FinInstnId><ClrSysMmbId><ClrSysId><Cd>USABA</Cd></ClrSysId><MmbId>021000021</MmbId></ClrSysMmbId></FinInstnId></CdtrAgt>
+        <Cdtr>
+          <Nm>Ian Crease</Nm>
+          <PstlAdr><StrtNm>Liberty Street</StrtNm><BldgNb>33</BldgNb><PstCd>10045</PstCd><TwnNm>New York</TwnNm><CtrySubDvsn>NY</CtrySubDvsn><Ctry>US</Ctry></PstlAdr>
+        </Cdtr>
+        <CdtrAcct><Id><Othr><Id>123456789</Id></Othr></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice 1001</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>NOTPROVIDED</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">10.00</InstdAmt></Amt>
+        <Cdtr><Nm>Euro Supplier</Nm></Cdtr>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestParsePain001(t *testing.T) {
+	doc, err := iso20022.ParsePain001(strings.NewReader(pain001))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if doc.MessageID != "MSG-1" || doc.InitiatingPartyName != "Acme ERP" || len(doc.Transfers) != 2 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+	tr := doc.Transfers[0]
+	if tr.Amount != 125050 || tr.Currency != "USD" || tr.EndToEndID != "INV-1001" {
+		t.Errorf("unexpected transfer: %+v", tr)
+	}
+	if tr.Debtor.Name != "Acme Corp" || tr.DebtorAgentRoutingNumber != "101050001" {
+		t.Errorf("expected the payment information debtor to be copied, got %+v", tr)
+	}
+	if !tr.RequestedExecutionDate.Equal(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected execution date %s", tr.RequestedExecutionDate)
+	}
+	if doc.Transfers[1].EndToEndID != "" || doc.Transfers[1].CreditorAccount != "DE89370400440532013000" {
+		t.Errorf("unexpected second transfer: %+v", doc.Transfers[1])
+	}
+
+	wire, err := tr.WireTransferNewParams("account_in71c4amph0vgo2qllky")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if err := wire.Validate(); err != nil {
+		t.Errorf("expected valid wire parameters, got %s", err)
+	}
+	addr := wire.Creditor.Value.Address.Value.Unstructured.Value
+	if addr.Line1.Value != "33 Liberty Street" || addr.Line2.Value != "New York, NY 10045" || addr.Line3.Value != "US" {
+		t.Errorf("unexpected creditor address: %+v", addr)
+	}
+
+	fednow, err := tr.FednowTransferNewParams("account_number_v18nkfqm6afpsrvy82b2")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if fednow.CreditorAddress.Value.City.Value != "New York" || fednow.RoutingNumber.Value != "021000021" {
+		t.Errorf("unexpected FedNow parameters: %+v", fednow)
+	}
+
+	if _, err := doc.Transfers[1].RealTimePaymentsTransferNewParams("account_number_v18nkfqm6afpsrvy82b2"); err == nil {
+		t.Errorf("expected an error for a EUR transfer")
+	}
+}
+
+func TestPain001RoundTrip(t *testing.T) {
+	params := increase.RealTimePaymentsTransferNewParams{
+		Amount:                            increase.F(int64(100)),
+		CreditorName:                      increase.F("Ian Crease"),
+		SourceAccountNumberID:             increase.F("account_number_v18nkfqm6afpsrvy82b2"),
+		UnstructuredRemittanceInformation: increase.F("Invoice 29582"),
+		AccountNumber:                     increase.F("987654321"),
+		RoutingNumber:                     increase.F("101050001"),
+	}
+	out, err := iso20022.MarshalPain001(iso20022.PaymentInitiation{
+		MessageID: "MSG-2",
+		CreatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Transfers: []iso20022.CreditTransfer{iso20022.FromRealTimePaymentsTransferNewParams(params)},
+	})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !bytes.Contains(out, []byte(iso20022.NamespacePain001)) {
+		t.Errorf("expected the pain.001 namespace in %s", out)
+	}
+	doc, err := iso20022.ParsePain001(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	back, err := doc.Transfers[0].RealTimePaymentsTransferNewParams("account_number_v18nkfqm6afpsrvy82b2")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if back.Amount.Value != 100 || back.CreditorName.Value != "Ian Crease" || back.AccountNumber.Value != "987654321" ||
+		back.RoutingNumber.Value != "101050001" || back.UnstructuredRemittanceInformation.Value != "Invoice 29582" {
+		t.Errorf("round trip lost data: %+v", back)
+	}
+}
+
+func TestPacs008RoundTrip(t *testing.T) {
+	transfer := iso20022.FromFednowTransferNewParams(increase.FednowTransferNewParams{
+		Amount:                            increase.F(int64(42)),
+		CreditorName:                      increase.F("Ian Crease"),
+		DebtorName:                        increase.F("Acme Corp"),
+		SourceAccountNumberID:             increase.F("account_number_v18nkfqm6afpsrvy82b2"),
+		UnstructuredRemittanceInformation: increase.F("Invoice 29582"),
+		AccountNumber:                     increase.F("987654321"),
+		RoutingNumber:                     increase.F("101050001"),
+		CreditorAddress: increase.F(increase.FednowTransferNewParamsCreditorAddress{
+			City:       increase.F("New York"),
+			PostalCode: increase.F("10045"),
+			State:      increase.F("NY"),
+		}),
+	})
+	transfer.UETR = "c5d6a7b8-1234-4cde-8f90-1234567890ab"
+	out, err := iso20022.MarshalPacs008(iso20022.FIToFICreditTransfer{MessageID: "MSG-3", Transfers: []iso20022.CreditTransfer{transfer}})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	doc, err := iso20022.ParsePacs008(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	got := doc.Transfers[0]
+	if got.Amount != 42 || got.UETR != transfer.UETR || got.Creditor.Address.TownName != "New York" || got.CreditorAgentRoutingNumber != "101050001" {
+		t.Errorf("round trip lost data: %+v", got)
+	}
+}
+
+func TestRemittanceLines(t *testing.T) {
+	doc, err := iso20022.ParsePain001(strings.NewReader(strings.Replace(pain001, "<Ustrd>Invoice 1001</Ustrd>", "<Ustrd>INV 1001</Ustrd><Ustrd>INV 1002</Ustrd>", 1)))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if got := doc.Transfers[0].RemittanceInformation; got != "INV 1001 INV 1002" {
+		t.Errorf("expected remittance lines joined with a space, got %q", got)
+	}
+}
+
+func TestRemittanceRoundTrip(t *testing.T) {
+	reference := strings.Repeat("REF0123456", 20)
+	for info, want := range map[string]string{
+		strings.Repeat("Invoice 29582, ", 12) + "paid in full": "",
+		"Invoice 1001  " + strings.Repeat("X", 130) + " end":   "",
+		// Words longer than a line are broken where they must be.
+		reference: reference[:140] + " " + reference[140:],
+	} {
+		if want == "" {
+			want = info
+		}
+		transfer := iso20022.CreditTransfer{Amount: 100, RemittanceInformation: info}
+		pain, err := iso20022.MarshalPain001(iso20022.PaymentInitiation{MessageID: "MSG-4", Transfers: []iso20022.CreditTransfer{transfer}})
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		initiation, err := iso20022.ParsePain001(bytes.NewReader(pain))
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		pacs, err := iso20022.MarshalPacs008(iso20022.FIToFICreditTransfer{MessageID: "MSG-4", Transfers: initiation.Transfers})
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		doc, err := iso20022.ParsePacs008(bytes.NewReader(pacs))
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		if got := doc.Transfers[0].RemittanceInformation; got != want {
+			t.Errorf("expected remittance information %q, got %q", want, got)
+		}
+		if bytes.Contains(pacs, []byte(strings.Repeat("X", 141))) || bytes.Contains(pacs, []byte(reference[:141])) {
+			t.Errorf("expected remittance lines of at most 140 characters")
+		}
+	}
+}
+
+func TestMarshalCamt054(t *testing.T) {
+	outbound := iso20022.EntryFromWireTransfer(increase.WireTransfer{
+		ID:            "wire_transfer_5akynk7dqsq25qwk9q2u",
+		AccountID:     "account_in71c4amph0vgo2qllky",
+		AccountNumber: "987654321",
+		RoutingNumber: "101050001",
+		Amount:        100,
+		Currency:      increase.WireTransferCurrencyUsd,
+		Status:        increase.WireTransferStatusComplete,
+		Creditor:      increase.WireTransferCreditor{Name: "Ian Crease"},
+	})
+	inbound := iso20022.EntryFromInboundFednowTransfer(increase.InboundFednowTransfer{
+		ID:                  "inbound_fednow_transfer_ctxxbc07oh5ke5w1hk20",
+		AccountID:           "account_in71c4amph0vgo2qllky",
+		Amount:              250,
+		Currency:            increase.InboundFednowTransferCurrencyUsd,
+		Status:              increase.InboundFednowTransferStatusPendingConfirming,
+		DebtorName:          "Acme Corp",
+		DebtorRoutingNumber: "101050001",
+	})
+	other := iso20022.EntryFromInboundWireTransfer(increase.InboundWireTransfer{
+		ID:        "inbound_wire_transfer_f228m6bmhtcxjco9pwp0",
+		AccountID: "account_other",
+		Amount:    300,
+		Status:    increase.InboundWireTransferStatusAccepted,
+	})
+	out, err := iso20022.MarshalCamt054(iso20022.Notification{MessageID: "NTF-1", Entries: []iso20022.Entry{outbound, inbound, other}})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	s := string(out)
+	for _, want := range []string{
+		iso20022.NamespaceCamt054,
+		"<NtryRef>wire_transfer_5akynk7dqsq25qwk9q2u</NtryRef>",
+		`<Amt Ccy="USD">1.00</Amt>`,
+		"<CdtDbtInd>DBIT</CdtDbtInd>",
+		"<Cd>BOOK</Cd>",
+		"<Cd>PDNG</Cd>",
+		"<Cd>RCDT</Cd>",
+		"<Id>NTF-1-2</Id>",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in\n%s", want, s)
+		}
+	}
+	if n := strings.Count(s, "<Ntfctn>"); n != 2 {
+		t.Errorf("expected 2 notification blocks, got %d", n)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]int64{"1": 100, "1.5": 150, "1.05": 105, "0.010": 1, "-2.50": -250}
+	for input, want := range cases {
+		got, err := iso20022.ParseAmount(input)
+		if err != nil || got != want {
+			t.Errorf("ParseAmount(%q) = %d, %v; expected %d", input, got, err, want)
+		}
+	}
+	if _, err := iso20022.ParseAmount("1.001"); err == nil {
+		t.Errorf("expected an error for sub-cent amounts")
+	}
+}
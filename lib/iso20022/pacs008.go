@@ -0,0 +1,133 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// FIToFICreditTransfer is a parsed FI to FI customer credit transfer
+// (pacs.008).
+type FIToFICreditTransfer struct {
+	MessageID string
+	CreatedAt time.Time
+	// Transfers holds each transaction of the message. RequestedExecutionDate is
+	// set from the interbank settlement date.
+	Transfers []CreditTransfer
+}
+
+type pacs008Document struct {
+	XMLName   xml.Name        `xml:"Document"`
+	Namespace string          `xml:"xmlns,attr,omitempty"`
+	Transfer  pacs008Transfer `xml:"FIToFICstmrCdtTrf"`
+}
+
+type pacs008Transfer struct {
+	GroupHeader  pacs008GroupHeader      `xml:"GrpHdr"`
+	Transactions []pacs008TransactionXML `xml:"CdtTrfTxInf"`
+}
+
+type pacs008GroupHeader struct {
+	MessageID            string `xml:"MsgId"`
+	CreatedAt            string `xml:"CreDtTm"`
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	SettlementMethod     string `xml:"SttlmInf>SttlmMtd"`
+}
+
+type pacs008TransactionXML struct {
+	PaymentID                 paymentIDXML   `xml:"PmtId"`
+	InterbankSettlementAmount amountXML      `xml:"IntrBkSttlmAmt"`
+	InterbankSettlementDate   string         `xml:"IntrBkSttlmDt,omitempty"`
+	ChargeBearer              string         `xml:"ChrgBr"`
+	Debtor                    *partyXML      `xml:"Dbtr"`
+	DebtorAccount             *accountXML    `xml:"DbtrAcct"`
+	DebtorAgent               *agentXML      `xml:"DbtrAgt"`
+	CreditorAgent             *agentXML      `xml:"CdtrAgt"`
+	Creditor                  *partyXML      `xml:"Cdtr"`
+	CreditorAccount           *accountXML    `xml:"CdtrAcct"`
+	Remittance                *remittanceXML `xml:"RmtInf"`
+}
+
+// ParsePacs008 reads a pacs.008 FI to FI customer credit transfer.
+func ParsePacs008(r io.Reader) (*FIToFICreditTransfer, error) {
+	var doc pacs008Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("iso20022: decoding pacs.008: %w", err)
+	}
+	h := doc.Transfer.GroupHeader
+	res := &FIToFICreditTransfer{MessageID: h.MessageID}
+	if h.CreatedAt != "" {
+		t, err := parseDateTime(h.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("iso20022: invalid CreDtTm: %w", err)
+		}
+		res.CreatedAt = t
+	}
+	for _, tx := range doc.Transfer.Transactions {
+		amount, err := ParseAmount(tx.InterbankSettlementAmount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("iso20022: transaction %s: %w", tx.PaymentID.EndToEndID, err)
+		}
+		date, err := (&dateXML{Value: tx.InterbankSettlementDate}).time()
+		if err != nil {
+			return nil, fmt.Errorf("iso20022: transaction %s has an invalid IntrBkSttlmDt: %w", tx.PaymentID.EndToEndID, err)
+		}
+		res.Transfers = append(res.Transfers, CreditTransfer{
+			InstructionID:              tx.PaymentID.InstructionID,
+			EndToEndID:                 tx.PaymentID.endToEndID(),
+			UETR:                       tx.PaymentID.UETR,
+			Amount:                     amount,
+			Currency:                   tx.InterbankSettlementAmount.Currency,
+			RequestedExecutionDate:     date,
+			Debtor:                     tx.Debtor.party(),
+			DebtorAccount:              tx.DebtorAccount.number(),
+			DebtorAgentRoutingNumber:   tx.DebtorAgent.routingNumber(),
+			Creditor:                   tx.Creditor.party(),
+			CreditorAccount:            tx.CreditorAccount.number(),
+			CreditorAgentRoutingNumber: tx.CreditorAgent.routingNumber(),
+			RemittanceInformation:      tx.Remittance.text(),
+		})
+	}
+	if n, err := strconv.Atoi(h.NumberOfTransactions); err == nil && n != len(res.Transfers) {
+		return nil, fmt.Errorf("iso20022: NbOfTxs is %d but the message contains %d transactions", n, len(res.Transfers))
+	}
+	return res, nil
+}
+
+// MarshalPacs008 renders an FI to FI customer credit transfer settled through
+// a clearing system.
+func MarshalPacs008(p FIToFICreditTransfer) ([]byte, error) {
+	doc := pacs008Document{Namespace: NamespacePacs008}
+	doc.Transfer.GroupHeader = pacs008GroupHeader{
+		MessageID:            p.MessageID,
+		CreatedAt:            formatDateTime(p.CreatedAt),
+		NumberOfTransactions: strconv.Itoa(len(p.Transfers)),
+		SettlementMethod:     "CLRG",
+	}
+	for _, t := range p.Transfers {
+		tx := pacs008TransactionXML{
+			PaymentID: paymentIDXML{
+				InstructionID: t.InstructionID,
+				EndToEndID:    orNotProvided(t.EndToEndID),
+				TransactionID: t.InstructionID,
+				UETR:          t.UETR,
+			},
+			InterbankSettlementAmount: amountXML{Currency: t.Currency, Value: FormatAmount(t.Amount)},
+			ChargeBearer:              "SLEV",
+			Debtor:                    orEmptyParty(newPartyXML(t.Debtor)),
+			DebtorAccount:             newAccountXML(t.DebtorAccount),
+			DebtorAgent:               orEmptyAgent(newAgentXML(t.DebtorAgentRoutingNumber)),
+			CreditorAgent:             orEmptyAgent(newAgentXML(t.CreditorAgentRoutingNumber)),
+			Creditor:                  orEmptyParty(newPartyXML(t.Creditor)),
+			CreditorAccount:           newAccountXML(t.CreditorAccount),
+			Remittance:                newRemittanceXML(t.RemittanceInformation),
+		}
+		if !t.RequestedExecutionDate.IsZero() {
+			tx.InterbankSettlementDate = t.RequestedExecutionDate.Format("2006-01-02")
+		}
+		doc.Transfer.Transactions = append(doc.Transfer.Transactions, tx)
+	}
+	return marshalDocument(doc)
+}
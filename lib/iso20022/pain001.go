@@ -0,0 +1,213 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// PaymentInitiation is a parsed customer credit transfer initiation (pain.001).
+type PaymentInitiation struct {
+	MessageID           string
+	CreatedAt           time.Time
+	InitiatingPartyName string
+	// Transfers holds the transactions of every payment information block, with
+	// the block's debtor, debtor account and execution date copied onto each.
+	Transfers []CreditTransfer
+}
+
+type pain001Document struct {
+	XMLName    xml.Name          `xml:"Document"`
+	Namespace  string            `xml:"xmlns,attr,omitempty"`
+	Initiation pain001Initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Initiation struct {
+	GroupHeader        pain001GroupHeader          `xml:"GrpHdr"`
+	PaymentInformation []pain001PaymentInformation `xml:"PmtInf"`
+}
+
+type pain001GroupHeader struct {
+	MessageID            string    `xml:"MsgId"`
+	CreatedAt            string    `xml:"CreDtTm"`
+	NumberOfTransactions string    `xml:"NbOfTxs"`
+	ControlSum           string    `xml:"CtrlSum,omitempty"`
+	InitiatingParty      *partyXML `xml:"InitgPty"`
+}
+
+type pain001PaymentInformation struct {
+	ID                     string                  `xml:"PmtInfId"`
+	Method                 string                  `xml:"PmtMtd"`
+	NumberOfTransactions   string                  `xml:"NbOfTxs,omitempty"`
+	ControlSum             string                  `xml:"CtrlSum,omitempty"`
+	RequestedExecutionDate *dateXML                `xml:"ReqdExctnDt"`
+	Debtor                 *partyXML               `xml:"Dbtr"`
+	DebtorAccount          *accountXML             `xml:"DbtrAcct"`
+	DebtorAgent            *agentXML               `xml:"DbtrAgt"`
+	Transactions           []pain001TransactionXML `xml:"CdtTrfTxInf"`
+}
+
+type pain001TransactionXML struct {
+	PaymentID       paymentIDXML        `xml:"PmtId"`
+	Amount          instructedAmountXML `xml:"Amt"`
+	CreditorAgent   *agentXML           `xml:"CdtrAgt"`
+	Creditor        *partyXML           `xml:"Cdtr"`
+	CreditorAccount *accountXML         `xml:"CdtrAcct"`
+	Remittance      *remittanceXML      `xml:"RmtInf"`
+}
+
+type instructedAmountXML struct {
+	Instructed amountXML `xml:"InstdAmt"`
+}
+
+// ParsePain001 reads a pain.001 customer credit transfer initiation.
+func ParsePain001(r io.Reader) (*PaymentInitiation, error) {
+	var doc pain001Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("iso20022: decoding pain.001: %w", err)
+	}
+	h := doc.Initiation.GroupHeader
+	res := &PaymentInitiation{MessageID: h.MessageID, InitiatingPartyName: h.InitiatingParty.party().Name}
+	if h.CreatedAt != "" {
+		t, err := parseDateTime(h.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("iso20022: invalid CreDtTm: %w", err)
+		}
+		res.CreatedAt = t
+	}
+	for _, pmt := range doc.Initiation.PaymentInformation {
+		if pmt.Method != "" && pmt.Method != "TRF" {
+			return nil, fmt.Errorf("iso20022: payment information %s has unsupported method %q", pmt.ID, pmt.Method)
+		}
+		date, err := pmt.RequestedExecutionDate.time()
+		if err != nil {
+			return nil, fmt.Errorf("iso20022: payment information %s has an invalid ReqdExctnDt: %w", pmt.ID, err)
+		}
+		for _, tx := range pmt.Transactions {
+			amount, err := ParseAmount(tx.Amount.Instructed.Value)
+			if err != nil {
+				return nil, fmt.Errorf("iso20022: transaction %s: %w", tx.PaymentID.EndToEndID, err)
+			}
+			res.Transfers = append(res.Transfers, CreditTransfer{
+				InstructionID:              tx.PaymentID.InstructionID,
+				EndToEndID:                 tx.PaymentID.endToEndID(),
+				UETR:                       tx.PaymentID.UETR,
+				Amount:                     amount,
+				Currency:                   tx.Amount.Instructed.Currency,
+				RequestedExecutionDate:     date,
+				Debtor:                     pmt.Debtor.party(),
+				DebtorAccount:              pmt.DebtorAccount.number(),
+				DebtorAgentRoutingNumber:   pmt.DebtorAgent.routingNumber(),
+				Creditor:                   tx.Creditor.party(),
+				CreditorAccount:            tx.CreditorAccount.number(),
+				CreditorAgentRoutingNumber: tx.CreditorAgent.routingNumber(),
+				RemittanceInformation:      tx.Remittance.text(),
+			})
+		}
+	}
+	if n, err := strconv.Atoi(h.NumberOfTransactions); err == nil && n != len(res.Transfers) {
+		return nil, fmt.Errorf("iso20022: NbOfTxs is %d but the message contains %d transactions", n, len(res.Transfers))
+	}
+	return res, nil
+}
+
+// MarshalPain001 renders a customer credit transfer initiation. Each transfer
+// is written to its own payment information block so that transfers from
+// different debtors or with different execution dates can share a message.
+func MarshalPain001(p PaymentInitiation) ([]byte, error) {
+	doc := pain001Document{Namespace: NamespacePain001}
+	doc.Initiation.GroupHeader = pain001GroupHeader{
+		MessageID:            p.MessageID,
+		CreatedAt:            formatDateTime(p.CreatedAt),
+		NumberOfTransactions: strconv.Itoa(len(p.Transfers)),
+		ControlSum:           controlSum(p.Transfers),
+		InitiatingParty:      &partyXML{Name: p.InitiatingPartyName},
+	}
+	for i, t := range p.Transfers {
+		date := t.RequestedExecutionDate
+		if date.IsZero() {
+			date = p.CreatedAt
+		}
+		if date.IsZero() {
+			date = time.Now()
+		}
+		doc.Initiation.PaymentInformation = append(doc.Initiation.PaymentInformation, pain001PaymentInformation{
+			ID:                     fmt.Sprintf("%s-%d", p.MessageID, i+1),
+			Method:                 "TRF",
+			NumberOfTransactions:   "1",
+			ControlSum:             FormatAmount(t.Amount),
+			RequestedExecutionDate: &dateXML{Date: date.Format("2006-01-02")},
+			Debtor:                 orEmptyParty(newPartyXML(t.Debtor)),
+			DebtorAccount:          newAccountXML(t.DebtorAccount),
+			DebtorAgent:            orEmptyAgent(newAgentXML(t.DebtorAgentRoutingNumber)),
+			Transactions: []pain001TransactionXML{{
+				PaymentID: paymentIDXML{
+					InstructionID: t.InstructionID,
+					EndToEndID:    orNotProvided(t.EndToEndID),
+					UETR:          t.UETR,
+				},
+				Amount:          instructedAmountXML{Instructed: amountXML{Currency: t.Currency, Value: FormatAmount(t.Amount)}},
+				CreditorAgent:   newAgentXML(t.CreditorAgentRoutingNumber),
+				Creditor:        orEmptyParty(newPartyXML(t.Creditor)),
+				CreditorAccount: newAccountXML(t.CreditorAccount),
+				Remittance:      newRemittanceXML(t.RemittanceInformation),
+			}},
+		})
+	}
+	return marshalDocument(doc)
+}
+
+func marshalDocument(doc any) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("iso20022: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func controlSum(transfers []CreditTransfer) string {
+	var sum int64
+	for _, t := range transfers {
+		sum += t.Amount
+	}
+	return FormatAmount(sum)
+}
+
+// orNotProvided returns the placeholder ISO 20022 uses for end-to-end
+// identifications that the initiating party did not assign.
+func orNotProvided(s string) string {
+	if s == "" {
+		return "NOTPROVIDED"
+	}
+	return s
+}
+
+func (x paymentIDXML) endToEndID() string {
+	if x.EndToEndID == "NOTPROVIDED" {
+		return ""
+	}
+	return x.EndToEndID
+}
+
+func orEmptyParty(p *partyXML) *partyXML {
+	if p == nil {
+		return &partyXML{}
+	}
+	return p
+}
+
+func orEmptyAgent(a *agentXML) *agentXML {
+	if a == nil {
+		return &agentXML{}
+	}
+	return a
+}
@@ -0,0 +1,223 @@
+package iso20022
+
+import (
+	"fmt"
+
+	"github.com/Increase/increase-go"
+)
+
+func (t CreditTransfer) checkUSD() error {
+	if t.Currency != "" && t.Currency != "USD" {
+		return fmt.Errorf("iso20022: transfer %s is in %s, but only USD transfers can be sent", t.EndToEndID, t.Currency)
+	}
+	return nil
+}
+
+// WireTransferNewParams builds the parameters for sending the transfer as a
+// wire from the given account. The creditor and debtor addresses are sent as
+// unstructured lines.
+func (t CreditTransfer) WireTransferNewParams(accountID string) (increase.WireTransferNewParams, error) {
+	if err := t.checkUSD(); err != nil {
+		return increase.WireTransferNewParams{}, err
+	}
+	p := increase.WireTransferNewParams{
+		AccountID: increase.F(accountID),
+		Amount:    increase.F(t.Amount),
+		Creditor: increase.F(increase.WireTransferNewParamsCreditor{
+			Name: increase.F(t.Creditor.Name),
+		}),
+		Remittance: increase.F(increase.WireTransferNewParamsRemittance{
+			Category: increase.F(increase.WireTransferNewParamsRemittanceCategoryUnstructured),
+			Unstructured: increase.F(increase.WireTransferNewParamsRemittanceUnstructured{
+				Message: increase.F(t.RemittanceInformation),
+			}),
+		}),
+	}
+	if t.CreditorAccount != "" {
+		p.AccountNumber = increase.F(t.CreditorAccount)
+	}
+	if t.CreditorAgentRoutingNumber != "" {
+		p.RoutingNumber = increase.F(t.CreditorAgentRoutingNumber)
+	}
+	if lines := t.Creditor.Address.Lines(); len(lines) > 0 {
+		u := increase.WireTransferNewParamsCreditorAddressUnstructured{Line1: increase.F(lines[0])}
+		if len(lines) > 1 {
+			u.Line2 = increase.F(lines[1])
+		}
+		if len(lines) > 2 {
+			u.Line3 = increase.F(lines[2])
+		}
+		p.Creditor.Value.Address = increase.F(increase.WireTransferNewParamsCreditorAddress{Unstructured: increase.F(u)})
+	}
+	if t.Debtor.Name != "" {
+		d := increase.WireTransferNewParamsDebtor{Name: increase.F(t.Debtor.Name)}
+		if lines := t.Debtor.Address.Lines(); len(lines) > 0 {
+			u := increase.WireTransferNewParamsDebtorAddressUnstructured{Line1: increase.F(lines[0])}
+			if len(lines) > 1 {
+				u.Line2 = increase.F(lines[1])
+			}
+			if len(lines) > 2 {
+				u.Line3 = increase.F(lines[2])
+			}
+			d.Address = increase.F(increase.WireTransferNewParamsDebtorAddress{Unstructured: increase.F(u)})
+		}
+		p.Debtor = increase.F(d)
+	}
+	return p, nil
+}
+
+// FednowTransferNewParams builds the parameters for sending the transfer over
+// FedNow from the given Account Number. Addresses are only sent when they have
+// a town, postal code and state.
+func (t CreditTransfer) FednowTransferNewParams(sourceAccountNumberID string) (increase.FednowTransferNewParams, error) {
+	if err := t.checkUSD(); err != nil {
+		return increase.FednowTransferNewParams{}, err
+	}
+	p := increase.FednowTransferNewParams{
+		Amount:                            increase.F(t.Amount),
+		CreditorName:                      increase.F(t.Creditor.Name),
+		DebtorName:                        increase.F(t.Debtor.Name),
+		SourceAccountNumberID:             increase.F(sourceAccountNumberID),
+		UnstructuredRemittanceInformation: increase.F(t.RemittanceInformation),
+	}
+	if t.CreditorAccount != "" {
+		p.AccountNumber = increase.F(t.CreditorAccount)
+	}
+	if t.CreditorAgentRoutingNumber != "" {
+		p.RoutingNumber = increase.F(t.CreditorAgentRoutingNumber)
+	}
+	if a := t.Creditor.Address; hasUSLocality(a) {
+		addr := increase.FednowTransferNewParamsCreditorAddress{
+			City:       increase.F(a.TownName),
+			PostalCode: increase.F(a.PostalCode),
+			State:      increase.F(a.CountrySubDivision),
+		}
+		if street := a.Street(); street != "" {
+			addr.Line1 = increase.F(street)
+		}
+		p.CreditorAddress = increase.F(addr)
+	}
+	if a := t.Debtor.Address; hasUSLocality(a) {
+		addr := increase.FednowTransferNewParamsDebtorAddress{
+			City:       increase.F(a.TownName),
+			PostalCode: increase.F(a.PostalCode),
+			State:      increase.F(a.CountrySubDivision),
+		}
+		if street := a.Street(); street != "" {
+			addr.Line1 = increase.F(street)
+		}
+		p.DebtorAddress = increase.F(addr)
+	}
+	return p, nil
+}
+
+func hasUSLocality(a PostalAddress) bool {
+	return a.TownName != "" && a.PostalCode != "" && a.CountrySubDivision != ""
+}
+
+// RealTimePaymentsTransferNewParams builds the parameters for sending the
+// transfer over the RTP network from the given Account Number.
+func (t CreditTransfer) RealTimePaymentsTransferNewParams(sourceAccountNumberID string) (increase.RealTimePaymentsTransferNewParams, error) {
+	if err := t.checkUSD(); err != nil {
+		return increase.RealTimePaymentsTransferNewParams{}, err
+	}
+	p := increase.RealTimePaymentsTransferNewParams{
+		Amount:                            increase.F(t.Amount),
+		CreditorName:                      increase.F(t.Creditor.Name),
+		SourceAccountNumberID:             increase.F(sourceAccountNumberID),
+		UnstructuredRemittanceInformation: increase.F(t.RemittanceInformation),
+	}
+	if t.CreditorAccount != "" {
+		p.AccountNumber = increase.F(t.CreditorAccount)
+	}
+	if t.CreditorAgentRoutingNumber != "" {
+		p.RoutingNumber = increase.F(t.CreditorAgentRoutingNumber)
+	}
+	if t.Debtor.Name != "" {
+		p.DebtorName = increase.F(t.Debtor.Name)
+	}
+	return p, nil
+}
+
+// FromWireTransferNewParams builds a credit transfer from wire transfer
+// parameters. Transfers to an External Account have no creditor account or
+// agent, since those are only known to Increase.
+func FromWireTransferNewParams(p increase.WireTransferNewParams) CreditTransfer {
+	t := CreditTransfer{
+		Amount:                     p.Amount.Value,
+		Currency:                   "USD",
+		CreditorAccount:            p.AccountNumber.Value,
+		CreditorAgentRoutingNumber: p.RoutingNumber.Value,
+	}
+	c := p.Creditor.Value
+	t.Creditor.Name = c.Name.Value
+	if u := c.Address.Value.Unstructured.Value; u.Line1.Present {
+		t.Creditor.Address.AddressLines = nonEmpty(u.Line1.Value, u.Line2.Value, u.Line3.Value)
+	}
+	d := p.Debtor.Value
+	t.Debtor.Name = d.Name.Value
+	if u := d.Address.Value.Unstructured.Value; u.Line1.Present {
+		t.Debtor.Address.AddressLines = nonEmpty(u.Line1.Value, u.Line2.Value, u.Line3.Value)
+	}
+	if r := p.Remittance.Value; r.Category.Value == increase.WireTransferNewParamsRemittanceCategoryUnstructured {
+		t.RemittanceInformation = r.Unstructured.Value.Message.Value
+	}
+	return t
+}
+
+// FromFednowTransferNewParams builds a credit transfer from FedNow transfer
+// parameters.
+func FromFednowTransferNewParams(p increase.FednowTransferNewParams) CreditTransfer {
+	t := CreditTransfer{
+		Amount:                     p.Amount.Value,
+		Currency:                   "USD",
+		Creditor:                   Party{Name: p.CreditorName.Value},
+		CreditorAccount:            p.AccountNumber.Value,
+		CreditorAgentRoutingNumber: p.RoutingNumber.Value,
+		Debtor:                     Party{Name: p.DebtorName.Value},
+		RemittanceInformation:      p.UnstructuredRemittanceInformation.Value,
+	}
+	if a := p.CreditorAddress.Value; p.CreditorAddress.Present {
+		t.Creditor.Address = PostalAddress{
+			StreetName:         a.Line1.Value,
+			TownName:           a.City.Value,
+			PostalCode:         a.PostalCode.Value,
+			CountrySubDivision: a.State.Value,
+			Country:            "US",
+		}
+	}
+	if a := p.DebtorAddress.Value; p.DebtorAddress.Present {
+		t.Debtor.Address = PostalAddress{
+			StreetName:         a.Line1.Value,
+			TownName:           a.City.Value,
+			PostalCode:         a.PostalCode.Value,
+			CountrySubDivision: a.State.Value,
+			Country:            "US",
+		}
+	}
+	return t
+}
+
+// FromRealTimePaymentsTransferNewParams builds a credit transfer from
+// Real-Time Payments transfer parameters.
+func FromRealTimePaymentsTransferNewParams(p increase.RealTimePaymentsTransferNewParams) CreditTransfer {
+	return CreditTransfer{
+		Amount:                     p.Amount.Value,
+		Currency:                   "USD",
+		Creditor:                   Party{Name: p.CreditorName.Value},
+		CreditorAccount:            p.AccountNumber.Value,
+		CreditorAgentRoutingNumber: p.RoutingNumber.Value,
+		Debtor:                     Party{Name: p.DebtorName.Value},
+		RemittanceInformation:      p.UnstructuredRemittanceInformation.Value,
+	}
+}
+
+func nonEmpty(values ...string) []string {
+	var res []string
+	for _, v := range values {
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
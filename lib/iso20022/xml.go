@@ -0,0 +1,201 @@
+package iso20022
+
+import (
+	"strings"
+	"time"
+)
+
+// The element types below are shared by the pain, pacs and camt messages.
+// Only the components this package reads or writes are declared; unknown
+// elements are ignored when parsing.
+
+type partyXML struct {
+	Name    string            `xml:"Nm,omitempty"`
+	Address *postalAddressXML `xml:"PstlAdr,omitempty"`
+}
+
+type postalAddressXML struct {
+	StreetName         string   `xml:"StrtNm,omitempty"`
+	BuildingNumber     string   `xml:"BldgNb,omitempty"`
+	PostalCode         string   `xml:"PstCd,omitempty"`
+	TownName           string   `xml:"TwnNm,omitempty"`
+	CountrySubDivision string   `xml:"CtrySubDvsn,omitempty"`
+	Country            string   `xml:"Ctry,omitempty"`
+	AddressLines       []string `xml:"AdrLine,omitempty"`
+}
+
+type accountXML struct {
+	ID accountIDXML `xml:"Id"`
+}
+
+type accountIDXML struct {
+	IBAN  string        `xml:"IBAN,omitempty"`
+	Other *genericIDXML `xml:"Othr,omitempty"`
+}
+
+type genericIDXML struct {
+	ID string `xml:"Id"`
+}
+
+type agentXML struct {
+	FinancialInstitution financialInstitutionXML `xml:"FinInstnId"`
+}
+
+type financialInstitutionXML struct {
+	BIC                  string                   `xml:"BICFI,omitempty"`
+	ClearingSystemMember *clearingSystemMemberXML `xml:"ClrSysMmbId,omitempty"`
+}
+
+type clearingSystemMemberXML struct {
+	ClearingSystemID *clearingSystemIDXML `xml:"ClrSysId,omitempty"`
+	MemberID         string               `xml:"MmbId"`
+}
+
+type clearingSystemIDXML struct {
+	Code string `xml:"Cd"`
+}
+
+type amountXML struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type remittanceXML struct {
+	Unstructured []string `xml:"Ustrd"`
+}
+
+type paymentIDXML struct {
+	InstructionID string `xml:"InstrId,omitempty"`
+	EndToEndID    string `xml:"EndToEndId"`
+	TransactionID string `xml:"TxId,omitempty"`
+	UETR          string `xml:"UETR,omitempty"`
+}
+
+func newPartyXML(p Party) *partyXML {
+	if p.Name == "" && p.Address.IsZero() {
+		return nil
+	}
+	x := &partyXML{Name: p.Name}
+	if !p.Address.IsZero() {
+		a := postalAddressXML(p.Address)
+		x.Address = &a
+	}
+	return x
+}
+
+func (x *partyXML) party() Party {
+	if x == nil {
+		return Party{}
+	}
+	p := Party{Name: strings.TrimSpace(x.Name)}
+	if x.Address != nil {
+		p.Address = PostalAddress(*x.Address)
+	}
+	return p
+}
+
+func newAccountXML(number string) *accountXML {
+	if number == "" {
+		return nil
+	}
+	return &accountXML{ID: accountIDXML{Other: &genericIDXML{ID: number}}}
+}
+
+func (x *accountXML) number() string {
+	switch {
+	case x == nil:
+		return ""
+	case x.ID.IBAN != "":
+		return x.ID.IBAN
+	case x.ID.Other != nil:
+		return strings.TrimSpace(x.ID.Other.ID)
+	}
+	return ""
+}
+
+func newAgentXML(routingNumber string) *agentXML {
+	if routingNumber == "" {
+		return nil
+	}
+	return &agentXML{FinancialInstitution: financialInstitutionXML{
+		ClearingSystemMember: &clearingSystemMemberXML{
+			ClearingSystemID: &clearingSystemIDXML{Code: ClearingSystemUSABA},
+			MemberID:         routingNumber,
+		},
+	}}
+}
+
+// routingNumber returns the ABA routing number of the agent. Members of other
+// clearing systems, and agents only identified by BIC, have none.
+func (x *agentXML) routingNumber() string {
+	if x == nil || x.FinancialInstitution.ClearingSystemMember == nil {
+		return ""
+	}
+	m := x.FinancialInstitution.ClearingSystemMember
+	if m.ClearingSystemID != nil && m.ClearingSystemID.Code != ClearingSystemUSABA {
+		return ""
+	}
+	return strings.TrimSpace(m.MemberID)
+}
+
+func newRemittanceXML(info string) *remittanceXML {
+	if info == "" {
+		return nil
+	}
+	// Unstructured remittance lines are limited to 140 characters each. Lines
+	// break at a space, which is dropped since parsing joins lines with one.
+	// Words longer than a line are broken where they must be.
+	var lines []string
+	r := []rune(info)
+	for len(r) > 140 {
+		n, next := 140, 140
+		for i := 140; i > 0; i-- {
+			if r[i] == ' ' {
+				n, next = i, i+1
+				break
+			}
+		}
+		lines = append(lines, string(r[:n]))
+		r = r[next:]
+	}
+	lines = append(lines, string(r))
+	return &remittanceXML{Unstructured: lines}
+}
+
+func (x *remittanceXML) text() string {
+	if x == nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(x.Unstructured, " "))
+}
+
+// dateXML is a date or date-time choice. Older message versions carry a bare
+// date as character data instead.
+type dateXML struct {
+	Date     string `xml:"Dt,omitempty"`
+	DateTime string `xml:"DtTm,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+func (x *dateXML) time() (time.Time, error) {
+	switch {
+	case x == nil:
+		return time.Time{}, nil
+	case x.Date != "":
+		return time.Parse("2006-01-02", strings.TrimSpace(x.Date))
+	case x.DateTime != "":
+		return parseDateTime(x.DateTime)
+	case strings.TrimSpace(x.Value) != "":
+		return time.Parse("2006-01-02", strings.TrimSpace(x.Value))
+	}
+	return time.Time{}, nil
+}
+
+// parseDateTime parses an ISODateTime, which may omit the UTC offset.
+func parseDateTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", s)
+}
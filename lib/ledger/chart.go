@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/Increase/increase-go"
+)
+
+// AccountType is the type of a ledger account.
+type AccountType string
+
+const (
+	AccountTypeAsset     AccountType = "asset"
+	AccountTypeLiability AccountType = "liability"
+	AccountTypeEquity    AccountType = "equity"
+	AccountTypeRevenue   AccountType = "revenue"
+	AccountTypeExpense   AccountType = "expense"
+)
+
+// DebitNormal reports whether accounts of the type increase with debits.
+func (t AccountType) DebitNormal() bool {
+	return t == AccountTypeAsset || t == AccountTypeExpense
+}
+
+// Account is an account of the chart of accounts.
+type Account struct {
+	Code string
+	Name string
+	Type AccountType
+}
+
+// Rules map Increase activity to ledger accounts. Every Transaction moves
+// money between the cash account of its Increase Account and a counter
+// account chosen by its source category.
+type Rules struct {
+	// The cash account of each Increase Account, by Account ID. Accounts
+	// without one use Cash.
+	CashAccounts map[string]string
+	Cash         string
+	// The counter account of each Transaction and Pending Transaction source
+	// category, such as "card_settlement" or "ach_transfer_instruction".
+	Categories map[string]string
+	// The counter account of activity without a rule.
+	Suspense string
+	// Chooses the counter account of individual Transactions before
+	// Categories, such as card settlements by merchant category. Optional.
+	Classify func(tx increase.Transaction) (string, bool)
+}
+
+// DefaultChart is a small chart of accounts for an operating business.
+var DefaultChart = []Account{
+	{"1000", "Cash at Increase", AccountTypeAsset},
+	{"1100", "Accounts Receivable", AccountTypeAsset},
+	{"1200", "Internal Transfers", AccountTypeAsset},
+	{"1900", "Suspense", AccountTypeAsset},
+	{"2000", "Accounts Payable", AccountTypeLiability},
+	{"3000", "Owner's Equity", AccountTypeEquity},
+	{"4000", "Interest Income", AccountTypeRevenue},
+	{"4100", "Rewards Income", AccountTypeRevenue},
+	{"5000", "Card Expenses", AccountTypeExpense},
+	{"5100", "Bank Fees", AccountTypeExpense},
+}
+
+// DefaultRules map the source categories of Increase activity to
+// [DefaultChart].
+var DefaultRules = Rules{
+	Cash:     "1000",
+	Suspense: "1900",
+	Categories: map[string]string{
+		"account_transfer_intention":                       "1200",
+		"account_transfer_instruction":                     "1200",
+		"ach_transfer_intention":                           "2000",
+		"ach_transfer_instruction":                         "2000",
+		"ach_transfer_rejection":                           "2000",
+		"ach_transfer_return":                              "2000",
+		"card_authorization":                               "5000",
+		"card_settlement":                                  "5000",
+		"card_refund":                                      "5000",
+		"card_financial":                                   "5000",
+		"card_dispute_acceptance":                          "5000",
+		"card_dispute_financial":                           "5000",
+		"card_dispute_loss":                                "5000",
+		"card_push_transfer_acceptance":                    "2000",
+		"card_push_transfer_instruction":                   "2000",
+		"cashback_payment":                                 "4100",
+		"card_revenue_payment":                             "4100",
+		"account_revenue_payment":                          "4100",
+		"check_deposit_acceptance":                         "1100",
+		"check_deposit_instruction":                        "1100",
+		"check_deposit_return":                             "1100",
+		"check_transfer_deposit":                           "2000",
+		"check_transfer_instruction":                       "2000",
+		"fee_payment":                                      "5100",
+		"fednow_transfer_acknowledgement":                  "2000",
+		"fednow_transfer_instruction":                      "2000",
+		"inbound_ach_transfer":                             "1100",
+		"inbound_ach_transfer_return_intention":            "1100",
+		"inbound_check_deposit_return_intention":           "2000",
+		"inbound_check_adjustment":                         "2000",
+		"inbound_fednow_transfer_confirmation":             "1100",
+		"inbound_real_time_payments_transfer_confirmation": "1100",
+		"inbound_wire_reversal":                            "2000",
+		"inbound_wire_transfer":                            "1100",
+		"inbound_wire_transfer_reversal":                   "1100",
+		"interest_payment":                                 "4000",
+		"real_time_payments_transfer_acknowledgement":      "2000",
+		"real_time_payments_transfer_instruction":          "2000",
+		"sample_funds":                                     "3000",
+		"swift_transfer_intention":                         "2000",
+		"swift_transfer_instruction":                       "2000",
+		"swift_transfer_return":                            "2000",
+		"wire_transfer_intention":                          "2000",
+		"wire_transfer_instruction":                        "2000",
+	},
+}
+
+// validate checks that the rules only use accounts of the chart.
+func (r Rules) validate(accounts map[string]Account) error {
+	check := func(what, code string) error {
+		if _, ok := accounts[code]; !ok {
+			return fmt.Errorf("ledger: %s account %q is not in the chart of accounts", what, code)
+		}
+		return nil
+	}
+	if err := check("cash", r.Cash); err != nil {
+		return err
+	}
+	if err := check("suspense", r.Suspense); err != nil {
+		return err
+	}
+	for id, code := range r.CashAccounts {
+		if err := check("cash account "+id, code); err != nil {
+			return err
+		}
+	}
+	for category, code := range r.Categories {
+		if err := check(category, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+// Package ledger projects Increase Transactions and Pending Transactions onto
+// a double-entry general ledger.
+//
+// Every Transaction becomes a balanced journal [Entry] between the cash
+// account of its Increase Account and a counter account chosen by [Rules]
+// from its source category. Pending Transactions are journaled as pending
+// entries that are settled by the Transaction that posts them, or voided when
+// they complete without one. Returns and reversals post against the counter
+// account of the entry they reverse.
+//
+//	l, err := ledger.New(ledger.DefaultChart, ledger.DefaultRules)
+//	for _, tx := range transactions {
+//		l.RecordTransaction(tx)
+//	}
+//	tie, err := l.Tie(ctx, client, "account_in71c4amph0vgo2qllky")
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrUnknownEntry is returned when reversing an entry the ledger does not
+// have.
+var ErrUnknownEntry = errors.New("ledger: unknown entry")
+
+// Status is the status of a journal entry.
+type Status string
+
+const (
+	// The entry of an open Pending Transaction.
+	StatusPending Status = "pending"
+	// The entry of a Transaction.
+	StatusPosted Status = "posted"
+	// The entry of a Pending Transaction that a Transaction posted.
+	StatusSettled Status = "settled"
+	// The entry of a Pending Transaction that completed without posting.
+	StatusVoided Status = "voided"
+	// A posted entry that was fully reversed by later entries.
+	StatusReversed Status = "reversed"
+)
+
+// Line is a debit or a credit of a ledger account, in the minor unit of the
+// entry's currency.
+type Line struct {
+	Account string
+	Debit   int64
+	Credit  int64
+}
+
+// Entry is a journal entry.
+type Entry struct {
+	// The ID of the Transaction or Pending Transaction, or of the entry it
+	// reverses followed by "_reversal" for manual reversals.
+	ID string
+	// The Increase Account.
+	AccountID   string
+	Date        time.Time
+	Description string
+	// The source category of the Transaction or Pending Transaction.
+	Category string
+	Currency string
+	Status   Status
+	Lines    []Line
+	// For posted Transactions, the pending entry they settled.
+	PendingID string
+	// The entry this entry reverses, and the entries that reverse it.
+	Reverses   string
+	ReversedBy []string
+
+	amount int64
+	// The counter account and the key that links pending and posted entries.
+	counter string
+	link    string
+}
+
+// Balanced reports whether the debits of the entry equal its credits.
+func (e Entry) Balanced() bool {
+	var debits, credits int64
+	for _, l := range e.Lines {
+		debits += l.Debit
+		credits += l.Credit
+	}
+	return debits == credits
+}
+
+// Ledger is a general ledger. Its methods are safe for concurrent use.
+type Ledger struct {
+	accounts map[string]Account
+	rules    Rules
+
+	mu      sync.Mutex
+	entries []*Entry
+	byID    map[string]*Entry
+	// Open pending entries, and the entries of posted Transactions, by link.
+	pending map[string]*Entry
+	posted  map[string]*Entry
+}
+
+// New returns an empty ledger. The rules may only use accounts of the chart.
+func New(chart []Account, rules Rules) (*Ledger, error) {
+	accounts := map[string]Account{}
+	for _, a := range chart {
+		if _, ok := accounts[a.Code]; ok {
+			return nil, fmt.Errorf("ledger: account %q appears twice in the chart of accounts", a.Code)
+		}
+		accounts[a.Code] = a
+	}
+	if err := rules.validate(accounts); err != nil {
+		return nil, err
+	}
+	return &Ledger{
+		accounts: accounts,
+		rules:    rules,
+		byID:     map[string]*Entry{},
+		pending:  map[string]*Entry{},
+		posted:   map[string]*Entry{},
+	}, nil
+}
+
+// Account returns an account of the chart.
+func (l *Ledger) Account(code string) (Account, bool) {
+	a, ok := l.accounts[code]
+	return a, ok
+}
+
+func (l *Ledger) cash(accountID string) string {
+	if code, ok := l.rules.CashAccounts[accountID]; ok {
+		return code
+	}
+	return l.rules.Cash
+}
+
+func (l *Ledger) counter(category string) string {
+	if code, ok := l.rules.Categories[category]; ok {
+		return code
+	}
+	return l.rules.Suspense
+}
+
+// journal sets the lines of an entry that moves amount into cash from
+// counter.
+func journal(e *Entry, cash, counter string, amount int64) {
+	e.amount, e.counter = amount, counter
+	switch {
+	case amount > 0:
+		e.Lines = []Line{{Account: cash, Debit: amount}, {Account: counter, Credit: amount}}
+	case amount < 0:
+		e.Lines = []Line{{Account: counter, Debit: -amount}, {Account: cash, Credit: -amount}}
+	}
+}
+
+// RecordPending records a Pending Transaction. Recording it again once it
+// completes voids its entry, unless a Transaction posted it.
+func (l *Ledger) RecordPending(p increase.PendingTransaction) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.byID[p.ID]
+	if !ok {
+		e = &Entry{
+			ID:          p.ID,
+			AccountID:   p.AccountID,
+			Date:        p.CreatedAt,
+			Description: p.Description,
+			Category:    string(p.Source.Category),
+			Currency:    string(p.Currency),
+			Status:      StatusPending,
+			link:        pendingLink(p),
+		}
+		journal(e, l.cash(p.AccountID), l.counter(e.Category), p.Amount)
+		l.add(e)
+		if e.link != "" {
+			l.pending[e.link] = e
+		}
+	}
+	if p.Status == increase.PendingTransactionStatusComplete && e.Status == StatusPending {
+		e.Status = StatusVoided
+		delete(l.pending, e.link)
+	}
+	return *e
+}
+
+// RecordTransaction records a Transaction. It posts the pending entry the
+// Transaction settles, and reverses the entry of the Transaction it returns.
+// Transactions are recorded once.
+func (l *Ledger) RecordTransaction(tx increase.Transaction) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.byID[tx.ID]; ok {
+		return *e
+	}
+	e := &Entry{
+		ID:          tx.ID,
+		AccountID:   tx.AccountID,
+		Date:        tx.CreatedAt,
+		Description: tx.Description,
+		Category:    string(tx.Source.Category),
+		Currency:    string(tx.Currency),
+		Status:      StatusPosted,
+	}
+	original, ok := l.byID[reversedTransactionID(tx)]
+	if !ok {
+		original, ok = l.posted[reversedLink(tx)]
+	}
+	if ok && original.Status != StatusPending {
+		// Returns post against the counter account of what they return.
+		journal(e, l.cash(tx.AccountID), original.counter, tx.Amount)
+		e.Reverses = original.ID
+		original.ReversedBy = append(original.ReversedBy, e.ID)
+		l.add(e)
+		if l.net(original) == 0 {
+			original.Status = StatusReversed
+		}
+		return *e
+	}
+
+	counter := ""
+	if l.rules.Classify != nil {
+		if code, ok := l.rules.Classify(tx); ok {
+			if _, known := l.accounts[code]; known {
+				counter = code
+			}
+		}
+	}
+	if counter == "" {
+		counter = l.counter(e.Category)
+	}
+	journal(e, l.cash(tx.AccountID), counter, tx.Amount)
+	if e.link = postedLink(tx); e.link != "" {
+		if p, ok := l.pending[e.link]; ok {
+			p.Status = StatusSettled
+			e.PendingID = p.ID
+			delete(l.pending, e.link)
+		}
+		l.posted[e.link] = e
+	}
+	l.add(e)
+	return *e
+}
+
+// net returns the amount of an entry plus the amounts of its reversals.
+func (l *Ledger) net(e *Entry) int64 {
+	n := e.amount
+	for _, id := range e.ReversedBy {
+		n += l.byID[id].amount
+	}
+	return n
+}
+
+// Reverse records an entry that reverses what remains of a posted entry, such
+// as to correct a Transaction that was mapped to the wrong account.
+func (l *Ledger) Reverse(id string, date time.Time, description string) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	original, ok := l.byID[id]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w %s", ErrUnknownEntry, id)
+	}
+	if original.Status != StatusPosted {
+		return Entry{}, fmt.Errorf("ledger: entry %s is %s, not posted", id, original.Status)
+	}
+	if original.Reverses != "" {
+		return Entry{}, fmt.Errorf("ledger: entry %s is a reversal", id)
+	}
+	e := &Entry{
+		ID:          id + "_reversal",
+		AccountID:   original.AccountID,
+		Date:        date,
+		Description: description,
+		Category:    original.Category,
+		Currency:    original.Currency,
+		Status:      StatusPosted,
+		Reverses:    id,
+	}
+	if _, ok := l.byID[e.ID]; ok {
+		return Entry{}, fmt.Errorf("ledger: entry %s was already reversed", id)
+	}
+	journal(e, l.cash(original.AccountID), original.counter, -l.net(original))
+	original.ReversedBy = append(original.ReversedBy, e.ID)
+	original.Status = StatusReversed
+	l.add(e)
+	return *e, nil
+}
+
+func (l *Ledger) add(e *Entry) {
+	l.entries = append(l.entries, e)
+	l.byID[e.ID] = e
+}
+
+// Entry returns an entry by ID.
+func (l *Ledger) Entry(id string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.byID[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Entries returns the entries in the order they were recorded.
+func (l *Ledger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	res := make([]Entry, len(l.entries))
+	for i, e := range l.entries {
+		res[i] = *e
+	}
+	return res
+}
+
+// counts reports whether an entry's lines are in the books. Posted entries
+// stay in the books when they are reversed, alongside their reversals.
+func (e *Entry) counts(includePending bool) bool {
+	switch e.Status {
+	case StatusPosted, StatusReversed:
+		return true
+	case StatusPending:
+		return includePending
+	}
+	return false
+}
+
+// TrialBalanceRow is the balance of a ledger account.
+type TrialBalanceRow struct {
+	Account Account
+	Debits  int64
+	Credits int64
+}
+
+// Balance returns the balance of the account on its normal side.
+func (r TrialBalanceRow) Balance() int64 {
+	if r.Account.Type.DebitNormal() {
+		return r.Debits - r.Credits
+	}
+	return r.Credits - r.Debits
+}
+
+// TrialBalance is the debits and credits of every ledger account.
+type TrialBalance struct {
+	Rows    []TrialBalanceRow
+	Debits  int64
+	Credits int64
+}
+
+// Balanced reports whether total debits equal total credits.
+func (tb TrialBalance) Balanced() bool {
+	return tb.Debits == tb.Credits
+}
+
+// Row returns the row of an account.
+func (tb TrialBalance) Row(code string) (TrialBalanceRow, bool) {
+	for _, r := range tb.Rows {
+		if r.Account.Code == code {
+			return r, true
+		}
+	}
+	return TrialBalanceRow{}, false
+}
+
+// TrialBalance returns the trial balance of posted entries, and of open
+// pending entries when includePending is set. Rows are sorted by account
+// code and only accounts with activity are included.
+func (l *Ledger) TrialBalance(includePending bool) TrialBalance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rows := map[string]*TrialBalanceRow{}
+	var tb TrialBalance
+	for _, e := range l.entries {
+		if !e.counts(includePending) {
+			continue
+		}
+		for _, line := range e.Lines {
+			r, ok := rows[line.Account]
+			if !ok {
+				r = &TrialBalanceRow{Account: l.accounts[line.Account]}
+				rows[line.Account] = r
+			}
+			r.Debits += line.Debit
+			r.Credits += line.Credit
+			tb.Debits += line.Debit
+			tb.Credits += line.Credit
+		}
+	}
+	for _, r := range rows {
+		tb.Rows = append(tb.Rows, *r)
+	}
+	sort.Slice(tb.Rows, func(i, j int) bool { return tb.Rows[i].Account.Code < tb.Rows[j].Account.Code })
+	return tb
+}
+
+// Tie compares the ledger's cash of an Increase Account with its balance.
+type Tie struct {
+	AccountID string
+	// The cash of the Account's posted entries, and the cash less its open
+	// pending debits.
+	LedgerCurrent   int64
+	LedgerAvailable int64
+	Balance         increase.BalanceLookup
+}
+
+// Ties reports whether the ledger agrees with the balance.
+func (t Tie) Ties() bool {
+	return t.LedgerCurrent == t.Balance.CurrentBalance && t.LedgerAvailable == t.Balance.AvailableBalance
+}
+
+// Tie retrieves the balance of an Increase Account with
+// [increase.AccountService.Balance] and compares it with the ledger. The
+// ledger must hold every Transaction and open Pending Transaction of the
+// Account.
+func (l *Ledger) Tie(ctx context.Context, client *increase.Client, accountID string, opts ...option.RequestOption) (*Tie, error) {
+	balance, err := client.Accounts.Balance(ctx, accountID, increase.AccountBalanceParams{}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("ledger: retrieving balance of %s: %w", accountID, err)
+	}
+	t := &Tie{AccountID: accountID, Balance: *balance}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.entries {
+		if e.AccountID != accountID {
+			continue
+		}
+		switch e.Status {
+		case StatusPosted, StatusReversed:
+			t.LedgerCurrent += e.amount
+			t.LedgerAvailable += e.amount
+		case StatusPending:
+			// Pending credits are not available until they post.
+			if e.amount < 0 {
+				t.LedgerAvailable += e.amount
+			}
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,165 @@
+package ledger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/ledger"
+)
+
+func transaction(t *testing.T, id string, amount int64, category, source string) increase.Transaction {
+	var tx increase.Transaction
+	body := fmt.Sprintf(`{"id": %q, "account_id": "account_1", "amount": %d, "currency": "USD", "created_at": "2024-03-01T00:00:00Z", "source": {"category": %q, %q: %s}}`, id, amount, category, category, source)
+	if err := json.Unmarshal([]byte(body), &tx); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return tx
+}
+
+func pending(t *testing.T, id string, amount int64, status, category, source string) increase.PendingTransaction {
+	var p increase.PendingTransaction
+	body := fmt.Sprintf(`{"id": %q, "account_id": "account_1", "amount": %d, "currency": "USD", "status": %q, "created_at": "2024-03-01T00:00:00Z", "source": {"category": %q, %q: %s}}`, id, amount, status, category, category, source)
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return p
+}
+
+func balance(t *testing.T, tb ledger.TrialBalance, code string) int64 {
+	row, ok := tb.Row(code)
+	if !ok {
+		return 0
+	}
+	return row.Balance()
+}
+
+func TestLedger(t *testing.T) {
+	l, err := ledger.New(ledger.DefaultChart, ledger.DefaultRules)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	l.RecordTransaction(transaction(t, "tx_funds", 100000, "sample_funds", `{"originator": "Increase"}`))
+
+	// A card authorization that settles.
+	if e := l.RecordPending(pending(t, "pending_auth", -5000, "pending", "card_authorization", `{"id": "card_authorization_1"}`)); e.Status != ledger.StatusPending || !e.Balanced() {
+		t.Errorf("unexpected pending entry %+v", e)
+	}
+	if tb := l.TrialBalance(false); balance(t, tb, "5000") != 0 {
+		t.Errorf("expected pending entries to be excluded, got %+v", tb)
+	}
+	if tb := l.TrialBalance(true); balance(t, tb, "5000") != 5000 {
+		t.Errorf("expected pending entries to be included, got %+v", tb)
+	}
+	settlement := l.RecordTransaction(transaction(t, "tx_settlement", -5000, "card_settlement", `{"pending_transaction_id": "pending_auth"}`))
+	if settlement.PendingID != "pending_auth" {
+		t.Errorf("expected the settlement to post the authorization, got %+v", settlement)
+	}
+	if e, _ := l.Entry("pending_auth"); e.Status != ledger.StatusSettled {
+		t.Errorf("unexpected pending entry %+v", e)
+	}
+
+	// An ACH transfer that posts and is returned.
+	l.RecordPending(pending(t, "pending_ach", -10000, "pending", "ach_transfer_instruction", `{"transfer_id": "ach_transfer_1", "amount": 10000}`))
+	l.RecordTransaction(transaction(t, "tx_ach", -10000, "ach_transfer_intention", `{"transfer_id": "ach_transfer_1", "amount": 10000}`))
+	if e := l.RecordTransaction(transaction(t, "tx_return", 10000, "ach_transfer_return", `{"transaction_id": "tx_ach", "transfer_id": "ach_transfer_1"}`)); e.Reverses != "tx_ach" || e.Lines[1].Account != "2000" {
+		t.Errorf("unexpected return %+v", e)
+	}
+	if e, _ := l.Entry("tx_ach"); e.Status != ledger.StatusReversed || len(e.ReversedBy) != 1 {
+		t.Errorf("unexpected reversed entry %+v", e)
+	}
+	if e, _ := l.Entry("pending_ach"); e.Status != ledger.StatusSettled {
+		t.Errorf("unexpected pending entry %+v", e)
+	}
+
+	l.RecordTransaction(transaction(t, "tx_interest", 100, "interest_payment", `{"accrued_on_account_id": "account_1"}`))
+	l.RecordTransaction(transaction(t, "tx_other", -50, "other", `{}`))
+	// Recording a Transaction twice is a no-op.
+	l.RecordTransaction(transaction(t, "tx_interest", 100, "interest_payment", `{"accrued_on_account_id": "account_1"}`))
+
+	// An open authorization, and a hold that was released.
+	l.RecordPending(pending(t, "pending_open", -700, "pending", "card_authorization", `{"id": "card_authorization_2"}`))
+	l.RecordPending(pending(t, "pending_hold", -300, "pending", "user_initiated_hold", `{}`))
+	if e := l.RecordPending(pending(t, "pending_hold", -300, "complete", "user_initiated_hold", `{}`)); e.Status != ledger.StatusVoided {
+		t.Errorf("unexpected voided entry %+v", e)
+	}
+
+	tb := l.TrialBalance(false)
+	if !tb.Balanced() {
+		t.Errorf("expected the trial balance to balance, got %+v", tb)
+	}
+	for code, expected := range map[string]int64{"1000": 95050, "1900": 50, "2000": 0, "3000": 100000, "4000": 100, "5000": 5000} {
+		if got := balance(t, tb, code); got != expected {
+			t.Errorf("account %s: expected %d, got %d", code, expected, got)
+		}
+	}
+
+	var balances string
+	client := testapi.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(balances))
+	}))
+
+	balances = `{"account_id": "account_1", "current_balance": 95050, "available_balance": 94350, "type": "balance_lookup"}`
+	tie, err := l.Tie(context.Background(), client, "account_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !tie.Ties() {
+		t.Errorf("expected the ledger to tie, got %+v", tie)
+	}
+	balances = `{"account_id": "account_1", "current_balance": 95000, "available_balance": 94300, "type": "balance_lookup"}`
+	if tie, err := l.Tie(context.Background(), client, "account_1"); err != nil || tie.Ties() {
+		t.Errorf("expected the ledger not to tie, got %+v, %v", tie, err)
+	}
+
+	// Correct the unmapped Transaction.
+	reversal, err := l.Reverse("tx_other", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "Reclassify")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if reversal.ID != "tx_other_reversal" || balance(t, l.TrialBalance(false), "1900") != 0 {
+		t.Errorf("unexpected reversal %+v", reversal)
+	}
+	if _, err := l.Reverse("tx_other", time.Now(), ""); err == nil {
+		t.Errorf("expected reversed entries not to be reversed again")
+	}
+	if _, err := l.Reverse("tx_missing", time.Now(), ""); !errors.Is(err, ledger.ErrUnknownEntry) {
+		t.Errorf("expected ErrUnknownEntry, got %v", err)
+	}
+	for _, e := range l.Entries() {
+		if !e.Balanced() {
+			t.Errorf("unbalanced entry %+v", e)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	rules := ledger.DefaultRules
+	rules.Classify = func(tx increase.Transaction) (string, bool) {
+		if tx.Source.Category == increase.TransactionSourceCategoryCardSettlement && tx.Source.CardSettlement.MerchantCategoryCode == "5734" {
+			return "5200", true
+		}
+		return "", false
+	}
+	chart := append(append([]ledger.Account{}, ledger.DefaultChart...), ledger.Account{Code: "5200", Name: "Software", Type: ledger.AccountTypeExpense})
+	l, err := ledger.New(chart, rules)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	e := l.RecordTransaction(transaction(t, "tx_1", -1000, "card_settlement", `{"merchant_category_code": "5734"}`))
+	if e.Lines[0].Account != "5200" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	if _, err := ledger.New(ledger.DefaultChart[1:], ledger.DefaultRules); err == nil || !strings.Contains(err.Error(), `"1000"`) {
+		t.Errorf("expected rules to be validated against the chart, got %v", err)
+	}
+}
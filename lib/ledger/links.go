@@ -0,0 +1,97 @@
+package ledger
+
+import "github.com/Increase/increase-go"
+
+// pendingLink returns the key that links a Pending Transaction to the
+// Transaction that posts it.
+func pendingLink(p increase.PendingTransaction) string {
+	s := p.Source
+	switch s.Category {
+	case increase.PendingTransactionSourceCategoryCardAuthorization:
+		return "pending_transaction:" + p.ID
+	case increase.PendingTransactionSourceCategoryAccountTransferInstruction:
+		return "transfer:" + s.AccountTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryACHTransferInstruction:
+		return "transfer:" + s.ACHTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryCardPushTransferInstruction:
+		return "transfer:" + s.CardPushTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryCheckDepositInstruction:
+		if s.CheckDepositInstruction.CheckDepositID != "" {
+			return "check_deposit:" + s.CheckDepositInstruction.CheckDepositID
+		}
+	case increase.PendingTransactionSourceCategoryCheckTransferInstruction:
+		return "transfer:" + s.CheckTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryFednowTransferInstruction:
+		return "transfer:" + s.FednowTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryRealTimePaymentsTransferInstruction:
+		return "transfer:" + s.RealTimePaymentsTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategorySwiftTransferInstruction:
+		return "transfer:" + s.SwiftTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryWireTransferInstruction:
+		return "transfer:" + s.WireTransferInstruction.TransferID
+	}
+	return ""
+}
+
+// postedLink returns the key that links a Transaction to the Pending
+// Transaction it posts.
+func postedLink(tx increase.Transaction) string {
+	s := tx.Source
+	switch s.Category {
+	case increase.TransactionSourceCategoryCardSettlement:
+		if s.CardSettlement.PendingTransactionID != "" {
+			return "pending_transaction:" + s.CardSettlement.PendingTransactionID
+		}
+	case increase.TransactionSourceCategoryAccountTransferIntention:
+		return "transfer:" + s.AccountTransferIntention.TransferID
+	case increase.TransactionSourceCategoryACHTransferIntention:
+		return "transfer:" + s.ACHTransferIntention.TransferID
+	case increase.TransactionSourceCategoryCardPushTransferAcceptance:
+		return "transfer:" + s.CardPushTransferAcceptance.TransferID
+	case increase.TransactionSourceCategoryCheckDepositAcceptance:
+		return "check_deposit:" + s.CheckDepositAcceptance.CheckDepositID
+	case increase.TransactionSourceCategoryCheckTransferDeposit:
+		if s.CheckTransferDeposit.TransferID != "" {
+			return "transfer:" + s.CheckTransferDeposit.TransferID
+		}
+	case increase.TransactionSourceCategoryFednowTransferAcknowledgement:
+		return "transfer:" + s.FednowTransferAcknowledgement.TransferID
+	case increase.TransactionSourceCategoryRealTimePaymentsTransferAcknowledgement:
+		return "transfer:" + s.RealTimePaymentsTransferAcknowledgement.TransferID
+	case increase.TransactionSourceCategorySwiftTransferIntention:
+		return "transfer:" + s.SwiftTransferIntention.TransferID
+	case increase.TransactionSourceCategoryWireTransferIntention:
+		return "transfer:" + s.WireTransferIntention.TransferID
+	}
+	return ""
+}
+
+// reversedTransactionID returns the ID of the Transaction a return or
+// reversal Transaction reverses.
+func reversedTransactionID(tx increase.Transaction) string {
+	s := tx.Source
+	switch s.Category {
+	case increase.TransactionSourceCategoryACHTransferReturn:
+		return s.ACHTransferReturn.TransactionID
+	case increase.TransactionSourceCategoryCheckDepositReturn:
+		return s.CheckDepositReturn.TransactionID
+	case increase.TransactionSourceCategoryInboundWireReversal:
+		return s.InboundWireReversal.TransactionID
+	case increase.TransactionSourceCategoryCardDisputeAcceptance:
+		return s.CardDisputeAcceptance.TransactionID
+	}
+	return ""
+}
+
+// reversedLink returns the link of the Transaction a return Transaction
+// reverses, for returns that only name the transfer.
+func reversedLink(tx increase.Transaction) string {
+	s := tx.Source
+	switch s.Category {
+	case increase.TransactionSourceCategoryACHTransferRejection:
+		return "transfer:" + s.ACHTransferRejection.TransferID
+	case increase.TransactionSourceCategorySwiftTransferReturn:
+		return "transfer:" + s.SwiftTransferReturn.TransferID
+	}
+	return ""
+}
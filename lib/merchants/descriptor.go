@@ -0,0 +1,107 @@
+package merchants
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Dictionary maps normalized descriptors to merchant names.
+type Dictionary interface {
+	// Lookup returns the merchant name of a descriptor that has been cleaned
+	// by [Clean].
+	Lookup(descriptor string) (string, bool)
+}
+
+// PrefixDictionary is a Dictionary of descriptor prefixes, such as "AMZN MKTP"
+// for Amazon. The longest matching prefix wins. Prefixes are compared after
+// [Clean].
+type PrefixDictionary map[string]string
+
+func (d PrefixDictionary) Lookup(descriptor string) (string, bool) {
+	prefixes := make([]string, 0, len(d))
+	for p := range d {
+		prefixes = append(prefixes, p)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, p := range prefixes {
+		key := Clean(p)
+		if key != "" && (descriptor == key || strings.HasPrefix(descriptor, key+" ")) {
+			return d[p], true
+		}
+	}
+	return "", false
+}
+
+// DefaultDictionary recognizes common merchants whose descriptors do not
+// clean up to their names.
+var DefaultDictionary = PrefixDictionary{
+	"AMZN":           "Amazon",
+	"AMAZON":         "Amazon",
+	"AMZN MKTP":      "Amazon Marketplace",
+	"AMAZON MKTPL":   "Amazon Marketplace",
+	"AWS":            "Amazon Web Services",
+	"APPLE COM BILL": "Apple",
+	"GOOGLE":         "Google",
+	"MSFT":           "Microsoft",
+	"UBER":           "Uber",
+	"UBER EATS":      "Uber Eats",
+	"LYFT":           "Lyft",
+	"DD DOORDASH":    "DoorDash",
+	"DOORDASH":       "DoorDash",
+	"WM SUPERCENTER": "Walmart",
+	"WAL MART":       "Walmart",
+	"SBUX":           "Starbucks",
+	"STARBUCKS":      "Starbucks",
+	"NETFLIX":        "Netflix",
+	"SPOTIFY":        "Spotify",
+}
+
+var (
+	// Payment facilitators prefix the merchant's name with their own, such as
+	// "SQ *BLUE BOTTLE" or "TST* JOE'S".
+	facilitator = regexp.MustCompile(`^(PAYPAL|SUMUP|\w{2,3})\s?\*\s*`)
+	// Store numbers, order references and phone numbers.
+	references = regexp.MustCompile(`\s(#\s?\w+|\d[\d\-*]{2,}\w*|\*\w+|\d{3}[\-.\s]?\d{3}[\-.\s]?\d{4})`)
+	domain     = regexp.MustCompile(`(?i)\.(com|net|org|io|co)\b`)
+	spaces     = regexp.MustCompile(`\s+`)
+)
+
+// Clean uppercases a descriptor and removes payment facilitator prefixes,
+// store numbers, references and punctuation, so that it can be looked up in a
+// Dictionary.
+func Clean(descriptor string) string {
+	s := strings.ToUpper(strings.TrimSpace(descriptor))
+	s = facilitator.ReplaceAllString(s, "")
+	s = domain.ReplaceAllString(s, " COM")
+	s = references.ReplaceAllString(" "+s, " ")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '&' || r == '\'' {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.TrimSpace(spaces.ReplaceAllString(s, " "))
+}
+
+// Normalize returns a clean merchant name for a descriptor: the dictionary's
+// name when it has one, and otherwise the cleaned descriptor in title case.
+// dict may be nil.
+func Normalize(descriptor string, dict Dictionary) string {
+	cleaned := Clean(descriptor)
+	if dict != nil {
+		if name, ok := dict.Lookup(cleaned); ok {
+			return name
+		}
+	}
+	words := strings.Fields(strings.TrimSuffix(cleaned, " COM"))
+	for i, w := range words {
+		if len(w) <= 2 || len(w) == 3 && !strings.ContainsAny(w, "AEIOUY") {
+			// Abbreviations such as NY or LLC.
+			continue
+		}
+		words[i] = w[:1] + strings.ToLower(w[1:])
+	}
+	return strings.Join(words, " ")
+}
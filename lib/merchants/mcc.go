@@ -0,0 +1,146 @@
+package merchants
+
+import "strconv"
+
+// Bucket is a broad spend category for expense reports.
+type Bucket string
+
+const (
+	BucketTravel        Bucket = "travel"
+	BucketLodging       Bucket = "lodging"
+	BucketTransport     Bucket = "transportation"
+	BucketFuel          Bucket = "fuel"
+	BucketDining        Bucket = "dining"
+	BucketGroceries     Bucket = "groceries"
+	BucketShopping      Bucket = "shopping"
+	BucketSoftware      Bucket = "software"
+	BucketUtilities     Bucket = "utilities"
+	BucketServices      Bucket = "services"
+	BucketHealth        Bucket = "health"
+	BucketEntertainment Bucket = "entertainment"
+	BucketFinancial     Bucket = "financial"
+	BucketGovernment    Bucket = "government"
+	BucketOther         Bucket = "other"
+)
+
+// Category is what a merchant category code (MCC) describes.
+type Category struct {
+	MCC    string
+	Name   string
+	Bucket Bucket
+}
+
+type mccRange struct {
+	from, to int
+	name     string
+	bucket   Bucket
+}
+
+// codes are the categories of individual MCCs, which take precedence over
+// ranges.
+var codes = map[int]mccRange{
+	4111: {name: "Commuter Transportation", bucket: BucketTransport},
+	4121: {name: "Taxis and Rideshare", bucket: BucketTransport},
+	4131: {name: "Bus Lines", bucket: BucketTransport},
+	4214: {name: "Freight and Courier Services", bucket: BucketServices},
+	4215: {name: "Courier Services", bucket: BucketServices},
+	4411: {name: "Cruise Lines", bucket: BucketTravel},
+	4511: {name: "Airlines", bucket: BucketTravel},
+	4722: {name: "Travel Agencies", bucket: BucketTravel},
+	4784: {name: "Tolls and Bridge Fees", bucket: BucketTransport},
+	4812: {name: "Telecommunication Equipment", bucket: BucketShopping},
+	4814: {name: "Telecommunication Services", bucket: BucketUtilities},
+	4816: {name: "Computer Network Services", bucket: BucketSoftware},
+	4899: {name: "Cable and Streaming Services", bucket: BucketEntertainment},
+	4900: {name: "Utilities", bucket: BucketUtilities},
+	5045: {name: "Computers and Peripherals", bucket: BucketShopping},
+	5111: {name: "Office Supplies", bucket: BucketShopping},
+	5200: {name: "Home Supply Warehouses", bucket: BucketShopping},
+	5310: {name: "Discount Stores", bucket: BucketShopping},
+	5311: {name: "Department Stores", bucket: BucketShopping},
+	5411: {name: "Grocery Stores", bucket: BucketGroceries},
+	5422: {name: "Meat Markets", bucket: BucketGroceries},
+	5441: {name: "Candy Stores", bucket: BucketGroceries},
+	5451: {name: "Dairy Stores", bucket: BucketGroceries},
+	5462: {name: "Bakeries", bucket: BucketGroceries},
+	5499: {name: "Convenience and Specialty Food Stores", bucket: BucketGroceries},
+	5541: {name: "Service Stations", bucket: BucketFuel},
+	5542: {name: "Automated Fuel Dispensers", bucket: BucketFuel},
+	5734: {name: "Computer Software Stores", bucket: BucketSoftware},
+	5812: {name: "Restaurants", bucket: BucketDining},
+	5813: {name: "Bars and Nightclubs", bucket: BucketDining},
+	5814: {name: "Fast Food Restaurants", bucket: BucketDining},
+	5815: {name: "Digital Media", bucket: BucketEntertainment},
+	5816: {name: "Digital Games", bucket: BucketEntertainment},
+	5817: {name: "Digital Applications", bucket: BucketSoftware},
+	5818: {name: "Digital Goods", bucket: BucketSoftware},
+	5912: {name: "Drug Stores and Pharmacies", bucket: BucketHealth},
+	5942: {name: "Book Stores", bucket: BucketShopping},
+	5968: {name: "Subscription Merchants", bucket: BucketServices},
+	6010: {name: "Manual Cash Disbursements", bucket: BucketFinancial},
+	6011: {name: "ATM Cash Disbursements", bucket: BucketFinancial},
+	6012: {name: "Financial Institutions", bucket: BucketFinancial},
+	6051: {name: "Quasi Cash and Money Orders", bucket: BucketFinancial},
+	6300: {name: "Insurance", bucket: BucketFinancial},
+	6513: {name: "Real Estate Agents and Rentals", bucket: BucketServices},
+	7011: {name: "Hotels and Motels", bucket: BucketLodging},
+	7012: {name: "Timeshares", bucket: BucketLodging},
+	7372: {name: "Computer Programming and Data Processing", bucket: BucketSoftware},
+	7399: {name: "Business Services", bucket: BucketServices},
+	7512: {name: "Car Rental", bucket: BucketTransport},
+	7523: {name: "Parking", bucket: BucketTransport},
+	7832: {name: "Movie Theaters", bucket: BucketEntertainment},
+	8011: {name: "Doctors", bucket: BucketHealth},
+	8021: {name: "Dentists", bucket: BucketHealth},
+	8062: {name: "Hospitals", bucket: BucketHealth},
+	8111: {name: "Legal Services", bucket: BucketServices},
+	8931: {name: "Accounting Services", bucket: BucketServices},
+	9211: {name: "Court Costs", bucket: BucketGovernment},
+	9222: {name: "Fines", bucket: BucketGovernment},
+	9311: {name: "Tax Payments", bucket: BucketGovernment},
+	9399: {name: "Government Services", bucket: BucketGovernment},
+	9402: {name: "Postal Services", bucket: BucketGovernment},
+}
+
+// ranges are the categories of MCC ranges, used when an MCC has no category
+// of its own.
+var ranges = []mccRange{
+	{0, 1499, "Agricultural Services", BucketServices},
+	{1500, 2999, "Contracted Services", BucketServices},
+	{3000, 3299, "Airlines", BucketTravel},
+	{3300, 3499, "Car Rental", BucketTransport},
+	{3500, 3999, "Hotels and Motels", BucketLodging},
+	{4000, 4799, "Transportation", BucketTransport},
+	{4800, 4999, "Utilities", BucketUtilities},
+	{5000, 5599, "Retail", BucketShopping},
+	{5600, 5699, "Clothing Stores", BucketShopping},
+	{5700, 5799, "Home Furnishing and Electronics", BucketShopping},
+	{5800, 5899, "Eating Places", BucketDining},
+	{5900, 5999, "Miscellaneous Stores", BucketShopping},
+	{6000, 6999, "Financial Services", BucketFinancial},
+	{7000, 7299, "Personal Services", BucketServices},
+	{7300, 7529, "Business Services", BucketServices},
+	{7530, 7799, "Repair Services", BucketServices},
+	{7800, 7999, "Amusement and Entertainment", BucketEntertainment},
+	{8000, 8099, "Medical Services", BucketHealth},
+	{8100, 8999, "Professional Services", BucketServices},
+	{9000, 9999, "Government Services", BucketGovernment},
+}
+
+// Categorize returns the category of a merchant category code. Unknown codes
+// are in BucketOther.
+func Categorize(mcc string) Category {
+	n, err := strconv.Atoi(mcc)
+	if err != nil || len(mcc) != 4 {
+		return Category{MCC: mcc, Name: "Unknown", Bucket: BucketOther}
+	}
+	if c, ok := codes[n]; ok {
+		return Category{MCC: mcc, Name: c.name, Bucket: c.bucket}
+	}
+	for _, r := range ranges {
+		if n >= r.from && n <= r.to {
+			return Category{MCC: mcc, Name: r.name, Bucket: r.bucket}
+		}
+	}
+	return Category{MCC: mcc, Name: "Unknown", Bucket: BucketOther}
+}
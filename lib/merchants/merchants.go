@@ -0,0 +1,182 @@
+// Package merchants enriches card transactions offline: it maps merchant
+// category codes to categories and spend buckets, and normalizes merchant
+// descriptors to clean merchant names with a pluggable [Dictionary].
+//
+//	enricher := merchants.New(merchants.DefaultDictionary)
+//	for _, tx := range enricher.Transactions(transactions) {
+//		if tx.Enrichment != nil {
+//			fmt.Println(tx.Enrichment.Name, tx.Enrichment.Category.Bucket)
+//		}
+//	}
+package merchants
+
+import (
+	"github.com/Increase/increase-go"
+)
+
+// Merchant is the raw merchant data of a card transaction.
+type Merchant struct {
+	AcceptorID   string
+	CategoryCode string
+	// The merchant descriptor or name, as sent by the network.
+	Descriptor string
+	City       string
+	State      string
+	Country    string
+	PostalCode string
+}
+
+// Enrichment is a merchant as it should be shown in an expense report.
+type Enrichment struct {
+	Merchant
+	// The clean merchant name.
+	Name     string
+	Category Category
+}
+
+// Enricher enriches merchants. The zero value uses no dictionary.
+type Enricher struct {
+	Dictionary Dictionary
+	// Categories that override the MCC of individual merchants, by acceptor
+	// ID, for merchants whose MCC does not describe what you buy from them.
+	Overrides map[string]Category
+}
+
+// New returns an enricher that normalizes descriptors with dict. dict may be
+// nil.
+func New(dict Dictionary) *Enricher {
+	return &Enricher{Dictionary: dict, Overrides: map[string]Category{}}
+}
+
+// Enrich returns the enrichment of a merchant.
+func (e *Enricher) Enrich(m Merchant) Enrichment {
+	category, ok := e.Overrides[m.AcceptorID]
+	if !ok || m.AcceptorID == "" {
+		category = Categorize(m.CategoryCode)
+	}
+	return Enrichment{
+		Merchant: m,
+		Name:     Normalize(m.Descriptor, e.Dictionary),
+		Category: category,
+	}
+}
+
+// EnrichedTransaction is a Transaction and the enrichment of its merchant.
+type EnrichedTransaction struct {
+	increase.Transaction
+	// Nil for Transactions that are not card transactions.
+	Enrichment *Enrichment
+}
+
+// Transaction returns the enrichment of a card settlement, refund or
+// financial Transaction.
+func (e *Enricher) Transaction(tx increase.Transaction) (Enrichment, bool) {
+	m, ok := TransactionMerchant(tx)
+	if !ok {
+		return Enrichment{}, false
+	}
+	return e.Enrich(m), true
+}
+
+// Transactions enriches Transactions.
+func (e *Enricher) Transactions(txs []increase.Transaction) []EnrichedTransaction {
+	res := make([]EnrichedTransaction, len(txs))
+	for i, tx := range txs {
+		res[i].Transaction = tx
+		if en, ok := e.Transaction(tx); ok {
+			res[i].Enrichment = &en
+		}
+	}
+	return res
+}
+
+// EnrichedCardPayment is a Card Payment and the enrichment of its merchant.
+type EnrichedCardPayment struct {
+	increase.CardPayment
+	// Nil for Card Payments without a merchant, such as card validations.
+	Enrichment *Enrichment
+}
+
+// CardPayment returns the enrichment of a Card Payment's merchant, taken from
+// its latest element that has one. Settlements carry the merchant's name,
+// which is preferred to the descriptor of authorizations.
+func (e *Enricher) CardPayment(cp increase.CardPayment) (Enrichment, bool) {
+	var found, resNamed bool
+	var res Merchant
+	for _, el := range cp.Elements {
+		m, ok := ElementMerchant(el)
+		if !ok {
+			continue
+		}
+		named := el.Category == increase.CardPaymentElementsCategoryCardSettlement || el.Category == increase.CardPaymentElementsCategoryCardRefund
+		if !found || named || !resNamed {
+			res, found, resNamed = m, true, named
+		}
+	}
+	if !found {
+		return Enrichment{}, false
+	}
+	return e.Enrich(res), true
+}
+
+// CardPayments enriches Card Payments.
+func (e *Enricher) CardPayments(cps []increase.CardPayment) []EnrichedCardPayment {
+	res := make([]EnrichedCardPayment, len(cps))
+	for i, cp := range cps {
+		res[i].CardPayment = cp
+		if en, ok := e.CardPayment(cp); ok {
+			res[i].Enrichment = &en
+		}
+	}
+	return res
+}
+
+// Totals sums the amounts of enriched Transactions by spend bucket.
+func Totals(txs []EnrichedTransaction) map[Bucket]int64 {
+	res := map[Bucket]int64{}
+	for _, tx := range txs {
+		if tx.Enrichment != nil {
+			res[tx.Enrichment.Category.Bucket] += tx.Amount
+		}
+	}
+	return res
+}
+
+// TransactionMerchant returns the merchant of a card settlement, refund or
+// financial Transaction.
+func TransactionMerchant(tx increase.Transaction) (Merchant, bool) {
+	switch tx.Source.Category {
+	case increase.TransactionSourceCategoryCardSettlement:
+		s := tx.Source.CardSettlement
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantName, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.TransactionSourceCategoryCardRefund:
+		s := tx.Source.CardRefund
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantName, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.TransactionSourceCategoryCardFinancial:
+		s := tx.Source.CardFinancial
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantDescriptor, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	}
+	return Merchant{}, false
+}
+
+// ElementMerchant returns the merchant of a Card Payment element.
+func ElementMerchant(el increase.CardPaymentElement) (Merchant, bool) {
+	switch el.Category {
+	case increase.CardPaymentElementsCategoryCardAuthorization:
+		s := el.CardAuthorization
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantDescriptor, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.CardPaymentElementsCategoryCardDecline:
+		s := el.CardDecline
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantDescriptor, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.CardPaymentElementsCategoryCardFinancial:
+		s := el.CardFinancial
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantDescriptor, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.CardPaymentElementsCategoryCardSettlement:
+		s := el.CardSettlement
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantName, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	case increase.CardPaymentElementsCategoryCardRefund:
+		s := el.CardRefund
+		return Merchant{s.MerchantAcceptorID, s.MerchantCategoryCode, s.MerchantName, s.MerchantCity, s.MerchantState, s.MerchantCountry, s.MerchantPostalCode}, true
+	}
+	return Merchant{}, false
+}
@@ -0,0 +1,93 @@
+package merchants_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/merchants"
+)
+
+func TestCategorize(t *testing.T) {
+	for mcc, want := range map[string]merchants.Bucket{
+		"5812": merchants.BucketDining,
+		"3015": merchants.BucketTravel,
+		"3650": merchants.BucketLodging,
+		"5542": merchants.BucketFuel,
+		"5411": merchants.BucketGroceries,
+		"7372": merchants.BucketSoftware,
+		"5651": merchants.BucketShopping,
+		"abcd": merchants.BucketOther,
+		"":     merchants.BucketOther,
+	} {
+		if got := merchants.Categorize(mcc); got.Bucket != want {
+			t.Errorf("%q: expected %s, got %+v", mcc, want, got)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	dict := merchants.PrefixDictionary{"BLUE BOTTLE": "Blue Bottle Coffee"}
+	for descriptor, want := range map[string]string{
+		"SQ *BLUE BOTTLE COFFE":          "Blue Bottle Coffee",
+		"AMZN Mktp US*2K4LM0ZX2":         "Amazon Marketplace",
+		"UBER   *TRIP HELP.UBER.COM":     "Uber",
+		"TST* JOE'S PIZZA #1042":         "Joe's Pizza",
+		"WHOLEFDS MKT 10234 NEW YORK NY": "Wholefds MKT New York NY",
+		"DD *DOORDASH BURGERS":           "DoorDash",
+		"GITHUB.COM":                     "Github",
+	} {
+		if got := merchants.Normalize(descriptor, chain{dict, merchants.DefaultDictionary}); got != want {
+			t.Errorf("%q: expected %q, got %q", descriptor, want, got)
+		}
+	}
+}
+
+type chain []merchants.Dictionary
+
+func (c chain) Lookup(descriptor string) (string, bool) {
+	for _, d := range c {
+		if name, ok := d.Lookup(descriptor); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func TestEnrich(t *testing.T) {
+	var txs []increase.Transaction
+	json.Unmarshal([]byte(`[
+		{"id":"transaction_1","amount":-1250,"source":{"category":"card_settlement","card_settlement":{"merchant_name":"SQ *BLUE BOTTLE","merchant_category_code":"5814","merchant_city":"Oakland"}}},
+		{"id":"transaction_2","amount":-5000,"source":{"category":"card_settlement","card_settlement":{"merchant_name":"DELTA AIR 0062345","merchant_category_code":"3058","merchant_acceptor_id":"delta"}}},
+		{"id":"transaction_3","amount":300,"source":{"category":"card_refund","card_refund":{"merchant_name":"SQ *BLUE BOTTLE","merchant_category_code":"5814"}}},
+		{"id":"transaction_4","amount":10000,"source":{"category":"inbound_ach_transfer"}}
+	]`), &txs)
+	enricher := merchants.New(merchants.DefaultDictionary)
+	enriched := enricher.Transactions(txs)
+	if e := enriched[0].Enrichment; e == nil || e.Name != "Blue Bottle" || e.Category.Bucket != merchants.BucketDining || e.City != "Oakland" {
+		t.Errorf("unexpected enrichment %+v", e)
+	}
+	if enriched[3].Enrichment != nil || enriched[3].ID != "transaction_4" {
+		t.Errorf("expected non-card transactions not to be enriched")
+	}
+	totals := merchants.Totals(enriched)
+	if totals[merchants.BucketDining] != -950 || totals[merchants.BucketTravel] != -5000 {
+		t.Errorf("unexpected totals %v", totals)
+	}
+
+	enricher.Overrides["delta"] = merchants.Category{MCC: "3058", Name: "Team Offsite", Bucket: merchants.BucketEntertainment}
+	if e, _ := enricher.Transaction(txs[1]); e.Category.Bucket != merchants.BucketEntertainment {
+		t.Errorf("expected the override to apply, got %+v", e.Category)
+	}
+
+	var cp increase.CardPayment
+	json.Unmarshal([]byte(`{"id":"card_payment_1","elements":[
+		{"category":"card_authorization","card_authorization":{"merchant_descriptor":"NETFLIX.COM 866-579-7172","merchant_category_code":"4899"}},
+		{"category":"card_settlement","card_settlement":{"merchant_name":"NETFLIX.COM","merchant_category_code":"4899"}},
+		{"category":"card_authorization","card_authorization":{"merchant_descriptor":"OTHER","merchant_category_code":"4899"}}
+	]}`), &cp)
+	e, ok := enricher.CardPayment(cp)
+	if !ok || e.Name != "Netflix" || e.Descriptor != "NETFLIX.COM" || e.Category.Bucket != merchants.BucketEntertainment {
+		t.Errorf("expected the settlement's merchant, got %+v", e)
+	}
+}
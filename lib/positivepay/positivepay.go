@@ -0,0 +1,336 @@
+// Package positivepay maintains a register of checks issued with Check
+// Transfers and matches Inbound Check Deposits against it.
+//
+// Presentments that do not match an issued check, or that differ from it in
+// amount or payee, or that arrive after the check went stale, are flagged as
+// exceptions. A [Policy] decides whether each exception is only reported or is
+// rejected automatically by declining or returning the deposit.
+package positivepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// Exception is a reason a presented check does not match the register.
+type Exception string
+
+const (
+	// No check with this number was issued from the account number.
+	ExceptionNotIssued Exception = "not_issued"
+	// The presented amount differs from the issued amount.
+	ExceptionAmountMismatch Exception = "amount_mismatch"
+	// Increase's analysis of the payee name on the check image did not match
+	// the issued payee.
+	ExceptionPayeeMismatch Exception = "payee_mismatch"
+	// The check was presented after its valid-until date.
+	ExceptionStale Exception = "stale"
+	// The check was already paid by an earlier deposit.
+	ExceptionDuplicatePresentment Exception = "duplicate_presentment"
+	// A stop payment was placed on the check, or it was voided.
+	ExceptionStopped Exception = "stopped"
+)
+
+// Action is what a [Policy] does about an exception.
+type Action string
+
+const (
+	// Report the exception without acting on the deposit.
+	ActionReview Action = "review"
+	// Decline the deposit if it is still pending, or return it if it has
+	// already been accepted.
+	ActionReject Action = "reject"
+)
+
+// Policy configures how exceptions are handled.
+type Policy struct {
+	// The action for each exception. Exceptions without an entry are reviewed.
+	Actions map[Exception]Action
+	// The reason used when returning an accepted deposit for each exception.
+	// Exceptions without an entry are returned as `not_authorized`.
+	ReturnReasons map[Exception]increase.InboundCheckDepositReturnParamsReason
+	// Whether [PositivePay.StopStaleChecks] places stop payments on unpaid checks
+	// that are past their valid-until date.
+	StopStaleChecks bool
+}
+
+// DefaultPolicy rejects presentments of unknown, altered, stopped or
+// previously paid checks, and only reports payee mismatches and stale checks
+// for review.
+func DefaultPolicy() Policy {
+	return Policy{
+		Actions: map[Exception]Action{
+			ExceptionNotIssued:            ActionReject,
+			ExceptionAmountMismatch:       ActionReject,
+			ExceptionDuplicatePresentment: ActionReject,
+			ExceptionStopped:              ActionReject,
+			ExceptionPayeeMismatch:        ActionReview,
+			ExceptionStale:                ActionReview,
+		},
+		ReturnReasons: map[Exception]increase.InboundCheckDepositReturnParamsReason{
+			ExceptionNotIssued:            increase.InboundCheckDepositReturnParamsReasonNotAuthorized,
+			ExceptionAmountMismatch:       increase.InboundCheckDepositReturnParamsReasonAlteredOrFictitious,
+			ExceptionPayeeMismatch:        increase.InboundCheckDepositReturnParamsReasonAlteredOrFictitious,
+			ExceptionDuplicatePresentment: increase.InboundCheckDepositReturnParamsReasonDuplicatePresentment,
+			ExceptionStopped:              increase.InboundCheckDepositReturnParamsReasonNotAuthorized,
+			ExceptionStale:                increase.InboundCheckDepositReturnParamsReasonReferToMaker,
+		},
+		StopStaleChecks: true,
+	}
+}
+
+func (p Policy) action(e Exception) Action {
+	if a, ok := p.Actions[e]; ok {
+		return a
+	}
+	return ActionReview
+}
+
+func (p Policy) returnReason(e Exception) increase.InboundCheckDepositReturnParamsReason {
+	if r, ok := p.ReturnReasons[e]; ok {
+		return r
+	}
+	return increase.InboundCheckDepositReturnParamsReasonNotAuthorized
+}
+
+// Match is the result of comparing an Inbound Check Deposit to the register.
+type Match struct {
+	Deposit increase.InboundCheckDeposit
+	// The issued check the deposit was matched to, or nil if there is none.
+	Check      *IssuedCheck
+	Exceptions []Exception
+}
+
+// OK reports whether the deposit matched an issued check without exceptions.
+func (m Match) OK() bool {
+	return m.Check != nil && len(m.Exceptions) == 0
+}
+
+// Decision is the outcome of processing a deposit.
+type Decision struct {
+	Match
+	// The action taken, or the empty string if the deposit matched.
+	Action Action
+	// The exception that determined the action.
+	Reason Exception
+	// The deposit as returned by the decline or return call, if one was made.
+	Updated *increase.InboundCheckDeposit
+}
+
+// PositivePay matches Inbound Check Deposits against a register of issued
+// checks.
+type PositivePay struct {
+	client   *increase.Client
+	register Register
+	policy   Policy
+	now      func() time.Time
+}
+
+// New returns a PositivePay using the given client, register and policy.
+func New(client *increase.Client, register Register, policy Policy) *PositivePay {
+	return &PositivePay{client: client, register: register, policy: policy, now: time.Now}
+}
+
+// Issue creates a Check Transfer and records the check in the register.
+func (p *PositivePay) Issue(ctx context.Context, params increase.CheckTransferNewParams, opts ...option.RequestOption) (*increase.CheckTransfer, error) {
+	transfer, err := p.client.CheckTransfers.New(ctx, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Record(ctx, *transfer); err != nil {
+		return transfer, err
+	}
+	return transfer, nil
+}
+
+// Record adds a Check Transfer to the register, or updates its entry. The
+// register status follows the transfer's status, except that a check already
+// marked as paid stays paid.
+func (p *PositivePay) Record(ctx context.Context, transfer increase.CheckTransfer) error {
+	check := IssuedCheck{
+		CheckTransferID:       transfer.ID,
+		AccountID:             transfer.AccountID,
+		SourceAccountNumberID: transfer.SourceAccountNumberID,
+		CheckNumber:           transfer.CheckNumber,
+		Amount:                transfer.Amount,
+		Payee:                 payee(transfer),
+		IssuedAt:              transfer.CreatedAt,
+		ValidUntil:            transfer.ValidUntilDate,
+		Status:                CheckStatusIssued,
+	}
+	switch transfer.Status {
+	case increase.CheckTransferStatusStopped:
+		check.Status = CheckStatusStopped
+	case increase.CheckTransferStatusCanceled, increase.CheckTransferStatusRejected, increase.CheckTransferStatusReturned:
+		check.Status = CheckStatusVoided
+	case increase.CheckTransferStatusDeposited:
+		check.Status = CheckStatusPaid
+		check.PaidByInboundCheckDepositID = transfer.ApprovedInboundCheckDepositID
+	}
+	existing, err := p.register.FindByCheckTransferID(ctx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Status == CheckStatusPaid {
+		check.Status = CheckStatusPaid
+		if check.PaidByInboundCheckDepositID == "" {
+			check.PaidByInboundCheckDepositID = existing.PaidByInboundCheckDepositID
+		}
+	}
+	return p.register.Save(ctx, check)
+}
+
+func payee(transfer increase.CheckTransfer) string {
+	if transfer.FulfillmentMethod == increase.CheckTransferFulfillmentMethodThirdParty {
+		return transfer.ThirdParty.RecipientName
+	}
+	return transfer.PhysicalCheck.RecipientName
+}
+
+// Sync records every Check Transfer of the account in the register.
+func (p *PositivePay) Sync(ctx context.Context, accountID string, opts ...option.RequestOption) error {
+	iter := p.client.CheckTransfers.ListAutoPaging(ctx, increase.CheckTransferListParams{
+		AccountID: increase.F(accountID),
+	}, opts...)
+	for iter.Next() {
+		if err := p.Record(ctx, iter.Current()); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// Match compares a deposit to the register without acting on it.
+func (p *PositivePay) Match(ctx context.Context, deposit increase.InboundCheckDeposit) (Match, error) {
+	m := Match{Deposit: deposit}
+	var err error
+	if deposit.CheckTransferID != "" {
+		m.Check, err = p.register.FindByCheckTransferID(ctx, deposit.CheckTransferID)
+	}
+	if err == nil && m.Check == nil && deposit.CheckNumber != "" && deposit.AccountNumberID != "" {
+		m.Check, err = p.register.FindByCheckNumber(ctx, deposit.AccountNumberID, deposit.CheckNumber)
+	}
+	if err != nil {
+		return m, err
+	}
+	if m.Check == nil {
+		m.Exceptions = append(m.Exceptions, ExceptionNotIssued)
+		return m, nil
+	}
+	switch m.Check.Status {
+	case CheckStatusStopped, CheckStatusVoided:
+		m.Exceptions = append(m.Exceptions, ExceptionStopped)
+	case CheckStatusPaid:
+		if m.Check.PaidByInboundCheckDepositID != deposit.ID {
+			m.Exceptions = append(m.Exceptions, ExceptionDuplicatePresentment)
+		}
+	}
+	if deposit.Amount != m.Check.Amount {
+		m.Exceptions = append(m.Exceptions, ExceptionAmountMismatch)
+	}
+	if deposit.PayeeNameAnalysis == increase.InboundCheckDepositPayeeNameAnalysisDoesNotMatch {
+		m.Exceptions = append(m.Exceptions, ExceptionPayeeMismatch)
+	}
+	if m.Check.StaleAt(deposit.CreatedAt) {
+		m.Exceptions = append(m.Exceptions, ExceptionStale)
+	}
+	return m, nil
+}
+
+// Process matches a deposit and applies the policy. Deposits that match, or
+// whose exceptions are only reviewed, mark the check as paid. The first
+// exception whose action is [ActionReject] rejects the deposit: pending deposits
+// are declined and accepted deposits are returned. Deposits that were already
+// declined or returned are matched but not acted on.
+func (p *PositivePay) Process(ctx context.Context, deposit increase.InboundCheckDeposit, opts ...option.RequestOption) (*Decision, error) {
+	m, err := p.Match(ctx, deposit)
+	if err != nil {
+		return nil, err
+	}
+	d := &Decision{Match: m}
+	for _, e := range m.Exceptions {
+		if p.policy.action(e) == ActionReject {
+			d.Action, d.Reason = ActionReject, e
+			break
+		}
+	}
+	if d.Action == "" && len(m.Exceptions) > 0 {
+		d.Action, d.Reason = ActionReview, m.Exceptions[0]
+	}
+
+	switch deposit.Status {
+	case increase.InboundCheckDepositStatusDeclined, increase.InboundCheckDepositStatusReturned:
+		return d, nil
+	}
+	if d.Action == ActionReject {
+		switch deposit.Status {
+		case increase.InboundCheckDepositStatusPending:
+			d.Updated, err = p.client.InboundCheckDeposits.Decline(ctx, deposit.ID, opts...)
+		case increase.InboundCheckDepositStatusAccepted:
+			d.Updated, err = p.client.InboundCheckDeposits.Return(ctx, deposit.ID, increase.InboundCheckDepositReturnParams{
+				Reason: increase.F(p.policy.returnReason(d.Reason)),
+			}, opts...)
+		default:
+			err = fmt.Errorf("positivepay: cannot reject inbound check deposit %s in status %s", deposit.ID, deposit.Status)
+		}
+		return d, err
+	}
+	if m.Check != nil && m.Check.Status == CheckStatusIssued {
+		paid := *m.Check
+		paid.Status = CheckStatusPaid
+		paid.PaidByInboundCheckDepositID = deposit.ID
+		if err := p.register.Save(ctx, paid); err != nil {
+			return d, err
+		}
+	}
+	return d, nil
+}
+
+// ProcessID retrieves an Inbound Check Deposit and processes it. Use it to
+// handle `inbound_check_deposit.created` events.
+func (p *PositivePay) ProcessID(ctx context.Context, inboundCheckDepositID string, opts ...option.RequestOption) (*Decision, error) {
+	if inboundCheckDepositID == "" {
+		return nil, errors.New("missing required inbound_check_deposit_id parameter")
+	}
+	deposit, err := p.client.InboundCheckDeposits.Get(ctx, inboundCheckDepositID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.Process(ctx, *deposit, opts...)
+}
+
+// StopStaleChecks lists issued, unpaid checks that are past their valid-until
+// date. If the policy enables it, a stop payment is placed on each and the
+// register is updated.
+func (p *PositivePay) StopStaleChecks(ctx context.Context, opts ...option.RequestOption) ([]IssuedCheck, error) {
+	checks, err := p.register.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := p.now()
+	var stale []IssuedCheck
+	for _, c := range checks {
+		if c.Status != CheckStatusIssued || !c.StaleAt(now) {
+			continue
+		}
+		if p.policy.StopStaleChecks {
+			_, err := p.client.CheckTransfers.StopPayment(ctx, c.CheckTransferID, increase.CheckTransferStopPaymentParams{
+				Reason: increase.F(increase.CheckTransferStopPaymentParamsReasonValidUntilDatePassed),
+			}, opts...)
+			if err != nil {
+				return stale, err
+			}
+			c.Status = CheckStatusStopped
+			if err := p.register.Save(ctx, c); err != nil {
+				return stale, err
+			}
+		}
+		stale = append(stale, c)
+	}
+	return stale, nil
+}
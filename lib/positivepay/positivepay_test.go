@@ -0,0 +1,290 @@
+package positivepay_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/positivepay"
+)
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	deposits map[string]map[string]any
+	calls    []string
+	bodies   map[string]map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{deposits: map[string]map[string]any{}, bodies: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /inbound_check_deposits/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.record(r)
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(api.deposits[r.PathValue("id")])
+	})
+	mux.HandleFunc("POST /inbound_check_deposits/{id}/decline", func(w http.ResponseWriter, r *http.Request) {
+		api.record(r)
+		api.respondDeposit(w, r.PathValue("id"), "declined")
+	})
+	mux.HandleFunc("POST /inbound_check_deposits/{id}/return", func(w http.ResponseWriter, r *http.Request) {
+		api.record(r)
+		api.respondDeposit(w, r.PathValue("id"), "returned")
+	})
+	mux.HandleFunc("POST /check_transfers/{id}/stop_payment", func(w http.ResponseWriter, r *http.Request) {
+		api.record(r)
+		json.NewEncoder(w).Encode(map[string]any{"id": r.PathValue("id"), "status": "stopped"})
+	})
+	mux.HandleFunc("POST /check_transfers", func(w http.ResponseWriter, r *http.Request) {
+		body := api.record(r)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":                       "check_transfer_new",
+			"account_id":               "account_1",
+			"source_account_number_id": "account_number_1",
+			"check_number":             "1001",
+			"amount":                   body["amount"],
+			"created_at":               "2026-10-01T00:00:00Z",
+			"fulfillment_method":       "physical_check",
+			"physical_check":           map[string]any{"recipient_name": "Ian Crease"},
+			"status":                   "pending_submission",
+		})
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func (a *fakeAPI) record(r *http.Request) map[string]any {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := r.Method + " " + r.URL.Path
+	a.calls = append(a.calls, key)
+	var body map[string]any
+	if json.NewDecoder(r.Body).Decode(&body) == nil {
+		a.bodies[key] = body
+	}
+	return body
+}
+
+func (a *fakeAPI) respondDeposit(w http.ResponseWriter, id string, status string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	d := a.deposits[id]
+	if d == nil {
+		d = map[string]any{"id": id}
+	}
+	d["status"] = status
+	json.NewEncoder(w).Encode(d)
+}
+
+func issued(t *testing.T, register positivepay.Register, check positivepay.IssuedCheck) {
+	t.Helper()
+	if err := register.Save(context.Background(), check); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+}
+
+func testCheck() positivepay.IssuedCheck {
+	return positivepay.IssuedCheck{
+		CheckTransferID:       "check_transfer_1",
+		AccountID:             "account_1",
+		SourceAccountNumberID: "account_number_1",
+		CheckNumber:           "1001",
+		Amount:                10000,
+		Payee:                 "Ian Crease",
+		IssuedAt:              time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil:            time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		Status:                positivepay.CheckStatusIssued,
+	}
+}
+
+func testDeposit(id string) increase.InboundCheckDeposit {
+	return increase.InboundCheckDeposit{
+		ID:                id,
+		AccountID:         "account_1",
+		AccountNumberID:   "account_number_1",
+		Amount:            10000,
+		CheckNumber:       "1001",
+		CreatedAt:         time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC),
+		PayeeNameAnalysis: increase.InboundCheckDepositPayeeNameAnalysisNameMatches,
+		Status:            increase.InboundCheckDepositStatusPending,
+	}
+}
+
+func TestMatch(t *testing.T) {
+	ctx := context.Background()
+	register := positivepay.NewMemoryRegister()
+	issued(t, register, testCheck())
+	pp := positivepay.New(nil, register, positivepay.DefaultPolicy())
+
+	tests := map[string]struct {
+		deposit func(*increase.InboundCheckDeposit)
+		want    []positivepay.Exception
+	}{
+		"matches by check number": {
+			deposit: func(d *increase.InboundCheckDeposit) {},
+		},
+		"matches by check transfer": {
+			deposit: func(d *increase.InboundCheckDeposit) { d.CheckNumber, d.CheckTransferID = "", "check_transfer_1" },
+		},
+		"not issued": {
+			deposit: func(d *increase.InboundCheckDeposit) { d.CheckNumber = "9999" },
+			want:    []positivepay.Exception{positivepay.ExceptionNotIssued},
+		},
+		"altered": {
+			deposit: func(d *increase.InboundCheckDeposit) {
+				d.Amount = 90000
+				d.PayeeNameAnalysis = increase.InboundCheckDepositPayeeNameAnalysisDoesNotMatch
+			},
+			want: []positivepay.Exception{positivepay.ExceptionAmountMismatch, positivepay.ExceptionPayeeMismatch},
+		},
+		"stale": {
+			deposit: func(d *increase.InboundCheckDeposit) { d.CreatedAt = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC) },
+			want:    []positivepay.Exception{positivepay.ExceptionStale},
+		},
+		"valid on last day": {
+			deposit: func(d *increase.InboundCheckDeposit) { d.CreatedAt = time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC) },
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := testDeposit("inbound_check_deposit_1")
+			tt.deposit(&d)
+			m, err := pp.Match(ctx, d)
+			if err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+			if len(m.Exceptions) != len(tt.want) {
+				t.Fatalf("expected exceptions %v, got %v", tt.want, m.Exceptions)
+			}
+			for i := range tt.want {
+				if m.Exceptions[i] != tt.want[i] {
+					t.Errorf("expected exceptions %v, got %v", tt.want, m.Exceptions)
+				}
+			}
+			if m.OK() != (len(tt.want) == 0) {
+				t.Errorf("unexpected OK() for %v", m.Exceptions)
+			}
+		})
+	}
+}
+
+func TestProcessDeclinesAndReturns(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	register := positivepay.NewMemoryRegister()
+	issued(t, register, testCheck())
+	pp := positivepay.New(client, register, positivepay.DefaultPolicy())
+
+	pending := testDeposit("inbound_check_deposit_pending")
+	pending.Amount = 99999
+	d, err := pp.Process(ctx, pending)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if d.Action != positivepay.ActionReject || d.Reason != positivepay.ExceptionAmountMismatch {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	if d.Updated == nil || d.Updated.Status != increase.InboundCheckDepositStatusDeclined {
+		t.Errorf("expected the deposit to be declined, got %+v", d.Updated)
+	}
+
+	accepted := testDeposit("inbound_check_deposit_accepted")
+	accepted.CheckNumber = "5555"
+	accepted.Status = increase.InboundCheckDepositStatusAccepted
+	if _, err := pp.Process(ctx, accepted); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if reason := api.bodies["POST /inbound_check_deposits/inbound_check_deposit_accepted/return"]["reason"]; reason != "not_authorized" {
+		t.Errorf("expected return reason not_authorized, got %v", reason)
+	}
+
+	c, _ := register.FindByCheckTransferID(ctx, "check_transfer_1")
+	if c.Status != positivepay.CheckStatusIssued {
+		t.Errorf("rejected deposits should not pay the check, got %s", c.Status)
+	}
+}
+
+func TestProcessPaysCheckAndFlagsDuplicate(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	register := positivepay.NewMemoryRegister()
+	issued(t, register, testCheck())
+	pp := positivepay.New(client, register, positivepay.DefaultPolicy())
+
+	api.deposits["inbound_check_deposit_1"] = map[string]any{
+		"id":                  "inbound_check_deposit_1",
+		"account_id":          "account_1",
+		"account_number_id":   "account_number_1",
+		"amount":              10000,
+		"check_number":        "1001",
+		"created_at":          "2026-10-10T00:00:00Z",
+		"payee_name_analysis": "name_matches",
+		"status":              "pending",
+	}
+	d, err := pp.ProcessID(ctx, "inbound_check_deposit_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !d.OK() || d.Action != "" {
+		t.Fatalf("expected a clean match, got %+v", d)
+	}
+	c, _ := register.FindByCheckTransferID(ctx, "check_transfer_1")
+	if c.Status != positivepay.CheckStatusPaid || c.PaidByInboundCheckDepositID != "inbound_check_deposit_1" {
+		t.Errorf("expected the check to be paid, got %+v", c)
+	}
+
+	d, err = pp.Process(ctx, testDeposit("inbound_check_deposit_2"))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if d.Reason != positivepay.ExceptionDuplicatePresentment || d.Updated == nil {
+		t.Errorf("expected the second presentment to be declined, got %+v", d)
+	}
+}
+
+func TestIssueAndStopStaleChecks(t *testing.T) {
+	ctx := context.Background()
+	api, client := newFakeAPI(t)
+	register := positivepay.NewMemoryRegister()
+	pp := positivepay.New(client, register, positivepay.DefaultPolicy())
+
+	_, err := pp.Issue(ctx, increase.CheckTransferNewParams{
+		AccountID:             increase.F("account_1"),
+		Amount:                increase.F(int64(2500)),
+		FulfillmentMethod:     increase.F(increase.CheckTransferNewParamsFulfillmentMethodPhysicalCheck),
+		SourceAccountNumberID: increase.F("account_number_1"),
+	})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	c, _ := register.FindByCheckNumber(ctx, "account_number_1", "1001")
+	if c == nil || c.Amount != 2500 || c.Payee != "Ian Crease" || c.Status != positivepay.CheckStatusIssued {
+		t.Fatalf("unexpected register entry: %+v", c)
+	}
+
+	stale := testCheck()
+	stale.CheckTransferID = "check_transfer_stale"
+	stale.CheckNumber = "1000"
+	stale.ValidUntil = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	issued(t, register, stale)
+
+	stopped, err := pp.StopStaleChecks(ctx)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(stopped) != 1 || stopped[0].CheckTransferID != "check_transfer_stale" {
+		t.Fatalf("unexpected stale checks: %+v", stopped)
+	}
+	if reason := api.bodies["POST /check_transfers/check_transfer_stale/stop_payment"]["reason"]; reason != "valid_until_date_passed" {
+		t.Errorf("unexpected stop payment reason: %v", reason)
+	}
+	c, _ = register.FindByCheckTransferID(ctx, "check_transfer_stale")
+	if c.Status != positivepay.CheckStatusStopped {
+		t.Errorf("expected the stale check to be stopped, got %s", c.Status)
+	}
+}
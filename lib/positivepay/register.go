@@ -0,0 +1,120 @@
+package positivepay
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckStatus is the positive pay status of an issued check.
+type CheckStatus string
+
+const (
+	// The check has been issued and not yet presented.
+	CheckStatusIssued CheckStatus = "issued"
+	// The check has been presented and paid.
+	CheckStatusPaid CheckStatus = "paid"
+	// A stop payment was placed on the check.
+	CheckStatusStopped CheckStatus = "stopped"
+	// The check transfer was canceled or rejected before the check was mailed.
+	CheckStatusVoided CheckStatus = "voided"
+)
+
+// IssuedCheck is an entry of the issued-check register.
+type IssuedCheck struct {
+	// The Increase identifier of the Check Transfer that issued the check.
+	CheckTransferID string
+	AccountID       string
+	// The Account Number the check is drawn on. Inbound check deposits are
+	// matched against it.
+	SourceAccountNumberID string
+	CheckNumber           string
+	// The amount of the check in USD cents.
+	Amount int64
+	// The name of the payee printed on the check.
+	Payee    string
+	IssuedAt time.Time
+	// The last day the check may be deposited. The zero value means the check
+	// does not go stale.
+	ValidUntil time.Time
+	Status     CheckStatus
+	// The Inbound Check Deposit that paid the check, once it has been paid.
+	PaidByInboundCheckDepositID string
+}
+
+// StaleAt reports whether the check is past its valid-until date at t.
+func (c IssuedCheck) StaleAt(t time.Time) bool {
+	if c.ValidUntil.IsZero() {
+		return false
+	}
+	y, m, d := c.ValidUntil.Date()
+	endOfDay := time.Date(y, m, d, 0, 0, 0, 0, c.ValidUntil.Location()).AddDate(0, 0, 1)
+	return !t.Before(endOfDay)
+}
+
+// Register stores issued checks. Implementations must be safe for concurrent
+// use. Find methods return nil and no error when there is no such check.
+type Register interface {
+	Save(ctx context.Context, check IssuedCheck) error
+	FindByCheckTransferID(ctx context.Context, checkTransferID string) (*IssuedCheck, error)
+	FindByCheckNumber(ctx context.Context, sourceAccountNumberID string, checkNumber string) (*IssuedCheck, error)
+	List(ctx context.Context) ([]IssuedCheck, error)
+}
+
+// MemoryRegister is an in-memory [Register], useful for tests and for
+// processes that rebuild the register from the API at start-up with
+// [PositivePay.Sync].
+type MemoryRegister struct {
+	mu     sync.Mutex
+	checks map[string]IssuedCheck
+}
+
+// NewMemoryRegister returns an empty in-memory register.
+func NewMemoryRegister() *MemoryRegister {
+	return &MemoryRegister{checks: map[string]IssuedCheck{}}
+}
+
+func (r *MemoryRegister) Save(ctx context.Context, check IssuedCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[check.CheckTransferID] = check
+	return nil
+}
+
+func (r *MemoryRegister) FindByCheckTransferID(ctx context.Context, checkTransferID string) (*IssuedCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.checks[checkTransferID]; ok {
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryRegister) FindByCheckNumber(ctx context.Context, sourceAccountNumberID string, checkNumber string) (*IssuedCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.checks {
+		if c.SourceAccountNumberID == sourceAccountNumberID && c.CheckNumber == checkNumber {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// List returns the register ordered by issue date.
+func (r *MemoryRegister) List(ctx context.Context) ([]IssuedCheck, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]IssuedCheck, 0, len(r.checks))
+	for _, c := range r.checks {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].IssuedAt.Equal(res[j].IssuedAt) {
+			return res[i].IssuedAt.Before(res[j].IssuedAt)
+		}
+		return res[i].CheckTransferID < res[j].CheckTransferID
+	})
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package receipts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Columns are the columns written by [WriteCSV]. Every row is a receipt line,
+// preceded by the receipt's columns.
+var Columns = []string{
+	"transaction_id", "card_payment_id", "date", "merchant_name", "merchant_category_code",
+	"merchant_city", "merchant_country", "currency", "total", "tax", "tax_included",
+	"purchase_identifier", "line", "kind", "description", "product_code", "commodity_code",
+	"quantity", "unit_of_measure", "unit_cost", "amount", "line_tax", "line_tax_rate", "line_discount",
+}
+
+// WriteCSV writes receipts as CSV, with a header naming [Columns] and one row
+// per receipt line. Amounts are decimal strings in the currency's major unit.
+func WriteCSV(w io.Writer, receipts ...*Receipt) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Columns); err != nil {
+		return fmt.Errorf("receipts: writing header: %w", err)
+	}
+	for _, r := range receipts {
+		for i, l := range r.Lines {
+			err := cw.Write([]string{
+				r.TransactionID, r.CardPaymentID, r.Date, r.Merchant.Name, r.Merchant.CategoryCode,
+				r.Merchant.City, r.Merchant.Country, r.Currency, FormatAmount(r.Total, r.Currency),
+				FormatAmount(r.Tax, r.Currency), strconv.FormatBool(r.TaxIncluded),
+				r.PurchaseIdentifier, strconv.Itoa(i + 1), string(l.Kind), l.Description, l.ProductCode, l.CommodityCode,
+				l.Quantity, l.UnitOfMeasure, l.UnitCost, FormatAmount(l.Amount, r.Currency),
+				FormatAmount(l.Tax, r.Currency), l.TaxRate, FormatAmount(l.Discount, r.Currency),
+			})
+			if err != nil {
+				return fmt.Errorf("receipts: writing %s: %w", r.TransactionID, err)
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatAmount renders an amount in the minor unit of a currency as a decimal
+// string in its major unit, such as "12.50" for 1250 USD and "1250" for 1250
+// JPY.
+func FormatAmount(minor int64, currency string) string {
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if currency == "JPY" {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
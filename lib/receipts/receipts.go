@@ -0,0 +1,425 @@
+// Package receipts reconstructs itemized receipts from card settlements and
+// the Level 3 data of their Card Purchase Supplements, for expense systems.
+//
+// A [Receipt] always itemizes the full settlement amount: line items, taxes,
+// shipping and discounts from the supplement, room nights and car rental days
+// from the settlement's purchase details, and an adjustment for whatever the
+// merchant did not itemize. Receipts marshal to JSON with [encoding/json], and
+// [WriteCSV] writes one row per line.
+//
+//	supplement, err := receipts.Supplement(ctx, client, tx)
+//	if err != nil {
+//		return err
+//	}
+//	receipt, err := receipts.Build(tx, supplement)
+package receipts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNotCardSettlement is returned for Transactions that are not card
+// settlements.
+var ErrNotCardSettlement = errors.New("receipts: transaction is not a card settlement")
+
+// Kind is what a receipt line is for.
+type Kind string
+
+const (
+	KindItem         Kind = "item"
+	KindCredit       Kind = "credit"
+	KindPayment      Kind = "payment"
+	KindShipping     Kind = "shipping"
+	KindTax          Kind = "tax"
+	KindDuty         Kind = "duty"
+	KindDiscount     Kind = "discount"
+	KindRoom         Kind = "room"
+	KindFoodBeverage Kind = "food_beverage"
+	KindPrepaid      Kind = "prepaid"
+	KindCashAdvance  Kind = "cash_advance"
+	KindRental       Kind = "rental"
+	KindFuel         Kind = "fuel"
+	KindInsurance    Kind = "insurance"
+	KindDropOff      Kind = "drop_off"
+	KindFare         Kind = "fare"
+	// The part of the settlement amount the merchant did not itemize.
+	KindAdjustment Kind = "adjustment"
+)
+
+// Line is a line of a receipt. Amounts are in the minor unit of the receipt's
+// currency; credits, payments and discounts are negative.
+type Line struct {
+	Kind          Kind   `json:"kind"`
+	Description   string `json:"description"`
+	ProductCode   string `json:"product_code,omitempty"`
+	CommodityCode string `json:"commodity_code,omitempty"`
+	// The quantity and unit cost, as decimal strings in the major unit.
+	Quantity      string `json:"quantity,omitempty"`
+	UnitOfMeasure string `json:"unit_of_measure,omitempty"`
+	UnitCost      string `json:"unit_cost,omitempty"`
+	Amount        int64  `json:"amount"`
+	// The tax and discount of the line, for information: whether the tax is
+	// included in Amount depends on the receipt's TaxIncluded.
+	Tax      int64  `json:"tax,omitempty"`
+	TaxRate  string `json:"tax_rate,omitempty"`
+	Discount int64  `json:"discount,omitempty"`
+}
+
+// Merchant is the merchant of a receipt.
+type Merchant struct {
+	Name         string `json:"name"`
+	AcceptorID   string `json:"acceptor_id"`
+	CategoryCode string `json:"category_code"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+	Country      string `json:"country,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+}
+
+// Lodging is the hotel stay of a receipt.
+type Lodging struct {
+	CheckIn   string `json:"check_in,omitempty"`
+	CheckOut  string `json:"check_out,omitempty"`
+	Nights    int64  `json:"nights"`
+	DailyRate int64  `json:"daily_rate"`
+	NoShow    bool   `json:"no_show,omitempty"`
+}
+
+// CarRental is the car rental of a receipt.
+type CarRental struct {
+	Checkout   string `json:"checkout,omitempty"`
+	Days       int64  `json:"days"`
+	DailyRate  int64  `json:"daily_rate"`
+	CarClass   string `json:"car_class,omitempty"`
+	RenterName string `json:"renter_name,omitempty"`
+}
+
+// Leg is a flight of a trip.
+type Leg struct {
+	From         string `json:"from,omitempty"`
+	To           string `json:"to"`
+	Carrier      string `json:"carrier,omitempty"`
+	FlightNumber string `json:"flight_number,omitempty"`
+	ServiceClass string `json:"service_class,omitempty"`
+	FareBasis    string `json:"fare_basis,omitempty"`
+	// Whether a stopover is allowed at the leg's destination.
+	StopOverAllowed bool `json:"stop_over_allowed,omitempty"`
+}
+
+// Travel is the airline ticket of a receipt.
+type Travel struct {
+	PassengerName string `json:"passenger_name,omitempty"`
+	TicketNumber  string `json:"ticket_number,omitempty"`
+	AgencyName    string `json:"agency_name,omitempty"`
+	DepartureDate string `json:"departure_date,omitempty"`
+	Legs          []Leg  `json:"legs,omitempty"`
+}
+
+// Receipt is a normalized itemized receipt. Its lines always add up to Total.
+type Receipt struct {
+	TransactionID            string `json:"transaction_id"`
+	CardPaymentID            string `json:"card_payment_id"`
+	CardPurchaseSupplementID string `json:"card_purchase_supplement_id,omitempty"`
+	// The order date of the invoice, or the date of the settlement.
+	Date     string   `json:"date"`
+	Merchant Merchant `json:"merchant"`
+	Currency string   `json:"currency"`
+	// The settlement amount, in the minor unit of Currency.
+	Total int64 `json:"total"`
+	// The sum of the item, credit and payment lines.
+	Subtotal int64 `json:"subtotal"`
+	// The total tax, whether or not it is included in the line amounts.
+	Tax         int64 `json:"tax"`
+	TaxIncluded bool  `json:"tax_included"`
+	Shipping    int64 `json:"shipping,omitempty"`
+	// The total discount, as a positive amount.
+	Discount           int64      `json:"discount,omitempty"`
+	PurchaseIdentifier string     `json:"purchase_identifier,omitempty"`
+	CustomerReference  string     `json:"customer_reference,omitempty"`
+	VATInvoiceNumber   string     `json:"vat_invoice_number,omitempty"`
+	Lines              []Line     `json:"lines"`
+	Lodging            *Lodging   `json:"lodging,omitempty"`
+	CarRental          *CarRental `json:"car_rental,omitempty"`
+	Travel             *Travel    `json:"travel,omitempty"`
+}
+
+// Supplement returns the Card Purchase Supplement of a card settlement
+// Transaction, or nil when the merchant sent none.
+func Supplement(ctx context.Context, client *increase.Client, tx increase.Transaction, opts ...option.RequestOption) (*increase.CardPurchaseSupplement, error) {
+	if tx.Source.Category != increase.TransactionSourceCategoryCardSettlement {
+		return nil, ErrNotCardSettlement
+	}
+	iter := client.CardPurchaseSupplements.ListAutoPaging(ctx, increase.CardPurchaseSupplementListParams{
+		CardPaymentID: increase.F(tx.Source.CardSettlement.CardPaymentID),
+	}, opts...)
+	for iter.Next() {
+		if s := iter.Current(); s.TransactionID == tx.ID {
+			return &s, nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("receipts: listing card purchase supplements: %w", err)
+	}
+	return nil, nil
+}
+
+// Build reconstructs the receipt of a card settlement Transaction from its
+// purchase details and Card Purchase Supplement. supplement may be nil.
+func Build(tx increase.Transaction, supplement *increase.CardPurchaseSupplement) (*Receipt, error) {
+	if tx.Source.Category != increase.TransactionSourceCategoryCardSettlement {
+		return nil, ErrNotCardSettlement
+	}
+	s := tx.Source.CardSettlement
+	if supplement != nil && supplement.TransactionID != "" && supplement.TransactionID != tx.ID {
+		return nil, fmt.Errorf("receipts: card purchase supplement %s belongs to transaction %s, not %s", supplement.ID, supplement.TransactionID, tx.ID)
+	}
+	r := &Receipt{
+		TransactionID: tx.ID,
+		CardPaymentID: s.CardPaymentID,
+		Date:          date(tx.CreatedAt),
+		Merchant: Merchant{
+			Name:         s.MerchantName,
+			AcceptorID:   s.MerchantAcceptorID,
+			CategoryCode: s.MerchantCategoryCode,
+			City:         s.MerchantCity,
+			State:        s.MerchantState,
+			Country:      s.MerchantCountry,
+			PostalCode:   s.MerchantPostalCode,
+		},
+		Currency: string(s.Currency),
+		Total:    s.Amount,
+	}
+
+	pd := s.PurchaseDetails
+	if !s.JSON.PurchaseDetails.IsNull() {
+		r.PurchaseIdentifier = pd.PurchaseIdentifier
+		r.CustomerReference = pd.CustomerReferenceIdentifier
+		r.Tax = pd.LocalTaxAmount + pd.NationalTaxAmount
+	}
+	if supplement != nil {
+		r.CardPurchaseSupplementID = supplement.ID
+		r.supplement(*supplement)
+	}
+	if !s.JSON.PurchaseDetails.IsNull() {
+		if !pd.JSON.Lodging.IsNull() {
+			r.lodging(pd.Lodging)
+		}
+		if !pd.JSON.CarRental.IsNull() {
+			r.carRental(pd.CarRental)
+		}
+		if !pd.JSON.Travel.IsNull() {
+			r.travel(pd.Travel)
+		}
+	}
+
+	var itemized int64
+	for _, l := range r.Lines {
+		itemized += l.Amount
+	}
+	if rest := r.Total - itemized; rest != 0 || len(r.Lines) == 0 {
+		kind, description := KindAdjustment, "Unitemized"
+		switch {
+		case len(r.Lines) == 0 && r.Travel != nil:
+			kind, description = KindFare, "Airfare"
+			if r.Travel.TicketNumber != "" {
+				description += " " + r.Travel.TicketNumber
+			}
+		case len(r.Lines) == 0:
+			kind, description = KindItem, r.Merchant.Name
+		}
+		r.Lines = append(r.Lines, Line{Kind: kind, Description: description, Amount: rest})
+	}
+	for _, l := range r.Lines {
+		switch l.Kind {
+		case KindItem, KindCredit, KindPayment:
+			r.Subtotal += l.Amount
+		}
+	}
+	return r, nil
+}
+
+// supplement adds the line items, taxes, shipping and discounts of a Card
+// Purchase Supplement.
+func (r *Receipt) supplement(s increase.CardPurchaseSupplement) {
+	inv := s.Invoice
+	hasInvoice := !s.JSON.Invoice.IsNull()
+	treatment := inv.TaxTreatments
+	r.TaxIncluded = treatment == increase.CardPurchaseSupplementInvoiceTaxTreatmentsGrossPriceLineItemLevel ||
+		treatment == increase.CardPurchaseSupplementInvoiceTaxTreatmentsGrossPriceInvoiceLevel
+
+	var tax int64
+	for _, item := range s.LineItems {
+		l := Line{
+			Kind:          KindItem,
+			Description:   strings.TrimSpace(item.ItemDescriptor),
+			ProductCode:   item.ProductCode,
+			CommodityCode: item.ItemCommodityCode,
+			Quantity:      item.ItemQuantity,
+			UnitOfMeasure: item.UnitOfMeasureCode,
+			UnitCost:      item.UnitCost,
+			Amount:        item.TotalAmount,
+			Tax:           item.SalesTaxAmount,
+			TaxRate:       item.SalesTaxRate,
+			Discount:      item.DiscountAmount,
+		}
+		switch item.DetailIndicator {
+		case increase.CardPurchaseSupplementLineItemsDetailIndicatorCredit:
+			l.Kind, l.Amount, l.Tax = KindCredit, -abs(l.Amount), -abs(l.Tax)
+		case increase.CardPurchaseSupplementLineItemsDetailIndicatorPayment:
+			l.Kind, l.Amount, l.Tax = KindPayment, -abs(l.Amount), -abs(l.Tax)
+		}
+		if l.Description == "" {
+			l.Description = l.ProductCode
+		}
+		tax += l.Tax
+		r.Discount += item.DiscountAmount
+		r.Lines = append(r.Lines, l)
+	}
+	if !hasInvoice {
+		if tax != 0 {
+			r.Tax = tax
+		}
+		return
+	}
+
+	if inv.ShippingAmount != 0 {
+		r.Shipping = inv.ShippingAmount
+		r.Lines = append(r.Lines, Line{Kind: KindShipping, Description: "Shipping", Amount: inv.ShippingAmount, Tax: inv.ShippingTaxAmount, TaxRate: inv.ShippingTaxRate})
+	}
+	tax += inv.ShippingTaxAmount
+	if tax != 0 {
+		r.Tax = tax
+	}
+	if !r.TaxIncluded && treatment != increase.CardPurchaseSupplementInvoiceTaxTreatmentsNoTaxApplies && tax != 0 {
+		r.Lines = append(r.Lines, Line{Kind: KindTax, Description: "Tax", Amount: tax})
+	}
+	if inv.DutyTaxAmount != 0 {
+		r.Tax += inv.DutyTaxAmount
+		r.Lines = append(r.Lines, Line{Kind: KindDuty, Description: "Duty", Amount: inv.DutyTaxAmount})
+	}
+	if inv.DiscountAmount != 0 {
+		r.Discount += abs(inv.DiscountAmount)
+		r.Lines = append(r.Lines, Line{Kind: KindDiscount, Description: "Discount", Amount: -abs(inv.DiscountAmount)})
+	}
+	if !inv.OrderDate.IsZero() {
+		r.Date = date(inv.OrderDate)
+	}
+	r.VATInvoiceNumber = inv.UniqueValueAddedTaxInvoiceReference
+}
+
+// lodging adds a hotel stay. Its charges are itemized only when the
+// supplement did not itemize the receipt.
+func (r *Receipt) lodging(l increase.TransactionSourceCardSettlementPurchaseDetailsLodging) {
+	r.Lodging = &Lodging{
+		CheckIn:   date(l.CheckInDate),
+		Nights:    l.RoomNights,
+		DailyRate: l.DailyRoomRateAmount,
+		NoShow:    l.NoShowIndicator == increase.TransactionSourceCardSettlementPurchaseDetailsLodgingNoShowIndicatorNoShow,
+	}
+	if !l.CheckInDate.IsZero() && l.RoomNights > 0 {
+		r.Lodging.CheckOut = date(l.CheckInDate.AddDate(0, 0, int(l.RoomNights)))
+	}
+	tax := l.TotalTaxAmount
+	if tax == 0 {
+		tax = l.TotalRoomTaxAmount
+	}
+	if r.Tax == 0 {
+		r.Tax = tax
+	}
+	if len(r.Lines) > 0 {
+		return
+	}
+	if l.RoomNights > 0 && l.DailyRoomRateAmount != 0 {
+		r.Lines = append(r.Lines, Line{
+			Kind:          KindRoom,
+			Description:   "Room",
+			Quantity:      fmt.Sprint(l.RoomNights),
+			UnitOfMeasure: "night",
+			UnitCost:      FormatAmount(l.DailyRoomRateAmount, r.Currency),
+			Amount:        l.RoomNights * l.DailyRoomRateAmount,
+		})
+	}
+	r.add(KindTax, "Room tax", tax)
+	r.add(KindFoodBeverage, "Food and beverage", l.FoodBeverageChargesAmount)
+	r.add(KindPrepaid, "Prepaid expenses", l.PrepaidExpensesAmount)
+	r.add(KindCashAdvance, "Folio cash advances", l.FolioCashAdvancesAmount)
+}
+
+// carRental adds a car rental. Its charges are itemized only when the
+// supplement did not itemize the receipt.
+func (r *Receipt) carRental(c increase.TransactionSourceCardSettlementPurchaseDetailsCarRental) {
+	r.CarRental = &CarRental{
+		Checkout:   date(c.CheckoutDate),
+		Days:       c.DaysRented,
+		DailyRate:  c.DailyRentalRateAmount,
+		CarClass:   c.CarClassCode,
+		RenterName: c.RenterName,
+	}
+	if len(r.Lines) > 0 {
+		return
+	}
+	if c.DaysRented > 0 && c.DailyRentalRateAmount != 0 {
+		r.Lines = append(r.Lines, Line{
+			Kind:          KindRental,
+			Description:   "Car rental",
+			Quantity:      fmt.Sprint(c.DaysRented),
+			UnitOfMeasure: "day",
+			UnitCost:      FormatAmount(c.DailyRentalRateAmount, r.Currency),
+			Amount:        c.DaysRented * c.DailyRentalRateAmount,
+		})
+	}
+	r.add(KindFuel, "Fuel", c.FuelChargesAmount)
+	r.add(KindInsurance, "Insurance", c.InsuranceChargesAmount)
+	r.add(KindDropOff, "One-way drop-off", c.OneWayDropOffChargesAmount)
+}
+
+// travel adds an airline ticket and its flight legs.
+func (r *Receipt) travel(t increase.TransactionSourceCardSettlementPurchaseDetailsTravel) {
+	r.Travel = &Travel{
+		PassengerName: t.PassengerName,
+		TicketNumber:  t.TicketNumber,
+		AgencyName:    t.TravelAgencyName,
+		DepartureDate: date(t.DepartureDate),
+	}
+	from := t.OriginationCityAirportCode
+	for _, leg := range t.TripLegs {
+		r.Travel.Legs = append(r.Travel.Legs, Leg{
+			From:            from,
+			To:              leg.DestinationCityAirportCode,
+			Carrier:         leg.CarrierCode,
+			FlightNumber:    leg.FlightNumber,
+			ServiceClass:    leg.ServiceClass,
+			FareBasis:       leg.FareBasisCode,
+			StopOverAllowed: leg.StopOverCode == increase.TransactionSourceCardSettlementPurchaseDetailsTravelTripLegsStopOverCodeStopOverAllowed,
+		})
+		from = leg.DestinationCityAirportCode
+	}
+}
+
+// add adds a line when its amount is not zero.
+func (r *Receipt) add(kind Kind, description string, amount int64) {
+	if amount != 0 {
+		r.Lines = append(r.Lines, Line{Kind: kind, Description: description, Amount: amount})
+	}
+}
+
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,183 @@
+package receipts_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/receipts"
+)
+
+func settlement(t *testing.T, amount int64, purchaseDetails string) increase.Transaction {
+	var tx increase.Transaction
+	err := json.Unmarshal([]byte(`{
+		"id": "transaction_1",
+		"created_at": "2024-03-05T12:00:00Z",
+		"amount": `+itoa(-amount)+`,
+		"source": {
+			"category": "card_settlement",
+			"card_settlement": {
+				"id": "card_settlement_1",
+				"amount": `+itoa(amount)+`,
+				"currency": "USD",
+				"card_payment_id": "card_payment_1",
+				"merchant_name": "ACME SUPPLY",
+				"merchant_acceptor_id": "5665270011000168",
+				"merchant_category_code": "5111",
+				"merchant_city": "New York",
+				"merchant_country": "US",
+				"purchase_details": `+purchaseDetails+`,
+				"transaction_id": "transaction_1"
+			}
+		}
+	}`), &tx)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return tx
+}
+
+func itoa(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+const supplementJSON = `{
+	"id": "card_purchase_supplement_1",
+	"card_payment_id": "card_payment_1",
+	"transaction_id": "transaction_1",
+	"invoice": {
+		"discount_amount": 500,
+		"order_date": "2024-03-04",
+		"shipping_amount": 1000,
+		"shipping_tax_amount": 80,
+		"tax_treatments": "net_price_line_item_level",
+		"unique_value_added_tax_invoice_reference": "INV-42"
+	},
+	"line_items": [
+		{"id": "1", "detail_indicator": "normal", "item_descriptor": "Paper, A4 ", "product_code": "P-A4", "item_quantity": "4", "unit_of_measure_code": "BOX", "unit_cost": "25.00", "total_amount": 10000, "sales_tax_amount": 800, "sales_tax_rate": "0.08"},
+		{"id": "2", "detail_indicator": "credit", "product_code": "RET-1", "total_amount": 2000, "sales_tax_amount": 160}
+	],
+	"type": "card_purchase_supplement"
+}`
+
+func TestBuildSupplement(t *testing.T) {
+	var supplement increase.CardPurchaseSupplement
+	if err := json.Unmarshal([]byte(supplementJSON), &supplement); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	// 100.00 - 20.00 + 10.00 shipping + 7.20 tax - 5.00 discount, and 0.30
+	// the merchant did not itemize.
+	r, err := receipts.Build(settlement(t, 9250, "null"), &supplement)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if r.Date != "2024-03-04" || r.Subtotal != 8000 || r.Tax != 720 || r.TaxIncluded || r.Shipping != 1000 || r.Discount != 500 || r.VATInvoiceNumber != "INV-42" {
+		t.Errorf("unexpected receipt %+v", r)
+	}
+	kinds := []receipts.Kind{receipts.KindItem, receipts.KindCredit, receipts.KindShipping, receipts.KindTax, receipts.KindDiscount, receipts.KindAdjustment}
+	amounts := []int64{10000, -2000, 1000, 720, -500, 30}
+	if len(r.Lines) != len(kinds) {
+		t.Fatalf("unexpected lines %+v", r.Lines)
+	}
+	for i, l := range r.Lines {
+		if l.Kind != kinds[i] || l.Amount != amounts[i] {
+			t.Errorf("line %d: expected %s %d, got %+v", i, kinds[i], amounts[i], l)
+		}
+	}
+	if l := r.Lines[0]; l.Description != "Paper, A4" || l.Quantity != "4" || l.UnitCost != "25.00" || l.Tax != 800 {
+		t.Errorf("unexpected item %+v", l)
+	}
+	if l := r.Lines[1]; l.Description != "RET-1" || l.Tax != -160 {
+		t.Errorf("unexpected credit %+v", l)
+	}
+
+	var buf strings.Builder
+	if err := receipts.WriteCSV(&buf, r); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(rows) != 1+len(r.Lines) || !strings.HasPrefix(rows[0], "transaction_id,") {
+		t.Fatalf("unexpected CSV\n%s", buf.String())
+	}
+	if !strings.Contains(rows[1], `,1,item,"Paper, A4",P-A4,,4,BOX,25.00,100.00,8.00,0.08,0.00`) || !strings.Contains(rows[5], ",5,discount,Discount,,,,,,-5.00,") {
+		t.Errorf("unexpected CSV\n%s", buf.String())
+	}
+}
+
+func TestBuildLodging(t *testing.T) {
+	r, err := receipts.Build(settlement(t, 53000, `{
+		"lodging": {
+			"check_in_date": "2024-03-01",
+			"daily_room_rate_amount": 15000,
+			"room_nights": 3,
+			"total_room_tax_amount": 5000,
+			"food_beverage_charges_amount": 3000,
+			"no_show_indicator": "not_applicable"
+		}
+	}`), nil)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if r.Lodging == nil || r.Lodging.CheckIn != "2024-03-01" || r.Lodging.CheckOut != "2024-03-04" || r.Lodging.Nights != 3 || r.Lodging.NoShow {
+		t.Errorf("unexpected lodging %+v", r.Lodging)
+	}
+	if len(r.Lines) != 3 || r.Lines[0].Kind != receipts.KindRoom || r.Lines[0].Amount != 45000 || r.Lines[0].Quantity != "3" || r.Lines[0].UnitCost != "150.00" {
+		t.Fatalf("unexpected lines %+v", r.Lines)
+	}
+	if r.Tax != 5000 || r.Lines[1].Kind != receipts.KindTax || r.Lines[2].Kind != receipts.KindFoodBeverage {
+		t.Errorf("unexpected lines %+v", r.Lines)
+	}
+}
+
+func TestBuildTravel(t *testing.T) {
+	r, err := receipts.Build(settlement(t, 45000, `{
+		"travel": {
+			"passenger_name": "LOVELACE/ADA",
+			"ticket_number": "0012345678901",
+			"origination_city_airport_code": "JFK",
+			"trip_legs": [
+				{"carrier_code": "AA", "destination_city_airport_code": "ORD", "flight_number": "100", "service_class": "Y", "stop_over_code": "stop_over_allowed"},
+				{"carrier_code": "AA", "destination_city_airport_code": "SFO", "flight_number": "200", "service_class": "Y", "stop_over_code": "none"}
+			]
+		}
+	}`), nil)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(r.Lines) != 1 || r.Lines[0].Kind != receipts.KindFare || r.Lines[0].Amount != 45000 || r.Lines[0].Description != "Airfare 0012345678901" {
+		t.Errorf("unexpected lines %+v", r.Lines)
+	}
+	legs := r.Travel.Legs
+	if len(legs) != 2 || legs[0].From != "JFK" || legs[0].To != "ORD" || !legs[0].StopOverAllowed || legs[1].From != "ORD" || legs[1].To != "SFO" || legs[1].StopOverAllowed {
+		t.Errorf("unexpected legs %+v", legs)
+	}
+}
+
+func TestBuildNotSettlement(t *testing.T) {
+	tx := increase.Transaction{ID: "transaction_1", Source: increase.TransactionSource{Category: increase.TransactionSourceCategoryACHTransferIntention}}
+	if _, err := receipts.Build(tx, nil); !errors.Is(err, receipts.ErrNotCardSettlement) {
+		t.Errorf("expected ErrNotCardSettlement, got %v", err)
+	}
+}
+
+func TestSupplement(t *testing.T) {
+	var query string
+	client := testapi.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"data": [{"id": "card_purchase_supplement_0", "transaction_id": "transaction_0"}, ` + supplementJSON + `], "next_cursor": null}`))
+	}))
+
+	supplement, err := receipts.Supplement(context.Background(), client, settlement(t, 9250, "null"))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if supplement == nil || supplement.ID != "card_purchase_supplement_1" || query != "card_payment_id=card_payment_1" {
+		t.Errorf("unexpected supplement %+v for query %s", supplement, query)
+	}
+}
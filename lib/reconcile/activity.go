@@ -0,0 +1,214 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// Kind is the kind of an Activity.
+type Kind string
+
+const (
+	KindTransaction         Kind = "transaction"
+	KindPendingTransaction  Kind = "pending_transaction"
+	KindDeclinedTransaction Kind = "declined_transaction"
+)
+
+// Activity is a Transaction, Pending Transaction or Declined Transaction.
+type Activity struct {
+	Kind        Kind
+	ID          string
+	AccountID   string
+	Amount      int64
+	Date        time.Time
+	Description string
+	// The source category, such as "ach_transfer_intention".
+	Category string
+	// The ID of the transfer or check deposit the activity belongs to, when
+	// its source has one.
+	TransferID string
+}
+
+// FromTransaction returns the Activity of a Transaction.
+func FromTransaction(tx increase.Transaction) Activity {
+	return Activity{
+		Kind:        KindTransaction,
+		ID:          tx.ID,
+		AccountID:   tx.AccountID,
+		Amount:      tx.Amount,
+		Date:        tx.CreatedAt,
+		Description: tx.Description,
+		Category:    string(tx.Source.Category),
+		TransferID:  TransactionTransferID(tx),
+	}
+}
+
+// FromPendingTransaction returns the Activity of a Pending Transaction.
+func FromPendingTransaction(p increase.PendingTransaction) Activity {
+	return Activity{
+		Kind:        KindPendingTransaction,
+		ID:          p.ID,
+		AccountID:   p.AccountID,
+		Amount:      p.Amount,
+		Date:        p.CreatedAt,
+		Description: p.Description,
+		Category:    string(p.Source.Category),
+		TransferID:  PendingTransactionTransferID(p),
+	}
+}
+
+// FromDeclinedTransaction returns the Activity of a Declined Transaction.
+func FromDeclinedTransaction(d increase.DeclinedTransaction) Activity {
+	return Activity{
+		Kind:        KindDeclinedTransaction,
+		ID:          d.ID,
+		AccountID:   d.AccountID,
+		Amount:      d.Amount,
+		Date:        d.CreatedAt,
+		Description: d.Description,
+		Category:    string(d.Source.Category),
+		TransferID:  DeclinedTransactionTransferID(d),
+	}
+}
+
+// Fetch lists the Transactions, open Pending Transactions and Declined
+// Transactions of an Account created in [from, to). accountID may be empty
+// to list the activity of every Account.
+func Fetch(ctx context.Context, client *increase.Client, accountID string, from, to time.Time, opts ...option.RequestOption) ([]Activity, error) {
+	var res []Activity
+
+	txParams := increase.TransactionListParams{CreatedAt: increase.F(increase.TransactionListParamsCreatedAt{OnOrAfter: increase.F(from), Before: increase.F(to)})}
+	if accountID != "" {
+		txParams.AccountID = increase.F(accountID)
+	}
+	txs := client.Transactions.ListAutoPaging(ctx, txParams, opts...)
+	for txs.Next() {
+		res = append(res, FromTransaction(txs.Current()))
+	}
+	if err := txs.Err(); err != nil {
+		return nil, fmt.Errorf("reconcile: listing transactions: %w", err)
+	}
+
+	pendingParams := increase.PendingTransactionListParams{
+		CreatedAt: increase.F(increase.PendingTransactionListParamsCreatedAt{OnOrAfter: increase.F(from), Before: increase.F(to)}),
+		Status:    increase.F(increase.PendingTransactionListParamsStatus{In: increase.F([]increase.PendingTransactionListParamsStatusIn{increase.PendingTransactionListParamsStatusInPending})}),
+	}
+	if accountID != "" {
+		pendingParams.AccountID = increase.F(accountID)
+	}
+	pending := client.PendingTransactions.ListAutoPaging(ctx, pendingParams, opts...)
+	for pending.Next() {
+		res = append(res, FromPendingTransaction(pending.Current()))
+	}
+	if err := pending.Err(); err != nil {
+		return nil, fmt.Errorf("reconcile: listing pending transactions: %w", err)
+	}
+
+	declinedParams := increase.DeclinedTransactionListParams{CreatedAt: increase.F(increase.DeclinedTransactionListParamsCreatedAt{OnOrAfter: increase.F(from), Before: increase.F(to)})}
+	if accountID != "" {
+		declinedParams.AccountID = increase.F(accountID)
+	}
+	declined := client.DeclinedTransactions.ListAutoPaging(ctx, declinedParams, opts...)
+	for declined.Next() {
+		res = append(res, FromDeclinedTransaction(declined.Current()))
+	}
+	if err := declined.Err(); err != nil {
+		return nil, fmt.Errorf("reconcile: listing declined transactions: %w", err)
+	}
+	return res, nil
+}
+
+// TransactionTransferID returns the ID of the transfer or check deposit of a
+// Transaction, or "".
+func TransactionTransferID(tx increase.Transaction) string {
+	s := tx.Source
+	switch s.Category {
+	case increase.TransactionSourceCategoryAccountTransferIntention:
+		return s.AccountTransferIntention.TransferID
+	case increase.TransactionSourceCategoryACHTransferIntention:
+		return s.ACHTransferIntention.TransferID
+	case increase.TransactionSourceCategoryACHTransferRejection:
+		return s.ACHTransferRejection.TransferID
+	case increase.TransactionSourceCategoryACHTransferReturn:
+		return s.ACHTransferReturn.TransferID
+	case increase.TransactionSourceCategoryCardPushTransferAcceptance:
+		return s.CardPushTransferAcceptance.TransferID
+	case increase.TransactionSourceCategoryCheckDepositAcceptance:
+		return s.CheckDepositAcceptance.CheckDepositID
+	case increase.TransactionSourceCategoryCheckDepositReturn:
+		return s.CheckDepositReturn.CheckDepositID
+	case increase.TransactionSourceCategoryCheckTransferDeposit:
+		return s.CheckTransferDeposit.TransferID
+	case increase.TransactionSourceCategoryFednowTransferAcknowledgement:
+		return s.FednowTransferAcknowledgement.TransferID
+	case increase.TransactionSourceCategoryInboundACHTransfer:
+		return s.InboundACHTransfer.TransferID
+	case increase.TransactionSourceCategoryInboundFednowTransferConfirmation:
+		return s.InboundFednowTransferConfirmation.TransferID
+	case increase.TransactionSourceCategoryInboundRealTimePaymentsTransferConfirmation:
+		return s.InboundRealTimePaymentsTransferConfirmation.TransferID
+	case increase.TransactionSourceCategoryInboundWireTransfer:
+		return s.InboundWireTransfer.TransferID
+	case increase.TransactionSourceCategoryRealTimePaymentsTransferAcknowledgement:
+		return s.RealTimePaymentsTransferAcknowledgement.TransferID
+	case increase.TransactionSourceCategorySwiftTransferIntention:
+		return s.SwiftTransferIntention.TransferID
+	case increase.TransactionSourceCategorySwiftTransferReturn:
+		return s.SwiftTransferReturn.TransferID
+	case increase.TransactionSourceCategoryWireTransferIntention:
+		return s.WireTransferIntention.TransferID
+	}
+	return ""
+}
+
+// PendingTransactionTransferID returns the ID of the transfer or check deposit
+// of a Pending Transaction, or "".
+func PendingTransactionTransferID(p increase.PendingTransaction) string {
+	s := p.Source
+	switch s.Category {
+	case increase.PendingTransactionSourceCategoryAccountTransferInstruction:
+		return s.AccountTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryACHTransferInstruction:
+		return s.ACHTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryCardPushTransferInstruction:
+		return s.CardPushTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryCheckDepositInstruction:
+		return s.CheckDepositInstruction.CheckDepositID
+	case increase.PendingTransactionSourceCategoryCheckTransferInstruction:
+		return s.CheckTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryFednowTransferInstruction:
+		return s.FednowTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryRealTimePaymentsTransferInstruction:
+		return s.RealTimePaymentsTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategorySwiftTransferInstruction:
+		return s.SwiftTransferInstruction.TransferID
+	case increase.PendingTransactionSourceCategoryWireTransferInstruction:
+		return s.WireTransferInstruction.TransferID
+	}
+	return ""
+}
+
+// DeclinedTransactionTransferID returns the ID of the transfer or check
+// deposit of a Declined Transaction, or "".
+func DeclinedTransactionTransferID(d increase.DeclinedTransaction) string {
+	s := d.Source
+	switch s.Category {
+	case increase.DeclinedTransactionSourceCategoryACHDecline:
+		return s.ACHDecline.InboundACHTransferID
+	case increase.DeclinedTransactionSourceCategoryCheckDecline:
+		return s.CheckDecline.CheckTransferID
+	case increase.DeclinedTransactionSourceCategoryCheckDepositRejection:
+		return s.CheckDepositRejection.CheckDepositID
+	case increase.DeclinedTransactionSourceCategoryInboundFednowTransferDecline:
+		return s.InboundFednowTransferDecline.TransferID
+	case increase.DeclinedTransactionSourceCategoryInboundRealTimePaymentsTransferDecline:
+		return s.InboundRealTimePaymentsTransferDecline.TransferID
+	case increase.DeclinedTransactionSourceCategoryWireDecline:
+		return s.WireDecline.InboundWireTransferID
+	}
+	return ""
+}
@@ -0,0 +1,332 @@
+// Package reconcile reconciles internal payment records against the activity
+// of Increase Accounts.
+//
+// [Fetch] lists the Transactions, Pending Transactions and Declined
+// Transactions of a window, and a [Reconciler] matches them to the entries
+// you expect: by transfer ID first, resolving idempotency keys to transfer
+// IDs, and otherwise by amount and date.
+//
+//	activity, err := reconcile.Fetch(ctx, client, accountID, from, to)
+//	if err != nil {
+//		return err
+//	}
+//	r := reconcile.New(reconcile.ClientResolver(client))
+//	report, err := r.Reconcile(ctx, expected, activity)
+//	for _, res := range report.Unmatched {
+//		log.Println(res.Reason)
+//	}
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Expected is an entry of your records that should appear on Increase.
+type Expected struct {
+	// Your reference for the entry.
+	ID string
+	// The Increase Account. Optional.
+	AccountID string
+	// The amount in the minor unit of the Account's currency, signed as it
+	// appears on the Account: negative for money that leaves it.
+	Amount int64
+	// The date the entry should post. Optional.
+	Date time.Time
+	// The ID of the transfer or check deposit. Optional.
+	TransferID string
+	// The idempotency key the transfer was created with, used to look up its
+	// ID when TransferID is empty. Optional.
+	IdempotencyKey string
+	// The rail of the transfer, which narrows the idempotency key lookup.
+	// Optional.
+	Rail Rail
+}
+
+// Status is the outcome of reconciling an entry or an activity.
+type Status string
+
+const (
+	// The entry posted as expected.
+	StatusMatched Status = "matched"
+	// The entry was found but is pending, or posted with another amount,
+	// on another date, or was later returned.
+	StatusPartial Status = "partial"
+	// The entry was not found, was declined, or the activity was not
+	// expected.
+	StatusUnmatched Status = "unmatched"
+	// The entry repeats another one, or the activity repeats an activity that
+	// already matched.
+	StatusDuplicate Status = "duplicate"
+)
+
+// Result is the outcome of reconciling an expected entry, or an activity
+// nothing expected.
+type Result struct {
+	Status Status
+	// Nil for activity that was not expected.
+	Expected *Expected
+	Activity []Activity
+	// Why the result is not a match.
+	Reason string
+}
+
+// Report is the outcome of a reconciliation.
+type Report struct {
+	Matched    []Result
+	Partial    []Result
+	Unmatched  []Result
+	Duplicates []Result
+}
+
+func (r *Report) add(res Result) {
+	switch res.Status {
+	case StatusMatched:
+		r.Matched = append(r.Matched, res)
+	case StatusPartial:
+		r.Partial = append(r.Partial, res)
+	case StatusUnmatched:
+		r.Unmatched = append(r.Unmatched, res)
+	case StatusDuplicate:
+		r.Duplicates = append(r.Duplicates, res)
+	}
+}
+
+// Reconciler matches expected entries to activity.
+type Reconciler struct {
+	// How far apart the expected and actual dates of an entry may be.
+	// Defaults to three days.
+	DateTolerance time.Duration
+	// Looks up the transfer IDs of idempotency keys. Optional.
+	Resolver Resolver
+}
+
+// New returns a reconciler that looks up idempotency keys with resolver.
+// resolver may be nil.
+func New(resolver Resolver) *Reconciler {
+	return &Reconciler{DateTolerance: 72 * time.Hour, Resolver: resolver}
+}
+
+// Reconcile matches expected entries to activity. Every expected entry and
+// every activity appears in exactly one result.
+func (r *Reconciler) Reconcile(ctx context.Context, expected []Expected, activity []Activity) (*Report, error) {
+	report := &Report{}
+	used := make([]bool, len(activity))
+	byTransfer := map[string][]int{}
+	for i, a := range activity {
+		if a.TransferID != "" {
+			byTransfer[a.TransferID] = append(byTransfer[a.TransferID], i)
+		}
+	}
+
+	var byTransferID, byAmount []*Expected
+	keys, transfers := map[string]string{}, map[string]string{}
+	for i := range expected {
+		e := &expected[i]
+		if e.IdempotencyKey != "" {
+			if other, ok := keys[e.IdempotencyKey]; ok {
+				report.add(Result{Status: StatusDuplicate, Expected: e, Reason: fmt.Sprintf("idempotency key %s is also used by %s", e.IdempotencyKey, other)})
+				continue
+			}
+			keys[e.IdempotencyKey] = e.ID
+		}
+		transferID, err := r.transferID(ctx, *e)
+		if err != nil {
+			return nil, err
+		}
+		if transferID == "" {
+			byAmount = append(byAmount, e)
+			continue
+		}
+		if other, ok := transfers[transferID]; ok {
+			report.add(Result{Status: StatusDuplicate, Expected: e, Reason: fmt.Sprintf("transfer %s is also expected by %s", transferID, other)})
+			continue
+		}
+		transfers[transferID] = e.ID
+		resolved := *e
+		resolved.TransferID = transferID
+		byTransferID = append(byTransferID, &resolved)
+	}
+
+	for _, e := range byTransferID {
+		var acts []Activity
+		for _, i := range byTransfer[e.TransferID] {
+			if e.AccountID == "" || activity[i].AccountID == e.AccountID {
+				acts = append(acts, activity[i])
+				used[i] = true
+			}
+		}
+		report.add(r.transfer(e, acts))
+	}
+
+	// Match the remaining entries by amount and date, earliest first, to the
+	// closest activity.
+	sort.SliceStable(byAmount, func(i, j int) bool { return byAmount[i].Date.Before(byAmount[j].Date) })
+	var matched []Result
+	for _, e := range byAmount {
+		best := -1
+		for i, a := range activity {
+			if used[i] || !r.candidate(*e, a) {
+				continue
+			}
+			if best == -1 || rank(*e, a) < rank(*e, activity[best]) {
+				best = i
+			}
+		}
+		if best == -1 {
+			report.add(Result{Status: StatusUnmatched, Expected: e, Reason: fmt.Sprintf("no activity of %d within %s of %s", e.Amount, r.DateTolerance, e.Date.Format(time.DateOnly))})
+			continue
+		}
+		used[best] = true
+		res := Result{Status: StatusMatched, Expected: e, Activity: []Activity{activity[best]}}
+		if activity[best].Kind == KindPendingTransaction {
+			res.Status, res.Reason = StatusPartial, "pending"
+		}
+		matched = append(matched, res)
+		report.add(res)
+	}
+
+	for i, a := range activity {
+		if used[i] {
+			continue
+		}
+		if a.Kind == KindDeclinedTransaction {
+			report.add(Result{Status: StatusUnmatched, Activity: []Activity{a}, Reason: fmt.Sprintf("declined %s was not expected", a.Category)})
+			continue
+		}
+		res := Result{Status: StatusUnmatched, Activity: []Activity{a}, Reason: "not expected"}
+		for _, m := range matched {
+			if r.candidate(*m.Expected, a) {
+				res = Result{Status: StatusDuplicate, Expected: m.Expected, Activity: []Activity{a}, Reason: fmt.Sprintf("repeats %s, which matched %s", m.Activity[0].ID, m.Expected.ID)}
+				break
+			}
+		}
+		report.add(res)
+	}
+	return report, nil
+}
+
+// transferID returns the transfer ID of an entry, looking up its idempotency
+// key when it has none.
+func (r *Reconciler) transferID(ctx context.Context, e Expected) (string, error) {
+	if e.TransferID != "" || e.IdempotencyKey == "" || r.Resolver == nil {
+		return e.TransferID, nil
+	}
+	rails := Rails
+	if e.Rail != "" {
+		rails = []Rail{e.Rail}
+	}
+	for _, rail := range rails {
+		id, err := r.Resolver(ctx, rail, e.IdempotencyKey)
+		if err != nil {
+			return "", fmt.Errorf("reconcile: looking up idempotency key %s: %w", e.IdempotencyKey, err)
+		}
+		if id != "" {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+// transfer reconciles an entry with the activity of its transfer.
+func (r *Reconciler) transfer(e *Expected, acts []Activity) Result {
+	res := Result{Expected: e, Activity: acts}
+	var posted, pending, declined []Activity
+	for _, a := range acts {
+		switch a.Kind {
+		case KindTransaction:
+			posted = append(posted, a)
+		case KindPendingTransaction:
+			pending = append(pending, a)
+		case KindDeclinedTransaction:
+			declined = append(declined, a)
+		}
+	}
+	var reasons []string
+	switch {
+	case len(posted) > 0:
+		res.Status = StatusMatched
+		var amount, net int64
+		var date time.Time
+		for _, a := range posted {
+			net += a.Amount
+			if (a.Amount < 0) == (e.Amount < 0) {
+				amount += a.Amount
+				if date.IsZero() {
+					date = a.Date
+				}
+			}
+		}
+		if amount != e.Amount {
+			reasons = append(reasons, fmt.Sprintf("posted %d, expected %d", amount, e.Amount))
+		} else if net != amount {
+			reasons = append(reasons, fmt.Sprintf("returned or reversed, net %d", net))
+		}
+		if !date.IsZero() && !r.near(e.Date, date) {
+			reasons = append(reasons, fmt.Sprintf("posted %s, expected %s", date.Format(time.DateOnly), e.Date.Format(time.DateOnly)))
+		}
+	case len(pending) > 0:
+		res.Status = StatusPartial
+		reasons = append(reasons, "pending")
+		var amount int64
+		for _, a := range pending {
+			amount += a.Amount
+		}
+		if amount != e.Amount {
+			reasons = append(reasons, fmt.Sprintf("pending %d, expected %d", amount, e.Amount))
+		}
+	case len(declined) > 0:
+		res.Status = StatusUnmatched
+		reasons = append(reasons, fmt.Sprintf("declined (%s)", declined[0].Category))
+	default:
+		res.Status = StatusUnmatched
+		reasons = append(reasons, fmt.Sprintf("no activity for transfer %s", e.TransferID))
+	}
+	if res.Status == StatusMatched && len(reasons) > 0 {
+		res.Status = StatusPartial
+	}
+	res.Reason = strings.Join(reasons, "; ")
+	return res
+}
+
+// candidate reports whether an activity could be an entry without a transfer
+// ID.
+func (r *Reconciler) candidate(e Expected, a Activity) bool {
+	if a.Kind == KindDeclinedTransaction || a.Amount != e.Amount {
+		return false
+	}
+	if e.AccountID != "" && a.AccountID != e.AccountID {
+		return false
+	}
+	return r.near(e.Date, a.Date)
+}
+
+func (r *Reconciler) near(expected, actual time.Time) bool {
+	if expected.IsZero() {
+		return true
+	}
+	return abs(actual.Sub(expected)) <= r.DateTolerance
+}
+
+// rank orders the candidates of an entry: Transactions before Pending
+// Transactions, then the closest date.
+func rank(e Expected, a Activity) time.Duration {
+	d := abs(a.Date.Sub(e.Date))
+	if e.Date.IsZero() {
+		d = 0
+	}
+	if a.Kind == KindPendingTransaction {
+		d += 1 << 62
+	}
+	return d
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,161 @@
+package reconcile_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/reconcile"
+)
+
+func date(day int) time.Time {
+	return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC)
+}
+
+func server(t *testing.T) *increase.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /transactions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [
+			{"id": "tx_ach", "account_id": "account_1", "amount": -10000, "created_at": "2024-03-02T12:00:00Z", "source": {"category": "ach_transfer_intention", "ach_transfer_intention": {"transfer_id": "ach_transfer_1"}}},
+			{"id": "tx_card", "account_id": "account_1", "amount": -2500, "created_at": "2024-03-03T12:00:00Z", "source": {"category": "card_settlement", "card_settlement": {}}}
+		], "next_cursor": null}`))
+	})
+	mux.HandleFunc("GET /pending_transactions", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status.in") != "pending" {
+			t.Errorf("expected only open pending transactions to be listed, got %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"data": [
+			{"id": "pending_wire", "account_id": "account_1", "amount": -50000, "status": "pending", "created_at": "2024-03-04T12:00:00Z", "source": {"category": "wire_transfer_instruction", "wire_transfer_instruction": {"transfer_id": "wire_transfer_1"}}}
+		], "next_cursor": null}`))
+	})
+	mux.HandleFunc("GET /declined_transactions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [
+			{"id": "declined_ach", "account_id": "account_1", "amount": 700, "created_at": "2024-03-05T12:00:00Z", "source": {"category": "ach_decline", "ach_decline": {"inbound_ach_transfer_id": "inbound_ach_transfer_1"}}}
+		], "next_cursor": null}`))
+	})
+	mux.HandleFunc("GET /ach_transfers", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("idempotency_key") == "payroll-1" {
+			w.Write([]byte(`{"data": [{"id": "ach_transfer_1"}], "next_cursor": null}`))
+			return
+		}
+		w.Write([]byte(`{"data": [], "next_cursor": null}`))
+	})
+	return testapi.NewClient(t, mux)
+}
+
+func TestFetch(t *testing.T) {
+	client := server(t)
+	activity, err := reconcile.Fetch(context.Background(), client, "account_1", date(1), date(8))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(activity) != 4 {
+		t.Fatalf("expected 4 activities, got %+v", activity)
+	}
+	for i, expected := range []reconcile.Activity{
+		{Kind: reconcile.KindTransaction, ID: "tx_ach", TransferID: "ach_transfer_1"},
+		{Kind: reconcile.KindTransaction, ID: "tx_card"},
+		{Kind: reconcile.KindPendingTransaction, ID: "pending_wire", TransferID: "wire_transfer_1"},
+		{Kind: reconcile.KindDeclinedTransaction, ID: "declined_ach", TransferID: "inbound_ach_transfer_1"},
+	} {
+		a := activity[i]
+		if a.Kind != expected.Kind || a.ID != expected.ID || a.TransferID != expected.TransferID {
+			t.Errorf("activity %d: expected %+v, got %+v", i, expected, a)
+		}
+	}
+
+	resolve := reconcile.ClientResolver(client)
+	if id, err := resolve(context.Background(), reconcile.RailACH, "payroll-1"); err != nil || id != "ach_transfer_1" {
+		t.Errorf("expected ach_transfer_1, got %q, %v", id, err)
+	}
+	if id, err := resolve(context.Background(), reconcile.RailACH, "payroll-2"); err != nil || id != "" {
+		t.Errorf("expected no transfer, got %q, %v", id, err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	activity := []reconcile.Activity{
+		{Kind: reconcile.KindTransaction, ID: "tx_ach", AccountID: "account_1", Amount: -10000, Date: date(2), TransferID: "ach_transfer_1"},
+		{Kind: reconcile.KindTransaction, ID: "tx_rtp", AccountID: "account_1", Amount: -4000, Date: date(2), TransferID: "real_time_payments_transfer_1"},
+		{Kind: reconcile.KindTransaction, ID: "tx_return", AccountID: "account_1", Amount: 3000, Date: date(6), TransferID: "ach_transfer_2"},
+		{Kind: reconcile.KindTransaction, ID: "tx_ach_2", AccountID: "account_1", Amount: -3000, Date: date(3), TransferID: "ach_transfer_2"},
+		{Kind: reconcile.KindPendingTransaction, ID: "pending_wire", AccountID: "account_1", Amount: -50000, Date: date(4), TransferID: "wire_transfer_1"},
+		{Kind: reconcile.KindDeclinedTransaction, ID: "declined_check", AccountID: "account_1", Amount: -900, Date: date(4), Category: "check_decline", TransferID: "check_transfer_1"},
+		{Kind: reconcile.KindTransaction, ID: "tx_card", AccountID: "account_1", Amount: -2500, Date: date(3)},
+		{Kind: reconcile.KindTransaction, ID: "tx_card_again", AccountID: "account_1", Amount: -2500, Date: date(3)},
+		{Kind: reconcile.KindTransaction, ID: "tx_interest", AccountID: "account_1", Amount: 12, Date: date(5)},
+		{Kind: reconcile.KindDeclinedTransaction, ID: "declined_ach", AccountID: "account_1", Amount: 700, Date: date(5), Category: "ach_decline"},
+	}
+	expected := []reconcile.Expected{
+		{ID: "payroll", Amount: -10000, Date: date(1), IdempotencyKey: "payroll-1", Rail: reconcile.RailACH},
+		{ID: "payroll-retry", Amount: -10000, Date: date(1), IdempotencyKey: "payroll-1", Rail: reconcile.RailACH},
+		{ID: "vendor", Amount: -5000, Date: date(2), TransferID: "real_time_payments_transfer_1"},
+		{ID: "refund", Amount: -3000, Date: date(3), TransferID: "ach_transfer_2"},
+		{ID: "rent", Amount: -50000, Date: date(4), TransferID: "wire_transfer_1"},
+		{ID: "check", Amount: -900, Date: date(4), TransferID: "check_transfer_1"},
+		{ID: "software", AccountID: "account_1", Amount: -2500, Date: date(2)},
+		{ID: "late", Amount: -1000, Date: date(1)},
+	}
+	r := reconcile.New(func(ctx context.Context, rail reconcile.Rail, key string) (string, error) {
+		if rail == reconcile.RailACH && key == "payroll-1" {
+			return "ach_transfer_1", nil
+		}
+		return "", nil
+	})
+	report, err := r.Reconcile(context.Background(), expected, activity)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	results := map[string]reconcile.Result{}
+	for _, set := range [][]reconcile.Result{report.Matched, report.Partial, report.Unmatched, report.Duplicates} {
+		for _, res := range set {
+			key := ""
+			if res.Expected != nil {
+				key = res.Expected.ID
+			}
+			if len(res.Activity) > 0 && (res.Expected == nil || res.Status == reconcile.StatusDuplicate && res.Expected.ID == "software") {
+				key = res.Activity[0].ID
+			}
+			results[key] = res
+		}
+	}
+	for key, want := range map[string]struct {
+		status reconcile.Status
+		reason string
+	}{
+		"payroll":       {reconcile.StatusMatched, ""},
+		"payroll-retry": {reconcile.StatusDuplicate, "idempotency key payroll-1"},
+		"vendor":        {reconcile.StatusPartial, "posted -4000, expected -5000"},
+		"refund":        {reconcile.StatusPartial, "returned or reversed, net 0"},
+		"rent":          {reconcile.StatusPartial, "pending"},
+		"check":         {reconcile.StatusUnmatched, "declined (check_decline)"},
+		"software":      {reconcile.StatusMatched, ""},
+		"late":          {reconcile.StatusUnmatched, "no activity of -1000"},
+		"tx_card_again": {reconcile.StatusDuplicate, "which matched software"},
+		"tx_interest":   {reconcile.StatusUnmatched, "not expected"},
+		"declined_ach":  {reconcile.StatusUnmatched, "declined ach_decline"},
+	} {
+		res, ok := results[key]
+		if !ok {
+			t.Errorf("%s: missing result", key)
+			continue
+		}
+		if res.Status != want.status || !strings.Contains(res.Reason, want.reason) || (want.reason == "" && res.Reason != "") {
+			t.Errorf("%s: expected %s %q, got %s %q", key, want.status, want.reason, res.Status, res.Reason)
+		}
+	}
+	if res := results["software"]; len(res.Activity) != 1 || res.Activity[0].ID != "tx_card" {
+		t.Errorf("expected software to match tx_card, got %+v", res.Activity)
+	}
+	if res := results["refund"]; len(res.Activity) != 2 {
+		t.Errorf("expected refund to include its return, got %+v", res.Activity)
+	}
+	if n := len(report.Matched) + len(report.Partial) + len(report.Unmatched) + len(report.Duplicates); n != 11 {
+		t.Errorf("expected 11 results, got %d", n)
+	}
+}
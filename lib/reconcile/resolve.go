@@ -0,0 +1,70 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+	"github.com/Increase/increase-go/packages/pagination"
+)
+
+// Rail is the kind of transfer an expected entry was created as.
+type Rail string
+
+const (
+	RailACH              Rail = "ach"
+	RailWire             Rail = "wire"
+	RailRealTimePayments Rail = "real_time_payments"
+	RailFednow           Rail = "fednow"
+	RailCheck            Rail = "check"
+	RailAccountTransfer  Rail = "account_transfer"
+	RailSwift            Rail = "swift"
+	RailCardPush         Rail = "card_push"
+)
+
+// Rails are the rails a [Resolver] searches when an expected entry has none.
+var Rails = []Rail{RailACH, RailWire, RailRealTimePayments, RailFednow, RailCheck, RailAccountTransfer, RailSwift, RailCardPush}
+
+// Resolver returns the ID of the transfer created on a rail with an
+// idempotency key, or "" when there is none.
+type Resolver func(ctx context.Context, rail Rail, idempotencyKey string) (string, error)
+
+// ClientResolver returns a Resolver that lists the transfers of a rail by
+// idempotency key.
+func ClientResolver(client *increase.Client, opts ...option.RequestOption) Resolver {
+	return func(ctx context.Context, rail Rail, key string) (string, error) {
+		switch rail {
+		case RailACH:
+			return first(client.ACHTransfers.List(ctx, increase.ACHTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.ACHTransfer) string { return t.ID })
+		case RailWire:
+			return first(client.WireTransfers.List(ctx, increase.WireTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.WireTransfer) string { return t.ID })
+		case RailRealTimePayments:
+			return first(client.RealTimePaymentsTransfers.List(ctx, increase.RealTimePaymentsTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.RealTimePaymentsTransfer) string { return t.ID })
+		case RailFednow:
+			return first(client.FednowTransfers.List(ctx, increase.FednowTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.FednowTransfer) string { return t.ID })
+		case RailCheck:
+			return first(client.CheckTransfers.List(ctx, increase.CheckTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.CheckTransfer) string { return t.ID })
+		case RailAccountTransfer:
+			return first(client.AccountTransfers.List(ctx, increase.AccountTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.AccountTransfer) string { return t.ID })
+		case RailSwift:
+			return first(client.SwiftTransfers.List(ctx, increase.SwiftTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.SwiftTransfer) string { return t.ID })
+		case RailCardPush:
+			return first(client.CardPushTransfers.List(ctx, increase.CardPushTransferListParams{IdempotencyKey: increase.F(key), Limit: increase.F(int64(1))}, opts...))(func(t increase.CardPushTransfer) string { return t.ID })
+		}
+		return "", fmt.Errorf("reconcile: unknown rail %q", rail)
+	}
+}
+
+// first returns a function that returns the ID of the first item of a page.
+func first[T any](page *pagination.Page[T], err error) func(id func(T) string) (string, error) {
+	return func(id func(T) string) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		if len(page.Data) == 0 {
+			return "", nil
+		}
+		return id(page.Data[0]), nil
+	}
+}
@@ -0,0 +1,315 @@
+package spendcontrols
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Increase/increase-go"
+)
+
+// Interval is the period a spending limit applies to.
+type Interval string
+
+const (
+	IntervalAllTime        Interval = "all_time"
+	IntervalPerTransaction Interval = "per_transaction"
+	IntervalPerDay         Interval = "per_day"
+	IntervalPerWeek        Interval = "per_week"
+	IntervalPerMonth       Interval = "per_month"
+)
+
+func (i Interval) valid() bool {
+	switch i {
+	case IntervalAllTime, IntervalPerTransaction, IntervalPerDay, IntervalPerWeek, IntervalPerMonth:
+		return true
+	}
+	return false
+}
+
+// SpendingLimit caps the amount settled on a multi-use card over an interval.
+type SpendingLimit struct {
+	Interval Interval
+	// The maximum settlement amount in cents.
+	Amount int64
+	// Restricts the limit to these merchant category codes. Empty means every
+	// merchant.
+	MerchantCategoryCodes []string
+}
+
+// Comparison is how a single-use card's settlement amount is checked.
+type Comparison string
+
+const (
+	ComparisonEquals           Comparison = "equals"
+	ComparisonLessThanOrEquals Comparison = "less_than_or_equals"
+)
+
+// SingleUse makes the card single-use, settling once for an amount.
+type SingleUse struct {
+	Comparison Comparison
+	// The settlement amount in cents.
+	Amount int64
+}
+
+// Profile is a named, reusable set of authorization controls. Each merchant
+// dimension may either allow or block a list of values, not both.
+type Profile struct {
+	Name string
+
+	AllowedMerchantCategoryCodes       []string
+	BlockedMerchantCategoryCodes       []string
+	AllowedMerchantAcceptorIdentifiers []string
+	BlockedMerchantAcceptorIdentifiers []string
+	// ISO 3166-1 alpha-2 country codes.
+	AllowedMerchantCountries []string
+	BlockedMerchantCountries []string
+
+	// Limits of a multi-use card. Mutually exclusive with SingleUse.
+	SpendingLimits []SpendingLimit
+	SingleUse      *SingleUse
+}
+
+// Validate checks the profile for values Increase would reject.
+func (p Profile) Validate() error {
+	var errs []error
+	if p.Name == "" {
+		errs = append(errs, errors.New("missing name"))
+	}
+	exclusive := func(dimension string, allowed, blocked []string) {
+		if len(allowed) > 0 && len(blocked) > 0 {
+			errs = append(errs, fmt.Errorf("%s cannot be both allowed and blocked", dimension))
+		}
+	}
+	exclusive("merchant category codes", p.AllowedMerchantCategoryCodes, p.BlockedMerchantCategoryCodes)
+	exclusive("merchant acceptor identifiers", p.AllowedMerchantAcceptorIdentifiers, p.BlockedMerchantAcceptorIdentifiers)
+	exclusive("merchant countries", p.AllowedMerchantCountries, p.BlockedMerchantCountries)
+
+	codes := append(append([]string{}, p.AllowedMerchantCategoryCodes...), p.BlockedMerchantCategoryCodes...)
+	for _, l := range p.SpendingLimits {
+		codes = append(codes, l.MerchantCategoryCodes...)
+	}
+	for _, c := range codes {
+		if !isDigits(c, 4) {
+			errs = append(errs, fmt.Errorf("invalid merchant category code %q", c))
+		}
+	}
+	for _, c := range append(append([]string{}, p.AllowedMerchantCountries...), p.BlockedMerchantCountries...) {
+		if len(c) != 2 || strings.ToUpper(c) != c {
+			errs = append(errs, fmt.Errorf("invalid merchant country %q", c))
+		}
+	}
+
+	if p.SingleUse != nil && len(p.SpendingLimits) > 0 {
+		errs = append(errs, errors.New("a single-use profile cannot have spending limits"))
+	}
+	for _, l := range p.SpendingLimits {
+		if !l.Interval.valid() {
+			errs = append(errs, fmt.Errorf("invalid spending limit interval %q", l.Interval))
+		}
+		if l.Amount < 0 {
+			errs = append(errs, fmt.Errorf("invalid spending limit amount %d", l.Amount))
+		}
+	}
+	if s := p.SingleUse; s != nil {
+		if s.Comparison != ComparisonEquals && s.Comparison != ComparisonLessThanOrEquals {
+			errs = append(errs, fmt.Errorf("invalid single-use comparison %q", s.Comparison))
+		}
+		if s.Amount <= 0 {
+			errs = append(errs, fmt.Errorf("invalid single-use amount %d", s.Amount))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("spendcontrols: profile %q: %w", p.Name, err)
+	}
+	return nil
+}
+
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Params returns the authorization controls to update a card with. Dimensions
+// the profile leaves empty are sent as null, which removes any controls the
+// card has for them.
+func (p Profile) Params() increase.CardUpdateParamsAuthorizationControls {
+	var res increase.CardUpdateParamsAuthorizationControls
+
+	res.MerchantCategoryCode = increase.Null[increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCode]()
+	if len(p.AllowedMerchantCategoryCodes) > 0 {
+		res.MerchantCategoryCode = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCode{
+			Allowed: increase.F(mapValues(p.AllowedMerchantCategoryCodes, func(c string) increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCodeAllowed {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCodeAllowed{Code: increase.F(c)}
+			})),
+		})
+	} else if len(p.BlockedMerchantCategoryCodes) > 0 {
+		res.MerchantCategoryCode = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCode{
+			Blocked: increase.F(mapValues(p.BlockedMerchantCategoryCodes, func(c string) increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCodeBlocked {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantCategoryCodeBlocked{Code: increase.F(c)}
+			})),
+		})
+	}
+
+	res.MerchantAcceptorIdentifier = increase.Null[increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifier]()
+	if len(p.AllowedMerchantAcceptorIdentifiers) > 0 {
+		res.MerchantAcceptorIdentifier = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifier{
+			Allowed: increase.F(mapValues(p.AllowedMerchantAcceptorIdentifiers, func(id string) increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifierAllowed {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifierAllowed{Identifier: increase.F(id)}
+			})),
+		})
+	} else if len(p.BlockedMerchantAcceptorIdentifiers) > 0 {
+		res.MerchantAcceptorIdentifier = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifier{
+			Blocked: increase.F(mapValues(p.BlockedMerchantAcceptorIdentifiers, func(id string) increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifierBlocked {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantAcceptorIdentifierBlocked{Identifier: increase.F(id)}
+			})),
+		})
+	}
+
+	res.MerchantCountry = increase.Null[increase.CardUpdateParamsAuthorizationControlsMerchantCountry]()
+	if len(p.AllowedMerchantCountries) > 0 {
+		res.MerchantCountry = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantCountry{
+			Allowed: increase.F(mapValues(p.AllowedMerchantCountries, func(c string) increase.CardUpdateParamsAuthorizationControlsMerchantCountryAllowed {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantCountryAllowed{Country: increase.F(c)}
+			})),
+		})
+	} else if len(p.BlockedMerchantCountries) > 0 {
+		res.MerchantCountry = increase.F(increase.CardUpdateParamsAuthorizationControlsMerchantCountry{
+			Blocked: increase.F(mapValues(p.BlockedMerchantCountries, func(c string) increase.CardUpdateParamsAuthorizationControlsMerchantCountryBlocked {
+				return increase.CardUpdateParamsAuthorizationControlsMerchantCountryBlocked{Country: increase.F(c)}
+			})),
+		})
+	}
+
+	res.Usage = increase.Null[increase.CardUpdateParamsAuthorizationControlsUsage]()
+	if s := p.SingleUse; s != nil {
+		res.Usage = increase.F(increase.CardUpdateParamsAuthorizationControlsUsage{
+			Category: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageCategorySingleUse),
+			SingleUse: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageSingleUse{
+				SettlementAmount: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageSingleUseSettlementAmount{
+					Comparison: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageSingleUseSettlementAmountComparison(s.Comparison)),
+					Value:      increase.F(s.Amount),
+				}),
+			}),
+		})
+	} else if len(p.SpendingLimits) > 0 {
+		res.Usage = increase.F(increase.CardUpdateParamsAuthorizationControlsUsage{
+			Category: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageCategoryMultiUse),
+			MultiUse: increase.F(increase.CardUpdateParamsAuthorizationControlsUsageMultiUse{
+				SpendingLimits: increase.F(mapValues(p.SpendingLimits, func(l SpendingLimit) increase.CardUpdateParamsAuthorizationControlsUsageMultiUseSpendingLimit {
+					limit := increase.CardUpdateParamsAuthorizationControlsUsageMultiUseSpendingLimit{
+						Interval:         increase.F(increase.CardUpdateParamsAuthorizationControlsUsageMultiUseSpendingLimitsInterval(l.Interval)),
+						SettlementAmount: increase.F(l.Amount),
+					}
+					if len(l.MerchantCategoryCodes) > 0 {
+						limit.MerchantCategoryCodes = increase.F(mapValues(l.MerchantCategoryCodes, func(c string) increase.CardUpdateParamsAuthorizationControlsUsageMultiUseSpendingLimitsMerchantCategoryCode {
+							return increase.CardUpdateParamsAuthorizationControlsUsageMultiUseSpendingLimitsMerchantCategoryCode{Code: increase.F(c)}
+						}))
+					}
+					return limit
+				})),
+			}),
+		})
+	}
+	return res
+}
+
+func mapValues[T, U any](values []T, f func(T) U) []U {
+	res := make([]U, len(values))
+	for i, v := range values {
+		res[i] = f(v)
+	}
+	return res
+}
+
+// Drift is a difference between a card's authorization controls and its
+// profile.
+type Drift struct {
+	// The control that differs, such as "merchant_category_code.allowed".
+	Field string
+	Want  string
+	Got   string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: want %s, got %s", d.Field, d.Want, d.Got)
+}
+
+// Diff compares a card's authorization controls with the profile. It returns
+// nil if they are equivalent. The order of values does not matter.
+func (p Profile) Diff(c increase.CardAuthorizationControls) []Drift {
+	var res []Drift
+	compare := func(field string, want, got []string) {
+		w, g := canonical(want), canonical(got)
+		if w != g {
+			res = append(res, Drift{Field: field, Want: w, Got: g})
+		}
+	}
+
+	compare("merchant_category_code.allowed", p.AllowedMerchantCategoryCodes, mapValues(c.MerchantCategoryCode.Allowed, func(v increase.CardAuthorizationControlsMerchantCategoryCodeAllowed) string { return v.Code }))
+	compare("merchant_category_code.blocked", p.BlockedMerchantCategoryCodes, mapValues(c.MerchantCategoryCode.Blocked, func(v increase.CardAuthorizationControlsMerchantCategoryCodeBlocked) string { return v.Code }))
+	compare("merchant_acceptor_identifier.allowed", p.AllowedMerchantAcceptorIdentifiers, mapValues(c.MerchantAcceptorIdentifier.Allowed, func(v increase.CardAuthorizationControlsMerchantAcceptorIdentifierAllowed) string {
+		return v.Identifier
+	}))
+	compare("merchant_acceptor_identifier.blocked", p.BlockedMerchantAcceptorIdentifiers, mapValues(c.MerchantAcceptorIdentifier.Blocked, func(v increase.CardAuthorizationControlsMerchantAcceptorIdentifierBlocked) string {
+		return v.Identifier
+	}))
+	compare("merchant_country.allowed", p.AllowedMerchantCountries, mapValues(c.MerchantCountry.Allowed, func(v increase.CardAuthorizationControlsMerchantCountryAllowed) string { return v.Country }))
+	compare("merchant_country.blocked", p.BlockedMerchantCountries, mapValues(c.MerchantCountry.Blocked, func(v increase.CardAuthorizationControlsMerchantCountryBlocked) string { return v.Country }))
+
+	var wantUse, gotUse []string
+	if s := p.SingleUse; s != nil {
+		wantUse = []string{fmt.Sprintf("single_use %s %d", s.Comparison, s.Amount)}
+	}
+	for _, l := range p.SpendingLimits {
+		wantUse = append(wantUse, limitString(string(l.Interval), l.Amount, l.MerchantCategoryCodes))
+	}
+	switch c.Usage.Category {
+	case increase.CardAuthorizationControlsUsageCategorySingleUse:
+		s := c.Usage.SingleUse.SettlementAmount
+		gotUse = []string{fmt.Sprintf("single_use %s %d", s.Comparison, s.Value)}
+	case increase.CardAuthorizationControlsUsageCategoryMultiUse:
+		for _, l := range c.Usage.MultiUse.SpendingLimits {
+			codes := mapValues(l.MerchantCategoryCodes, func(v increase.CardAuthorizationControlsUsageMultiUseSpendingLimitsMerchantCategoryCode) string {
+				return v.Code
+			})
+			gotUse = append(gotUse, limitString(string(l.Interval), l.SettlementAmount, codes))
+		}
+	}
+	compare("usage", wantUse, gotUse)
+	return res
+}
+
+func limitString(interval string, amount int64, codes []string) string {
+	s := fmt.Sprintf("%s %d", interval, amount)
+	if len(codes) > 0 {
+		s += " for " + canonical(codes)
+	}
+	return s
+}
+
+// canonical returns a sorted, deduplicated representation of values, or
+// "none".
+func canonical(values []string) string {
+	set := map[string]bool{}
+	for _, v := range values {
+		set[v] = true
+	}
+	if len(set) == 0 {
+		return "none"
+	}
+	sorted := make([]string, 0, len(set))
+	for v := range set {
+		sorted = append(sorted, v)
+	}
+	sort.Strings(sorted)
+	return "[" + strings.Join(sorted, ", ") + "]"
+}
@@ -0,0 +1,158 @@
+// Package spendcontrols manages card authorization controls through reusable,
+// named spend profiles.
+//
+// A [Profile] describes the merchant category codes, merchant acceptor
+// identifiers, merchant countries and usage limits a card may be used with,
+// such as "travel", "fuel-only" or "SaaS with $2k/month". A [Manager] applies
+// profiles to cards with [increase.CardService.Update], detects drift between
+// a card's current authorization controls and its assigned profile, and
+// reconciles many cards at once.
+package spendcontrols
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrUnknownProfile is returned for a profile name that is not registered.
+var ErrUnknownProfile = errors.New("spendcontrols: unknown profile")
+
+// Manager applies spend profiles to cards.
+type Manager struct {
+	client   *increase.Client
+	profiles map[string]Profile
+}
+
+// New returns a manager for the profiles. Every profile must be valid and
+// have a distinct name.
+func New(client *increase.Client, profiles ...Profile) (*Manager, error) {
+	m := &Manager{client: client, profiles: map[string]Profile{}}
+	for _, p := range profiles {
+		if err := m.Register(p); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Register adds a profile, replacing any profile with the same name.
+func (m *Manager) Register(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	m.profiles[p.Name] = p
+	return nil
+}
+
+// Profile returns the named profile.
+func (m *Manager) Profile(name string) (Profile, error) {
+	p, ok := m.profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w %q", ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// Profiles returns the registered profiles sorted by name.
+func (m *Manager) Profiles() []Profile {
+	res := make([]Profile, 0, len(m.profiles))
+	for _, p := range m.profiles {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Apply sets the card's authorization controls to the profile.
+func (m *Manager) Apply(ctx context.Context, cardID string, profile string, opts ...option.RequestOption) (*increase.Card, error) {
+	p, err := m.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return m.client.Cards.Update(ctx, cardID, increase.CardUpdateParams{
+		AuthorizationControls: increase.F(p.Params()),
+	}, opts...)
+}
+
+// Check retrieves the card and returns its drift from the profile.
+func (m *Manager) Check(ctx context.Context, cardID string, profile string, opts ...option.RequestOption) ([]Drift, error) {
+	p, err := m.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+	card, err := m.client.Cards.Get(ctx, cardID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.Diff(card.AuthorizationControls), nil
+}
+
+// Result is the outcome of reconciling a card.
+type Result struct {
+	CardID  string
+	Profile string
+	// The drift found before any update.
+	Drift []Drift
+	// Whether the card was updated.
+	Updated bool
+	// Canceled cards cannot be updated and are skipped.
+	Skipped bool
+	Card    *increase.Card
+	Err     error
+}
+
+// Reconcile checks every card against its assigned profile, keyed by Card ID,
+// and updates the cards that have drifted. With dryRun, drift is reported but
+// no card is updated. Errors for individual cards are reported in their
+// results; the returned error is only set when the assignments reference an
+// unknown profile, in which case nothing is checked.
+func (m *Manager) Reconcile(ctx context.Context, assignments map[string]string, dryRun bool, opts ...option.RequestOption) ([]Result, error) {
+	cardIDs := make([]string, 0, len(assignments))
+	for cardID, profile := range assignments {
+		if _, err := m.Profile(profile); err != nil {
+			return nil, fmt.Errorf("card %s: %w", cardID, err)
+		}
+		cardIDs = append(cardIDs, cardID)
+	}
+	sort.Strings(cardIDs)
+
+	res := make([]Result, 0, len(cardIDs))
+	for _, cardID := range cardIDs {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		res = append(res, m.reconcile(ctx, cardID, assignments[cardID], dryRun, opts))
+	}
+	return res, nil
+}
+
+func (m *Manager) reconcile(ctx context.Context, cardID string, profile string, dryRun bool, opts []option.RequestOption) Result {
+	r := Result{CardID: cardID, Profile: profile}
+	p := m.profiles[profile]
+	r.Card, r.Err = m.client.Cards.Get(ctx, cardID, opts...)
+	if r.Err != nil {
+		return r
+	}
+	r.Drift = p.Diff(r.Card.AuthorizationControls)
+	if r.Card.Status == increase.CardStatusCanceled {
+		r.Skipped = true
+		return r
+	}
+	if len(r.Drift) == 0 || dryRun {
+		return r
+	}
+	card, err := m.client.Cards.Update(ctx, cardID, increase.CardUpdateParams{
+		AuthorizationControls: increase.F(p.Params()),
+	}, opts...)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.Card, r.Updated = card, true
+	return r
+}
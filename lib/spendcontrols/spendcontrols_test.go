@@ -0,0 +1,134 @@
+package spendcontrols_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/spendcontrols"
+)
+
+type fakeAPI struct {
+	mu      sync.Mutex
+	cards   map[string]map[string]any
+	updates []map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{cards: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(api.cards[r.PathValue("id")])
+	})
+	mux.HandleFunc("PATCH /cards/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		api.updates = append(api.updates, body)
+		card := api.cards[r.PathValue("id")]
+		card["authorization_controls"] = body["authorization_controls"]
+		json.NewEncoder(w).Encode(card)
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func profiles() []spendcontrols.Profile {
+	return []spendcontrols.Profile{
+		{
+			Name:                         "fuel-only",
+			AllowedMerchantCategoryCodes: []string{"5541", "5542"},
+			AllowedMerchantCountries:     []string{"US"},
+		},
+		{
+			Name:                         "saas",
+			AllowedMerchantCategoryCodes: []string{"5734", "7372"},
+			SpendingLimits:               []spendcontrols.SpendingLimit{{Interval: spendcontrols.IntervalPerMonth, Amount: 200000}},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	api, client := newFakeAPI(t)
+	api.cards["card_1"] = map[string]any{"id": "card_1", "status": "active", "authorization_controls": map[string]any{
+		"merchant_category_code": map[string]any{"allowed": []any{map[string]any{"code": "5542"}, map[string]any{"code": "5541"}}},
+		"merchant_country":       map[string]any{"allowed": []any{map[string]any{"country": "US"}}},
+	}}
+	api.cards["card_2"] = map[string]any{"id": "card_2", "status": "active", "authorization_controls": map[string]any{
+		"merchant_category_code": map[string]any{"allowed": []any{map[string]any{"code": "5734"}}},
+		"merchant_country":       map[string]any{"blocked": []any{map[string]any{"country": "RU"}}},
+	}}
+	api.cards["card_3"] = map[string]any{"id": "card_3", "status": "canceled", "authorization_controls": nil}
+
+	m, err := spendcontrols.New(client, profiles()...)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	assignments := map[string]string{"card_1": "fuel-only", "card_2": "saas", "card_3": "saas"}
+
+	results, err := m.Reconcile(context.Background(), assignments, true)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(api.updates) != 0 {
+		t.Fatalf("a dry run should not update cards")
+	}
+	if len(results[0].Drift) != 0 {
+		t.Errorf("card_1 should match its profile regardless of order, got %v", results[0].Drift)
+	}
+	if len(results[1].Drift) != 3 {
+		t.Errorf("expected drift in codes, countries and usage, got %v", results[1].Drift)
+	}
+	if !results[2].Skipped {
+		t.Errorf("canceled cards should be skipped, got %+v", results[2])
+	}
+
+	results, err = m.Reconcile(context.Background(), assignments, false)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(api.updates) != 1 || !results[1].Updated {
+		t.Fatalf("expected only card_2 to be updated, got %+v", api.updates)
+	}
+	controls := api.updates[0]["authorization_controls"].(map[string]any)
+	if controls["merchant_country"] != nil || controls["usage"].(map[string]any)["category"] != "multi_use" {
+		t.Errorf("unexpected controls: %+v", controls)
+	}
+
+	drift, err := m.Check(context.Background(), "card_2", "saas")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("expected no drift after reconciling, got %v", drift)
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	_, client := newFakeAPI(t)
+	m, _ := spendcontrols.New(client, profiles()...)
+	_, err := m.Reconcile(context.Background(), map[string]string{"card_1": "travel"}, false)
+	if !errors.Is(err, spendcontrols.ErrUnknownProfile) {
+		t.Errorf("expected ErrUnknownProfile, got %v", err)
+	}
+}
+
+func TestProfileValidation(t *testing.T) {
+	for _, p := range []spendcontrols.Profile{
+		{Name: "both", AllowedMerchantCountries: []string{"US"}, BlockedMerchantCountries: []string{"CA"}},
+		{Name: "code", AllowedMerchantCategoryCodes: []string{"55"}},
+		{Name: "usage", SingleUse: &spendcontrols.SingleUse{Comparison: spendcontrols.ComparisonEquals, Amount: 100}, SpendingLimits: []spendcontrols.SpendingLimit{{Interval: spendcontrols.IntervalPerDay, Amount: 1}}},
+		{AllowedMerchantCountries: []string{"US"}},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("expected profile %+v to be invalid", p)
+		}
+	}
+}
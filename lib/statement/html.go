@@ -0,0 +1,142 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"image"
+	"image/png"
+	"io"
+	"time"
+)
+
+// Funcs are the functions available to HTML statement templates:
+//
+//   - amount formats an amount in the minor unit of a currency
+//   - date formats a time with a Go layout
+//   - period formats the dates a statement covers
+//   - logo returns a PNG data URL of an image, for img elements
+var Funcs = template.FuncMap{
+	"amount": FormatAmount,
+	"date":   func(t time.Time, layout string) string { return t.Format(layout) },
+	"period": period,
+	"logo":   logo,
+}
+
+// DefaultHTML is the layout of [HTML]. It is executed with a *[Statement].
+var DefaultHTML = template.Must(template.New("statement").Funcs(Funcs).Parse(defaultHTML))
+
+// HTML renders a statement as a standalone HTML page with [DefaultHTML].
+func HTML(w io.Writer, s *Statement) error {
+	return DefaultHTML.Execute(w, s)
+}
+
+// HTMLTemplate returns a Template that executes t with a *[Statement]. Parse
+// t with [Funcs] to use them.
+func HTMLTemplate(t *template.Template) Template {
+	return func(w io.Writer, s *Statement) error {
+		return t.Execute(w, s)
+	}
+}
+
+func logo(img image.Image) (template.URL, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+const defaultHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Branding.Name}} Account Statement</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; color: #111; max-width: 800px; margin: 40px auto; }
+header { display: flex; justify-content: space-between; border-bottom: 1px solid #111; padding-bottom: 12px; }
+header img { max-height: 48px; margin-right: 12px; }
+h1 { font-size: 20px; margin: 0; }
+h2 { font-size: 15px; margin-top: 28px; }
+table { width: 100%; border-collapse: collapse; }
+th { background: #e6e6e6; text-align: left; }
+th, td { padding: 4px 6px; }
+.amount { text-align: right; white-space: nowrap; }
+.summary { width: 360px; }
+.total td { font-weight: bold; border-top: 1px solid #111; }
+footer { margin-top: 32px; font-size: 11px; color: #555; }
+</style>
+</head>
+<body>
+<header>
+<div style="display: flex">
+{{- with .Branding.Logo}}<img src="{{logo .}}" alt="">{{end}}
+<div>
+<h1>{{.Branding.Name}}</h1>
+{{- range .Branding.Address}}
+<div>{{.}}</div>
+{{- end}}
+</div>
+</div>
+<div style="text-align: right">
+<strong>Account Statement</strong>
+<div>{{period .}}</div>
+{{- with .AccountName}}
+<div>{{.}}</div>
+{{- end}}
+<div>{{.AccountID}}</div>
+</div>
+</header>
+
+<h2>Summary</h2>
+<table class="summary">
+<tr><td>Starting balance</td><td class="amount">{{amount .StartingBalance .Currency}}</td></tr>
+<tr><td>Deposits and credits ({{.DepositCount}})</td><td class="amount">{{amount .Deposits .Currency}}</td></tr>
+<tr><td>Withdrawals and debits ({{.WithdrawalCount}})</td><td class="amount">{{amount .Withdrawals .Currency}}</td></tr>
+<tr><td>Interest paid</td><td class="amount">{{amount .Interest .Currency}}</td></tr>
+<tr><td>Fees</td><td class="amount">{{amount .Fees .Currency}}</td></tr>
+<tr class="total"><td>Ending balance</td><td class="amount">{{amount .EndingBalance .Currency}}</td></tr>
+</table>
+
+<h2>Activity</h2>
+<table>
+<tr><th>Date</th><th>Description</th><th class="amount">Amount</th><th class="amount">Balance</th></tr>
+{{- $currency := .Currency}}
+{{- range .Lines}}
+<tr><td>{{date .Date "Jan 2"}}</td><td>{{.Description}}</td><td class="amount">{{amount .Amount $currency}}</td><td class="amount">{{amount .Balance $currency}}</td></tr>
+{{- else}}
+<tr><td></td><td>No activity this period.</td><td></td><td></td></tr>
+{{- end}}
+</table>
+
+{{- with .Section "interest"}}
+<h2>Interest</h2>
+<table>
+<tr><th>Date</th><th>Description</th><th class="amount">Amount</th></tr>
+{{- range .}}
+<tr><td>{{date .Date "Jan 2"}}</td><td>{{.Description}}</td><td class="amount">{{amount .Amount $currency}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+{{- with .Section "fee"}}
+<h2>Fees</h2>
+<table>
+<tr><th>Date</th><th>Description</th><th class="amount">Amount</th></tr>
+{{- range .}}
+<tr><td>{{date .Date "Jan 2"}}</td><td>{{.Description}}</td><td class="amount">{{amount .Amount $currency}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+<footer>
+{{- with .Branding.Support}}
+<p><strong>Questions?</strong> {{.}}</p>
+{{- end}}
+{{- with .Branding.Disclosures}}
+<p>{{.}}</p>
+{{- end}}
+</footer>
+</body>
+</html>
+`
@@ -0,0 +1,258 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Increase/increase-go/lib/internal/pdf"
+)
+
+// Layout of statement pages, in points.
+const (
+	pageMargin = 54.0
+	rowHeight  = 14.0
+	footer     = 54.0
+	dateCol    = pageMargin
+	descCol    = pageMargin + 60
+	amountCol  = pdf.LetterWidth - pageMargin - 90
+	balanceCol = pdf.LetterWidth - pageMargin
+)
+
+// PDF renders a statement as a US letter PDF: the branded header, the account
+// summary, the activity with a running balance, interest and fees, and the
+// disclosures.
+func PDF(w io.Writer, s *Statement) error {
+	doc := pdf.New()
+	doc.Title = "Statement " + s.ID
+	r := &pdfRenderer{doc: doc, s: s}
+	r.newPage()
+	r.summary()
+	r.activity()
+	r.charges()
+	r.disclosures()
+	r.pageNumbers()
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+type pdfRenderer struct {
+	doc   *pdf.Document
+	s     *Statement
+	pages []*pdf.Page
+	page  *pdf.Page
+	// The baseline of the next line.
+	y float64
+}
+
+func (r *pdfRenderer) amount(minor int64) string {
+	return FormatAmount(minor, r.s.Currency)
+}
+
+// newPage starts a page with the branded header.
+func (r *pdfRenderer) newPage() {
+	r.page = r.doc.AddPage(pdf.LetterWidth, pdf.LetterHeight)
+	r.pages = append(r.pages, r.page)
+	p, s, b := r.page, r.s, r.s.Branding
+	top := pdf.LetterHeight - pageMargin
+	left := pageMargin
+	if b.Logo != nil {
+		bounds := b.Logo.Bounds()
+		h := 36.0
+		w := h * float64(bounds.Dx()) / float64(bounds.Dy())
+		if w > 144 {
+			w = 144
+			h = w * float64(bounds.Dy()) / float64(bounds.Dx())
+		}
+		p.Image(b.Logo, left, top-h, w, h)
+		left += w + 12
+	}
+	p.Text(pdf.HelveticaBold, 16, left, top-16, b.Name)
+	y := top - 30
+	for _, line := range b.Address {
+		p.Text(pdf.Helvetica, 8, left, y, line)
+		y -= 10
+	}
+
+	right := pdf.LetterWidth - pageMargin
+	p.TextRight(pdf.HelveticaBold, 12, right, top-16, "Account Statement")
+	p.TextRight(pdf.Helvetica, 9, right, top-30, period(s))
+	if s.AccountName != "" {
+		p.TextRight(pdf.Helvetica, 9, right, top-42, s.AccountName)
+	}
+	p.TextRight(pdf.Helvetica, 8, right, top-54, s.AccountID)
+	p.Line(pageMargin, top-64, right, top-64, 0.75)
+	r.y = top - 88
+}
+
+// need starts a new page unless height fits above the footer. It reports
+// whether it did.
+func (r *pdfRenderer) need(height float64) bool {
+	if r.y-height >= footer+rowHeight {
+		return false
+	}
+	r.newPage()
+	return true
+}
+
+func (r *pdfRenderer) heading(title string) {
+	r.need(rowHeight * 3)
+	r.page.Text(pdf.HelveticaBold, 11, pageMargin, r.y, title)
+	r.y -= rowHeight + 4
+}
+
+func (r *pdfRenderer) summary() {
+	s := r.s
+	r.heading("Summary")
+	rows := []struct {
+		label  string
+		amount int64
+	}{
+		{"Starting balance", s.StartingBalance},
+		{fmt.Sprintf("Deposits and credits (%d)", s.DepositCount), s.Deposits},
+		{fmt.Sprintf("Withdrawals and debits (%d)", s.WithdrawalCount), s.Withdrawals},
+		{"Interest paid", s.Interest},
+		{"Fees", s.Fees},
+	}
+	right := pageMargin + 260
+	for _, row := range rows {
+		r.page.Text(pdf.Helvetica, 9, pageMargin, r.y, row.label)
+		r.page.TextRight(pdf.Helvetica, 9, right, r.y, r.amount(row.amount))
+		r.y -= rowHeight
+	}
+	r.page.Line(pageMargin, r.y+rowHeight-4, right, r.y+rowHeight-4, 0.5)
+	r.page.Text(pdf.HelveticaBold, 9, pageMargin, r.y-2, "Ending balance")
+	r.page.TextRight(pdf.HelveticaBold, 9, right, r.y-2, r.amount(s.EndingBalance))
+	r.y -= rowHeight*2 + 8
+}
+
+// table draws lines with a header row, repeating the header on every page.
+func (r *pdfRenderer) table(title string, lines []Line, balance bool) {
+	r.heading(title)
+	header := func() {
+		r.page.FillRect(pageMargin, r.y-4, pdf.LetterWidth-2*pageMargin, rowHeight, 0.9)
+		r.page.Text(pdf.HelveticaBold, 8, dateCol+2, r.y, "Date")
+		r.page.Text(pdf.HelveticaBold, 8, descCol, r.y, "Description")
+		if balance {
+			r.page.TextRight(pdf.HelveticaBold, 8, amountCol, r.y, "Amount")
+			r.page.TextRight(pdf.HelveticaBold, 8, balanceCol-2, r.y, "Balance")
+		} else {
+			r.page.TextRight(pdf.HelveticaBold, 8, balanceCol-2, r.y, "Amount")
+		}
+		r.y -= rowHeight
+	}
+	header()
+	if len(lines) == 0 {
+		r.page.Text(pdf.Helvetica, 8, descCol, r.y, "No activity this period.")
+		r.y -= rowHeight
+	}
+	width := amountCol - 80 - descCol
+	if !balance {
+		width = balanceCol - 80 - descCol
+	}
+	for _, l := range lines {
+		if r.need(rowHeight) {
+			r.heading(title + " (continued)")
+			header()
+		}
+		r.page.Text(pdf.Helvetica, 8, dateCol+2, r.y, l.Date.Format("Jan 2"))
+		r.page.Text(pdf.Helvetica, 8, descCol, r.y, truncate(l.Description, pdf.Helvetica, 8, width))
+		if balance {
+			r.page.TextRight(pdf.Helvetica, 8, amountCol, r.y, r.amount(l.Amount))
+			r.page.TextRight(pdf.Helvetica, 8, balanceCol-2, r.y, r.amount(l.Balance))
+		} else {
+			r.page.TextRight(pdf.Helvetica, 8, balanceCol-2, r.y, r.amount(l.Amount))
+		}
+		r.y -= rowHeight
+	}
+	r.y -= rowHeight
+}
+
+func (r *pdfRenderer) activity() {
+	r.table("Activity", r.s.Lines, true)
+}
+
+func (r *pdfRenderer) charges() {
+	if interest := r.s.Section(KindInterest); len(interest) > 0 {
+		r.table("Interest", interest, false)
+	}
+	if fees := r.s.Section(KindFee); len(fees) > 0 {
+		r.table("Fees", fees, false)
+	}
+}
+
+func (r *pdfRenderer) disclosures() {
+	b := r.s.Branding
+	width := pdf.LetterWidth - 2*pageMargin
+	if b.Support != "" {
+		r.need(rowHeight * 2)
+		r.page.Text(pdf.HelveticaBold, 8, pageMargin, r.y, "Questions? "+b.Support)
+		r.y -= rowHeight
+	}
+	for _, line := range wrap(b.Disclosures, pdf.Helvetica, 7, width) {
+		r.need(10)
+		r.page.Text(pdf.Helvetica, 7, pageMargin, r.y, line)
+		r.y -= 10
+	}
+}
+
+func (r *pdfRenderer) pageNumbers() {
+	for i, p := range r.pages {
+		p.SetGray(0.4)
+		p.Text(pdf.Helvetica, 7, pageMargin, footer-20, r.s.Branding.Name)
+		p.TextRight(pdf.Helvetica, 7, pdf.LetterWidth-pageMargin, footer-20, fmt.Sprintf("Page %d of %d", i+1, len(r.pages)))
+		p.SetGray(0)
+	}
+}
+
+// period returns the dates a statement covers, such as "March 1 - March 31,
+// 2024".
+func period(s *Statement) string {
+	start, end := s.PeriodStart, s.LastDay()
+	if start.Year() == end.Year() {
+		return start.Format("January 2") + " - " + end.Format("January 2, 2006")
+	}
+	return start.Format("January 2, 2006") + " - " + end.Format("January 2, 2006")
+}
+
+// truncate shortens s with an ellipsis to fit width.
+func truncate(s string, f *pdf.Font, size, width float64) string {
+	if f.Width(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && f.Width(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// wrap splits text into lines no wider than width, keeping explicit newlines.
+func wrap(text string, f *pdf.Font, size float64, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && f.Width(line+" "+word, size) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,308 @@
+// Package statement generates branded account statements.
+//
+// Account Statements only carry the balances and period of a statement, and
+// the File of Increase's own PDF. A [Generator] combines an Account Statement
+// with the Transactions of its period into a [Statement]: a summary of
+// deposits, withdrawals, interest and fees, and the itemized activity with a
+// running balance. A [Template] then renders it; [PDF] and [HTML] are
+// provided, and [HTMLTemplate] renders your own html/template.
+//
+//	g := statement.New(statement.Branding{Name: "Acme Bank", Support: "help@acme.example"})
+//	s, err := g.Fetch(ctx, client, accountStatement)
+//	if err != nil {
+//		return err
+//	}
+//	if err := statement.PDF(w, s); err != nil {
+//		return err
+//	}
+package statement
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/balances"
+	"github.com/Increase/increase-go/option"
+)
+
+// Branding is how a statement presents the program issuing it.
+type Branding struct {
+	// The name printed at the top of the statement.
+	Name    string
+	Address []string
+	// Drawn at the top left of the statement, in grayscale on PDFs. Optional.
+	Logo image.Image
+	// How to reach support, such as an email address or phone number.
+	Support string
+	// Legal text printed at the end of the statement.
+	Disclosures string
+}
+
+// Kind is the section of a statement a line belongs to.
+type Kind string
+
+const (
+	KindActivity Kind = "activity"
+	KindInterest Kind = "interest"
+	KindFee      Kind = "fee"
+)
+
+// Line is a Transaction on a statement.
+type Line struct {
+	TransactionID string
+	Date          time.Time
+	Description   string
+	// The Transaction source category, such as "card_settlement".
+	Category string
+	Kind     Kind
+	// In the minor unit of the currency, negative for debits.
+	Amount int64
+	// The balance after the Transaction.
+	Balance int64
+}
+
+// Statement is the content of a branded statement.
+type Statement struct {
+	ID          string
+	AccountID   string
+	AccountName string
+	Currency    string
+	// The period is [PeriodStart, PeriodEnd); see [Statement.LastDay].
+	PeriodStart     time.Time
+	PeriodEnd       time.Time
+	StartingBalance int64
+	EndingBalance   int64
+
+	// Activity other than interest and fees. Withdrawals are negative.
+	Deposits        int64
+	DepositCount    int
+	Withdrawals     int64
+	WithdrawalCount int
+	Interest        int64
+	// Negative for fees charged.
+	Fees int64
+
+	// Every Transaction of the period, oldest first.
+	Lines []Line
+	// How much the Transactions of the period are off from the ending balance.
+	// Zero when they agree.
+	Discrepancy int64
+
+	Branding  Branding
+	Generated time.Time
+}
+
+// LastDay returns the last day the statement covers.
+func (s *Statement) LastDay() time.Time {
+	return s.PeriodEnd.Add(-time.Nanosecond)
+}
+
+// Section returns the lines of a kind.
+func (s *Statement) Section(kind Kind) []Line {
+	var lines []Line
+	for _, l := range s.Lines {
+		if l.Kind == kind {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// Template renders a statement.
+type Template func(w io.Writer, s *Statement) error
+
+// Generator builds statements.
+type Generator struct {
+	Branding Branding
+	// Describes a Transaction on the statement. Defaults to [Description].
+	Describe func(increase.Transaction) string
+	// The time zone of the dates on the statement. Defaults to UTC.
+	Location *time.Location
+
+	now func() time.Time
+}
+
+// New returns a generator of statements with the given branding.
+func New(branding Branding) *Generator {
+	return &Generator{Branding: branding, Describe: Description, Location: time.UTC, now: time.Now}
+}
+
+// Fetch retrieves the Account of an Account Statement and the Transactions of
+// its period, and builds the statement.
+func (g *Generator) Fetch(ctx context.Context, client *increase.Client, st increase.AccountStatement, opts ...option.RequestOption) (*Statement, error) {
+	account, err := client.Accounts.Get(ctx, st.AccountID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("statement: retrieving account %s: %w", st.AccountID, err)
+	}
+	iter := client.Transactions.ListAutoPaging(ctx, increase.TransactionListParams{
+		AccountID: increase.F(st.AccountID),
+		CreatedAt: increase.F(increase.TransactionListParamsCreatedAt{
+			OnOrAfter: increase.F(st.StatementPeriodStart),
+			Before:    increase.F(st.StatementPeriodEnd),
+		}),
+	}, opts...)
+	var txs []increase.Transaction
+	for iter.Next() {
+		txs = append(txs, iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("statement: listing transactions of %s: %w", st.AccountID, err)
+	}
+	return g.Build(st, account, txs), nil
+}
+
+// Build builds the statement of an Account Statement from the Transactions of
+// its period. Transactions outside the period are ignored. account may be nil,
+// in which case the statement has no account name and is in USD.
+func (g *Generator) Build(st increase.AccountStatement, account *increase.Account, txs []increase.Transaction) *Statement {
+	loc := g.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	describe := g.Describe
+	if describe == nil {
+		describe = Description
+	}
+	s := &Statement{
+		ID:              st.ID,
+		AccountID:       st.AccountID,
+		Currency:        "USD",
+		PeriodStart:     st.StatementPeriodStart.In(loc),
+		PeriodEnd:       st.StatementPeriodEnd.In(loc),
+		StartingBalance: st.StartingBalance,
+		EndingBalance:   st.EndingBalance,
+		Branding:        g.Branding,
+		Generated:       g.now().In(loc),
+	}
+	if account != nil {
+		s.AccountName = account.Name
+		s.Currency = string(account.Currency)
+	}
+
+	series := balances.Replay(st.AccountID, st.StartingBalance, st.StatementPeriodStart, st.StatementPeriodEnd, txs)
+	byID := make(map[string]increase.Transaction, len(txs))
+	for _, tx := range txs {
+		byID[tx.ID] = tx
+	}
+	for _, p := range series.Points {
+		tx := byID[p.TransactionID]
+		line := Line{
+			TransactionID: tx.ID,
+			Date:          tx.CreatedAt.In(loc),
+			Description:   describe(tx),
+			Category:      string(tx.Source.Category),
+			Kind:          kind(tx),
+			Amount:        tx.Amount,
+			Balance:       p.Balance,
+		}
+		switch {
+		case line.Kind == KindInterest:
+			s.Interest += tx.Amount
+		case line.Kind == KindFee:
+			s.Fees += tx.Amount
+		case tx.Amount < 0:
+			s.Withdrawals += tx.Amount
+			s.WithdrawalCount++
+		default:
+			s.Deposits += tx.Amount
+			s.DepositCount++
+		}
+		s.Lines = append(s.Lines, line)
+	}
+	s.Discrepancy = series.Ending() - st.EndingBalance
+	return s
+}
+
+func kind(tx increase.Transaction) Kind {
+	switch tx.Source.Category {
+	case increase.TransactionSourceCategoryInterestPayment:
+		return KindInterest
+	case increase.TransactionSourceCategoryFeePayment:
+		return KindFee
+	}
+	return KindActivity
+}
+
+// Description returns a short description of a Transaction for a statement,
+// built from its source, falling back to the Transaction's own description.
+func Description(tx increase.Transaction) string {
+	s := tx.Source
+	var parts []string
+	switch s.Category {
+	case increase.TransactionSourceCategoryCardSettlement:
+		parts = []string{s.CardSettlement.MerchantName, s.CardSettlement.MerchantCity, s.CardSettlement.MerchantState}
+	case increase.TransactionSourceCategoryCardRefund:
+		parts = []string{"Refund", s.CardRefund.MerchantName, s.CardRefund.MerchantCity}
+	case increase.TransactionSourceCategoryACHTransferIntention:
+		parts = []string{"ACH transfer", s.ACHTransferIntention.StatementDescriptor}
+	case increase.TransactionSourceCategoryInboundACHTransfer:
+		parts = []string{s.InboundACHTransfer.OriginatorCompanyName, s.InboundACHTransfer.OriginatorCompanyEntryDescription}
+	case increase.TransactionSourceCategoryWireTransferIntention:
+		parts = []string{"Wire transfer", s.WireTransferIntention.MessageToRecipient}
+	case increase.TransactionSourceCategoryInboundWireTransfer:
+		parts = []string{from("Wire", s.InboundWireTransfer.DebtorName), s.InboundWireTransfer.UnstructuredRemittanceInformation}
+	case increase.TransactionSourceCategoryRealTimePaymentsTransferAcknowledgement:
+		parts = []string{"Real-Time Payment", s.RealTimePaymentsTransferAcknowledgement.UnstructuredRemittanceInformation}
+	case increase.TransactionSourceCategoryInboundRealTimePaymentsTransferConfirmation:
+		parts = []string{from("Real-Time Payment", s.InboundRealTimePaymentsTransferConfirmation.DebtorName), s.InboundRealTimePaymentsTransferConfirmation.UnstructuredRemittanceInformation}
+	case increase.TransactionSourceCategoryCheckDepositAcceptance:
+		parts = []string{"Check deposit", s.CheckDepositAcceptance.SerialNumber}
+	case increase.TransactionSourceCategoryCheckTransferDeposit:
+		parts = []string{"Check paid"}
+	case increase.TransactionSourceCategoryAccountTransferIntention:
+		parts = []string{s.AccountTransferIntention.Description}
+	case increase.TransactionSourceCategoryInterestPayment:
+		parts = []string{"Interest"}
+	case increase.TransactionSourceCategoryFeePayment:
+		parts = []string{"Fee"}
+	}
+	if d := join(parts); d != "" {
+		return d
+	}
+	return tx.Description
+}
+
+func from(label, name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return label + " from " + name
+	}
+	return label
+}
+
+// join joins the non-empty parts of a description.
+func join(parts []string) string {
+	var res []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	return strings.Join(res, " - ")
+}
+
+// FormatAmount renders an amount in the minor unit of a currency with
+// thousands separators, such as "-1,234.56" for -123456 USD.
+func FormatAmount(minor int64, currency string) string {
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	decimals := int64(100)
+	if currency == "JPY" {
+		decimals = 1
+	}
+	whole := fmt.Sprint(minor / decimals)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	if decimals == 1 {
+		return sign + whole
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, whole, minor%decimals)
+}
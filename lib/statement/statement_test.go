@@ -0,0 +1,174 @@
+package statement_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/statement"
+)
+
+const transactions = `{"data": [
+	{"id": "tx_fee", "account_id": "account_1", "amount": -500, "created_at": "2024-03-31T12:00:00Z", "source": {"category": "fee_payment", "fee_payment": {"amount": 500}}},
+	{"id": "tx_card", "account_id": "account_1", "amount": -2550, "created_at": "2024-03-05T12:00:00Z", "description": "Card", "source": {"category": "card_settlement", "card_settlement": {"merchant_name": "Coffee (Downtown)", "merchant_city": "New York", "merchant_state": "NY"}}},
+	{"id": "tx_ach", "account_id": "account_1", "amount": 100000, "created_at": "2024-03-01T12:00:00Z", "source": {"category": "inbound_ach_transfer", "inbound_ach_transfer": {"originator_company_name": "ACME CORP", "originator_company_entry_description": "PAYROLL"}}},
+	{"id": "tx_interest", "account_id": "account_1", "amount": 125, "created_at": "2024-03-31T13:00:00Z", "source": {"category": "interest_payment", "interest_payment": {"amount": 125}}},
+	{"id": "tx_other", "account_id": "account_1", "amount": -1000, "created_at": "2024-03-10T12:00:00Z", "description": "Adjustment", "source": {"category": "other"}}
+], "next_cursor": null}`
+
+func accountStatement(t *testing.T, ending int64) increase.AccountStatement {
+	var st increase.AccountStatement
+	body := fmt.Sprintf(`{"id": "account_statement_1", "account_id": "account_1", "starting_balance": 50000, "ending_balance": %d, "statement_period_start": "2024-03-01T00:00:00Z", "statement_period_end": "2024-04-01T00:00:00Z"}`, ending)
+	if err := json.Unmarshal([]byte(body), &st); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	return st
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/account_1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "account_1", "name": "Operating", "currency": "USD"}`))
+	})
+	mux.HandleFunc("GET /transactions", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("created_at.before") != "2024-04-01T00:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(transactions))
+	})
+	client := testapi.NewClient(t, mux)
+
+	g := statement.New(statement.Branding{Name: "Acme Bank", Address: []string{"1 Main Street", "New York, NY 10001"}, Support: "help@acme.example", Disclosures: "Deposits are FDIC insured."})
+	s, err := g.Fetch(context.Background(), client, accountStatement(t, 146075))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if s.AccountName != "Operating" || s.Currency != "USD" || len(s.Lines) != 5 || s.Discrepancy != 0 {
+		t.Fatalf("unexpected statement %+v", s)
+	}
+	if s.Deposits != 100000 || s.DepositCount != 1 || s.Withdrawals != -3550 || s.WithdrawalCount != 2 || s.Interest != 125 || s.Fees != -500 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	for i, expected := range []struct {
+		id, description string
+		balance         int64
+	}{
+		{"tx_ach", "ACME CORP - PAYROLL", 150000},
+		{"tx_card", "Coffee (Downtown) - New York - NY", 147450},
+		{"tx_other", "Adjustment", 146450},
+		{"tx_fee", "Fee", 145950},
+		{"tx_interest", "Interest", 146075},
+	} {
+		if l := s.Lines[i]; l.TransactionID != expected.id || l.Description != expected.description || l.Balance != expected.balance {
+			t.Errorf("line %d: expected %+v, got %+v", i, expected, l)
+		}
+	}
+	if fees := s.Section(statement.KindFee); len(fees) != 1 || fees[0].TransactionID != "tx_fee" {
+		t.Errorf("unexpected fees %+v", fees)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := statement.New(statement.Branding{Name: "Acme Bank", Logo: image.NewGray(image.Rect(0, 0, 40, 20)), Support: "help@acme.example", Disclosures: "Deposits are FDIC insured."})
+	var txs []increase.Transaction
+	if err := json.Unmarshal([]byte(transactions[strings.Index(transactions, "["):strings.LastIndex(transactions, "]")+1]), &txs); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	s := g.Build(accountStatement(t, 146000), nil, txs)
+	if s.Discrepancy != 75 {
+		t.Errorf("expected a discrepancy of 75, got %d", s.Discrepancy)
+	}
+
+	var buf bytes.Buffer
+	if err := statement.PDF(&buf, s); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.Contains(out, "/Subtype /Image") {
+		t.Fatalf("expected a PDF with a logo")
+	}
+	content := streams(t, out)
+	for _, want := range []string{
+		"(Acme Bank)",
+		"(March 1 - March 31, 2024)",
+		"(Coffee \\(Downtown\\) - New York - NY)",
+		"(1,000.00)",
+		"(-25.50)",
+		"(1,460.00)",
+		"(Questions? help@acme.example)",
+		"(Page 1 of 1)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected page content to contain %q", want)
+		}
+	}
+
+	// Long statements continue on more pages.
+	long := *s
+	for i := 0; i < 80; i++ {
+		long.Lines = append(long.Lines, s.Lines[1])
+	}
+	buf.Reset()
+	if err := statement.PDF(&buf, &long); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if content := streams(t, buf.String()); !strings.Contains(content, "(Page 3 of 3)") || !strings.Contains(content, "(Activity \\(continued\\))") {
+		t.Errorf("expected the activity to continue over three pages")
+	}
+
+	buf.Reset()
+	if err := statement.HTML(&buf, s); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		"<h1>Acme Bank</h1>",
+		"March 1 - March 31, 2024",
+		"Coffee (Downtown) - New York - NY",
+		`<td class="amount">-25.50</td>`,
+		"<h2>Interest</h2>",
+		"<h2>Fees</h2>",
+		`src="data:image/png;base64,`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q", want)
+		}
+	}
+
+	custom := statement.HTMLTemplate(template.Must(template.New("custom").Funcs(statement.Funcs).Parse(`{{.Branding.Name}}: {{amount .EndingBalance .Currency}}`)))
+	buf.Reset()
+	if err := custom(&buf, s); err != nil || buf.String() != "Acme Bank: 1,460.00" {
+		t.Errorf("unexpected custom template output %q, %v", buf.String(), err)
+	}
+}
+
+// streams returns the decompressed contents of every stream in a PDF.
+func streams(t *testing.T, doc string) string {
+	t.Helper()
+	var res strings.Builder
+	for {
+		i := strings.Index(doc, "stream\n")
+		if i < 0 {
+			return res.String()
+		}
+		doc = doc[i+len("stream\n"):]
+		j := strings.Index(doc, "\nendstream")
+		r, err := zlib.NewReader(strings.NewReader(doc[:j]))
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		data, _ := io.ReadAll(r)
+		res.Write(data)
+		doc = doc[j+len("\nendstream"):]
+	}
+}
@@ -0,0 +1,356 @@
+// Package sweep keeps account balances within bounds by moving money between
+// accounts, or to and from External Accounts over ACH.
+//
+// Each [Rule] sets a minimum, target and maximum balance for an account. An
+// [Engine] reads the account balances, plans the transfers needed to bring
+// accounts that are out of bounds back to their target, and executes the plan.
+// Transfers are sent with an idempotency key derived from the rule and the
+// sweep window, so running the engine again within the same window does not
+// move money twice.
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// BalanceType selects which balance a rule is evaluated against.
+type BalanceType string
+
+const (
+	// The current balance less pending debits. This is the default.
+	BalanceTypeAvailable BalanceType = "available"
+	// The balance of posted transactions.
+	BalanceTypeCurrent BalanceType = "current"
+)
+
+// Rule keeps the balance of an account between Min and Max. When the balance
+// falls below Min, the account is funded up to Target; when it rises above
+// Max, the excess above Target is swept out.
+type Rule struct {
+	// A name that identifies the rule in plans and idempotency keys. Defaults to
+	// the account ID.
+	Name      string
+	AccountID string
+	Min       int64
+	Target    int64
+	// Zero means there is no maximum.
+	Max     int64
+	Balance BalanceType
+
+	// The account money is moved to and from with Account Transfers.
+	CounterpartyAccountID string
+	// The External Account money is pulled from and pushed to with ACH
+	// transfers, when CounterpartyAccountID is not set.
+	ExternalAccountID string
+	// The statement descriptor of ACH transfers. Defaults to "Sweep".
+	StatementDescriptor string
+
+	// Movements smaller than this are skipped.
+	MinimumAmount int64
+	// Only fund the account; never sweep excess out.
+	FundOnly bool
+}
+
+func (r Rule) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.AccountID
+}
+
+// Validate checks that the rule is consistent.
+func (r Rule) Validate() error {
+	switch {
+	case r.AccountID == "":
+		return fmt.Errorf("sweep: rule %s is missing an account", r.name())
+	case r.CounterpartyAccountID == "" && r.ExternalAccountID == "":
+		return fmt.Errorf("sweep: rule %s needs a counterparty account or an external account", r.name())
+	case r.CounterpartyAccountID == r.AccountID:
+		return fmt.Errorf("sweep: rule %s sweeps an account into itself", r.name())
+	case r.Target < r.Min:
+		return fmt.Errorf("sweep: rule %s has a target below its minimum", r.name())
+	case r.Max != 0 && r.Target > r.Max:
+		return fmt.Errorf("sweep: rule %s has a target above its maximum", r.name())
+	}
+	return nil
+}
+
+// Method is how an action moves money.
+type Method string
+
+const (
+	MethodAccountTransfer Method = "account_transfer"
+	// An ACH debit that pulls funds from the External Account.
+	MethodACHDebit Method = "ach_debit"
+	// An ACH credit that pushes funds to the External Account.
+	MethodACHCredit Method = "ach_credit"
+)
+
+// Direction is whether an action funds or drains the rule's account.
+type Direction string
+
+const (
+	DirectionFund  Direction = "fund"
+	DirectionSweep Direction = "sweep"
+)
+
+// Action is a planned transfer.
+type Action struct {
+	Rule      string
+	Direction Direction
+	Method    Method
+	// The account the money leaves, for Account Transfers and ACH credits.
+	FromAccountID string
+	// The account the money arrives in, for Account Transfers and ACH debits.
+	ToAccountID       string
+	ExternalAccountID string
+	// The amount in the minor unit of the account's currency. Always positive.
+	Amount int64
+	// The balance of the rule's account when the plan was made, and after the
+	// action.
+	Balance          int64
+	ProjectedBalance int64
+	IdempotencyKey   string
+	Description      string
+}
+
+// Skip records a rule that did not produce an action.
+type Skip struct {
+	Rule   string
+	Reason string
+}
+
+// Plan is the set of transfers for one sweep window.
+type Plan struct {
+	// The start of the sweep window.
+	Window  time.Time
+	At      time.Time
+	Actions []Action
+	Skipped []Skip
+}
+
+// Result is the outcome of executing an action.
+type Result struct {
+	Action          Action
+	AccountTransfer *increase.AccountTransfer
+	ACHTransfer     *increase.ACHTransfer
+	// The ID of the transfer created earlier in the window, when the idempotency
+	// key had already been used with different parameters. The action is then
+	// not repeated and Err is nil.
+	ExistingTransferID string
+	Err                error
+}
+
+// Engine plans and executes sweeps.
+type Engine struct {
+	client *increase.Client
+	rules  []Rule
+	// The length of a sweep window. Each rule moves money in each direction at
+	// most once per window. Defaults to a day.
+	Window time.Duration
+	// Whether Account Transfers are created pending approval.
+	RequireApproval bool
+	now             func() time.Time
+}
+
+// New returns an engine for the rules, or an error if a rule is invalid.
+func New(client *increase.Client, rules []Rule) (*Engine, error) {
+	names := map[string]bool{}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		if names[r.name()] {
+			return nil, fmt.Errorf("sweep: duplicate rule %s", r.name())
+		}
+		names[r.name()] = true
+	}
+	return &Engine{client: client, rules: rules, Window: 24 * time.Hour, now: time.Now}, nil
+}
+
+func (e *Engine) window(t time.Time) time.Time {
+	w := e.Window
+	if w <= 0 {
+		w = 24 * time.Hour
+	}
+	return t.UTC().Truncate(w)
+}
+
+// Plan reads balances and returns the transfers that would bring every account
+// within bounds, without moving money. Rules are evaluated in order, and the
+// projected effect of earlier actions is taken into account by later rules
+// that share an account.
+func (e *Engine) Plan(ctx context.Context, opts ...option.RequestOption) (*Plan, error) {
+	now := e.now()
+	plan := &Plan{Window: e.window(now), At: now}
+	balances := map[string]*increase.BalanceLookup{}
+	// Projected changes by account from earlier actions in the plan.
+	deltas := map[string]int64{}
+	balance := func(accountID string, typ BalanceType) (int64, error) {
+		b, ok := balances[accountID]
+		if !ok {
+			var err error
+			b, err = e.client.Accounts.Balance(ctx, accountID, increase.AccountBalanceParams{}, opts...)
+			if err != nil {
+				return 0, fmt.Errorf("sweep: reading balance of %s: %w", accountID, err)
+			}
+			balances[accountID] = b
+		}
+		if typ == BalanceTypeCurrent {
+			return b.CurrentBalance + deltas[accountID], nil
+		}
+		return b.AvailableBalance + deltas[accountID], nil
+	}
+
+	for _, r := range e.rules {
+		current, err := balance(r.AccountID, r.Balance)
+		if err != nil {
+			return nil, err
+		}
+		a := Action{Rule: r.name(), Balance: current}
+		switch {
+		case current < r.Min:
+			a.Direction, a.Amount = DirectionFund, r.Target-current
+		case r.Max != 0 && current > r.Max && !r.FundOnly:
+			a.Direction, a.Amount = DirectionSweep, current-r.Target
+		default:
+			plan.Skipped = append(plan.Skipped, Skip{Rule: r.name(), Reason: "within bounds"})
+			continue
+		}
+
+		if r.CounterpartyAccountID != "" && a.Direction == DirectionFund {
+			// Never overdraw the funding account.
+			available, err := balance(r.CounterpartyAccountID, BalanceTypeAvailable)
+			if err != nil {
+				return nil, err
+			}
+			if available < a.Amount {
+				a.Amount = max(available, 0)
+			}
+		}
+		if a.Amount == 0 || a.Amount < r.MinimumAmount {
+			plan.Skipped = append(plan.Skipped, Skip{Rule: r.name(), Reason: fmt.Sprintf("%s of %d is below the minimum amount", a.Direction, a.Amount)})
+			continue
+		}
+
+		switch {
+		case r.CounterpartyAccountID != "" && a.Direction == DirectionFund:
+			a.Method, a.FromAccountID, a.ToAccountID = MethodAccountTransfer, r.CounterpartyAccountID, r.AccountID
+		case r.CounterpartyAccountID != "":
+			a.Method, a.FromAccountID, a.ToAccountID = MethodAccountTransfer, r.AccountID, r.CounterpartyAccountID
+		case a.Direction == DirectionFund:
+			a.Method, a.ToAccountID, a.ExternalAccountID = MethodACHDebit, r.AccountID, r.ExternalAccountID
+		default:
+			a.Method, a.FromAccountID, a.ExternalAccountID = MethodACHCredit, r.AccountID, r.ExternalAccountID
+		}
+		if a.Direction == DirectionFund {
+			a.ProjectedBalance = current + a.Amount
+		} else {
+			a.ProjectedBalance = current - a.Amount
+		}
+		a.IdempotencyKey = fmt.Sprintf("sweep-%s-%s-%s", r.name(), a.Direction, plan.Window.Format("20060102T150405Z"))
+		a.Description = fmt.Sprintf("Sweep %s %s", r.name(), a.Direction)
+		plan.Actions = append(plan.Actions, a)
+
+		// ACH transfers settle later, so only Account Transfers change the
+		// balances seen by later rules.
+		if a.Method == MethodAccountTransfer {
+			deltas[a.FromAccountID] -= a.Amount
+			deltas[a.ToAccountID] += a.Amount
+		}
+	}
+	return plan, nil
+}
+
+// Execute creates the transfers of a plan. Every action is attempted; the
+// returned error joins the errors of the actions that failed.
+func (e *Engine) Execute(ctx context.Context, plan *Plan, opts ...option.RequestOption) ([]Result, error) {
+	results := make([]Result, 0, len(plan.Actions))
+	var errs []error
+	for _, a := range plan.Actions {
+		res := Result{Action: a}
+		actionOpts := append(append([]option.RequestOption{}, opts...), option.WithHeader("Idempotency-Key", a.IdempotencyKey))
+		switch a.Method {
+		case MethodAccountTransfer:
+			params := increase.AccountTransferNewParams{
+				AccountID:            increase.F(a.FromAccountID),
+				DestinationAccountID: increase.F(a.ToAccountID),
+				Amount:               increase.F(a.Amount),
+				Description:          increase.F(a.Description),
+			}
+			if e.RequireApproval {
+				params.RequireApproval = increase.F(true)
+			}
+			res.AccountTransfer, res.Err = e.client.AccountTransfers.New(ctx, params, actionOpts...)
+		case MethodACHDebit, MethodACHCredit:
+			accountID, amount := a.FromAccountID, a.Amount
+			if a.Method == MethodACHDebit {
+				accountID, amount = a.ToAccountID, -a.Amount
+			}
+			res.ACHTransfer, res.Err = e.client.ACHTransfers.New(ctx, increase.ACHTransferNewParams{
+				AccountID:           increase.F(accountID),
+				Amount:              increase.F(amount),
+				ExternalAccountID:   increase.F(a.ExternalAccountID),
+				StatementDescriptor: increase.F(e.statementDescriptor(a.Rule)),
+			}, actionOpts...)
+		default:
+			res.Err = fmt.Errorf("sweep: unknown method %q", a.Method)
+		}
+		var apiErr *increase.Error
+		if errors.As(res.Err, &apiErr) && apiErr.Type == increase.ErrorTypeIdempotencyKeyAlreadyUsedError {
+			res.ExistingTransferID, res.Err = apiErr.ResourceID, nil
+		}
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("sweep: rule %s: %w", a.Rule, res.Err))
+		}
+		results = append(results, res)
+	}
+	return results, errors.Join(errs...)
+}
+
+func (e *Engine) statementDescriptor(rule string) string {
+	for _, r := range e.rules {
+		if r.name() == rule && r.StatementDescriptor != "" {
+			return r.StatementDescriptor
+		}
+	}
+	return "Sweep"
+}
+
+// Run plans and executes a sweep.
+func (e *Engine) Run(ctx context.Context, opts ...option.RequestOption) (*Plan, []Result, error) {
+	plan, err := e.Plan(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, err := e.Execute(ctx, plan, opts...)
+	return plan, results, err
+}
+
+// Schedule runs a sweep immediately and then at every interval until the
+// context is done, passing each outcome to report. It returns the context's
+// error, or an error without running if interval is not positive.
+func (e *Engine) Schedule(ctx context.Context, interval time.Duration, report func(*Plan, []Result, error), opts ...option.RequestOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("sweep: schedule interval %s is not positive", interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		plan, results, err := e.Run(ctx, opts...)
+		if report != nil {
+			report(plan, results, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package sweep_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/sweep"
+)
+
+type request struct {
+	path           string
+	idempotencyKey string
+	body           map[string]any
+}
+
+type fakeAPI struct {
+	mu       sync.Mutex
+	balances map[string]int64
+	requests []request
+	// Objects created by idempotency key, so retries return the same object.
+	created map[string]map[string]any
+}
+
+func newFakeAPI(t *testing.T, balances map[string]int64) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{balances: balances, created: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/{id}/balance", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		b := api.balances[r.PathValue("id")]
+		json.NewEncoder(w).Encode(map[string]any{"account_id": r.PathValue("id"), "available_balance": b, "current_balance": b})
+	})
+	create := func(prefix string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			api.mu.Lock()
+			defer api.mu.Unlock()
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			key := r.Header.Get("Idempotency-Key")
+			api.requests = append(api.requests, request{path: r.URL.Path, idempotencyKey: key, body: body})
+			if obj, ok := api.created[key]; ok {
+				if obj["amount"] != body["amount"] {
+					w.WriteHeader(http.StatusConflict)
+					json.NewEncoder(w).Encode(map[string]any{
+						"status":      409,
+						"type":        "idempotency_key_already_used_error",
+						"title":       "The idempotency key has already been used.",
+						"resource_id": obj["id"],
+					})
+					return
+				}
+				json.NewEncoder(w).Encode(obj)
+				return
+			}
+			body["id"] = prefix + "_" + key
+			api.created[key] = body
+			json.NewEncoder(w).Encode(body)
+		}
+	}
+	mux.HandleFunc("POST /account_transfers", create("account_transfer"))
+	mux.HandleFunc("POST /ach_transfers", create("ach_transfer"))
+	return api, testapi.NewClient(t, mux)
+}
+
+func rules() []sweep.Rule {
+	return []sweep.Rule{
+		{Name: "payroll", AccountID: "account_payroll", Min: 50000, Target: 100000, Max: 200000, CounterpartyAccountID: "account_treasury"},
+		{Name: "operating", AccountID: "account_operating", Min: 50000, Target: 100000, Max: 200000, CounterpartyAccountID: "account_treasury"},
+		{Name: "treasury", AccountID: "account_treasury", Min: 100000, Target: 500000, ExternalAccountID: "external_account_1", MinimumAmount: 1000},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	api, client := newFakeAPI(t, map[string]int64{
+		"account_payroll":   10000,
+		"account_operating": 350000,
+		"account_treasury":  120000,
+	})
+	e, err := sweep.New(client, rules())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	plan, err := e.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(api.requests) != 0 {
+		t.Fatalf("planning should not create transfers, got %+v", api.requests)
+	}
+	if len(plan.Actions) != 2 {
+		t.Fatalf("expected 2 actions, got %+v", plan)
+	}
+
+	fund, excess := plan.Actions[0], plan.Actions[1]
+	if fund.Method != sweep.MethodAccountTransfer || fund.FromAccountID != "account_treasury" || fund.Amount != 90000 || fund.ProjectedBalance != 100000 {
+		t.Errorf("unexpected funding action: %+v", fund)
+	}
+	if excess.Direction != sweep.DirectionSweep || excess.ToAccountID != "account_treasury" || excess.Amount != 250000 {
+		t.Errorf("unexpected sweep action: %+v", excess)
+	}
+	// The treasury is evaluated after both transfers, at a projected
+	// 120,000 - 90,000 + 250,000 = 280,000.
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Rule != "treasury" {
+		t.Errorf("expected the treasury to be within bounds, got %+v", plan.Skipped)
+	}
+}
+
+func TestRunIsIdempotentPerWindow(t *testing.T) {
+	api, client := newFakeAPI(t, map[string]int64{
+		"account_payroll":   10000,
+		"account_operating": 100000,
+		"account_treasury":  50000,
+	})
+	e, err := sweep.New(client, rules())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	plan, results, err := e.Run(context.Background())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	// The treasury can only fund 50,000 of the 90,000 payroll needs, and then
+	// pulls 500,000 from the external account.
+	if len(results) != 2 || results[0].AccountTransfer == nil || results[1].ACHTransfer == nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if plan.Actions[0].Amount != 50000 || plan.Actions[1].Method != sweep.MethodACHDebit || plan.Actions[1].Amount != 500000 {
+		t.Errorf("unexpected plan: %+v", plan.Actions)
+	}
+	ach := api.requests[1]
+	if ach.path != "/ach_transfers" || ach.body["amount"] != float64(-500000) || ach.body["external_account_id"] != "external_account_1" {
+		t.Errorf("unexpected ACH transfer: %+v", ach)
+	}
+	for _, r := range api.requests {
+		if !strings.HasPrefix(r.idempotencyKey, "sweep-") {
+			t.Errorf("expected an idempotency key, got %q", r.idempotencyKey)
+		}
+	}
+
+	_, again, err := e.Run(context.Background())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if again[0].AccountTransfer.ID != results[0].AccountTransfer.ID || again[1].ACHTransfer.ID != results[1].ACHTransfer.ID {
+		t.Errorf("a second run in the same window should reuse the idempotency keys")
+	}
+
+	// Balances change before the next run, but both accounts have already been
+	// funded in this window.
+	api.balances["account_payroll"] = 45000
+	api.balances["account_treasury"] = 40000
+	_, again, err = e.Run(context.Background())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(again) != 2 || again[0].ExistingTransferID != results[0].AccountTransfer.ID || again[1].ACHTransfer.ID != results[1].ACHTransfer.ID {
+		t.Errorf("expected the funding to be reported as already done, got %+v", again)
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	_, err := sweep.New(nil, []sweep.Rule{{AccountID: "account_1", Min: 100, Target: 50, CounterpartyAccountID: "account_2"}})
+	if err == nil {
+		t.Errorf("expected an error for a target below the minimum")
+	}
+	_, err = sweep.New(nil, []sweep.Rule{{AccountID: "account_1"}})
+	if err == nil {
+		t.Errorf("expected an error for a rule without a counterparty")
+	}
+}
+
+func TestScheduleInterval(t *testing.T) {
+	e, err := sweep.New(nil, rules())
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	ran := false
+	err = e.Schedule(context.Background(), 0, func(*sweep.Plan, []sweep.Result, error) { ran = true })
+	if err == nil || ran {
+		t.Errorf("expected an error without running, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package threeds
+
+import (
+	"context"
+
+	"github.com/Increase/increase-go"
+)
+
+// DefaultScorer scores an authentication attempt with simple rules on its
+// amount, device and addresses:
+//
+//   - 40 for purchases of 1,000.00 or more in the cardholder's currency, and
+//     20 for 250.00 or more;
+//   - 30 for quasi-cash, account funding and prepaid load transactions;
+//   - 20 when the shipping and billing countries differ;
+//   - 10 when the merchant is not in the billing country;
+//   - 10 for browsers with JavaScript disabled;
+//   - minus 20 when the requestor authenticated the cardholder before, and
+//     minus 10 when it says it already performed risk analysis or strong
+//     authentication.
+func DefaultScorer(ctx context.Context, a increase.RealTimeDecisionCardAuthentication) (int, error) {
+	var score int
+	payment := a.MessageCategory.Payment
+	if a.MessageCategory.Category == increase.RealTimeDecisionCardAuthenticationMessageCategoryCategoryPaymentAuthentication {
+		amount := payment.PurchaseAmountCardholderEstimated
+		if amount == 0 {
+			amount = payment.PurchaseAmount
+		}
+		switch {
+		case amount >= 100000:
+			score += 40
+		case amount >= 25000:
+			score += 20
+		}
+		switch payment.TransactionType {
+		case increase.RealTimeDecisionCardAuthenticationMessageCategoryPaymentTransactionTypeQuasiCashTransaction,
+			increase.RealTimeDecisionCardAuthenticationMessageCategoryPaymentTransactionTypeAccountFunding,
+			increase.RealTimeDecisionCardAuthenticationMessageCategoryPaymentTransactionTypePrepaidActivationAndLoad:
+			score += 30
+		}
+	}
+	if a.ShippingAddressCountry != "" && a.BillingAddressCountry != "" && a.ShippingAddressCountry != a.BillingAddressCountry {
+		score += 20
+	}
+	if a.MerchantCountry != "" && a.BillingAddressCountry != "" && a.MerchantCountry != a.BillingAddressCountry {
+		score += 10
+	}
+	if a.DeviceChannel.Browser.JavascriptEnabled == increase.RealTimeDecisionCardAuthenticationDeviceChannelBrowserJavascriptEnabledDisabled {
+		score += 10
+	}
+	if a.PriorAuthenticatedCardPaymentID != "" {
+		score -= 20
+	}
+	switch a.RequestorChallengeIndicator {
+	case increase.RealTimeDecisionCardAuthenticationRequestorChallengeIndicatorNoChallengeRequestedTransactionalRiskAnalysisAlreadyPerformed,
+		increase.RealTimeDecisionCardAuthenticationRequestorChallengeIndicatorNoChallengeRequestedStrongConsumerAuthenticationAlreadyPerformed:
+		score -= 10
+	}
+	return min(max(score, 0), 100), nil
+}
@@ -0,0 +1,407 @@
+// Package threeds answers 3-D Secure Real-Time Decisions.
+//
+// For `card_authentication_requested` decisions a [Handler] scores the risk of
+// the attempt with a pluggable [Scorer] and approves it frictionlessly, asks
+// for a challenge or denies it. For `card_authentication_challenge_requested`
+// decisions it delivers the one-time code to the cardholder with a pluggable
+// [Sender] and remembers it, so that it can be verified with
+// [Handler.Verify].
+//
+//	handler := threeds.NewHandler(client, func(ctx context.Context, c threeds.Challenge) (threeds.Delivery, error) {
+//		return threeds.Delivery{Phone: phones[c.CardID]}, sms.Send(ctx, phones[c.CardID], "Your code is "+c.Code)
+//	})
+//	http.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+//		// Unwrap the event, then:
+//		handler.HandleEvent(r.Context(), event)
+//	})
+package threeds
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+var (
+	// ErrNotAuthentication is returned for Real-Time Decisions and events of
+	// other categories.
+	ErrNotAuthentication = errors.New("threeds: not a card authentication real-time decision")
+	// ErrNotPending is returned for Real-Time Decisions that were already
+	// responded to or timed out.
+	ErrNotPending = errors.New("threeds: real-time decision is not pending")
+	// ErrInvalidCode is returned by [Handler.Verify] for a wrong code.
+	ErrInvalidCode = errors.New("threeds: invalid one-time code")
+	// ErrCodeExpired is returned by [Handler.Verify] for codes that expired,
+	// were already used, or were never sent.
+	ErrCodeExpired = errors.New("threeds: one-time code expired")
+	// ErrTooManyAttempts is returned by [Handler.Verify] once a code was
+	// guessed wrong too many times. The code can no longer be used.
+	ErrTooManyAttempts = errors.New("threeds: too many one-time code attempts")
+)
+
+// Scorer scores the risk of an authentication attempt, from 0 (no risk) to
+// 100.
+type Scorer func(ctx context.Context, a increase.RealTimeDecisionCardAuthentication) (int, error)
+
+// Challenge is a one-time code to deliver to a cardholder.
+type Challenge struct {
+	RealTimeDecisionID string
+	AccountID          string
+	CardID             string
+	CardPaymentID      string
+	Code               string
+	// The merchant and amount of the authentication attempt, when the
+	// handler decided it.
+	MerchantName     string
+	PurchaseAmount   int64
+	PurchaseCurrency string
+}
+
+// Delivery is where a one-time code was delivered.
+type Delivery struct {
+	Email string
+	Phone string
+}
+
+// Sender delivers a one-time code to the cardholder.
+type Sender func(ctx context.Context, c Challenge) (Delivery, error)
+
+// Decision is the answer to a 3-D Secure Real-Time Decision.
+type Decision struct {
+	RealTimeDecisionID string
+	Category           increase.RealTimeDecisionCategory
+	CardID             string
+	CardPaymentID      string
+
+	// For authentications.
+	Score  int
+	Action increase.RealTimeDecisionActionParamsCardAuthenticationDecision
+	Reason string
+
+	// For challenges. Err is why the code could not be delivered.
+	Delivered bool
+	Delivery  Delivery
+	Err       error
+}
+
+// Params returns the action that responds with the decision.
+func (d *Decision) Params() increase.RealTimeDecisionActionParams {
+	if d.Category == increase.RealTimeDecisionCategoryCardAuthenticationRequested {
+		return increase.RealTimeDecisionActionParams{
+			CardAuthentication: increase.F(increase.RealTimeDecisionActionParamsCardAuthentication{
+				Decision: increase.F(d.Action),
+			}),
+		}
+	}
+	challenge := increase.RealTimeDecisionActionParamsCardAuthenticationChallenge{
+		Result: increase.F(increase.RealTimeDecisionActionParamsCardAuthenticationChallengeResultFailure),
+	}
+	if d.Delivered {
+		challenge.Result = increase.F(increase.RealTimeDecisionActionParamsCardAuthenticationChallengeResultSuccess)
+		success := increase.RealTimeDecisionActionParamsCardAuthenticationChallengeSuccess{}
+		if d.Delivery.Email != "" {
+			success.Email = increase.F(d.Delivery.Email)
+		}
+		if d.Delivery.Phone != "" {
+			success.Phone = increase.F(d.Delivery.Phone)
+		}
+		challenge.Success = increase.F(success)
+	}
+	return increase.RealTimeDecisionActionParams{CardAuthenticationChallenge: increase.F(challenge)}
+}
+
+type code struct {
+	code      string
+	expiresAt time.Time
+	attempts  int
+}
+
+// pending is a challenged authentication waiting for its challenge.
+type pending struct {
+	authentication increase.RealTimeDecisionCardAuthentication
+	expiresAt      time.Time
+}
+
+// Handler answers 3-D Secure Real-Time Decisions. Its methods are safe for
+// concurrent use. Zero fields take their defaults, so a Handler literal may
+// leave them out.
+type Handler struct {
+	// Defaults to [DefaultScorer].
+	Scorer Scorer
+	// Attempts scoring at least ChallengeScore are challenged, and at least
+	// DenyScore denied. They default to 30 and 80.
+	ChallengeScore int
+	DenyScore      int
+	// Delivers one-time codes. Without a Sender, attempts that would be
+	// challenged are denied.
+	Sender Sender
+	// How long delivered codes can be verified, and how long challenged
+	// authentications are remembered for their challenge. Defaults to ten
+	// minutes.
+	CodeTTL time.Duration
+	// How many wrong codes are accepted before a code is revoked. Defaults to
+	// three.
+	MaxAttempts int
+	// Called with every decision, after it is sent.
+	OnDecision func(Decision)
+
+	client *increase.Client
+	now    func() time.Time
+
+	mu sync.Mutex
+	// The challenged authentications and delivered codes by Card Payment ID.
+	// Expired ones are swept as decisions are made.
+	pending map[string]pending
+	codes   map[string]*code
+}
+
+// NewHandler returns a handler that delivers codes with sender. sender may be
+// nil to never challenge.
+func NewHandler(client *increase.Client, sender Sender) *Handler {
+	return &Handler{
+		Scorer:         DefaultScorer,
+		ChallengeScore: 30,
+		DenyScore:      80,
+		Sender:         sender,
+		CodeTTL:        10 * time.Minute,
+		MaxAttempts:    3,
+		client:         client,
+		now:            time.Now,
+		pending:        map[string]pending{},
+		codes:          map[string]*code{},
+	}
+}
+
+func (h *Handler) clock() time.Time {
+	if h.now == nil {
+		return time.Now()
+	}
+	return h.now()
+}
+
+func (h *Handler) thresholds() (challenge, deny int) {
+	challenge, deny = h.ChallengeScore, h.DenyScore
+	if challenge == 0 {
+		challenge = 30
+	}
+	if deny == 0 {
+		deny = 80
+	}
+	return challenge, deny
+}
+
+func (h *Handler) codeTTL() time.Duration {
+	if h.CodeTTL <= 0 {
+		return 10 * time.Minute
+	}
+	return h.CodeTTL
+}
+
+func (h *Handler) maxAttempts() int {
+	if h.MaxAttempts <= 0 {
+		return 3
+	}
+	return h.MaxAttempts
+}
+
+// sweep forgets expired challenged authentications and codes, which are left
+// behind by abandoned authentications and codes that are never verified. It
+// must be called with h.mu held.
+func (h *Handler) sweep(now time.Time) {
+	for id, p := range h.pending {
+		if !now.Before(p.expiresAt) {
+			delete(h.pending, id)
+		}
+	}
+	for id, c := range h.codes {
+		if !now.Before(c.expiresAt) {
+			delete(h.codes, id)
+		}
+	}
+}
+
+// Decide decides a `card_authentication_requested` or
+// `card_authentication_challenge_requested` Real-Time Decision. Challenge
+// codes are delivered and remembered as they are decided.
+func (h *Handler) Decide(ctx context.Context, rtd increase.RealTimeDecision) (*Decision, error) {
+	h.mu.Lock()
+	h.sweep(h.clock())
+	h.mu.Unlock()
+	switch rtd.Category {
+	case increase.RealTimeDecisionCategoryCardAuthenticationRequested:
+		return h.authenticate(ctx, rtd)
+	case increase.RealTimeDecisionCategoryCardAuthenticationChallengeRequested:
+		return h.challenge(ctx, rtd)
+	}
+	return nil, ErrNotAuthentication
+}
+
+func (h *Handler) authenticate(ctx context.Context, rtd increase.RealTimeDecision) (*Decision, error) {
+	a := rtd.CardAuthentication
+	d := &Decision{RealTimeDecisionID: rtd.ID, Category: rtd.Category, CardID: a.CardID, CardPaymentID: a.UpcomingCardPaymentID}
+	scorer := h.Scorer
+	if scorer == nil {
+		scorer = DefaultScorer
+	}
+	score, err := scorer(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("threeds: scoring %s: %w", rtd.ID, err)
+	}
+	d.Score = score
+	challengeScore, denyScore := h.thresholds()
+
+	mandate := a.RequestorChallengeIndicator == increase.RealTimeDecisionCardAuthenticationRequestorChallengeIndicatorChallengeRequestedMandate
+	// Requestor-initiated attempts happen without the cardholder, who cannot
+	// be challenged.
+	unattended := a.DeviceChannel.Category == increase.RealTimeDecisionCardAuthenticationDeviceChannelCategoryThreeDSRequestorInitiated
+	switch {
+	case score >= denyScore:
+		d.Action, d.Reason = increase.RealTimeDecisionActionParamsCardAuthenticationDecisionDeny, fmt.Sprintf("risk score %d", score)
+	case unattended && (score >= challengeScore || mandate):
+		d.Action, d.Reason = increase.RealTimeDecisionActionParamsCardAuthenticationDecisionDeny, fmt.Sprintf("risk score %d without the cardholder", score)
+	case score >= challengeScore || mandate:
+		if h.Sender == nil {
+			d.Action, d.Reason = increase.RealTimeDecisionActionParamsCardAuthenticationDecisionDeny, "challenge required but no sender configured"
+			break
+		}
+		d.Action = increase.RealTimeDecisionActionParamsCardAuthenticationDecisionChallenge
+		if mandate {
+			d.Reason = "challenge mandated by the requestor"
+		} else {
+			d.Reason = fmt.Sprintf("risk score %d", score)
+		}
+		h.mu.Lock()
+		if h.pending == nil {
+			h.pending = map[string]pending{}
+		}
+		h.pending[a.UpcomingCardPaymentID] = pending{authentication: a, expiresAt: h.clock().Add(h.codeTTL())}
+		h.mu.Unlock()
+	default:
+		d.Action = increase.RealTimeDecisionActionParamsCardAuthenticationDecisionApprove
+	}
+	return d, nil
+}
+
+func (h *Handler) challenge(ctx context.Context, rtd increase.RealTimeDecision) (*Decision, error) {
+	c := rtd.CardAuthenticationChallenge
+	d := &Decision{RealTimeDecisionID: rtd.ID, Category: rtd.Category, CardID: c.CardID, CardPaymentID: c.CardPaymentID}
+	challenge := Challenge{
+		RealTimeDecisionID: rtd.ID,
+		AccountID:          c.AccountID,
+		CardID:             c.CardID,
+		CardPaymentID:      c.CardPaymentID,
+		Code:               c.OneTimeCode,
+	}
+	h.mu.Lock()
+	p, ok := h.pending[c.CardPaymentID]
+	delete(h.pending, c.CardPaymentID)
+	h.mu.Unlock()
+	if a := p.authentication; ok {
+		challenge.MerchantName = a.MerchantName
+		challenge.PurchaseAmount = a.MessageCategory.Payment.PurchaseAmount
+		challenge.PurchaseCurrency = a.MessageCategory.Payment.PurchaseCurrency
+	}
+	if challenge.Code == "" {
+		generated, err := GenerateCode(6)
+		if err != nil {
+			return nil, err
+		}
+		challenge.Code = generated
+	}
+	if h.Sender == nil {
+		d.Err = errors.New("threeds: no sender configured")
+		return d, nil
+	}
+	delivery, err := h.Sender(ctx, challenge)
+	if err != nil {
+		d.Err = fmt.Errorf("threeds: delivering code for %s: %w", c.CardPaymentID, err)
+		return d, nil
+	}
+	d.Delivered, d.Delivery = true, delivery
+	h.mu.Lock()
+	if h.codes == nil {
+		h.codes = map[string]*code{}
+	}
+	h.codes[c.CardPaymentID] = &code{code: challenge.Code, expiresAt: h.clock().Add(h.codeTTL())}
+	h.mu.Unlock()
+	return d, nil
+}
+
+// Respond decides a pending 3-D Secure Real-Time Decision and sends the answer
+// with [increase.RealTimeDecisionService.Action].
+func (h *Handler) Respond(ctx context.Context, rtd increase.RealTimeDecision, opts ...option.RequestOption) (*Decision, error) {
+	if rtd.Status != increase.RealTimeDecisionStatusPending {
+		return nil, ErrNotPending
+	}
+	d, err := h.Decide(ctx, rtd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.client.RealTimeDecisions.Action(ctx, rtd.ID, d.Params(), opts...); err != nil {
+		return nil, err
+	}
+	if h.OnDecision != nil {
+		h.OnDecision(*d)
+	}
+	return d, nil
+}
+
+// HandleEvent retrieves the Real-Time Decision of a
+// `real_time_decision.card_authentication_requested` or
+// `real_time_decision.card_authentication_challenge_requested` event and
+// responds to it.
+func (h *Handler) HandleEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*Decision, error) {
+	switch event.Category {
+	case increase.EventCategoryRealTimeDecisionCardAuthenticationRequested, increase.EventCategoryRealTimeDecisionCardAuthenticationChallengeRequested:
+	default:
+		return nil, ErrNotAuthentication
+	}
+	rtd, err := h.client.RealTimeDecisions.Get(ctx, event.AssociatedObjectID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return h.Respond(ctx, *rtd, opts...)
+}
+
+// Verify checks a code the cardholder entered for the challenge of a Card
+// Payment. A code verifies once.
+func (h *Handler) Verify(cardPaymentID, entered string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c, ok := h.codes[cardPaymentID]
+	if !ok || !h.clock().Before(c.expiresAt) {
+		delete(h.codes, cardPaymentID)
+		return ErrCodeExpired
+	}
+	if subtle.ConstantTimeCompare([]byte(c.code), []byte(entered)) == 1 {
+		delete(h.codes, cardPaymentID)
+		return nil
+	}
+	c.attempts++
+	if c.attempts >= h.maxAttempts() {
+		delete(h.codes, cardPaymentID)
+		return ErrTooManyAttempts
+	}
+	return ErrInvalidCode
+}
+
+// GenerateCode returns a random numeric code of n digits.
+func GenerateCode(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("threeds: generating code: %w", err)
+		}
+		b[i] = byte('0' + d.Int64())
+	}
+	return string(b), nil
+}
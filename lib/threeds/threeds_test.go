@@ -0,0 +1,238 @@
+package threeds_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/threeds"
+)
+
+type fakeAPI struct {
+	mu        sync.Mutex
+	decisions map[string]map[string]any
+	actions   map[string]map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{decisions: map[string]map[string]any{}, actions: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /real_time_decisions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		json.NewEncoder(w).Encode(api.decisions[r.PathValue("id")])
+	})
+	mux.HandleFunc("POST /real_time_decisions/{id}/action", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		api.actions[r.PathValue("id")] = body
+		json.NewEncoder(w).Encode(map[string]any{"id": r.PathValue("id"), "status": "responded"})
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func (api *fakeAPI) authentication(id string, amount int64, deviceChannel string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.decisions[id] = map[string]any{
+		"id":       id,
+		"category": "card_authentication_requested",
+		"status":   "pending",
+		"card_authentication": map[string]any{
+			"account_id":               "account_1",
+			"card_id":                  "card_1",
+			"upcoming_card_payment_id": "card_payment_" + id,
+			"merchant_name":            "ACME",
+			"merchant_country":         "US",
+			"billing_address_country":  "US",
+			"device_channel":           map[string]any{"category": deviceChannel},
+			"message_category": map[string]any{
+				"category": "payment_authentication",
+				"payment":  map[string]any{"purchase_amount": amount, "purchase_currency": "USD"},
+			},
+		},
+	}
+}
+
+func (api *fakeAPI) challenge(id, cardPaymentID string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.decisions[id] = map[string]any{
+		"id":       id,
+		"category": "card_authentication_challenge_requested",
+		"status":   "pending",
+		"card_authentication_challenge": map[string]any{
+			"account_id":      "account_1",
+			"card_id":         "card_1",
+			"card_payment_id": cardPaymentID,
+			"one_time_code":   "123456",
+		},
+	}
+}
+
+func event(category increase.EventCategory, id string) increase.Event {
+	return increase.Event{Category: category, AssociatedObjectID: id}
+}
+
+func TestChallenge(t *testing.T) {
+	api, client := newFakeAPI(t)
+	var sent []threeds.Challenge
+	handler := threeds.NewHandler(client, func(ctx context.Context, c threeds.Challenge) (threeds.Delivery, error) {
+		sent = append(sent, c)
+		return threeds.Delivery{Phone: "+15555550100"}, nil
+	})
+	ctx := context.Background()
+
+	api.authentication("rtd_1", 150000, "browser")
+	d, err := handler.HandleEvent(ctx, event(increase.EventCategoryRealTimeDecisionCardAuthenticationRequested, "rtd_1"))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if d.Action != increase.RealTimeDecisionActionParamsCardAuthenticationDecisionChallenge || d.Score != 40 {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if action := api.actions["rtd_1"]["card_authentication"].(map[string]any); action["decision"] != "challenge" {
+		t.Errorf("unexpected action %+v", api.actions["rtd_1"])
+	}
+
+	api.challenge("rtd_2", "card_payment_rtd_1")
+	d, err = handler.HandleEvent(ctx, event(increase.EventCategoryRealTimeDecisionCardAuthenticationChallengeRequested, "rtd_2"))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if !d.Delivered || len(sent) != 1 || sent[0].Code != "123456" || sent[0].MerchantName != "ACME" || sent[0].PurchaseAmount != 150000 {
+		t.Fatalf("unexpected decision %+v after sending %+v", d, sent)
+	}
+	action := api.actions["rtd_2"]["card_authentication_challenge"].(map[string]any)
+	if action["result"] != "success" || action["success"].(map[string]any)["phone"] != "+15555550100" {
+		t.Errorf("unexpected action %+v", action)
+	}
+
+	if err := handler.Verify("card_payment_rtd_1", "000000"); !errors.Is(err, threeds.ErrInvalidCode) {
+		t.Errorf("expected ErrInvalidCode, got %v", err)
+	}
+	if err := handler.Verify("card_payment_rtd_1", "123456"); err != nil {
+		t.Errorf("err should be nil: %s", err)
+	}
+	if err := handler.Verify("card_payment_rtd_1", "123456"); !errors.Is(err, threeds.ErrCodeExpired) {
+		t.Errorf("expected codes to verify once, got %v", err)
+	}
+}
+
+func TestChallengeDeliveryFailure(t *testing.T) {
+	api, client := newFakeAPI(t)
+	handler := threeds.NewHandler(client, func(ctx context.Context, c threeds.Challenge) (threeds.Delivery, error) {
+		return threeds.Delivery{}, errors.New("no phone number")
+	})
+
+	api.challenge("rtd_1", "card_payment_1")
+	d, err := handler.HandleEvent(context.Background(), event(increase.EventCategoryRealTimeDecisionCardAuthenticationChallengeRequested, "rtd_1"))
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if d.Delivered || d.Err == nil || api.actions["rtd_1"]["card_authentication_challenge"].(map[string]any)["result"] != "failure" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if err := handler.Verify("card_payment_1", "123456"); !errors.Is(err, threeds.ErrCodeExpired) {
+		t.Errorf("expected undelivered codes not to verify, got %v", err)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	api, client := newFakeAPI(t)
+	handler := threeds.NewHandler(client, nil)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		amount        int64
+		deviceChannel string
+		action        increase.RealTimeDecisionActionParamsCardAuthenticationDecision
+	}{
+		{1000, "browser", increase.RealTimeDecisionActionParamsCardAuthenticationDecisionApprove},
+		// Challenges need a sender.
+		{150000, "app", increase.RealTimeDecisionActionParamsCardAuthenticationDecisionDeny},
+		{150000, "three_ds_requestor_initiated", increase.RealTimeDecisionActionParamsCardAuthenticationDecisionDeny},
+	} {
+		api.authentication("rtd_1", tt.amount, tt.deviceChannel)
+		rtd, err := client.RealTimeDecisions.Get(ctx, "rtd_1")
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		d, err := handler.Decide(ctx, *rtd)
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		if d.Action != tt.action {
+			t.Errorf("%d from %s: expected %s, got %+v", tt.amount, tt.deviceChannel, tt.action, d)
+		}
+	}
+
+	if _, err := handler.HandleEvent(ctx, event(increase.EventCategoryCardCreated, "card_1")); !errors.Is(err, threeds.ErrNotAuthentication) {
+		t.Errorf("expected ErrNotAuthentication, got %v", err)
+	}
+}
+
+func TestVerifyTooManyAttempts(t *testing.T) {
+	api, client := newFakeAPI(t)
+	handler := threeds.NewHandler(client, func(ctx context.Context, c threeds.Challenge) (threeds.Delivery, error) {
+		return threeds.Delivery{Email: "ada@example.com"}, nil
+	})
+	handler.MaxAttempts = 2
+
+	api.challenge("rtd_1", "card_payment_1")
+	if _, err := handler.HandleEvent(context.Background(), event(increase.EventCategoryRealTimeDecisionCardAuthenticationChallengeRequested, "rtd_1")); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if err := handler.Verify("card_payment_1", "000000"); !errors.Is(err, threeds.ErrInvalidCode) {
+		t.Errorf("expected ErrInvalidCode, got %v", err)
+	}
+	if err := handler.Verify("card_payment_1", "000000"); !errors.Is(err, threeds.ErrTooManyAttempts) {
+		t.Errorf("expected ErrTooManyAttempts, got %v", err)
+	}
+	if err := handler.Verify("card_payment_1", "123456"); !errors.Is(err, threeds.ErrCodeExpired) {
+		t.Errorf("expected the code to be revoked, got %v", err)
+	}
+}
+
+func TestHandlerLiteral(t *testing.T) {
+	handler := &threeds.Handler{Sender: func(ctx context.Context, c threeds.Challenge) (threeds.Delivery, error) {
+		return threeds.Delivery{Email: "ada@example.com"}, nil
+	}}
+	var rtd increase.RealTimeDecision
+	body := `{"id": "rtd_1", "category": "card_authentication_challenge_requested", "status": "pending", "card_authentication_challenge": {"card_id": "card_1", "card_payment_id": "card_payment_1", "one_time_code": "123456"}}`
+	if err := json.Unmarshal([]byte(body), &rtd); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	d, err := handler.Decide(context.Background(), rtd)
+	if err != nil || !d.Delivered {
+		t.Fatalf("expected the code to be delivered, got %+v, %v", d, err)
+	}
+	if err := handler.Verify("card_payment_1", "000000"); !errors.Is(err, threeds.ErrInvalidCode) {
+		t.Errorf("expected ErrInvalidCode, got %v", err)
+	}
+	if err := handler.Verify("card_payment_1", "123456"); err != nil {
+		t.Errorf("err should be nil: %s", err)
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	code, err := threeds.GenerateCode(8)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(code) != 8 {
+		t.Errorf("unexpected code %q", code)
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			t.Errorf("unexpected code %q", code)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package wallettokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+var (
+	// ErrNotTokenRequest is returned for Real-Time Decisions and events of
+	// other categories.
+	ErrNotTokenRequest = errors.New("wallettokens: not a digital_wallet_token_requested real-time decision")
+	// ErrNotPending is returned for Real-Time Decisions that were already
+	// responded to or timed out.
+	ErrNotPending = errors.New("wallettokens: real-time decision is not pending")
+)
+
+// Contact is where the cardholder receives the one-time passcode that
+// verifies a new token.
+type Contact struct {
+	Email string
+	Phone string
+}
+
+// Policy decides whether a Card may be added to a digital wallet.
+type Policy struct {
+	// The wallets cards may be added to. Empty allows every wallet.
+	AllowedWallets []increase.RealTimeDecisionDigitalWalletTokenDigitalWallet
+	// Device identifiers that may never hold a token.
+	BlockedDevices []string
+	// Decline when the Card already has this many active tokens. Zero disables
+	// the check.
+	MaxActiveTokens int
+	// Decline when the Card has had this many provisioning attempts declined
+	// within [Monitor.Window]. Zero disables the check.
+	MaxRecentDeclines int
+	// Returns where the cardholder of a Card verifies new tokens. Optional.
+	Contact func(ctx context.Context, cardID string) (Contact, error)
+}
+
+// Decision is the answer to a token request.
+type Decision struct {
+	RealTimeDecisionID string
+	CardID             string
+	Approve            bool
+	// Why the request was declined. It is logged by Increase, not shown to
+	// the cardholder.
+	Reason  string
+	Contact Contact
+}
+
+// Decide applies the policy to a `digital_wallet_token_requested` Real-Time
+// Decision, using what the monitor knows about the Card's tokens.
+func (m *Monitor) Decide(ctx context.Context, rtd increase.RealTimeDecision) (*Decision, error) {
+	if rtd.Category != increase.RealTimeDecisionCategoryDigitalWalletTokenRequested {
+		return nil, ErrNotTokenRequest
+	}
+	req := rtd.DigitalWalletToken
+	d := &Decision{RealTimeDecisionID: rtd.ID, CardID: req.CardID}
+	p := m.Policy
+	c := m.Card(req.CardID)
+	if c == nil {
+		c = &CardTokens{CardID: req.CardID}
+	}
+
+	var recent int
+	since := rtd.CreatedAt.Add(-m.Window)
+	for _, t := range c.Declined() {
+		if t.CreatedAt.After(since) {
+			recent++
+		}
+	}
+	switch {
+	case len(p.AllowedWallets) > 0 && !slices.Contains(p.AllowedWallets, req.DigitalWallet):
+		d.Reason = fmt.Sprintf("digital wallet %s is not allowed", req.DigitalWallet)
+	case req.Device.Identifier != "" && slices.Contains(p.BlockedDevices, req.Device.Identifier):
+		d.Reason = "device is blocked"
+	case p.MaxRecentDeclines > 0 && recent >= p.MaxRecentDeclines:
+		d.Reason = fmt.Sprintf("%d provisioning attempts declined within %s", recent, m.Window)
+	case p.MaxActiveTokens > 0 && len(c.Active()) >= p.MaxActiveTokens:
+		if _, ok := c.Device(req.Device.Identifier); ok {
+			// Re-provisioning a device that already holds a token does not add
+			// one.
+			d.Approve = true
+		} else {
+			d.Reason = fmt.Sprintf("card already has %d active tokens", len(c.Active()))
+		}
+	default:
+		d.Approve = true
+	}
+	if d.Approve && p.Contact != nil {
+		contact, err := p.Contact(ctx, req.CardID)
+		if err != nil {
+			return nil, fmt.Errorf("wallettokens: looking up contact for card %s: %w", req.CardID, err)
+		}
+		d.Contact = contact
+	}
+	return d, nil
+}
+
+// Params returns the action that responds with the decision.
+func (d *Decision) Params() increase.RealTimeDecisionActionParams {
+	token := increase.RealTimeDecisionActionParamsDigitalWalletToken{}
+	if d.Approve {
+		approval := increase.RealTimeDecisionActionParamsDigitalWalletTokenApproval{}
+		if d.Contact.Email != "" {
+			approval.Email = increase.F(d.Contact.Email)
+		}
+		if d.Contact.Phone != "" {
+			approval.Phone = increase.F(d.Contact.Phone)
+		}
+		token.Approval = increase.F(approval)
+	} else {
+		token.Decline = increase.F(increase.RealTimeDecisionActionParamsDigitalWalletTokenDecline{
+			Reason: increase.F(d.Reason),
+		})
+	}
+	return increase.RealTimeDecisionActionParams{DigitalWalletToken: increase.F(token)}
+}
+
+// Respond decides a pending `digital_wallet_token_requested` Real-Time
+// Decision and sends the answer with [increase.RealTimeDecisionService.Action].
+func (m *Monitor) Respond(ctx context.Context, rtd increase.RealTimeDecision, opts ...option.RequestOption) (*Decision, error) {
+	if rtd.Status != increase.RealTimeDecisionStatusPending {
+		return nil, ErrNotPending
+	}
+	d, err := m.Decide(ctx, rtd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.client.RealTimeDecisions.Action(ctx, rtd.ID, d.Params(), opts...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// HandleDecisionEvent retrieves the Real-Time Decision of a
+// `real_time_decision.digital_wallet_token_requested` event and responds to
+// it.
+func (m *Monitor) HandleDecisionEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*Decision, error) {
+	if event.Category != increase.EventCategoryRealTimeDecisionDigitalWalletTokenRequested {
+		return nil, ErrNotTokenRequest
+	}
+	rtd, err := m.client.RealTimeDecisions.Get(ctx, event.AssociatedObjectID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Respond(ctx, *rtd, opts...)
+}
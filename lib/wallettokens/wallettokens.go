@@ -0,0 +1,345 @@
+// Package wallettokens maintains a per-card view of Digital Wallet Tokens,
+// summarizes why provisioning attempts were declined, detects suspicious
+// tokenization patterns, and answers `digital_wallet_token_requested` Real-Time
+// Decisions with an approval [Policy].
+//
+//	monitor := wallettokens.NewMonitor(client)
+//	monitor.OnAlert = func(a wallettokens.Alert) { log.Println(a) }
+//	// For each digital_wallet_token.created and .updated event:
+//	monitor.HandleEvent(ctx, event)
+package wallettokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/option"
+)
+
+// ErrNotDigitalWalletTokenEvent is returned for events about other objects.
+var ErrNotDigitalWalletTokenEvent = errors.New("wallettokens: not a digital wallet token event")
+
+// CardTokens is every Digital Wallet Token of a Card.
+type CardTokens struct {
+	CardID string
+	// Oldest first.
+	Tokens []increase.DigitalWalletToken
+}
+
+// WithStatus returns the tokens with the status.
+func (c *CardTokens) WithStatus(status increase.DigitalWalletTokenStatus) []increase.DigitalWalletToken {
+	var res []increase.DigitalWalletToken
+	for _, t := range c.Tokens {
+		if t.Status == status {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// Active returns the tokens that can be used to pay.
+func (c *CardTokens) Active() []increase.DigitalWalletToken {
+	return c.WithStatus(increase.DigitalWalletTokenStatusActive)
+}
+
+// Suspended returns the tokens that are temporarily unusable.
+func (c *CardTokens) Suspended() []increase.DigitalWalletToken {
+	return c.WithStatus(increase.DigitalWalletTokenStatusSuspended)
+}
+
+// Deactivated returns the tokens that are permanently unusable.
+func (c *CardTokens) Deactivated() []increase.DigitalWalletToken {
+	return c.WithStatus(increase.DigitalWalletTokenStatusDeactivated)
+}
+
+// Declined returns the provisioning attempts that were declined.
+func (c *CardTokens) Declined() []increase.DigitalWalletToken {
+	return c.WithStatus(increase.DigitalWalletTokenStatusDeclined)
+}
+
+// Declines counts the declined provisioning attempts by reason.
+func (c *CardTokens) Declines() map[increase.DigitalWalletTokenDeclineReason]int {
+	res := map[increase.DigitalWalletTokenDeclineReason]int{}
+	for _, t := range c.Declined() {
+		res[t.Decline.Reason]++
+	}
+	return res
+}
+
+// Device returns the token provisioned to a device, if any. Declined tokens
+// are ignored.
+func (c *CardTokens) Device(identifier string) (increase.DigitalWalletToken, bool) {
+	for _, t := range c.Tokens {
+		if identifier != "" && t.Device.Identifier == identifier && t.Status != increase.DigitalWalletTokenStatusDeclined {
+			return t, true
+		}
+	}
+	return increase.DigitalWalletToken{}, false
+}
+
+func (c *CardTokens) upsert(token increase.DigitalWalletToken) {
+	for i, t := range c.Tokens {
+		if t.ID == token.ID {
+			c.Tokens[i] = token
+			return
+		}
+	}
+	c.Tokens = append(c.Tokens, token)
+	sort.SliceStable(c.Tokens, func(i, j int) bool { return c.Tokens[i].CreatedAt.Before(c.Tokens[j].CreatedAt) })
+}
+
+// AlertCategory is a kind of suspicious pattern.
+type AlertCategory string
+
+const (
+	// A Card has more active tokens than [Monitor.MaxActiveTokens].
+	AlertManyTokens AlertCategory = "many_tokens"
+	// A Card has had more provisioning attempts declined within
+	// [Monitor.Window] than [Monitor.MaxDeclines].
+	AlertRepeatedDeclines AlertCategory = "repeated_declines"
+	// A high-value authorization was made with a token provisioned within
+	// [Monitor.Window].
+	AlertNewDeviceHighValue AlertCategory = "new_device_high_value"
+)
+
+// Alert is a suspicious pattern on a Card.
+type Alert struct {
+	Category AlertCategory
+	CardID   string
+	// The tokens involved.
+	TokenIDs []string
+	// The Real-Time Decision of the authorization, for new_device_high_value.
+	RealTimeDecisionID string
+	Message            string
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s: %s: %s", a.CardID, a.Category, a.Message)
+}
+
+// Monitor maintains the Digital Wallet Tokens of Cards and detects suspicious
+// patterns. It is safe for concurrent use.
+type Monitor struct {
+	client *increase.Client
+	// Cards with more active tokens are reported. Defaults to 5.
+	MaxActiveTokens int
+	// Cards with more declined provisioning attempts within Window are
+	// reported. Defaults to 3.
+	MaxDeclines int
+	// The window for repeated declines and new devices. Defaults to 24 hours.
+	Window time.Duration
+	// Authorizations of at least this amount, in the minor unit of the
+	// settlement currency, with a token provisioned within Window are
+	// reported. Defaults to 50000.
+	HighValueAmount int64
+	// Called for every alert raised.
+	OnAlert func(Alert)
+	// Applied to `digital_wallet_token_requested` Real-Time Decisions.
+	Policy Policy
+
+	mu      sync.Mutex
+	cards   map[string]*CardTokens
+	alerted map[string]bool
+	now     func() time.Time
+}
+
+// NewMonitor returns a monitor that retrieves tokens with the client.
+func NewMonitor(client *increase.Client) *Monitor {
+	return &Monitor{
+		client:          client,
+		MaxActiveTokens: 5,
+		MaxDeclines:     3,
+		Window:          24 * time.Hour,
+		HighValueAmount: 50000,
+		cards:           map[string]*CardTokens{},
+		alerted:         map[string]bool{},
+		now:             time.Now,
+	}
+}
+
+// Card returns the tokens of a Card, or nil if none have been seen.
+func (m *Monitor) Card(cardID string) *CardTokens {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.cards[cardID]
+	if !ok {
+		return nil
+	}
+	return &CardTokens{CardID: c.CardID, Tokens: append([]increase.DigitalWalletToken{}, c.Tokens...)}
+}
+
+// Load lists every token of a Card, replacing what was known about it, and
+// raises the alerts that apply.
+func (m *Monitor) Load(ctx context.Context, cardID string, opts ...option.RequestOption) (*CardTokens, error) {
+	iter := m.client.DigitalWalletTokens.ListAutoPaging(ctx, increase.DigitalWalletTokenListParams{
+		CardID: increase.F(cardID),
+	}, opts...)
+	c := &CardTokens{CardID: cardID}
+	for iter.Next() {
+		c.upsert(iter.Current())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.cards[cardID] = c
+	m.mu.Unlock()
+	m.check(cardID)
+	return m.Card(cardID), nil
+}
+
+// HandleEvent refreshes the token of a `digital_wallet_token.created` or
+// `digital_wallet_token.updated` event.
+func (m *Monitor) HandleEvent(ctx context.Context, event increase.Event, opts ...option.RequestOption) (*CardTokens, error) {
+	if event.Category != increase.EventCategoryDigitalWalletTokenCreated && event.Category != increase.EventCategoryDigitalWalletTokenUpdated {
+		return nil, ErrNotDigitalWalletTokenEvent
+	}
+	token, err := m.client.DigitalWalletTokens.Get(ctx, event.AssociatedObjectID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return m.Track(*token), nil
+}
+
+// Track records a token and raises the alerts that apply to its Card.
+func (m *Monitor) Track(token increase.DigitalWalletToken) *CardTokens {
+	m.mu.Lock()
+	c, ok := m.cards[token.CardID]
+	if !ok {
+		c = &CardTokens{CardID: token.CardID}
+		m.cards[token.CardID] = c
+	}
+	c.upsert(token)
+	m.mu.Unlock()
+	m.check(token.CardID)
+	return m.Card(token.CardID)
+}
+
+// Declines counts the declined provisioning attempts of every known Card by
+// reason.
+func (m *Monitor) Declines() map[increase.DigitalWalletTokenDeclineReason]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := map[increase.DigitalWalletTokenDeclineReason]int{}
+	for _, c := range m.cards {
+		for reason, n := range c.Declines() {
+			res[reason] += n
+		}
+	}
+	return res
+}
+
+// Alerts returns the token-count and decline alerts that currently apply to a
+// Card.
+func (m *Monitor) Alerts(cardID string) []Alert {
+	c := m.Card(cardID)
+	if c == nil {
+		return nil
+	}
+	var res []Alert
+	if active := c.Active(); m.MaxActiveTokens > 0 && len(active) > m.MaxActiveTokens {
+		res = append(res, Alert{
+			Category: AlertManyTokens,
+			CardID:   cardID,
+			TokenIDs: ids(active),
+			Message:  fmt.Sprintf("%d active tokens, more than %d", len(active), m.MaxActiveTokens),
+		})
+	}
+	var recent []increase.DigitalWalletToken
+	since := m.now().Add(-m.Window)
+	for _, t := range c.Declined() {
+		if t.CreatedAt.After(since) {
+			recent = append(recent, t)
+		}
+	}
+	if m.MaxDeclines > 0 && len(recent) > m.MaxDeclines {
+		res = append(res, Alert{
+			Category: AlertRepeatedDeclines,
+			CardID:   cardID,
+			TokenIDs: ids(recent),
+			Message:  fmt.Sprintf("%d provisioning attempts declined within %s", len(recent), m.Window),
+		})
+	}
+	return res
+}
+
+// check raises the alerts of a Card that have not been raised for the same
+// tokens.
+func (m *Monitor) check(cardID string) {
+	if m.OnAlert == nil {
+		return
+	}
+	for _, a := range m.Alerts(cardID) {
+		key := fmt.Sprint(a.CardID, a.Category, a.TokenIDs)
+		m.mu.Lock()
+		seen := m.alerted[key]
+		m.alerted[key] = true
+		m.mu.Unlock()
+		if !seen {
+			m.OnAlert(a)
+		}
+	}
+}
+
+// CheckAuthorization reports a `card_authorization_requested` Real-Time
+// Decision of at least HighValueAmount made with a token provisioned within
+// Window. It returns nil when the authorization is not suspicious.
+func (m *Monitor) CheckAuthorization(rtd increase.RealTimeDecision) *Alert {
+	auth := rtd.CardAuthorization
+	if rtd.Category != increase.RealTimeDecisionCategoryCardAuthorizationRequested || auth.DigitalWalletTokenID == "" || auth.SettlementAmount < m.HighValueAmount {
+		return nil
+	}
+	c := m.Card(auth.CardID)
+	if c == nil {
+		return nil
+	}
+	for _, t := range c.Tokens {
+		if t.ID != auth.DigitalWalletTokenID {
+			continue
+		}
+		provisioned := activatedAt(t)
+		if provisioned.IsZero() || rtd.CreatedAt.Sub(provisioned) > m.Window {
+			return nil
+		}
+		a := &Alert{
+			Category:           AlertNewDeviceHighValue,
+			CardID:             auth.CardID,
+			TokenIDs:           []string{t.ID},
+			RealTimeDecisionID: rtd.ID,
+			Message: fmt.Sprintf("authorization of %d %s with a token provisioned on %q %s earlier",
+				auth.SettlementAmount, auth.SettlementCurrency, t.Device.Name, rtd.CreatedAt.Sub(provisioned).Round(time.Minute)),
+		}
+		if m.OnAlert != nil {
+			m.OnAlert(*a)
+		}
+		return a
+	}
+	return nil
+}
+
+// activatedAt returns when a token first became active, falling back to when
+// it was created.
+func activatedAt(t increase.DigitalWalletToken) time.Time {
+	for _, u := range t.Updates {
+		if u.Status == increase.DigitalWalletTokenUpdatesStatusActive {
+			return u.Timestamp
+		}
+	}
+	if t.Status == increase.DigitalWalletTokenStatusDeclined {
+		return time.Time{}
+	}
+	return t.CreatedAt
+}
+
+func ids(tokens []increase.DigitalWalletToken) []string {
+	res := make([]string, len(tokens))
+	for i, t := range tokens {
+		res[i] = t.ID
+	}
+	return res
+}
@@ -0,0 +1,205 @@
+package wallettokens_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Increase/increase-go"
+	"github.com/Increase/increase-go/lib/internal/testapi"
+	"github.com/Increase/increase-go/lib/wallettokens"
+)
+
+type fakeAPI struct {
+	mu      sync.Mutex
+	tokens  []map[string]any
+	actions map[string]map[string]any
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *increase.Client) {
+	api := &fakeAPI{actions: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /digital_wallet_tokens", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var data []map[string]any
+		for _, token := range api.tokens {
+			if token["card_id"] == r.URL.Query().Get("card_id") {
+				data = append(data, token)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data, "next_cursor": nil})
+	})
+	mux.HandleFunc("GET /digital_wallet_tokens/{id}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		for _, token := range api.tokens {
+			if token["id"] == r.PathValue("id") {
+				json.NewEncoder(w).Encode(token)
+			}
+		}
+	})
+	mux.HandleFunc("GET /real_time_decisions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":         r.PathValue("id"),
+			"category":   "digital_wallet_token_requested",
+			"status":     "pending",
+			"created_at": time.Now(),
+			"digital_wallet_token": map[string]any{
+				"card_id":        "card_1",
+				"digital_wallet": "apple_pay",
+				"device":         map[string]any{"identifier": "device_new"},
+			},
+		})
+	})
+	mux.HandleFunc("POST /real_time_decisions/{id}/action", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		api.actions[r.PathValue("id")] = body
+		json.NewEncoder(w).Encode(map[string]any{"id": r.PathValue("id"), "status": "responded"})
+	})
+	return api, testapi.NewClient(t, mux)
+}
+
+func token(id, status string, created time.Time) map[string]any {
+	res := map[string]any{
+		"id":         id,
+		"card_id":    "card_1",
+		"status":     status,
+		"created_at": created,
+		"device":     map[string]any{"identifier": "device_" + id, "name": "Phone " + id},
+		"updates":    []any{},
+	}
+	if status == "declined" {
+		res["decline"] = map[string]any{"reason": "incorrect_card_verification_code"}
+	} else {
+		res["updates"] = []any{map[string]any{"status": "active", "timestamp": created}}
+	}
+	return res
+}
+
+func TestMonitor(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	api, client := newFakeAPI(t)
+	api.tokens = []map[string]any{
+		token("1", "active", now.Add(-90*24*time.Hour)),
+		token("2", "suspended", now.Add(-60*24*time.Hour)),
+		token("3", "deactivated", now.Add(-30*24*time.Hour)),
+	}
+	for i := 0; i < 4; i++ {
+		api.tokens = append(api.tokens, token(fmt.Sprint("declined_", i), "declined", now.Add(-time.Duration(i+1)*time.Hour)))
+	}
+
+	monitor := wallettokens.NewMonitor(client)
+	var alerts []wallettokens.Alert
+	monitor.OnAlert = func(a wallettokens.Alert) { alerts = append(alerts, a) }
+	c, err := monitor.Load(ctx, "card_1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(c.Active()) != 1 || len(c.Suspended()) != 1 || len(c.Deactivated()) != 1 || len(c.Declined()) != 4 {
+		t.Errorf("unexpected view %+v", c)
+	}
+	if n := monitor.Declines()[increase.DigitalWalletTokenDeclineReasonIncorrectCardVerificationCode]; n != 4 {
+		t.Errorf("expected 4 declines, got %d", n)
+	}
+	if len(alerts) != 1 || alerts[0].Category != wallettokens.AlertRepeatedDeclines {
+		t.Fatalf("expected a repeated declines alert, got %v", alerts)
+	}
+
+	api.mu.Lock()
+	api.tokens = append(api.tokens, token("new", "active", now.Add(-time.Hour)))
+	api.mu.Unlock()
+	if _, err := monitor.HandleEvent(ctx, increase.Event{Category: increase.EventCategoryDigitalWalletTokenCreated, AssociatedObjectID: "new"}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if len(alerts) != 1 {
+		t.Errorf("expected alerts not to be raised twice, got %v", alerts)
+	}
+
+	var rtd increase.RealTimeDecision
+	json.Unmarshal([]byte(fmt.Sprintf(`{"id":"real_time_decision_1","category":"card_authorization_requested","created_at":%q,"card_authorization":{"card_id":"card_1","digital_wallet_token_id":"new","settlement_amount":75000,"settlement_currency":"USD"}}`, now.Format(time.RFC3339))), &rtd)
+	if a := monitor.CheckAuthorization(rtd); a == nil || a.Category != wallettokens.AlertNewDeviceHighValue {
+		t.Errorf("expected a new device alert, got %v", a)
+	}
+	rtd.CardAuthorization.DigitalWalletTokenID = "1"
+	if a := monitor.CheckAuthorization(rtd); a != nil {
+		t.Errorf("expected an established token not to be reported, got %v", a)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	api, client := newFakeAPI(t)
+	api.tokens = []map[string]any{token("1", "active", now.Add(-time.Hour))}
+
+	monitor := wallettokens.NewMonitor(client)
+	monitor.Load(ctx, "card_1")
+	monitor.Policy = wallettokens.Policy{
+		MaxActiveTokens: 2,
+		Contact: func(ctx context.Context, cardID string) (wallettokens.Contact, error) {
+			return wallettokens.Contact{Email: "ian@example.com"}, nil
+		},
+	}
+	event := increase.Event{Category: increase.EventCategoryRealTimeDecisionDigitalWalletTokenRequested, AssociatedObjectID: "real_time_decision_1"}
+	d, err := monitor.HandleDecisionEvent(ctx, event)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	approval, _ := api.actions["real_time_decision_1"]["digital_wallet_token"].(map[string]any)["approval"].(map[string]any)
+	if !d.Approve || approval["email"] != "ian@example.com" {
+		t.Errorf("expected an approval with the contact, got %+v", api.actions)
+	}
+
+	monitor.Policy.AllowedWallets = []increase.RealTimeDecisionDigitalWalletTokenDigitalWallet{increase.RealTimeDecisionDigitalWalletTokenDigitalWalletGooglePay}
+	d, _ = monitor.HandleDecisionEvent(ctx, event)
+	decline, _ := api.actions["real_time_decision_1"]["digital_wallet_token"].(map[string]any)["decline"].(map[string]any)
+	if d.Approve || decline["reason"] != "digital wallet apple_pay is not allowed" {
+		t.Errorf("expected a decline, got %+v", api.actions)
+	}
+
+	if _, err := monitor.HandleDecisionEvent(ctx, increase.Event{Category: increase.EventCategoryCardCreated}); !errors.Is(err, wallettokens.ErrNotTokenRequest) {
+		t.Errorf("expected ErrNotTokenRequest, got %v", err)
+	}
+}
+
+func TestPolicyDeclinesBeforeKnownDevices(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	api, client := newFakeAPI(t)
+	api.tokens = []map[string]any{
+		token("1", "active", now.Add(-48*time.Hour)),
+		token("2", "active", now.Add(-48*time.Hour)),
+		token("3", "declined", now.Add(-time.Hour)),
+		token("4", "declined", now.Add(-time.Hour)),
+	}
+	monitor := wallettokens.NewMonitor(client)
+	if _, err := monitor.Load(ctx, "card_1"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	monitor.Policy = wallettokens.Policy{MaxActiveTokens: 2, MaxRecentDeclines: 2}
+
+	// The card is at its cap and device_1 already holds a token, but the
+	// repeated declines still apply.
+	var rtd increase.RealTimeDecision
+	body := fmt.Sprintf(`{"id": "real_time_decision_1", "category": "digital_wallet_token_requested", "status": "pending", "created_at": %q, "digital_wallet_token": {"card_id": "card_1", "digital_wallet": "apple_pay", "device": {"identifier": "device_1"}}}`, now.Format(time.RFC3339))
+	if err := json.Unmarshal([]byte(body), &rtd); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	d, err := monitor.Decide(ctx, rtd)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if d.Approve {
+		t.Errorf("expected repeated declines to decline a known device, got %+v", d)
+	}
+}
@@ -0,0 +1,307 @@
+package increase
+
+import (
+	"github.com/Increase/increase-go/internal/param"
+	"github.com/Increase/increase-go/internal/validation"
+)
+
+// ValidationError is returned by the Validate methods on request parameters. It
+// lists every parameter that failed an offline check, keyed by the parameter's
+// dotted JSON path.
+type ValidationError = validation.Error
+
+// ValidationFieldError describes a single invalid request parameter.
+type ValidationFieldError = validation.FieldError
+
+// Field limits enforced by the ACH and Fedwire networks and by SWIFT
+// messages. Values longer than these cannot be carried by the network.
+const (
+	achCompanyDescriptiveDateMaxLength    = 6
+	achCompanyDiscretionaryDataMaxLength  = 20
+	achCompanyEntryDescriptionMaxLength   = 10
+	achCompanyNameMaxLength               = 16
+	achIndividualIDMaxLength              = 15
+	achIndividualNameMaxLength            = 22
+	achPaymentRelatedInformationMaxLength = 80
+	achAccountNumberMaxLength             = 17
+	statementDescriptorMaxLength          = 200
+	wireAccountNumberMaxLength            = 34
+	wireNameMaxLength                     = 35
+	wireAddressLineMaxLength              = 35
+	wireRemittanceMessageMaxLength        = 140
+	wireTaxTypeCodeLength                 = 5
+	wireTaxIdentificationNumberMaxLength  = 9
+	swiftNameMaxLength                    = 140
+	swiftAddressLineMaxLength             = 70
+	swiftRemittanceMaxLength              = 140
+	swiftAccountNumberMaxLength           = 34
+)
+
+// Validate performs offline format, length and checksum checks on the
+// parameters, such as the routing number check digit and Nacha field lengths.
+// It returns a [*ValidationError] describing every invalid parameter, or nil. A
+// nil result does not guarantee that the API will accept the transfer.
+func (r ACHTransferNewParams) Validate() error {
+	c := &validation.Collector{}
+	checkString(c, "account_id", r.AccountID, true)
+	if checkPresent(c, "amount", r.Amount, true) && r.Amount.Value == 0 {
+		c.Addf("amount", "must not be zero")
+	}
+	checkString(c, "statement_descriptor", r.StatementDescriptor, true, validation.PrintableASCII, maxLength(statementDescriptorMaxLength))
+	if r.ExternalAccountID.Present {
+		checkString(c, "external_account_id", r.ExternalAccountID, true)
+		checkAbsent(c, "account_number", r.AccountNumber, "must be absent when external_account_id is set")
+		checkAbsent(c, "routing_number", r.RoutingNumber, "must be absent when external_account_id is set")
+		checkAbsent(c, "funding", r.Funding, "must be absent when external_account_id is set")
+	} else {
+		checkString(c, "account_number", r.AccountNumber, true, accountNumber(achAccountNumberMaxLength))
+		checkString(c, "routing_number", r.RoutingNumber, true, validation.RoutingNumber)
+		checkEnum(c, "funding", r.Funding)
+	}
+	checkString(c, "company_descriptive_date", r.CompanyDescriptiveDate, false, validation.PrintableASCII, maxLength(achCompanyDescriptiveDateMaxLength))
+	checkString(c, "company_discretionary_data", r.CompanyDiscretionaryData, false, validation.PrintableASCII, maxLength(achCompanyDiscretionaryDataMaxLength))
+	checkString(c, "company_entry_description", r.CompanyEntryDescription, false, validation.PrintableASCII, maxLength(achCompanyEntryDescriptionMaxLength))
+	checkString(c, "company_name", r.CompanyName, false, validation.PrintableASCII, maxLength(achCompanyNameMaxLength))
+	checkString(c, "individual_id", r.IndividualID, false, validation.PrintableASCII, maxLength(achIndividualIDMaxLength))
+	checkString(c, "individual_name", r.IndividualName, false, validation.PrintableASCII, maxLength(achIndividualNameMaxLength))
+	checkEnum(c, "destination_account_holder", r.DestinationAccountHolder)
+	checkEnum(c, "standard_entry_class_code", r.StandardEntryClassCode)
+	checkEnum(c, "transaction_timing", r.TransactionTiming)
+	if checkPresent(c, "preferred_effective_date", r.PreferredEffectiveDate, false) {
+		d := r.PreferredEffectiveDate.Value
+		if d.Date.Present == d.SettlementSchedule.Present {
+			c.Addf("preferred_effective_date", "exactly one of date and settlement_schedule must be set")
+		}
+		checkEnum(c, "preferred_effective_date.settlement_schedule", d.SettlementSchedule)
+	}
+	if checkPresent(c, "addenda", r.Addenda, false) {
+		r.Addenda.Value.validate(c, "addenda", r.StandardEntryClassCode)
+	}
+	return c.Err()
+}
+
+func (r ACHTransferNewParamsAddenda) validate(c *validation.Collector, path string, sec param.Field[ACHTransferNewParamsStandardEntryClassCode]) {
+	checkEnum(c, validation.Join(path, "category"), r.Category)
+	switch r.Category.Value {
+	case ACHTransferNewParamsAddendaCategoryFreeform:
+		freeform := validation.Join(path, "freeform")
+		if !checkPresent(c, freeform, r.Freeform, true) {
+			return
+		}
+		entries := validation.Join(freeform, "entries")
+		if !checkPresent(c, entries, r.Freeform.Value.Entries, true) {
+			return
+		}
+		if len(r.Freeform.Value.Entries.Value) == 0 {
+			c.Addf(entries, "must contain at least one entry")
+		}
+		if len(r.Freeform.Value.Entries.Value) > 1 && sec.Value != ACHTransferNewParamsStandardEntryClassCodeCorporateTradeExchange {
+			c.Addf(entries, "may only contain more than one entry for corporate_trade_exchange transfers")
+		}
+		for i, entry := range r.Freeform.Value.Entries.Value {
+			checkString(c, validation.Join(validation.Index(entries, i), "payment_related_information"), entry.PaymentRelatedInformation, true, validation.PrintableASCII, maxLength(achPaymentRelatedInformationMaxLength))
+		}
+		checkAbsent(c, validation.Join(path, "payment_order_remittance_advice"), r.PaymentOrderRemittanceAdvice, "must be absent when category is freeform")
+	case ACHTransferNewParamsAddendaCategoryPaymentOrderRemittanceAdvice:
+		advice := validation.Join(path, "payment_order_remittance_advice")
+		if !checkPresent(c, advice, r.PaymentOrderRemittanceAdvice, true) {
+			return
+		}
+		invoices := validation.Join(advice, "invoices")
+		if !checkPresent(c, invoices, r.PaymentOrderRemittanceAdvice.Value.Invoices, true) {
+			return
+		}
+		for i, invoice := range r.PaymentOrderRemittanceAdvice.Value.Invoices.Value {
+			p := validation.Index(invoices, i)
+			checkString(c, validation.Join(p, "invoice_number"), invoice.InvoiceNumber, true, validation.PrintableASCII)
+			checkPresent(c, validation.Join(p, "paid_amount"), invoice.PaidAmount, true)
+		}
+		checkAbsent(c, validation.Join(path, "freeform"), r.Freeform, "must be absent when category is payment_order_remittance_advice")
+	}
+}
+
+// Validate performs offline format, length and checksum checks on the
+// parameters, such as the routing number check digit and Fedwire field lengths.
+// It returns a [*ValidationError] describing every invalid parameter, or nil. A
+// nil result does not guarantee that the API will accept the transfer.
+func (r WireTransferNewParams) Validate() error {
+	c := &validation.Collector{}
+	checkString(c, "account_id", r.AccountID, true)
+	if checkPresent(c, "amount", r.Amount, true) && r.Amount.Value <= 0 {
+		c.Addf("amount", "must be positive")
+	}
+	switch {
+	case r.ExternalAccountID.Present:
+		checkString(c, "external_account_id", r.ExternalAccountID, true)
+		checkAbsent(c, "account_number", r.AccountNumber, "must be absent when external_account_id is set")
+		checkAbsent(c, "routing_number", r.RoutingNumber, "must be absent when external_account_id is set")
+	case r.InboundWireDrawdownRequestID.Present:
+		checkString(c, "account_number", r.AccountNumber, false, accountNumber(wireAccountNumberMaxLength))
+		checkString(c, "routing_number", r.RoutingNumber, false, validation.RoutingNumber)
+	default:
+		checkString(c, "account_number", r.AccountNumber, true, accountNumber(wireAccountNumberMaxLength))
+		checkString(c, "routing_number", r.RoutingNumber, true, validation.RoutingNumber)
+	}
+	if checkPresent(c, "creditor", r.Creditor, true) {
+		checkString(c, "creditor.name", r.Creditor.Value.Name, true, validation.PrintableASCII, maxLength(wireNameMaxLength))
+		if checkPresent(c, "creditor.address", r.Creditor.Value.Address, false) {
+			if a := r.Creditor.Value.Address.Value.Unstructured; checkPresent(c, "creditor.address.unstructured", a, false) {
+				checkWireAddress(c, "creditor.address.unstructured", a.Value.Line1, a.Value.Line2, a.Value.Line3)
+			}
+		}
+	}
+	if checkPresent(c, "debtor", r.Debtor, false) {
+		checkString(c, "debtor.name", r.Debtor.Value.Name, true, validation.PrintableASCII, maxLength(wireNameMaxLength))
+		if checkPresent(c, "debtor.address", r.Debtor.Value.Address, false) {
+			if a := r.Debtor.Value.Address.Value.Unstructured; checkPresent(c, "debtor.address.unstructured", a, false) {
+				checkWireAddress(c, "debtor.address.unstructured", a.Value.Line1, a.Value.Line2, a.Value.Line3)
+			}
+		}
+	}
+	if checkPresent(c, "remittance", r.Remittance, true) {
+		rem := r.Remittance.Value
+		checkEnum(c, "remittance.category", rem.Category)
+		switch rem.Category.Value {
+		case WireTransferNewParamsRemittanceCategoryUnstructured:
+			if checkPresent(c, "remittance.unstructured", rem.Unstructured, true) {
+				checkString(c, "remittance.unstructured.message", rem.Unstructured.Value.Message, true, validation.PrintableASCII, maxLength(wireRemittanceMessageMaxLength))
+			}
+			checkAbsent(c, "remittance.tax", rem.Tax, "must be absent when category is unstructured")
+		case WireTransferNewParamsRemittanceCategoryTax:
+			if checkPresent(c, "remittance.tax", rem.Tax, true) {
+				checkPresent(c, "remittance.tax.date", rem.Tax.Value.Date, true)
+				checkString(c, "remittance.tax.identification_number", rem.Tax.Value.IdentificationNumber, true, validation.PrintableASCII, maxLength(wireTaxIdentificationNumberMaxLength))
+				checkString(c, "remittance.tax.type_code", rem.Tax.Value.TypeCode, true, validation.PrintableASCII, exactLength(wireTaxTypeCodeLength))
+			}
+			checkAbsent(c, "remittance.unstructured", rem.Unstructured, "must be absent when category is tax")
+		}
+	}
+	return c.Err()
+}
+
+func checkWireAddress(c *validation.Collector, path string, line1, line2, line3 param.Field[string]) {
+	checkString(c, validation.Join(path, "line1"), line1, true, validation.PrintableASCII, maxLength(wireAddressLineMaxLength))
+	checkString(c, validation.Join(path, "line2"), line2, false, validation.PrintableASCII, maxLength(wireAddressLineMaxLength))
+	checkString(c, validation.Join(path, "line3"), line3, false, validation.PrintableASCII, maxLength(wireAddressLineMaxLength))
+}
+
+// Validate performs offline format, length and checksum checks on the
+// parameters, such as the format of the bank identification code and the IBAN
+// checksum of the account number. It returns a [*ValidationError] describing
+// every invalid parameter, or nil. A nil result does not guarantee that the API
+// will accept the transfer.
+func (r SwiftTransferNewParams) Validate() error {
+	c := &validation.Collector{}
+	checkString(c, "account_id", r.AccountID, true)
+	checkString(c, "source_account_number_id", r.SourceAccountNumberID, true)
+	checkString(c, "bank_identification_code", r.BankIdentificationCode, true, validation.BIC)
+	checkString(c, "intermediary_bank_identification_code", r.IntermediaryBankIdentificationCode, false, validation.BIC)
+	checkString(c, "account_number", r.AccountNumber, true, func(s string) error {
+		if validation.LooksLikeIBAN(s) {
+			return validation.IBAN(s)
+		}
+		return maxLength(swiftAccountNumberMaxLength)(s)
+	})
+	if checkPresent(c, "instructed_amount", r.InstructedAmount, true) && r.InstructedAmount.Value <= 0 {
+		c.Addf("instructed_amount", "must be positive")
+	}
+	if checkPresent(c, "instructed_currency", r.InstructedCurrency, true) {
+		checkEnum(c, "instructed_currency", r.InstructedCurrency)
+	}
+	checkString(c, "creditor_name", r.CreditorName, true, validation.PrintableASCII, maxLength(swiftNameMaxLength))
+	checkString(c, "debtor_name", r.DebtorName, true, validation.PrintableASCII, maxLength(swiftNameMaxLength))
+	if a := r.CreditorAddress; checkPresent(c, "creditor_address", a, true) {
+		checkSwiftAddress(c, "creditor_address", a.Value.Line1, a.Value.Line2, a.Value.City, a.Value.State, a.Value.PostalCode, a.Value.Country)
+	}
+	if a := r.DebtorAddress; checkPresent(c, "debtor_address", a, true) {
+		checkSwiftAddress(c, "debtor_address", a.Value.Line1, a.Value.Line2, a.Value.City, a.Value.State, a.Value.PostalCode, a.Value.Country)
+	}
+	checkString(c, "unstructured_remittance_information", r.UnstructuredRemittanceInformation, true, validation.PrintableASCII, maxLength(swiftRemittanceMaxLength))
+	checkString(c, "routing_number", r.RoutingNumber, false, validation.PrintableASCII)
+	return c.Err()
+}
+
+func checkSwiftAddress(c *validation.Collector, path string, line1, line2, city, state, postalCode, country param.Field[string]) {
+	checkString(c, validation.Join(path, "line1"), line1, true, validation.PrintableASCII, maxLength(swiftAddressLineMaxLength))
+	checkString(c, validation.Join(path, "line2"), line2, false, validation.PrintableASCII, maxLength(swiftAddressLineMaxLength))
+	checkString(c, validation.Join(path, "city"), city, true, validation.PrintableASCII, maxLength(swiftAddressLineMaxLength))
+	checkString(c, validation.Join(path, "state"), state, false, validation.PrintableASCII, maxLength(swiftAddressLineMaxLength))
+	checkString(c, validation.Join(path, "postal_code"), postalCode, false, validation.PrintableASCII, maxLength(swiftAddressLineMaxLength))
+	checkString(c, validation.Join(path, "country"), country, true, validation.Country)
+}
+
+// Validate performs offline format and checksum checks on the parameters, such
+// as the routing number check digit. It returns a [*ValidationError] describing
+// every invalid parameter, or nil.
+func (r ExternalAccountNewParams) Validate() error {
+	c := &validation.Collector{}
+	// External Accounts are used for ACH transfers.
+	checkString(c, "account_number", r.AccountNumber, true, accountNumber(achAccountNumberMaxLength))
+	checkString(c, "routing_number", r.RoutingNumber, true, validation.RoutingNumber)
+	checkString(c, "description", r.Description, true, maxLength(statementDescriptorMaxLength))
+	checkEnum(c, "account_holder", r.AccountHolder)
+	checkEnum(c, "funding", r.Funding)
+	return c.Err()
+}
+
+// checkPresent records an error for a required field that is missing or null,
+// and reports whether the field holds a value that can be inspected further.
+// Fields set with [Raw] are never inspected.
+func checkPresent[T any](c *validation.Collector, path string, f param.Field[T], required bool) bool {
+	switch {
+	case !f.Present:
+		if required {
+			c.Addf(path, "is required")
+		}
+		return false
+	case f.Null:
+		if required {
+			c.Addf(path, "must not be null")
+		}
+		return false
+	case f.Raw != nil:
+		return false
+	}
+	return true
+}
+
+// checkString runs checks against a string field, stopping at the first
+// failure. Required fields must also be non-blank.
+func checkString(c *validation.Collector, path string, f param.Field[string], required bool, checks ...func(string) error) {
+	if !checkPresent(c, path, f, required) {
+		return
+	}
+	if required {
+		checks = append([]func(string) error{maxLength(0)}, checks...)
+	}
+	for _, check := range checks {
+		if err := check(f.Value); err != nil {
+			c.Check(path, err)
+			return
+		}
+	}
+}
+
+func checkEnum[T interface{ IsKnown() bool }](c *validation.Collector, path string, f param.Field[T]) {
+	if checkPresent(c, path, f, false) && !f.Value.IsKnown() {
+		c.Addf(path, "has unsupported value %q", f.String())
+	}
+}
+
+func checkAbsent[T any](c *validation.Collector, path string, f param.Field[T], message string) {
+	if f.Present {
+		c.Addf(path, "%s", message)
+	}
+}
+
+func maxLength(max int) func(string) error {
+	return func(s string) error { return validation.Length(s, 1, max) }
+}
+
+func exactLength(n int) func(string) error {
+	return func(s string) error { return validation.Length(s, n, n) }
+}
+
+func accountNumber(max int) func(string) error {
+	return func(s string) error { return validation.AccountNumber(s, max) }
+}
@@ -0,0 +1,157 @@
+package increase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Increase/increase-go"
+)
+
+func TestACHTransferNewParamsValidate(t *testing.T) {
+	params := increase.ACHTransferNewParams{
+		AccountID:               increase.F("account_in71c4amph0vgo2qllky"),
+		Amount:                  increase.F(int64(100)),
+		StatementDescriptor:     increase.F("New ACH transfer"),
+		AccountNumber:           increase.F("987654321"),
+		RoutingNumber:           increase.F("101050001"),
+		CompanyEntryDescription: increase.F("PAYROLL"),
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("expected valid params, got %s", err)
+	}
+
+	params.RoutingNumber = increase.F("101050002")
+	params.CompanyEntryDescription = increase.F("PAYROLL FOR MAY")
+	params.IndividualName = increase.F("Zoë")
+	params.StatementDescriptor = increase.F("Café rent")
+	err := params.Validate()
+	var verr *increase.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *increase.ValidationError, got %v", err)
+	}
+	for _, field := range []string{"routing_number", "company_entry_description", "individual_name", "statement_descriptor"} {
+		if _, ok := verr.Field(field); !ok {
+			t.Errorf("expected an error for %s, got %s", field, err)
+		}
+	}
+	if len(verr.Fields) != 4 {
+		t.Errorf("expected 4 field errors, got %d: %s", len(verr.Fields), err)
+	}
+}
+
+func TestACHTransferNewParamsValidateExternalAccount(t *testing.T) {
+	params := increase.ACHTransferNewParams{
+		AccountID:           increase.F("account_in71c4amph0vgo2qllky"),
+		Amount:              increase.F(int64(-100)),
+		StatementDescriptor: increase.F("Invoice 123"),
+		ExternalAccountID:   increase.F("external_account_ukk55lr923a3ac0pp7iv"),
+		RoutingNumber:       increase.F("101050001"),
+	}
+	err := params.Validate()
+	if err == nil || !strings.Contains(err.Error(), "routing_number: must be absent") {
+		t.Fatalf("expected routing_number to be rejected, got %v", err)
+	}
+}
+
+func TestWireTransferNewParamsValidate(t *testing.T) {
+	params := increase.WireTransferNewParams{
+		AccountID:     increase.F("account_in71c4amph0vgo2qllky"),
+		Amount:        increase.F(int64(100)),
+		AccountNumber: increase.F("987654321"),
+		RoutingNumber: increase.F("101050001"),
+		Creditor: increase.F(increase.WireTransferNewParamsCreditor{
+			Name: increase.F("Ian Crease"),
+		}),
+		Remittance: increase.F(increase.WireTransferNewParamsRemittance{
+			Category: increase.F(increase.WireTransferNewParamsRemittanceCategoryUnstructured),
+			Unstructured: increase.F(increase.WireTransferNewParamsRemittanceUnstructured{
+				Message: increase.F("New account transfer"),
+			}),
+		}),
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("expected valid params, got %s", err)
+	}
+
+	params.Remittance = increase.F(increase.WireTransferNewParamsRemittance{
+		Category: increase.F(increase.WireTransferNewParamsRemittanceCategoryTax),
+	})
+	params.Creditor = increase.F(increase.WireTransferNewParamsCreditor{
+		Name: increase.F(strings.Repeat("x", 36)),
+	})
+	var verr *increase.ValidationError
+	if !errors.As(params.Validate(), &verr) {
+		t.Fatalf("expected *increase.ValidationError")
+	}
+	for _, field := range []string{"remittance.tax", "creditor.name"} {
+		if _, ok := verr.Field(field); !ok {
+			t.Errorf("expected an error for %s, got %s", field, verr)
+		}
+	}
+}
+
+func TestSwiftTransferNewParamsValidate(t *testing.T) {
+	address := increase.SwiftTransferNewParamsCreditorAddress{
+		City:    increase.F("Frankfurt"),
+		Country: increase.F("DE"),
+		Line1:   increase.F("Taunusanlage 12"),
+	}
+	params := increase.SwiftTransferNewParams{
+		AccountID:              increase.F("account_in71c4amph0vgo2qllky"),
+		AccountNumber:          increase.F("DE89370400440532013000"),
+		BankIdentificationCode: increase.F("DEUTDEFF"),
+		CreditorAddress:        increase.F(address),
+		CreditorName:           increase.F("Ian Crease"),
+		DebtorAddress: increase.F(increase.SwiftTransferNewParamsDebtorAddress{
+			City:    increase.F("New York"),
+			Country: increase.F("US"),
+			Line1:   increase.F("33 Liberty Street"),
+		}),
+		DebtorName:                        increase.F("Ian Crease"),
+		InstructedAmount:                  increase.F(int64(100)),
+		InstructedCurrency:                increase.F(increase.SwiftTransferNewParamsInstructedCurrencyUsd),
+		SourceAccountNumberID:             increase.F("account_number_v18nkfqm6afpsrvy82b2"),
+		UnstructuredRemittanceInformation: increase.F("New Swift transfer"),
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("expected valid params, got %s", err)
+	}
+
+	params.AccountNumber = increase.F("DE89370400440532013001")
+	params.BankIdentificationCode = increase.F("DEUTDEF")
+	params.InstructedCurrency = increase.F(increase.SwiftTransferNewParamsInstructedCurrency("EUR"))
+	address.Country = increase.F("Germany")
+	params.CreditorAddress = increase.F(address)
+	var verr *increase.ValidationError
+	if !errors.As(params.Validate(), &verr) {
+		t.Fatalf("expected *increase.ValidationError")
+	}
+	for _, field := range []string{"account_number", "bank_identification_code", "instructed_currency", "creditor_address.country"} {
+		if _, ok := verr.Field(field); !ok {
+			t.Errorf("expected an error for %s, got %s", field, verr)
+		}
+	}
+}
+
+func TestExternalAccountNewParamsValidate(t *testing.T) {
+	params := increase.ExternalAccountNewParams{
+		AccountNumber: increase.F("987654321"),
+		Description:   increase.F("Landlord"),
+		RoutingNumber: increase.F("101050001"),
+	}
+	if err := params.Validate(); err != nil {
+		t.Fatalf("expected valid params, got %s", err)
+	}
+	params.RoutingNumber = increase.F("1010500")
+	params.Description = increase.F("")
+	// Wire account numbers may be longer than ACH ones.
+	params.AccountNumber = increase.F("123456789012345678")
+	var verr *increase.ValidationError
+	if !errors.As(params.Validate(), &verr) || len(verr.Fields) != 3 {
+		t.Fatalf("expected three field errors, got %v", verr)
+	}
+	if _, ok := verr.Field("account_number"); !ok {
+		t.Errorf("expected an error for account_number, got %s", verr)
+	}
+}